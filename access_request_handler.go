@@ -65,16 +65,45 @@ func (f *Fosite) NewAccessRequest(ctx context.Context, r *http.Request, session
 	}
 
 	accessRequest.SetRequestedScopes(RemoveEmpty(strings.Split(r.PostForm.Get("scope"), " ")))
-	accessRequest.SetRequestedAudience(GetAudiences(r.PostForm))
+	accessRequest.SetRequestedAudience(append(GetAudiences(r.PostForm), GetResources(r.PostForm)...))
 	accessRequest.GrantTypes = RemoveEmpty(strings.Split(r.PostForm.Get("grant_type"), " "))
 	if len(accessRequest.GrantTypes) < 1 {
 		return accessRequest, errorsx.WithStack(ErrInvalidRequest.WithHint("Request parameter 'grant_type' is missing"))
 	}
 
+	// DPoP proofs are validated before the handlers are called, so that they can access the key binding through the
+	// session: https://www.rfc-editor.org/rfc/rfc9449#section-5
+	jkt, err := f.validateDPoPProof(ctx, r, f.dpopTokenEndpointURIs(ctx, r), "")
+	if err != nil {
+		return accessRequest, err
+	}
+	if s, ok := session.(DPoPSession); ok && jkt != "" {
+		s.SetDPoPJWKThumbprint(jkt)
+	}
+
 	client, clientErr := f.AuthenticateClient(ctx, r, r.PostForm)
+	var x5t string
 	if clientErr == nil {
 		accessRequest.Client = client
+
+		// Access tokens are bound to the client certificate: https://www.rfc-editor.org/rfc/rfc8705#section-3
+		if x5t, err = f.certificateThumbprintForClient(ctx, r, client); err != nil {
+			return accessRequest, err
+		}
+		if s, ok := session.(CertificateBoundSession); ok && x5t != "" {
+			s.SetCertificateThumbprint(x5t)
+		}
+
+		if err := f.validateResources(ctx, client, GetResources(r.PostForm)); err != nil {
+			return accessRequest, err
+		}
+	}
+
+	details, err := f.parseAuthorizationDetails(ctx, accessRequest.GetClient(), r.PostForm)
+	if err != nil {
+		return accessRequest, err
 	}
+	accessRequest.SetRequestedAuthorizationDetails(details)
 
 	var found = false
 	for _, loader := range f.Config.GetTokenEndpointHandlers(ctx) {
@@ -107,5 +136,15 @@ func (f *Fosite) NewAccessRequest(ctx context.Context, r *http.Request, session
 	if !found {
 		return nil, errorsx.WithStack(ErrInvalidRequest)
 	}
+
+	// Handlers may have replaced the session with the one stored during a previous request, which is bound to
+	// the key of this request's DPoP proof and client certificate, if any.
+	if s, ok := accessRequest.GetSession().(DPoPSession); ok {
+		s.SetDPoPJWKThumbprint(jkt)
+	}
+	if s, ok := accessRequest.GetSession().(CertificateBoundSession); ok {
+		s.SetCertificateThumbprint(x5t)
+	}
+
 	return accessRequest, nil
 }
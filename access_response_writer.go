@@ -41,5 +41,11 @@ func (f *Fosite) NewAccessResponse(ctx context.Context, requester AccessRequeste
 			WithLocalizer(f.Config.GetMessageCatalog(ctx), getLangFromRequester(requester)))
 	}
 
+	// The granted authorization details are returned in the token response:
+	// https://www.rfc-editor.org/rfc/rfc9396#section-7
+	if requester != nil && len(requester.GetGrantedAuthorizationDetails()) > 0 {
+		response.SetExtra("authorization_details", requester.GetGrantedAuthorizationDetails())
+	}
+
 	return response, nil
 }
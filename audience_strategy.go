@@ -92,6 +92,34 @@ func GetAudiences(form url.Values) []string {
 	}
 }
 
+// GetResources returns the resource indicators of the repeated "resource" form parameter as specified in
+// https://www.rfc-editor.org/rfc/rfc8707#section-2.
+func GetResources(form url.Values) []string {
+	return RemoveEmpty(form["resource"])
+}
+
+// validateResources validates that each resource indicator is an absolute URI without a fragment component and that
+// the client is allowed to request it. Resources are audiences, which is why they are matched against the audiences
+// of the client using the audience matching strategy.
+func (f *Fosite) validateResources(ctx context.Context, client Client, resources []string) error {
+	for _, resource := range resources {
+		u, err := url.Parse(resource)
+		if err != nil {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf("Unable to parse requested resource '%s'.", resource).WithWrap(err).WithDebug(err.Error()))
+		} else if !u.IsAbs() {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf("Requested resource '%s' must be an absolute URI.", resource))
+		} else if u.Fragment != "" || strings.Contains(resource, "#") {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf("Requested resource '%s' must not include a fragment component.", resource))
+		}
+
+		if err := f.Config.GetAudienceStrategy(ctx)(client.GetAudience(), []string{resource}); err != nil {
+			return errorsx.WithStack(ErrInvalidTarget.WithHintf("Requested resource '%s' has not been whitelisted by the OAuth 2.0 Client.", resource).WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	return nil
+}
+
 func (f *Fosite) validateAuthorizeAudience(ctx context.Context, r *http.Request, request *AuthorizeRequest) error {
 	audience := GetAudiences(request.Form)
 
@@ -99,6 +127,11 @@ func (f *Fosite) validateAuthorizeAudience(ctx context.Context, r *http.Request,
 		return err
 	}
 
-	request.SetRequestedAudience(audience)
+	resources := GetResources(request.Form)
+	if err := f.validateResources(ctx, request.Client, resources); err != nil {
+		return err
+	}
+
+	request.SetRequestedAudience(append(audience, resources...))
 	return nil
 }
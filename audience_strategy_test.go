@@ -4,7 +4,9 @@
 package fosite
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -255,3 +257,32 @@ func TestExactAudienceMatchingStrategy(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateResources(t *testing.T) {
+	f := &Fosite{Config: &Config{}}
+	client := &DefaultClient{Audience: []string{"https://api.example.com"}}
+
+	for k, tc := range []struct {
+		resources []string
+		err       bool
+	}{
+		{resources: []string{}},
+		{resources: []string{"https://api.example.com"}},
+		{resources: []string{"https://api.example.com/payments"}},
+		{resources: []string{"https://api.example.com", "https://other.example.com"}, err: true},
+		{resources: []string{"/payments"}, err: true},
+		{resources: []string{"https://api.example.com#payments"}, err: true},
+		{resources: []string{"https://api.example.com#"}, err: true},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			err := f.validateResources(context.Background(), client, tc.resources)
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidTarget)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	require.Equal(t, []string{"https://api.example.com", "https://files.example.com"}, GetResources(url.Values{"resource": {"https://api.example.com", "", "https://files.example.com"}}))
+}
@@ -0,0 +1,182 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+)
+
+// AuthorizationDetail is an entry of the "authorization_details" request parameter as specified in
+// https://www.rfc-editor.org/rfc/rfc9396#section-2.
+type AuthorizationDetail struct {
+	// Type is the identifier of the authorization details type.
+	Type string
+
+	// Locations, Actions, DataTypes, Identifier and Privileges are the common data fields of
+	// https://www.rfc-editor.org/rfc/rfc9396#section-2.2.
+	Locations  []string
+	Actions    []string
+	DataTypes  []string
+	Identifier string
+	Privileges []string
+
+	// Extra contains the fields specific to the authorization details type.
+	Extra map[string]interface{}
+}
+
+// AuthorizationDetails is the list of authorization details of a request.
+type AuthorizationDetails []AuthorizationDetail
+
+// AuthorizationDetailValidator validates an authorization detail requested by the client. It may modify the
+// authorization detail, for example to enrich it with default values.
+type AuthorizationDetailValidator func(ctx context.Context, client Client, detail *AuthorizationDetail) error
+
+// AuthorizationDetailValidators maps authorization details types to their validators. Authorization details of a
+// type without a validator are rejected.
+type AuthorizationDetailValidators map[string]AuthorizationDetailValidator
+
+var authorizationDetailCommonFields = map[string]bool{
+	"type": true, "locations": true, "actions": true, "datatypes": true, "identifier": true, "privileges": true,
+}
+
+func (d AuthorizationDetail) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{}, len(d.Extra)+6)
+	for k, v := range d.Extra {
+		if !authorizationDetailCommonFields[k] {
+			out[k] = v
+		}
+	}
+
+	out["type"] = d.Type
+	if len(d.Locations) > 0 {
+		out["locations"] = d.Locations
+	}
+	if len(d.Actions) > 0 {
+		out["actions"] = d.Actions
+	}
+	if len(d.DataTypes) > 0 {
+		out["datatypes"] = d.DataTypes
+	}
+	if d.Identifier != "" {
+		out["identifier"] = d.Identifier
+	}
+	if len(d.Privileges) > 0 {
+		out["privileges"] = d.Privileges
+	}
+
+	return json.Marshal(out)
+}
+
+func (d *AuthorizationDetail) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var detail AuthorizationDetail
+	for k, raw := range fields {
+		var err error
+		switch k {
+		case "type":
+			err = json.Unmarshal(raw, &detail.Type)
+		case "locations":
+			err = json.Unmarshal(raw, &detail.Locations)
+		case "actions":
+			err = json.Unmarshal(raw, &detail.Actions)
+		case "datatypes":
+			err = json.Unmarshal(raw, &detail.DataTypes)
+		case "identifier":
+			err = json.Unmarshal(raw, &detail.Identifier)
+		case "privileges":
+			err = json.Unmarshal(raw, &detail.Privileges)
+		default:
+			var value interface{}
+			err = json.Unmarshal(raw, &value)
+			if detail.Extra == nil {
+				detail.Extra = map[string]interface{}{}
+			}
+			detail.Extra[k] = value
+		}
+		if err != nil {
+			return errors.Wrapf(err, "unable to decode field '%s' of the authorization detail", k)
+		}
+	}
+
+	*d = detail
+	return nil
+}
+
+// Equals returns true if both authorization details have the same JSON representation.
+func (d AuthorizationDetail) Equals(other AuthorizationDetail) bool {
+	a, err := json.Marshal(d)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(other)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(a, b)
+}
+
+// Has returns true if the list contains the authorization detail.
+func (d AuthorizationDetails) Has(detail AuthorizationDetail) bool {
+	for _, has := range d {
+		if has.Equals(detail) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizationDetailValidators returns the validators of the supported authorization details types, or nil if the
+// configuration does not implement AuthorizationDetailValidatorsProvider.
+func (f *Fosite) authorizationDetailValidators(ctx context.Context) AuthorizationDetailValidators {
+	if c, ok := f.Config.(AuthorizationDetailValidatorsProvider); ok {
+		return c.GetAuthorizationDetailValidators(ctx)
+	}
+	return nil
+}
+
+// parseAuthorizationDetails parses the "authorization_details" request parameter and validates each entry using the
+// validator registered for its type, see https://www.rfc-editor.org/rfc/rfc9396#section-5
+func (f *Fosite) parseAuthorizationDetails(ctx context.Context, client Client, form url.Values) (AuthorizationDetails, error) {
+	raw := form.Get("authorization_details")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var details AuthorizationDetails
+	if err := json.Unmarshal([]byte(raw), &details); err != nil {
+		return nil, errorsx.WithStack(ErrInvalidAuthorizationDetails.WithHint("The 'authorization_details' parameter must be a JSON array of JSON objects.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	validators := f.authorizationDetailValidators(ctx)
+	for i := range details {
+		if details[i].Type == "" {
+			return nil, errorsx.WithStack(ErrInvalidAuthorizationDetails.WithHint("Each authorization detail must contain the 'type' field."))
+		}
+
+		validate, ok := validators[details[i].Type]
+		if !ok {
+			return nil, errorsx.WithStack(ErrInvalidAuthorizationDetails.WithHintf("The authorization details type '%s' is not supported.", details[i].Type))
+		}
+
+		if err := validate(ctx, client, &details[i]); err != nil {
+			var rfcerr *RFC6749Error
+			if errors.As(err, &rfcerr) {
+				return nil, err
+			}
+			return nil, errorsx.WithStack(ErrInvalidAuthorizationDetails.WithHintf("The authorization detail of type '%s' is invalid.", details[i].Type).WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	return details, nil
+}
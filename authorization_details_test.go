@@ -0,0 +1,155 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/internal"
+	"github.com/ory/fosite/storage"
+)
+
+func TestAuthorizationDetailJSON(t *testing.T) {
+	raw := `{"type":"payment_initiation","actions":["initiate","status"],"locations":["https://example.com/payments"],"instructedAmount":{"currency":"EUR","amount":"123.50"},"creditorName":"Merchant A"}`
+
+	var detail AuthorizationDetail
+	require.NoError(t, json.Unmarshal([]byte(raw), &detail))
+	assert.Equal(t, "payment_initiation", detail.Type)
+	assert.Equal(t, []string{"initiate", "status"}, detail.Actions)
+	assert.Equal(t, []string{"https://example.com/payments"}, detail.Locations)
+	assert.Equal(t, "Merchant A", detail.Extra["creditorName"])
+	assert.Equal(t, map[string]interface{}{"currency": "EUR", "amount": "123.50"}, detail.Extra["instructedAmount"])
+
+	out, err := json.Marshal(detail)
+	require.NoError(t, err)
+	assert.JSONEq(t, raw, string(out))
+
+	var other AuthorizationDetail
+	require.NoError(t, json.Unmarshal(out, &other))
+	assert.True(t, detail.Equals(other))
+	assert.True(t, AuthorizationDetails{other}.Has(detail))
+
+	other.Extra["creditorName"] = "Merchant B"
+	assert.False(t, AuthorizationDetails{other}.Has(detail))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"payment_initiation","actions":"initiate"}`), &detail))
+}
+
+func TestNewAccessRequestWithAuthorizationDetails(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	f := &Fosite{Store: storage.NewMemoryStore(), Config: &Config{
+		TokenEndpointHandlers: TokenEndpointHandlers{handler},
+		AuthorizationDetailValidators: AuthorizationDetailValidators{
+			"payment_initiation": func(_ context.Context, _ Client, detail *AuthorizationDetail) error {
+				if _, ok := detail.Extra["instructedAmount"]; !ok {
+					return errors.New("the instructed amount is missing")
+				}
+				if len(detail.Actions) == 0 {
+					detail.Actions = []string{"initiate"}
+				}
+				return nil
+			},
+		},
+	}}
+
+	newRequest := func(details string) *http.Request {
+		form := url.Values{"grant_type": {"client_credentials"}}
+		if details != "" {
+			form.Set("authorization_details", details)
+		}
+		r, err := http.NewRequest("POST", "https://auth.example.com/oauth2/token", strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	for k, tc := range []struct {
+		d         string
+		details   string
+		expect    AuthorizationDetails
+		expectErr error
+	}{
+		{
+			d: "should pass without authorization details",
+		},
+		{
+			d:       "should pass and apply the validator",
+			details: `[{"type":"payment_initiation","instructedAmount":{"currency":"EUR","amount":"123.50"}}]`,
+			expect: AuthorizationDetails{{
+				Type:    "payment_initiation",
+				Actions: []string{"initiate"},
+				Extra:   map[string]interface{}{"instructedAmount": map[string]interface{}{"currency": "EUR", "amount": "123.50"}},
+			}},
+		},
+		{
+			d:         "should fail with malformed authorization details",
+			details:   `{"type":"payment_initiation"}`,
+			expectErr: ErrInvalidAuthorizationDetails,
+		},
+		{
+			d:         "should fail without a type",
+			details:   `[{"actions":["initiate"]}]`,
+			expectErr: ErrInvalidAuthorizationDetails,
+		},
+		{
+			d:         "should fail with an unknown type",
+			details:   `[{"type":"account_information"}]`,
+			expectErr: ErrInvalidAuthorizationDetails,
+		},
+		{
+			d:         "should fail if the validator rejects the authorization detail",
+			details:   `[{"type":"payment_initiation"}]`,
+			expectErr: ErrInvalidAuthorizationDetails,
+		},
+	} {
+		t.Run("case="+tc.d, func(t *testing.T) {
+			ar, err := f.NewAccessRequest(ctx, newRequest(tc.details), new(DefaultSession))
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr, "%d", k)
+				return
+			}
+			require.NoError(t, err, "%d", k)
+			assert.Equal(t, tc.expect, ar.GetRequestedAuthorizationDetails())
+		})
+	}
+}
+
+func TestNewAccessResponseWithAuthorizationDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().PopulateTokenEndpointResponse(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ context.Context, _ AccessRequester, resp AccessResponder) {
+		resp.SetAccessToken("foo")
+		resp.SetTokenType("bearer")
+	}).Return(nil)
+
+	f := &Fosite{Config: &Config{TokenEndpointHandlers: TokenEndpointHandlers{handler}}}
+	details := AuthorizationDetails{{Type: "payment_initiation", Actions: []string{"initiate"}}}
+	ar := NewAccessRequest(new(DefaultSession))
+	ar.GrantAuthorizationDetails(details)
+
+	resp, err := f.NewAccessResponse(context.Background(), ar)
+	require.NoError(t, err)
+	assert.Equal(t, details, resp.GetExtra("authorization_details"))
+}
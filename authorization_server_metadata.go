@@ -0,0 +1,398 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite/token/jwt"
+)
+
+// AuthorizationServerMetadata is the authorization server metadata of https://www.rfc-editor.org/rfc/rfc8414#section-2
+// and the OpenID Provider metadata of https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata.
+type AuthorizationServerMetadata struct {
+	Issuer                                                    string   `json:"issuer,omitempty"`
+	AuthorizationEndpoint                                     string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                                             string   `json:"token_endpoint,omitempty"`
+	UserinfoEndpoint                                          string   `json:"userinfo_endpoint,omitempty"`
+	JSONWebKeysURI                                            string   `json:"jwks_uri,omitempty"`
+	RegistrationEndpoint                                      string   `json:"registration_endpoint,omitempty"`
+	ScopesSupported                                           []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported                                    []string `json:"response_types_supported,omitempty"`
+	ResponseModesSupported                                    []string `json:"response_modes_supported,omitempty"`
+	GrantTypesSupported                                       []string `json:"grant_types_supported,omitempty"`
+	SubjectTypesSupported                                     []string `json:"subject_types_supported,omitempty"`
+	IDTokenSigningAlgValuesSupported                          []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	ClaimsSupported                                           []string `json:"claims_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported                         []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	TokenEndpointAuthSigningAlgValuesSupported                []string `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
+	ServiceDocumentation                                      string   `json:"service_documentation,omitempty"`
+	UILocalesSupported                                        []string `json:"ui_locales_supported,omitempty"`
+	OPPolicyURI                                               string   `json:"op_policy_uri,omitempty"`
+	OPTermsOfServiceURI                                       string   `json:"op_tos_uri,omitempty"`
+	RevocationEndpoint                                        string   `json:"revocation_endpoint,omitempty"`
+	IntrospectionEndpoint                                     string   `json:"introspection_endpoint,omitempty"`
+	IntrospectionSigningAlgValuesSupported                    []string `json:"introspection_signing_alg_values_supported,omitempty"`
+	CodeChallengeMethodsSupported                             []string `json:"code_challenge_methods_supported,omitempty"`
+	ClaimsParameterSupported                                  bool     `json:"claims_parameter_supported,omitempty"`
+	RequestParameterSupported                                 bool     `json:"request_parameter_supported,omitempty"`
+	RequestURIParameterSupported                              bool     `json:"request_uri_parameter_supported,omitempty"`
+	RequestObjectSigningAlgValuesSupported                    []string `json:"request_object_signing_alg_values_supported,omitempty"`
+	RequestObjectEncryptionAlgValuesSupported                 []string `json:"request_object_encryption_alg_values_supported,omitempty"`
+	RequestObjectEncryptionEncValuesSupported                 []string `json:"request_object_encryption_enc_values_supported,omitempty"`
+	PushedAuthorizationRequestEndpoint                        string   `json:"pushed_authorization_request_endpoint,omitempty"`
+	RequirePushedAuthorizationRequests                        bool     `json:"require_pushed_authorization_requests,omitempty"`
+	DeviceAuthorizationEndpoint                               string   `json:"device_authorization_endpoint,omitempty"`
+	DPoPSigningAlgValuesSupported                             []string `json:"dpop_signing_alg_values_supported,omitempty"`
+	TLSClientCertificateBoundAccessTokens                     bool     `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+	AuthorizationSigningAlgValuesSupported                    []string `json:"authorization_signing_alg_values_supported,omitempty"`
+	AuthorizationDetailsTypesSupported                        []string `json:"authorization_details_types_supported,omitempty"`
+	UserinfoSigningAlgValuesSupported                         []string `json:"userinfo_signing_alg_values_supported,omitempty"`
+	EndSessionEndpoint                                        string   `json:"end_session_endpoint,omitempty"`
+	BackchannelAuthenticationEndpoint                         string   `json:"backchannel_authentication_endpoint,omitempty"`
+	BackchannelTokenDeliveryModesSupported                    []string `json:"backchannel_token_delivery_modes_supported,omitempty"`
+	BackchannelAuthenticationRequestSigningAlgValuesSupported []string `json:"backchannel_authentication_request_signing_alg_values_supported,omitempty"`
+	BackchannelUserCodeParameterSupported                     bool     `json:"backchannel_user_code_parameter_supported,omitempty"`
+	AuthorizationResponseIssParameterSupported                bool     `json:"authorization_response_iss_parameter_supported,omitempty"`
+
+	// Extra contains metadata fosite does not know about, for example of extensions implemented by the application.
+	Extra map[string]interface{} `json:"-"`
+}
+
+type authorizationServerMetadata AuthorizationServerMetadata
+
+// asymmetricSigningAlgorithms are the JWS algorithms fosite verifies client assertions and request objects with.
+var asymmetricSigningAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// keyEncryptionAlgorithms and contentEncryptionAlgorithms are the JWE algorithms fosite decrypts request objects with.
+var (
+	keyEncryptionAlgorithms     = []string{"RSA-OAEP", "RSA-OAEP-256", "ECDH-ES", "ECDH-ES+A128KW", "ECDH-ES+A192KW", "ECDH-ES+A256KW"}
+	contentEncryptionAlgorithms = []string{"A128CBC-HS256", "A192CBC-HS384", "A256CBC-HS512", "A128GCM", "A192GCM", "A256GCM"}
+)
+
+func (m AuthorizationServerMetadata) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(authorizationServerMetadata(m))
+	if err != nil || len(m.Extra) == 0 {
+		return raw, err
+	}
+
+	out := make(map[string]interface{}, len(m.Extra))
+	for k, v := range m.Extra {
+		out[k] = v
+	}
+	// The known fields take precedence over the extra fields.
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return json.Marshal(out)
+}
+
+// AddResponseTypes adds the response types to the supported response types, unless they are already present.
+func (m *AuthorizationServerMetadata) AddResponseTypes(responseTypes ...string) {
+	m.ResponseTypesSupported = appendUnique(m.ResponseTypesSupported, responseTypes...)
+}
+
+// AddGrantTypes adds the grant types to the supported grant types, unless they are already present.
+func (m *AuthorizationServerMetadata) AddGrantTypes(grantTypes ...string) {
+	m.GrantTypesSupported = appendUnique(m.GrantTypesSupported, grantTypes...)
+}
+
+// AddScopes adds the scopes to the supported scopes, unless they are already present.
+func (m *AuthorizationServerMetadata) AddScopes(scopes ...string) {
+	m.ScopesSupported = appendUnique(m.ScopesSupported, scopes...)
+}
+
+// AddSubjectTypes adds the subject identifier types to the supported subject types, unless they are already present.
+func (m *AuthorizationServerMetadata) AddSubjectTypes(subjectTypes ...string) {
+	m.SubjectTypesSupported = appendUnique(m.SubjectTypesSupported, subjectTypes...)
+}
+
+// AddCodeChallengeMethods adds the PKCE code challenge methods to the supported methods, unless they are already
+// present.
+func (m *AuthorizationServerMetadata) AddCodeChallengeMethods(methods ...string) {
+	m.CodeChallengeMethodsSupported = appendUnique(m.CodeChallengeMethodsSupported, methods...)
+}
+
+// userinfoURL returns the URL of the UserInfo endpoint, or an empty string if the configuration does not implement
+// UserinfoProvider.
+func (f *Fosite) userinfoURL(ctx context.Context) string {
+	if c, ok := f.Config.(UserinfoProvider); ok {
+		return c.GetUserinfoURL(ctx)
+	}
+	return ""
+}
+
+// endSessionURL returns the URL of the end session endpoint, or an empty string if the configuration does not
+// implement EndSessionProvider.
+func (f *Fosite) endSessionURL(ctx context.Context) string {
+	if c, ok := f.Config.(EndSessionProvider); ok {
+		return c.GetEndSessionURL(ctx)
+	}
+	return ""
+}
+
+// backchannelAuthenticationURL returns the URL of the backchannel authentication endpoint, or an empty string if the
+// configuration does not implement BackchannelAuthenticationProvider.
+func (f *Fosite) backchannelAuthenticationURL(ctx context.Context) string {
+	if c, ok := f.Config.(BackchannelAuthenticationProvider); ok {
+		return c.GetBackchannelAuthenticationURL(ctx)
+	}
+	return ""
+}
+
+// authorizationServerMetadataOverrides returns the configured metadata overrides, or nil if the configuration does not
+// implement AuthorizationServerMetadataProvider.
+func (f *Fosite) authorizationServerMetadataOverrides(ctx context.Context) *AuthorizationServerMetadata {
+	if c, ok := f.Config.(AuthorizationServerMetadataProvider); ok {
+		return c.GetAuthorizationServerMetadataOverrides(ctx)
+	}
+	return nil
+}
+
+// applyOverrides replaces every field of the metadata which is set in the overrides.
+func (m *AuthorizationServerMetadata) applyOverrides(overrides *AuthorizationServerMetadata) {
+	if overrides == nil {
+		return
+	}
+
+	target := reflect.ValueOf(m).Elem()
+	source := reflect.ValueOf(overrides).Elem()
+	for i := 0; i < source.NumField(); i++ {
+		if field := source.Field(i); !field.IsZero() && source.Type().Field(i).Name != "Extra" {
+			target.Field(i).Set(field)
+		}
+	}
+
+	for k, v := range overrides.Extra {
+		if m.Extra == nil {
+			m.Extra = map[string]interface{}{}
+		}
+		m.Extra[k] = v
+	}
+}
+
+// NewAuthorizationServerMetadata generates the authorization server metadata from the configuration. Handlers
+// contribute the capabilities they implement, such as the supported grant and response types, by implementing
+// AuthorizationServerMetadataHandler. Metadata which can not be inferred, such as the URLs of endpoints fosite does
+// not know about, are set using the AuthorizationServerMetadataProvider.
+func (f *Fosite) NewAuthorizationServerMetadata(ctx context.Context) (*AuthorizationServerMetadata, error) {
+	metadata := &AuthorizationServerMetadata{
+		Issuer:                                f.Config.GetAccessTokenIssuer(ctx),
+		BackchannelAuthenticationEndpoint:     f.backchannelAuthenticationURL(ctx),
+		EndSessionEndpoint:                    f.endSessionURL(ctx),
+		UserinfoEndpoint:                      f.userinfoURL(ctx),
+		RegistrationEndpoint:                  f.clientRegistrationURL(ctx),
+		ResponseModesSupported:                []string{string(ResponseModeQuery), string(ResponseModeFragment), string(ResponseModeFormPost)},
+		DPoPSigningAlgValuesSupported:         f.dpopSigningAlgorithms(ctx),
+		TLSClientCertificateBoundAccessTokens: f.tlsClientCertificateAuthorities(ctx) != nil,
+	}
+	if metadata.Issuer == "" {
+		metadata.Issuer = f.Config.GetIDTokenIssuer(ctx)
+	}
+	if urls := RemoveEmpty(f.Config.GetTokenURLs(ctx)); len(urls) > 0 {
+		metadata.TokenEndpoint = urls[0]
+	}
+	if c, ok := f.Config.(PushedAuthorizeRequestConfigProvider); ok {
+		metadata.RequirePushedAuthorizationRequests = c.EnforcePushedAuthorize(ctx)
+	}
+
+	// Whether mutual TLS is available depends on how TLS is terminated, which fosite only knows if the certificate
+	// authorities are configured. The client_secret_jwt method depends on the clients storing their plaintext secret.
+	// Both can be advertised using the AuthorizationServerMetadataProvider.
+	if f.Config.GetClientAuthenticationStrategy(ctx) == nil {
+		metadata.TokenEndpointAuthMethodsSupported = []string{"client_secret_basic", "client_secret_post", "private_key_jwt", "none"}
+		metadata.TokenEndpointAuthSigningAlgValuesSupported = asymmetricSigningAlgorithms
+		if metadata.TLSClientCertificateBoundAccessTokens {
+			metadata.TokenEndpointAuthMethodsSupported = append(metadata.TokenEndpointAuthMethodsSupported, TLSClientAuthMethod, SelfSignedTLSClientAuthMethod)
+		}
+	}
+
+	if ext := f.Config.GetResponseModeHandlerExtension(ctx); ext != nil {
+		for _, rm := range ext.ResponseModes() {
+			metadata.ResponseModesSupported = appendUnique(metadata.ResponseModesSupported, string(rm))
+		}
+	}
+
+	if signer := f.jwtSecuredAuthorizeResponseModeSigner(ctx); signer != nil {
+		for _, rm := range []ResponseModeType{ResponseModeJWT, ResponseModeQueryJWT, ResponseModeFragmentJWT, ResponseModeFormPostJWT} {
+			metadata.ResponseModesSupported = appendUnique(metadata.ResponseModesSupported, string(rm))
+		}
+		if alg, err := signingAlgorithm(ctx, signer); err != nil {
+			return nil, err
+		} else if alg != "" {
+			metadata.AuthorizationSigningAlgValuesSupported = []string{alg}
+		}
+	}
+
+	if signer := f.jwtIntrospectionResponseSigner(ctx); signer != nil {
+		if alg, err := signingAlgorithm(ctx, signer); err != nil {
+			return nil, err
+		} else if alg != "" {
+			metadata.IntrospectionSigningAlgValuesSupported = []string{alg}
+		}
+	}
+
+	if signer := f.userinfoSigner(ctx); signer != nil {
+		if alg, err := signingAlgorithm(ctx, signer); err != nil {
+			return nil, err
+		} else if alg != "" {
+			metadata.UserinfoSigningAlgValuesSupported = []string{alg}
+		}
+	}
+
+	for detailType := range f.authorizationDetailValidators(ctx) {
+		metadata.AuthorizationDetailsTypesSupported = append(metadata.AuthorizationDetailsTypesSupported, detailType)
+	}
+	sort.Strings(metadata.AuthorizationDetailsTypesSupported)
+
+	var handlers []interface{}
+	for _, h := range f.Config.GetAuthorizeEndpointHandlers(ctx) {
+		handlers = append(handlers, h)
+	}
+	for _, h := range f.Config.GetTokenEndpointHandlers(ctx) {
+		handlers = append(handlers, h)
+	}
+	if c, ok := f.Config.(PushedAuthorizeRequestHandlersProvider); ok {
+		for _, h := range c.GetPushedAuthorizeEndpointHandlers(ctx) {
+			handlers = append(handlers, h)
+		}
+	}
+	if c, ok := f.Config.(DeviceEndpointHandlersProvider); ok {
+		for _, h := range c.GetDeviceEndpointHandlers(ctx) {
+			handlers = append(handlers, h)
+		}
+	}
+	if c, ok := f.Config.(BackchannelAuthenticationEndpointHandlersProvider); ok {
+		for _, h := range c.GetBackchannelAuthenticationEndpointHandlers(ctx) {
+			handlers = append(handlers, h)
+		}
+	}
+	for _, h := range f.Config.GetTokenIntrospectionHandlers(ctx) {
+		handlers = append(handlers, h)
+	}
+	for _, h := range f.Config.GetRevocationHandlers(ctx) {
+		handlers = append(handlers, h)
+	}
+
+	// Handlers are often registered for several endpoints, which is why populating the metadata must be idempotent.
+	for _, h := range handlers {
+		if mh, ok := h.(AuthorizationServerMetadataHandler); ok {
+			mh.PopulateAuthorizationServerMetadata(ctx, metadata)
+		}
+	}
+
+	// Pairwise subject identifiers are issued by the OpenID Connect handlers: https://openid.net/specs/openid-connect-core-1_0.html#SubjectIDTypes
+	if len(metadata.SubjectTypesSupported) > 0 && pairwiseSubjectAlgorithm(ctx, f.Config) != nil {
+		metadata.AddSubjectTypes(SubjectTypePairwise)
+	}
+
+	// The claims parameter is an OpenID Connect feature: https://openid.net/specs/openid-connect-core-1_0.html#ClaimsParameter
+	metadata.ClaimsParameterSupported = Arguments(metadata.ScopesSupported).Has("openid")
+
+	// Request objects can be used with any authorization request: https://www.rfc-editor.org/rfc/rfc9101#section-10.1
+	if len(metadata.ResponseTypesSupported) > 0 {
+		metadata.RequestParameterSupported = true
+		metadata.AuthorizationResponseIssParameterSupported = f.authorizationResponseIssuer(ctx) != ""
+		metadata.RequestURIParameterSupported = true
+		metadata.RequestObjectSigningAlgValuesSupported = append([]string{"none"}, asymmetricSigningAlgorithms...)
+		if c, ok := f.Config.(JWTSecuredAuthorizeRequestProvider); ok {
+			metadata.RequestURIParameterSupported = !c.GetDisableRemoteRequestURIs(ctx)
+			if c.GetJWTSecuredAuthorizeRequestDecrypter(ctx) != nil {
+				metadata.RequestObjectEncryptionAlgValuesSupported = keyEncryptionAlgorithms
+				metadata.RequestObjectEncryptionEncValuesSupported = contentEncryptionAlgorithms
+			}
+		}
+	}
+
+	// Signed authentication requests must use asymmetric algorithms: https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#signed_auth_request
+	if metadata.BackchannelAuthenticationEndpoint != "" {
+		metadata.BackchannelAuthenticationRequestSigningAlgValuesSupported = asymmetricSigningAlgorithms
+	}
+
+	metadata.applyOverrides(f.authorizationServerMetadataOverrides(ctx))
+	return metadata, nil
+}
+
+// WriteAuthorizationServerMetadata writes the authorization server metadata response of
+// https://www.rfc-editor.org/rfc/rfc8414#section-3.2
+func (f *Fosite) WriteAuthorizationServerMetadata(ctx context.Context, rw http.ResponseWriter, metadata *AuthorizationServerMetadata) {
+	js, err := json.Marshal(metadata)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(js)
+}
+
+// AuthorizationServerMetadataEndpoint returns a http.Handler which serves the authorization server metadata, for
+// example at "/.well-known/oauth-authorization-server" or "/.well-known/openid-configuration".
+func (f *Fosite) AuthorizationServerMetadataEndpoint() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if r.Method != "GET" && r.Method != "HEAD" {
+			rw.Header().Set("Allow", "GET, HEAD")
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		metadata, err := f.NewAuthorizationServerMetadata(ctx)
+		if err != nil {
+			http.Error(rw, `{"error":"server_error"}`, http.StatusInternalServerError)
+			return
+		}
+
+		f.WriteAuthorizationServerMetadata(ctx, rw, metadata)
+	})
+}
+
+// signingAlgorithm returns the JWS algorithm the signer signs tokens with, or an empty string if it is unknown.
+func signingAlgorithm(ctx context.Context, signer jwt.Signer) (string, error) {
+	s, ok := signer.(*jwt.DefaultSigner)
+	if !ok {
+		return "", nil
+	}
+
+	key, err := s.GetPrivateKey(ctx)
+	if err != nil {
+		return "", errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	switch t := key.(type) {
+	case *jose.JSONWebKey:
+		return t.Algorithm, nil
+	case jose.JSONWebKey:
+		return t.Algorithm, nil
+	case *rsa.PrivateKey:
+		return string(jose.RS256), nil
+	case *ecdsa.PrivateKey:
+		return string(jose.ES256), nil
+	case jose.OpaqueSigner:
+		if algs := t.Algs(); len(algs) > 0 {
+			return string(algs[0]), nil
+		}
+	}
+	return "", nil
+}
+
+func appendUnique(values []string, add ...string) []string {
+	for _, v := range add {
+		if !Arguments(values).Has(v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
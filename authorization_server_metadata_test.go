@@ -0,0 +1,144 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestAuthorizationServerMetadata(t *testing.T) {
+	ctx := context.Background()
+	key := gen.MustRSAKey()
+
+	t.Run("case=should infer the metadata of the composed handlers", func(t *testing.T) {
+		config := &Config{
+			AccessTokenIssuer:                     "https://auth.example.com",
+			TokenURL:                              "https://auth.example.com/oauth2/token",
+			ClientRegistrationURL:                 "https://auth.example.com/oauth2/register",
+			IsPushedAuthorizeEnforced:             true,
+			JWTSecuredAuthorizeResponseModeSigner: &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return key, nil }},
+			AuthorizationDetailValidators: AuthorizationDetailValidators{
+				"payment_initiation": func(context.Context, Client, *AuthorizationDetail) error { return nil },
+			},
+		}
+		f := compose.ComposeAllEnabled(config, storage.NewMemoryStore(), key)
+
+		metadata, err := f.NewAuthorizationServerMetadata(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, "https://auth.example.com", metadata.Issuer)
+		assert.Equal(t, "https://auth.example.com/oauth2/token", metadata.TokenEndpoint)
+		assert.Equal(t, "https://auth.example.com/oauth2/register", metadata.RegistrationEndpoint)
+		assert.True(t, metadata.RequirePushedAuthorizationRequests)
+		assert.ElementsMatch(t, []string{
+			"authorization_code", "implicit", "client_credentials", "refresh_token", "password",
+			"urn:ietf:params:oauth:grant-type:jwt-bearer",
+			"urn:ietf:params:oauth:grant-type:token-exchange",
+			"urn:ietf:params:oauth:grant-type:device_code",
+			"urn:openid:params:grant-type:ciba",
+		}, metadata.GrantTypesSupported)
+		assert.ElementsMatch(t, []string{"poll", "ping"}, metadata.BackchannelTokenDeliveryModesSupported)
+		assert.ElementsMatch(t, []string{
+			"code", "token", "id_token", "id_token token", "code id_token", "code token", "code id_token token",
+		}, metadata.ResponseTypesSupported)
+		assert.ElementsMatch(t, []string{"query", "fragment", "form_post", "jwt", "query.jwt", "fragment.jwt", "form_post.jwt"}, metadata.ResponseModesSupported)
+		assert.True(t, metadata.AuthorizationResponseIssParameterSupported)
+		assert.Equal(t, []string{"S256"}, metadata.CodeChallengeMethodsSupported)
+		assert.Equal(t, []string{"openid"}, metadata.ScopesSupported)
+		assert.Equal(t, []string{"public"}, metadata.SubjectTypesSupported)
+		assert.Equal(t, []string{"RS256"}, metadata.AuthorizationSigningAlgValuesSupported)
+		assert.Equal(t, []string{"payment_initiation"}, metadata.AuthorizationDetailsTypesSupported)
+		assert.Equal(t, []string{"client_secret_basic", "client_secret_post", "private_key_jwt", "none"}, metadata.TokenEndpointAuthMethodsSupported)
+		assert.NotContains(t, metadata.TokenEndpointAuthSigningAlgValuesSupported, "HS256")
+		assert.False(t, metadata.TLSClientCertificateBoundAccessTokens)
+		assert.True(t, metadata.RequestParameterSupported)
+	})
+
+	t.Run("case=should advertise pairwise subjects", func(t *testing.T) {
+		config := &Config{
+			AccessTokenIssuer:        "https://auth.example.com",
+			PairwiseSubjectAlgorithm: &HMACPairwiseSubjectAlgorithm{Salt: []byte("salt")},
+		}
+		f := compose.ComposeAllEnabled(config, storage.NewMemoryStore(), key)
+
+		metadata, err := f.NewAuthorizationServerMetadata(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"public", "pairwise"}, metadata.SubjectTypesSupported)
+	})
+
+	t.Run("case=should advertise mutual TLS if certificate authorities are configured", func(t *testing.T) {
+		config := &Config{
+			AccessTokenIssuer:               "https://auth.example.com",
+			TLSClientCertificateAuthorities: x509.NewCertPool(),
+		}
+		f := compose.ComposeAllEnabled(config, storage.NewMemoryStore(), key)
+
+		metadata, err := f.NewAuthorizationServerMetadata(ctx)
+		require.NoError(t, err)
+		assert.Subset(t, metadata.TokenEndpointAuthMethodsSupported, []string{TLSClientAuthMethod, SelfSignedTLSClientAuthMethod})
+		assert.True(t, metadata.TLSClientCertificateBoundAccessTokens)
+	})
+
+	t.Run("case=should not advertise client authentication methods of custom strategies", func(t *testing.T) {
+		config := &Config{
+			AccessTokenIssuer:            "https://auth.example.com",
+			ClientAuthenticationStrategy: func(context.Context, *http.Request, url.Values) (Client, error) { return nil, nil },
+		}
+		f := compose.ComposeAllEnabled(config, storage.NewMemoryStore(), key)
+
+		metadata, err := f.NewAuthorizationServerMetadata(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, metadata.TokenEndpointAuthMethodsSupported)
+		assert.False(t, metadata.TLSClientCertificateBoundAccessTokens)
+	})
+
+	t.Run("case=should apply the overrides", func(t *testing.T) {
+		config := &Config{
+			AccessTokenIssuer:              "https://auth.example.com",
+			EnablePKCEPlainChallengeMethod: true,
+			AuthorizationServerMetadataOverrides: &AuthorizationServerMetadata{
+				AuthorizationEndpoint:            "https://auth.example.com/oauth2/auth",
+				JSONWebKeysURI:                   "https://auth.example.com/.well-known/jwks.json",
+				ScopesSupported:                  []string{"openid", "offline_access"},
+				IDTokenSigningAlgValuesSupported: []string{"RS256"},
+				Extra:                            map[string]interface{}{"end_session_endpoint": "https://auth.example.com/logout"},
+			},
+		}
+		f := compose.ComposeAllEnabled(config, storage.NewMemoryStore(), key)
+
+		rw := httptest.NewRecorder()
+		f.AuthorizationServerMetadataEndpoint().ServeHTTP(rw, httptest.NewRequest("GET", "/.well-known/openid-configuration", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, "application/json;charset=UTF-8", rw.Header().Get("Content-Type"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+		assert.Equal(t, "https://auth.example.com", body["issuer"])
+		assert.Equal(t, "https://auth.example.com/oauth2/auth", body["authorization_endpoint"])
+		assert.Equal(t, "https://auth.example.com/.well-known/jwks.json", body["jwks_uri"])
+		assert.Equal(t, []interface{}{"openid", "offline_access"}, body["scopes_supported"])
+		assert.Equal(t, []interface{}{"S256", "plain"}, body["code_challenge_methods_supported"])
+		assert.Equal(t, "https://auth.example.com/logout", body["end_session_endpoint"])
+		assert.NotContains(t, body, "require_pushed_authorization_requests")
+
+		rw = httptest.NewRecorder()
+		f.AuthorizationServerMetadataEndpoint().ServeHTTP(rw, httptest.NewRequest("POST", "/.well-known/openid-configuration", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	})
+}
@@ -14,28 +14,15 @@ func (f *Fosite) WriteAuthorizeError(ctx context.Context, rw http.ResponseWriter
 	rw.Header().Set("Cache-Control", "no-store")
 	rw.Header().Set("Pragma", "no-cache")
 
+	iss := f.authorizationResponseIssuer(ctx)
 	if f.ResponseModeHandler(ctx).ResponseModes().Has(ar.GetResponseMode()) {
-		f.ResponseModeHandler(ctx).WriteAuthorizeError(ctx, rw, ar, err)
+		f.ResponseModeHandler(ctx).WriteAuthorizeError(context.WithValue(ctx, AuthorizeResponseIssuerContextKey, iss), rw, ar, err)
 		return
 	}
 
 	rfcerr := ErrorToRFC6749Error(err).WithLegacyFormat(f.Config.GetUseLegacyErrorFormat(ctx)).WithExposeDebug(f.Config.GetSendDebugMessagesToClients(ctx)).WithLocalizer(f.Config.GetMessageCatalog(ctx), getLangFromRequester(ar))
 	if !ar.IsRedirectURIValid() {
-		rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
-
-		js, err := json.Marshal(rfcerr)
-		if err != nil {
-			if f.Config.GetSendDebugMessagesToClients(ctx) {
-				errorMessage := EscapeJSONString(err.Error())
-				http.Error(rw, fmt.Sprintf(`{"error":"server_error","error_description":"%s"}`, errorMessage), http.StatusInternalServerError)
-			} else {
-				http.Error(rw, `{"error":"server_error"}`, http.StatusInternalServerError)
-			}
-			return
-		}
-
-		rw.WriteHeader(rfcerr.CodeField)
-		_, _ = rw.Write(js)
+		f.writeAuthorizeErrorJSON(ctx, rw, rfcerr)
 		return
 	}
 
@@ -46,12 +33,21 @@ func (f *Fosite) WriteAuthorizeError(ctx context.Context, rw http.ResponseWriter
 
 	errors := rfcerr.ToValues()
 	errors.Set("state", ar.GetState())
+	if iss != "" {
+		// https://www.rfc-editor.org/rfc/rfc9207#section-2
+		errors.Set("iss", iss)
+	}
 
 	var redirectURIString string
 	if ar.GetResponseMode() == ResponseModeFormPost {
 		rw.Header().Set("Content-Type", "text/html;charset=UTF-8")
 		WriteAuthorizeFormPostResponse(redirectURI.String(), errors, GetPostFormHTMLTemplate(ctx, f), rw)
 		return
+	} else if isJWTSecuredResponseMode(ar.GetResponseMode()) {
+		if err := f.writeJWTSecuredAuthorizeResponse(ctx, rw, ar, errors); err != nil {
+			f.writeAuthorizeErrorJSON(ctx, rw, ErrorToRFC6749Error(err).WithLegacyFormat(f.Config.GetUseLegacyErrorFormat(ctx)).WithExposeDebug(f.Config.GetSendDebugMessagesToClients(ctx)).WithLocalizer(f.Config.GetMessageCatalog(ctx), getLangFromRequester(ar)))
+		}
+		return
 	} else if ar.GetResponseMode() == ResponseModeFragment {
 		redirectURIString = redirectURI.String() + "#" + errors.Encode()
 	} else {
@@ -67,3 +63,21 @@ func (f *Fosite) WriteAuthorizeError(ctx context.Context, rw http.ResponseWriter
 	rw.Header().Set("Location", redirectURIString)
 	rw.WriteHeader(http.StatusSeeOther)
 }
+
+func (f *Fosite) writeAuthorizeErrorJSON(ctx context.Context, rw http.ResponseWriter, rfcerr *RFC6749Error) {
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+
+	js, err := json.Marshal(rfcerr)
+	if err != nil {
+		if f.Config.GetSendDebugMessagesToClients(ctx) {
+			errorMessage := EscapeJSONString(err.Error())
+			http.Error(rw, fmt.Sprintf(`{"error":"server_error","error_description":"%s"}`, errorMessage), http.StatusInternalServerError)
+		} else {
+			http.Error(rw, `{"error":"server_error"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	rw.WriteHeader(rfcerr.CodeField)
+	_, _ = rw.Write(js)
+}
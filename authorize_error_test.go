@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
@@ -447,3 +448,39 @@ func copyUrl(u *url.URL) *url.URL {
 	u2, _ := url.Parse(u.String())
 	return u2
 }
+
+func TestWriteAuthorizeErrorIssuer(t *testing.T) {
+	extension := &capturingResponseModeHandler{}
+	f := &Fosite{Config: &Config{AccessTokenIssuer: "https://auth.example.com", ResponseModeHandlerExtension: extension}}
+
+	write := func(rm ResponseModeType) *httptest.ResponseRecorder {
+		redir, _ := url.Parse("https://client.example.com/cb")
+		ar := NewAuthorizeRequest()
+		ar.RedirectURI = redir
+		ar.ResponseMode = rm
+		ar.State = "state"
+		ar.Client = &DefaultClient{RedirectURIs: []string{"https://client.example.com/cb"}}
+
+		rw := httptest.NewRecorder()
+		f.WriteAuthorizeError(context.Background(), rw, ar, ErrAccessDenied)
+		return rw
+	}
+
+	for _, rm := range []ResponseModeType{ResponseModeQuery, ResponseModeFragment} {
+		location, err := url.Parse(write(rm).Header().Get("Location"))
+		assert.NoError(t, err)
+
+		params := location.Query()
+		if rm == ResponseModeFragment {
+			params, err = url.ParseQuery(location.Fragment)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, "https://auth.example.com", params.Get("iss"), "%s", rm)
+		assert.Equal(t, "access_denied", params.Get("error"), "%s", rm)
+	}
+
+	assert.Contains(t, write(ResponseModeFormPost).Body.String(), `name="iss" value="https://auth.example.com"`)
+
+	write("custom")
+	assert.Equal(t, "https://auth.example.com", extension.issuer)
+}
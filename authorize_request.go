@@ -14,6 +14,12 @@ const (
 	ResponseModeFormPost = ResponseModeType("form_post")
 	ResponseModeQuery    = ResponseModeType("query")
 	ResponseModeFragment = ResponseModeType("fragment")
+
+	// JWT Secured Authorization Response Modes, see https://openid.net/specs/oauth-v2-jarm.html#section-2.3
+	ResponseModeJWT         = ResponseModeType("jwt")
+	ResponseModeQueryJWT    = ResponseModeType("query.jwt")
+	ResponseModeFragmentJWT = ResponseModeType("fragment.jwt")
+	ResponseModeFormPostJWT = ResponseModeType("form_post.jwt")
 )
 
 // AuthorizeRequest is an implementation of AuthorizeRequester
@@ -24,6 +30,8 @@ type AuthorizeRequest struct {
 	HandledResponseTypes Arguments        `json:"handledResponseTypes" gorethink:"handledResponseTypes"`
 	ResponseMode         ResponseModeType `json:"ResponseModes" gorethink:"ResponseModes"`
 	DefaultResponseMode  ResponseModeType `json:"DefaultResponseMode" gorethink:"DefaultResponseMode"`
+	RequestedClaims      *ClaimsRequest   `json:"requestedClaims,omitempty" gorethink:"requestedClaims"`
+	RequestedACRValues   Arguments        `json:"requestedACRValues,omitempty" gorethink:"requestedACRValues"`
 
 	Request
 }
@@ -96,3 +104,11 @@ func (d *AuthorizeRequest) SetDefaultResponseMode(defaultResponseMode ResponseMo
 func (d *AuthorizeRequest) GetDefaultResponseMode() ResponseModeType {
 	return d.DefaultResponseMode
 }
+
+func (d *AuthorizeRequest) GetRequestedClaims() *ClaimsRequest {
+	return d.RequestedClaims
+}
+
+func (d *AuthorizeRequest) GetRequestedACRValues() Arguments {
+	return d.RequestedACRValues
+}
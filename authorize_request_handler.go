@@ -5,6 +5,7 @@ package fosite
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -31,36 +32,64 @@ func wrapSigningKeyFailure(outer *RFC6749Error, inner error) *RFC6749Error {
 	return outer
 }
 
-func (f *Fosite) authorizeRequestParametersFromOpenIDConnectRequest(ctx context.Context, request *AuthorizeRequest, isPARRequest bool) error {
+// requestObjectJWTClaims are the claims of request objects which are used to validate the JWT and are not copied into
+// the authorization request parameters.
+var requestObjectJWTClaims = map[string]bool{"iss": true, "aud": true, "exp": true, "iat": true, "nbf": true, "jti": true}
+
+// jwtSecuredAuthorizeRequestAudience returns the identifier the "aud" claim of request objects must contain. It
+// defaults to the issuer of the authorization server if none is configured.
+func (f *Fosite) jwtSecuredAuthorizeRequestAudience(ctx context.Context) string {
+	if c, ok := f.Config.(JWTSecuredAuthorizeRequestProvider); ok {
+		if audience := c.GetJWTSecuredAuthorizeRequestAudience(ctx); audience != "" {
+			return audience
+		}
+	}
+	return f.authorizationResponseIssuer(ctx)
+}
+
+func (f *Fosite) authorizeRequestParametersFromRequestObject(ctx context.Context, request *AuthorizeRequest, isPARRequest bool) error {
 	var scope Arguments = RemoveEmpty(strings.Split(request.Form.Get("scope"), " "))
 
-	// Even if a scope parameter is present in the Request Object value, a scope parameter MUST always be passed using
-	// the OAuth 2.0 request syntax containing the openid scope value to indicate to the underlying OAuth 2.0 logic that this is an OpenID Connect request.
-	// Source: http://openid.net/specs/openid-connect-core-1_0.html#CodeFlowAuth
-	if !scope.Has("openid") {
-		return nil
+	var requireSignedRequestObject bool
+	if jarClient, ok := request.Client.(JWTSecuredAuthorizeRequestClient); ok {
+		requireSignedRequestObject = jarClient.GetRequireSignedRequestObject()
 	}
 
 	if len(request.Form.Get("request")+request.Form.Get("request_uri")) == 0 {
+		if requireSignedRequestObject {
+			return errorsx.WithStack(ErrInvalidRequest.WithHint("The OAuth 2.0 Client requires authorization requests to be passed as signed request objects using the 'request' or 'request_uri' parameter."))
+		}
 		return nil
 	} else if len(request.Form.Get("request")) > 0 && len(request.Form.Get("request_uri")) > 0 {
-		return errorsx.WithStack(ErrInvalidRequest.WithHint("OpenID Connect parameters 'request' and 'request_uri' were both given, but you can use at most one."))
+		return errorsx.WithStack(ErrInvalidRequest.WithHint("Authorization request parameters 'request' and 'request_uri' were both given, but you can use at most one."))
 	}
 
 	oidcClient, ok := request.Client.(OpenIDConnectClient)
 	if !ok {
 		if len(request.Form.Get("request_uri")) > 0 {
-			return errorsx.WithStack(ErrRequestURINotSupported.WithHint("OpenID Connect 'request_uri' context was given, but the OAuth 2.0 Client does not implement advanced OpenID Connect capabilities."))
+			return errorsx.WithStack(ErrRequestURINotSupported.WithHint("Authorization request parameter 'request_uri' was given, but the OAuth 2.0 Client does not implement advanced OpenID Connect capabilities."))
 		}
-		return errorsx.WithStack(ErrRequestNotSupported.WithHint("OpenID Connect 'request' context was given, but the OAuth 2.0 Client does not implement advanced OpenID Connect capabilities."))
+		return errorsx.WithStack(ErrRequestNotSupported.WithHint("Authorization request parameter 'request' was given, but the OAuth 2.0 Client does not implement advanced OpenID Connect capabilities."))
+	}
+
+	// The client_id MUST be passed using the OAuth 2.0 request syntax so that the request object can be verified.
+	// Source: https://www.rfc-editor.org/rfc/rfc9101#section-5
+	if len(request.Form.Get("client_id")) == 0 {
+		return errorsx.WithStack(ErrInvalidRequest.WithHint("Authorization request parameter 'client_id' must be given when using the 'request' or 'request_uri' parameter."))
 	}
 
 	if oidcClient.GetJSONWebKeys() == nil && len(oidcClient.GetJSONWebKeysURI()) == 0 {
-		return errorsx.WithStack(ErrInvalidRequest.WithHint("OpenID Connect 'request' or 'request_uri' context was given, but the OAuth 2.0 Client does not have any JSON Web Keys registered."))
+		return errorsx.WithStack(ErrInvalidRequest.WithHint("Authorization request parameter 'request' or 'request_uri' was given, but the OAuth 2.0 Client does not have any JSON Web Keys registered."))
 	}
 
 	assertion := request.Form.Get("request")
 	if location := request.Form.Get("request_uri"); len(location) > 0 {
+		// Request URIs issued by the authorization server itself, such as those of pushed authorization requests,
+		// are resolved before the request object is processed. All other request URIs are hosted by the client.
+		if c, ok := f.Config.(JWTSecuredAuthorizeRequestProvider); ok && c.GetDisableRemoteRequestURIs(ctx) {
+			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Request URI '%s' was not issued by the authorization server.", location))
+		}
+
 		if !stringslice.Has(oidcClient.GetRequestURIs(), location) {
 			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Request URI '%s' is not whitelisted by the OAuth 2.0 Client.", location))
 		}
@@ -68,22 +97,27 @@ func (f *Fosite) authorizeRequestParametersFromOpenIDConnectRequest(ctx context.
 		hc := f.Config.GetHTTPClient(ctx)
 		response, err := hc.Get(location)
 		if err != nil {
-			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch OpenID Connect request parameters from 'request_uri' because: %s.", err.Error()).WithWrap(err).WithDebug(err.Error()))
+			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch request object from 'request_uri' because: %s.", err.Error()).WithWrap(err).WithDebug(err.Error()))
 		}
 		defer response.Body.Close()
 
 		if response.StatusCode != http.StatusOK {
-			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch OpenID Connect request parameters from 'request_uri' because status code '%d' was expected, but got '%d'.", http.StatusOK, response.StatusCode))
+			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch request object from 'request_uri' because status code '%d' was expected, but got '%d'.", http.StatusOK, response.StatusCode))
 		}
 
 		body, err := io.ReadAll(response.Body)
 		if err != nil {
-			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch OpenID Connect request parameters from 'request_uri' because body parsing failed with: %s.", err).WithWrap(err).WithDebug(err.Error()))
+			return errorsx.WithStack(ErrInvalidRequestURI.WithHintf("Unable to fetch request object from 'request_uri' because body parsing failed with: %s.", err).WithWrap(err).WithDebug(err.Error()))
 		}
 
 		assertion = string(body)
 	}
 
+	assertion, err := f.decryptRequestObject(ctx, request.Client, assertion)
+	if err != nil {
+		return err
+	}
+
 	token, err := jwt.ParseWithClaims(assertion, jwt.MapClaims{}, func(t *jwt.Token) (interface{}, error) {
 		// request_object_signing_alg - OPTIONAL.
 		//  JWS [JWS] alg algorithm [JWA] that MUST be used for signing Request Objects sent to the OP. All Request Objects from this Client MUST be rejected,
@@ -95,6 +129,9 @@ func (f *Fosite) authorizeRequestParametersFromOpenIDConnectRequest(ctx context.
 		}
 
 		if t.Method == jwt.SigningMethodNone {
+			if requireSignedRequestObject {
+				return nil, errorsx.WithStack(ErrInvalidRequestObject.WithHint("The request object is not signed, but the requested OAuth 2.0 Client requires signed request objects."))
+			}
 			return jwt.UnsafeAllowNoneSignatureType, nil
 		}
 
@@ -139,28 +176,128 @@ func (f *Fosite) authorizeRequestParametersFromOpenIDConnectRequest(ctx context.
 	}
 
 	claims := token.Claims
-	// Reject the request if the "request_uri" authorization request
-	// parameter is provided.
-	if requestURI, _ := claims["request_uri"].(string); isPARRequest && requestURI != "" {
+	// The request and request_uri parameters MUST NOT be included in request objects.
+	// Source: https://www.rfc-editor.org/rfc/rfc9101#section-4
+	if _, ok := claims["request_uri"]; ok && isPARRequest {
 		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("Pushed Authorization Requests can not contain the 'request_uri' parameter."))
+	} else if _, ok := claims["request_uri"]; ok {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("The request object must not contain the 'request_uri' parameter."))
+	} else if _, ok := claims["request"]; ok {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("The request object must not contain the 'request' parameter."))
+	}
+
+	// The client_id of the request object MUST match the one of the OAuth 2.0 request syntax, and a signed request
+	// object must be issued by the client and intended for this authorization server. Unsigned request objects are
+	// only checked for the claims they contain.
+	// Source: https://www.rfc-editor.org/rfc/rfc9101#section-5 and https://www.rfc-editor.org/rfc/rfc9101#section-10.8
+	clientID := request.Form.Get("client_id")
+	if v, ok := claims["client_id"]; ok && v != clientID {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("The 'client_id' claim of the request object does not match the 'client_id' parameter of the authorization request."))
+	}
+
+	isSigned := token.Method != jwt.SigningMethodNone
+	if _, ok := claims["iss"]; (ok || isSigned) && !claims.VerifyIssuer(clientID, true) {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("The 'iss' claim of the request object must be the 'client_id' of the OAuth 2.0 Client."))
+	}
+	audience := f.jwtSecuredAuthorizeRequestAudience(ctx)
+	if len(audience) == 0 && isSigned {
+		return errorsx.WithStack(ErrServerError.WithHint("Unable to verify the 'aud' claim of the request object.").WithDebug("Configure the audience of JWT-secured authorization requests or the issuer of authorization responses."))
+	} else if _, ok := claims["aud"]; len(audience) > 0 && (ok || isSigned) && !claims.VerifyAudience(audience, true) {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHintf("The 'aud' claim of the request object must contain '%s'.", audience))
+	}
+
+	// The parameters of the request object take precedence over those of the OAuth 2.0 request syntax. OpenID Connect
+	// requests may still pass further parameters, such as state and nonce, outside of the request object. All other
+	// requests must only use the parameters of the request object.
+	// Source: https://openid.net/specs/openid-connect-core-1_0.html#SignedRequestObject and https://www.rfc-editor.org/rfc/rfc9101#section-5
+	isOpenIDConnectRequest := scope.Has("openid")
+	if !isOpenIDConnectRequest {
+		for k := range request.Form {
+			if k != "client_id" && k != "request" && k != "request_uri" {
+				delete(request.Form, k)
+			}
+		}
 	}
 
 	for k, v := range claims {
-		request.Form.Set(k, fmt.Sprintf("%s", v))
+		// The registered claims of the JWT are not authorization request parameters.
+		if requestObjectJWTClaims[k] {
+			continue
+		}
+		request.Form.Set(k, requestObjectParameterValue(v))
 	}
 
-	claimScope := RemoveEmpty(strings.Split(request.Form.Get("scope"), " "))
-	for _, s := range scope {
-		if !stringslice.Has(claimScope, s) {
-			claimScope = append(claimScope, s)
+	// Even if a scope parameter is present in the Request Object value, a scope parameter MUST always be passed using
+	// the OAuth 2.0 request syntax containing the openid scope value to indicate to the underlying OAuth 2.0 logic that this is an OpenID Connect request.
+	// Source: http://openid.net/specs/openid-connect-core-1_0.html#CodeFlowAuth
+	if isOpenIDConnectRequest {
+		claimScope := RemoveEmpty(strings.Split(request.Form.Get("scope"), " "))
+		if !stringslice.Has(claimScope, "openid") {
+			claimScope = append(claimScope, "openid")
 		}
+		request.Form.Set("scope", strings.Join(claimScope, " "))
 	}
 
 	request.State = request.Form.Get("state")
-	request.Form.Set("scope", strings.Join(claimScope, " "))
 	return nil
 }
 
+// requestObjectParameterValue converts a member of a request object into the value of an authorization request
+// parameter. Structured members, such as the claims parameter, are JSON encoded.
+func requestObjectParameterValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	js, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(js)
+}
+
+// decryptRequestObject returns the signed request object nested in an encrypted request object, see
+// https://www.rfc-editor.org/rfc/rfc9101#section-6.1. Unencrypted request objects are returned as they are, unless the
+// client registered a request object encryption algorithm.
+func (f *Fosite) decryptRequestObject(ctx context.Context, client Client, assertion string) (string, error) {
+	var alg, enc string
+	if jarClient, ok := client.(JWTSecuredAuthorizeRequestClient); ok {
+		alg = jarClient.GetRequestObjectEncryptionAlgorithm()
+		enc = jarClient.GetRequestObjectEncryptionEncryption()
+		if alg != "" && enc == "" {
+			enc = string(jose.A128CBC_HS256)
+		}
+	}
+
+	if !jwt.IsEncrypted(assertion) {
+		if alg != "" {
+			return "", errorsx.WithStack(ErrInvalidRequestObject.WithHintf("The request object is not encrypted, but the requested OAuth 2.0 Client requires request objects encrypted with algorithm '%s'.", alg))
+		}
+		return assertion, nil
+	}
+
+	var decrypter jwt.Decrypter
+	if c, ok := f.Config.(JWTSecuredAuthorizeRequestProvider); ok {
+		decrypter = c.GetJWTSecuredAuthorizeRequestDecrypter(ctx)
+	}
+	if decrypter == nil {
+		return "", errorsx.WithStack(ErrInvalidRequestObject.WithHint("The request object is encrypted, but the authorization server does not support encrypted request objects."))
+	}
+
+	plaintext, header, err := decrypter.Decrypt(ctx, assertion)
+	if err != nil {
+		return "", errorsx.WithStack(ErrInvalidRequestObject.WithHint("Unable to decrypt the request object.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if alg != "" && header.Algorithm != alg {
+		return "", errorsx.WithStack(ErrInvalidRequestObject.WithHintf("The request object is encrypted with algorithm '%s', but the requested OAuth 2.0 Client enforces algorithm '%s'.", header.Algorithm, alg))
+	} else if actual := fmt.Sprintf("%v", header.ExtraHeaders["enc"]); enc != "" && actual != enc {
+		return "", errorsx.WithStack(ErrInvalidRequestObject.WithHintf("The request object is encrypted with content encryption '%s', but the requested OAuth 2.0 Client enforces content encryption '%s'.", actual, enc))
+	}
+
+	return string(plaintext), nil
+}
+
 func (f *Fosite) validateAuthorizeRedirectURI(_ *http.Request, request *AuthorizeRequest) error {
 	// Fetch redirect URI from request
 	rawRedirURI := request.Form.Get("redirect_uri")
@@ -230,6 +367,9 @@ func (f *Fosite) ParseResponseMode(ctx context.Context, r *http.Request, request
 		if f.ResponseModeHandler(ctx).ResponseModes().Has(rm) {
 			request.ResponseMode = rm
 			break
+		} else if isJWTSecuredResponseMode(rm) && f.jwtSecuredAuthorizeResponseModeSigner(ctx) != nil {
+			request.ResponseMode = rm
+			break
 		}
 		return errorsx.WithStack(ErrUnsupportedResponseMode.WithHintf("Request with unsupported response_mode \"%s\".", responseMode))
 	}
@@ -295,6 +435,8 @@ func (f *Fosite) authorizeRequestFromPAR(ctx context.Context, r *http.Request, r
 	request.ResponseTypes = parRequest.GetResponseTypes()
 	request.State = parRequest.GetState()
 	request.ResponseMode = parRequest.GetResponseMode()
+	request.RequestedClaims = parRequest.GetRequestedClaims()
+	request.RequestedACRValues = parRequest.GetRequestedACRValues()
 
 	if err := storage.DeletePARSession(ctx, requestURI); err != nil {
 		return false, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
@@ -353,7 +495,7 @@ func (f *Fosite) newAuthorizeRequest(ctx context.Context, r *http.Request, isPAR
 	//
 	// All other parse methods should come afterwards so that we ensure that the data is taken
 	// from the request_object if set.
-	if err := f.authorizeRequestParametersFromOpenIDConnectRequest(ctx, request, isPARRequest); err != nil {
+	if err := f.authorizeRequestParametersFromRequestObject(ctx, request, isPARRequest); err != nil {
 		return request, err
 	}
 
@@ -375,6 +517,23 @@ func (f *Fosite) newAuthorizeRequest(ctx context.Context, r *http.Request, isPAR
 		return request, err
 	}
 
+	claims, err := ParseClaimsRequest(request.Form.Get("claims"))
+	if err != nil {
+		return request, err
+	}
+	request.RequestedClaims = claims
+	request.RequestedACRValues = RemoveEmpty(strings.Split(request.Form.Get("acr_values"), " "))
+
+	details, err := f.parseAuthorizationDetails(ctx, request.GetClient(), request.Form)
+	if err != nil {
+		return request, err
+	}
+	request.SetRequestedAuthorizationDetails(details)
+
+	if err := validateDPoPJKT(request.Form.Get("dpop_jkt")); err != nil {
+		return request, err
+	}
+
 	if len(request.Form.Get("registration")) > 0 {
 		return request, errorsx.WithStack(ErrRegistrationNotSupported)
 	}
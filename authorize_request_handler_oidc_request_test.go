@@ -47,7 +47,7 @@ func mustGenerateNoneAssertion(t *testing.T, claims jwt.MapClaims) string {
 	return tokenString
 }
 
-func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
+func TestAuthorizeRequestParametersFromRequestObject(t *testing.T) {
 	key, err := rsa.GenerateKey(rand.Reader, 1024)
 	if err != nil {
 		panic(err)
@@ -62,8 +62,9 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 		},
 	}
 
-	validRequestObject := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar", "baz": "baz", "response_type": "token", "response_mode": "post_form"}, key, "kid-foo")
-	validRequestObjectWithoutKid := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar", "baz": "baz"}, key, "")
+	validRequestObject := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar", "baz": "baz", "response_type": "token", "response_mode": "post_form", "iss": "foo", "aud": "https://auth.example.com"}, key, "kid-foo")
+	validRequestObjectWithoutKid := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar", "baz": "baz", "iss": "foo", "aud": "https://auth.example.com"}, key, "")
+	validRequestObjectWithClaims := mustGenerateAssertion(t, jwt.MapClaims{"scope": "foo", "client_id": "foo", "iss": "foo", "aud": "https://auth.example.com"}, key, "kid-foo")
 	validNoneRequestObject := mustGenerateNoneAssertion(t, jwt.MapClaims{"scope": "foo", "foo": "bar", "baz": "baz", "state": "some-state"})
 
 	var reqH http.HandlerFunc = func(rw http.ResponseWriter, r *http.Request) {
@@ -78,7 +79,7 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 	reqJWK := httptest.NewServer(hJWK)
 	defer reqJWK.Close()
 
-	f := &Fosite{Config: &Config{JWKSFetcherStrategy: NewDefaultJWKSFetcherStrategy()}}
+	f := &Fosite{Config: &Config{JWKSFetcherStrategy: NewDefaultJWKSFetcherStrategy(), IDTokenIssuer: "https://auth.example.com"}}
 	for k, tc := range []struct {
 		client Client
 		form   url.Values
@@ -101,40 +102,39 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 			expectForm: url.Values{"scope": {"openid"}},
 		},
 		{
-			d:          "should pass because request context given but not openid",
-			form:       url.Values{"request": {"foo"}},
-			expectErr:  nil,
-			expectForm: url.Values{"request": {"foo"}},
+			d:         "should fail because request context given but not an OpenIDConnect compliant client",
+			form:      url.Values{"client_id": {"foo"}, "request": {"foo"}},
+			expectErr: ErrRequestNotSupported,
 		},
 		{
 			d:          "should fail because not an OpenIDConnect compliant client",
-			form:       url.Values{"scope": {"openid"}, "request": {"foo"}},
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {"foo"}},
 			expectErr:  ErrRequestNotSupported,
 			expectForm: url.Values{"scope": {"openid"}},
 		},
 		{
 			d:          "should fail because not an OpenIDConnect compliant client",
-			form:       url.Values{"scope": {"openid"}, "request_uri": {"foo"}},
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request_uri": {"foo"}},
 			expectErr:  ErrRequestURINotSupported,
 			expectForm: url.Values{"scope": {"openid"}},
 		},
 		{
 			d:          "should fail because token invalid an no key set",
-			form:       url.Values{"scope": {"openid"}, "request_uri": {"foo"}},
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request_uri": {"foo"}},
 			client:     &DefaultOpenIDConnectClient{RequestObjectSigningAlgorithm: "RS256"},
 			expectErr:  ErrInvalidRequest,
 			expectForm: url.Values{"scope": {"openid"}},
 		},
 		{
 			d:          "should fail because token invalid",
-			form:       url.Values{"scope": {"openid"}, "request": {"foo"}},
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {"foo"}},
 			client:     &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
 			expectErr:  ErrInvalidRequestObject,
 			expectForm: url.Values{"scope": {"openid"}},
 		},
 		{
 			d:               "should fail because kid does not exist",
-			form:            url.Values{"scope": {"openid"}, "request": {mustGenerateAssertion(t, jwt.MapClaims{}, key, "does-not-exists")}},
+			form:            url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {mustGenerateAssertion(t, jwt.MapClaims{}, key, "does-not-exists")}},
 			client:          &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
 			expectErr:       ErrInvalidRequestObject,
 			expectErrReason: "Unable to retrieve RSA signing key from OAuth 2.0 Client. The JSON Web Token uses signing key with kid 'does-not-exists', which could not be found.",
@@ -142,7 +142,7 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 		},
 		{
 			d:               "should fail because not RS256 token",
-			form:            url.Values{"scope": {"openid"}, "request": {mustGenerateHSAssertion(t, jwt.MapClaims{})}},
+			form:            url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {mustGenerateHSAssertion(t, jwt.MapClaims{})}},
 			client:          &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
 			expectErr:       ErrInvalidRequestObject,
 			expectErrReason: "The request object uses signing algorithm 'HS256', but the requested OAuth 2.0 Client enforces signing algorithm 'RS256'.",
@@ -150,41 +150,115 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 		},
 		{
 			d:      "should pass and set request parameters properly",
-			form:   url.Values{"scope": {"openid"}, "response_type": {"code"}, "response_mode": {"none"}, "request": {validRequestObject}},
+			form:   url.Values{"client_id": {"foo"}, "scope": {"openid"}, "response_type": {"code"}, "response_mode": {"none"}, "request": {validRequestObject}},
 			client: &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
 			// The values from form are overwritten by the request object.
-			expectForm: url.Values{"response_type": {"token"}, "response_mode": {"post_form"}, "scope": {"foo openid"}, "request": {validRequestObject}, "foo": {"bar"}, "baz": {"baz"}},
+			expectForm: url.Values{"client_id": {"foo"}, "response_type": {"token"}, "response_mode": {"post_form"}, "scope": {"foo openid"}, "request": {validRequestObject}, "foo": {"bar"}, "baz": {"baz"}},
 		},
 		{
 			d:          "should pass even if kid is unset",
-			form:       url.Values{"scope": {"openid"}, "request": {validRequestObjectWithoutKid}},
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {validRequestObjectWithoutKid}},
 			client:     &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
-			expectForm: url.Values{"scope": {"foo openid"}, "request": {validRequestObjectWithoutKid}, "foo": {"bar"}, "baz": {"baz"}},
+			expectForm: url.Values{"client_id": {"foo"}, "scope": {"foo openid"}, "request": {validRequestObjectWithoutKid}, "foo": {"bar"}, "baz": {"baz"}},
 		},
 		{
 			d:          "should fail because request uri is not whitelisted",
-			form:       url.Values{"scope": {"openid"}, "request_uri": {reqTS.URL}},
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request_uri": {reqTS.URL}},
 			client:     &DefaultOpenIDConnectClient{JSONWebKeysURI: reqJWK.URL, RequestObjectSigningAlgorithm: "RS256"},
 			expectForm: url.Values{"scope": {"foo openid"}, "request_uri": {reqTS.URL}, "foo": {"bar"}, "baz": {"baz"}},
 			expectErr:  ErrInvalidRequestURI,
 		},
 		{
 			d:          "should pass and set request_uri parameters properly and also fetch jwk from remote",
-			form:       url.Values{"scope": {"openid"}, "request_uri": {reqTS.URL}},
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request_uri": {reqTS.URL}},
 			client:     &DefaultOpenIDConnectClient{JSONWebKeysURI: reqJWK.URL, RequestObjectSigningAlgorithm: "RS256", RequestURIs: []string{reqTS.URL}},
-			expectForm: url.Values{"response_type": {"token"}, "response_mode": {"post_form"}, "scope": {"foo openid"}, "request_uri": {reqTS.URL}, "foo": {"bar"}, "baz": {"baz"}},
+			expectForm: url.Values{"client_id": {"foo"}, "response_type": {"token"}, "response_mode": {"post_form"}, "scope": {"foo openid"}, "request_uri": {reqTS.URL}, "foo": {"bar"}, "baz": {"baz"}},
 		},
 		{
 			d:          "should pass when request object uses algorithm none",
-			form:       url.Values{"scope": {"openid"}, "request": {validNoneRequestObject}},
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {validNoneRequestObject}},
 			client:     &DefaultOpenIDConnectClient{JSONWebKeysURI: reqJWK.URL, RequestObjectSigningAlgorithm: "none"},
-			expectForm: url.Values{"state": {"some-state"}, "scope": {"foo openid"}, "request": {validNoneRequestObject}, "foo": {"bar"}, "baz": {"baz"}},
+			expectForm: url.Values{"client_id": {"foo"}, "state": {"some-state"}, "scope": {"foo openid"}, "request": {validNoneRequestObject}, "foo": {"bar"}, "baz": {"baz"}},
 		},
 		{
 			d:          "should pass when request object uses algorithm none and the client did not explicitly allow any algorithm",
-			form:       url.Values{"scope": {"openid"}, "request": {validNoneRequestObject}},
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {validNoneRequestObject}},
 			client:     &DefaultOpenIDConnectClient{JSONWebKeysURI: reqJWK.URL},
-			expectForm: url.Values{"state": {"some-state"}, "scope": {"foo openid"}, "request": {validNoneRequestObject}, "foo": {"bar"}, "baz": {"baz"}},
+			expectForm: url.Values{"client_id": {"foo"}, "state": {"some-state"}, "scope": {"foo openid"}, "request": {validNoneRequestObject}, "foo": {"bar"}, "baz": {"baz"}},
+		},
+		{
+			d:          "should pass and only use the request object parameters if not openid",
+			form:       url.Values{"client_id": {"foo"}, "scope": {"bar"}, "response_type": {"code"}, "state": {"query-state"}, "request": {validRequestObject}},
+			client:     &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
+			expectForm: url.Values{"client_id": {"foo"}, "response_type": {"token"}, "response_mode": {"post_form"}, "scope": {"foo"}, "request": {validRequestObject}, "foo": {"bar"}, "baz": {"baz"}},
+		},
+		{
+			d:          "should pass and prefer the scope of the request object over the one of the query",
+			form:       url.Values{"client_id": {"foo"}, "scope": {"openid bar"}, "state": {"query-state"}, "request": {validRequestObject}},
+			client:     &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
+			expectForm: url.Values{"client_id": {"foo"}, "response_type": {"token"}, "response_mode": {"post_form"}, "scope": {"foo openid"}, "state": {"query-state"}, "request": {validRequestObject}, "foo": {"bar"}, "baz": {"baz"}},
+		},
+		{
+			d:          "should pass with matching client_id, iss and aud claims",
+			form:       url.Values{"client_id": {"foo"}, "request": {validRequestObjectWithClaims}},
+			client:     &DefaultOpenIDConnectClient{JSONWebKeys: jwks},
+			expectForm: url.Values{"client_id": {"foo"}, "scope": {"foo"}, "request": {validRequestObjectWithClaims}},
+		},
+		{
+			d:         "should fail because the client_id is missing",
+			form:      url.Values{"request": {validRequestObject}},
+			client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks},
+			expectErr: ErrInvalidRequest,
+		},
+		{
+			d:         "should fail because the client_id claim does not match",
+			form:      url.Values{"client_id": {"foo"}, "request": {mustGenerateAssertion(t, jwt.MapClaims{"client_id": "bar"}, key, "kid-foo")}},
+			client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks},
+			expectErr: ErrInvalidRequestObject,
+		},
+		{
+			d:         "should fail because the iss claim does not match",
+			form:      url.Values{"client_id": {"foo"}, "request": {mustGenerateAssertion(t, jwt.MapClaims{"iss": "bar"}, key, "kid-foo")}},
+			client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks},
+			expectErr: ErrInvalidRequestObject,
+		},
+		{
+			d:         "should fail because the aud claim does not match",
+			form:      url.Values{"client_id": {"foo"}, "request": {mustGenerateAssertion(t, jwt.MapClaims{"iss": "foo", "aud": "https://other.example.com"}, key, "kid-foo")}},
+			client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks},
+			expectErr: ErrInvalidRequestObject,
+		},
+		{
+			d:               "should fail because the iss claim of a signed request object is missing",
+			form:            url.Values{"client_id": {"foo"}, "request": {mustGenerateAssertion(t, jwt.MapClaims{"aud": "https://auth.example.com"}, key, "kid-foo")}},
+			client:          &DefaultOpenIDConnectClient{JSONWebKeys: jwks},
+			expectErr:       ErrInvalidRequestObject,
+			expectErrReason: "The 'iss' claim of the request object must be the 'client_id' of the OAuth 2.0 Client.",
+		},
+		{
+			d:               "should fail because the aud claim of a signed request object is missing",
+			form:            url.Values{"client_id": {"foo"}, "request": {mustGenerateAssertion(t, jwt.MapClaims{"iss": "foo"}, key, "kid-foo")}},
+			client:          &DefaultOpenIDConnectClient{JSONWebKeys: jwks},
+			expectErr:       ErrInvalidRequestObject,
+			expectErrReason: "The 'aud' claim of the request object must contain 'https://auth.example.com'.",
+		},
+		{
+			d:         "should fail because the request object contains a request_uri",
+			form:      url.Values{"client_id": {"foo"}, "request": {mustGenerateAssertion(t, jwt.MapClaims{"request_uri": reqTS.URL}, key, "kid-foo")}},
+			client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks},
+			expectErr: ErrInvalidRequestObject,
+		},
+		{
+			d:         "should fail because the client requires signed request objects but none was given",
+			form:      url.Values{"client_id": {"foo"}, "scope": {"openid"}},
+			client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequireSignedRequestObject: true},
+			expectErr: ErrInvalidRequest,
+		},
+		{
+			d:         "should fail because the client requires signed request objects",
+			form:      url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {validNoneRequestObject}},
+			client:    &DefaultOpenIDConnectClient{JSONWebKeysURI: reqJWK.URL, RequireSignedRequestObject: true},
+			expectErr: ErrInvalidRequestObject,
 		},
 	} {
 		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
@@ -195,7 +269,7 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 				},
 			}
 
-			err := f.authorizeRequestParametersFromOpenIDConnectRequest(context.Background(), req, false)
+			err := f.authorizeRequestParametersFromRequestObject(context.Background(), req, false)
 			if tc.expectErr != nil {
 				require.EqualError(t, err, tc.expectErr.Error(), "%+v", err)
 				if tc.expectErrReason != "" {
@@ -217,4 +291,119 @@ func TestAuthorizeRequestParametersFromOpenIDConnectRequest(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("case=should fail because remote request URIs are disabled", func(t *testing.T) {
+		f := &Fosite{Config: &Config{JWKSFetcherStrategy: NewDefaultJWKSFetcherStrategy(), DisableRemoteRequestURIs: true}}
+		req := &AuthorizeRequest{
+			Request: Request{
+				Client: &DefaultOpenIDConnectClient{JSONWebKeysURI: reqJWK.URL, RequestURIs: []string{reqTS.URL}},
+				Form:   url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request_uri": {reqTS.URL}},
+			},
+		}
+
+		err := f.authorizeRequestParametersFromRequestObject(context.Background(), req, false)
+		require.ErrorIs(t, err, ErrInvalidRequestURI)
+	})
+
+	t.Run("case=should fail because the audience of signed request objects is unknown", func(t *testing.T) {
+		req := &AuthorizeRequest{
+			Request: Request{
+				Client: &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
+				Form:   url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {validRequestObject}},
+			},
+		}
+
+		f := &Fosite{Config: &Config{JWKSFetcherStrategy: NewDefaultJWKSFetcherStrategy()}}
+		err := f.authorizeRequestParametersFromRequestObject(context.Background(), req, false)
+		require.ErrorIs(t, err, ErrServerError)
+	})
+
+	t.Run("case=encrypted request objects", func(t *testing.T) {
+		encryptionKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		f := &Fosite{Config: &Config{
+			JWKSFetcherStrategy: NewDefaultJWKSFetcherStrategy(),
+			IDTokenIssuer:       "https://auth.example.com",
+			JWTSecuredAuthorizeRequestDecrypter: &jwt.DefaultDecrypter{GetPrivateKeys: func(context.Context) (*jose.JSONWebKeySet, error) {
+				return &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: encryptionKey, KeyID: "enc", Use: "enc"}}}, nil
+			}},
+		}}
+
+		encrypt := func(t *testing.T, alg jose.KeyAlgorithm, enc jose.ContentEncryption) string {
+			encrypter, err := jose.NewEncrypter(enc, jose.Recipient{Algorithm: alg, Key: &encryptionKey.PublicKey, KeyID: "enc"}, (&jose.EncrypterOptions{}).WithContentType("JWT"))
+			require.NoError(t, err)
+			jwe, err := encrypter.Encrypt([]byte(validRequestObject))
+			require.NoError(t, err)
+			token, err := jwe.CompactSerialize()
+			require.NoError(t, err)
+			return token
+		}
+		encryptedRequestObject := encrypt(t, jose.RSA_OAEP, jose.A128CBC_HS256)
+
+		for k, tc := range []struct {
+			d         string
+			f         *Fosite
+			client    Client
+			request   string
+			expectErr error
+		}{
+			{
+				d:       "should pass with a nested request object",
+				client:  &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectSigningAlgorithm: "RS256"},
+				request: encryptedRequestObject,
+			},
+			{
+				d:       "should pass with the encryption registered by the client",
+				client:  &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectEncryptionAlgorithm: "RSA-OAEP"},
+				request: encryptedRequestObject,
+			},
+			{
+				d:         "should fail because the client requires encrypted request objects",
+				client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectEncryptionAlgorithm: "RSA-OAEP"},
+				request:   validRequestObject,
+				expectErr: ErrInvalidRequestObject,
+			},
+			{
+				d:         "should fail because the request object uses another key encryption algorithm",
+				client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectEncryptionAlgorithm: "RSA-OAEP-256"},
+				request:   encryptedRequestObject,
+				expectErr: ErrInvalidRequestObject,
+			},
+			{
+				d:         "should fail because the request object uses another content encryption algorithm",
+				client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks, RequestObjectEncryptionAlgorithm: "RSA-OAEP"},
+				request:   encrypt(t, jose.RSA_OAEP, jose.A256GCM),
+				expectErr: ErrInvalidRequestObject,
+			},
+			{
+				d:         "should fail because encrypted request objects are not supported",
+				f:         &Fosite{Config: &Config{JWKSFetcherStrategy: NewDefaultJWKSFetcherStrategy()}},
+				client:    &DefaultOpenIDConnectClient{JSONWebKeys: jwks},
+				request:   encryptedRequestObject,
+				expectErr: ErrInvalidRequestObject,
+			},
+		} {
+			t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+				req := &AuthorizeRequest{
+					Request: Request{
+						Client: tc.client,
+						Form:   url.Values{"client_id": {"foo"}, "scope": {"openid"}, "request": {tc.request}},
+					},
+				}
+
+				provider := f
+				if tc.f != nil {
+					provider = tc.f
+				}
+				err := provider.authorizeRequestParametersFromRequestObject(context.Background(), req, false)
+				if tc.expectErr != nil {
+					require.ErrorIs(t, err, tc.expectErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, "token", req.Form.Get("response_type"))
+				assert.Equal(t, "foo openid", req.Form.Get("scope"))
+			})
+		}
+	})
 }
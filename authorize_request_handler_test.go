@@ -532,6 +532,63 @@ func TestNewAuthorizeRequest(t *testing.T) {
 				},
 			},
 		},
+		/* claims parameter */
+		{
+			desc: "claims parameter is parsed",
+			conf: &Fosite{Store: store, Config: &Config{ScopeStrategy: ExactScopeStrategy, AudienceMatchingStrategy: DefaultAudienceMatchingStrategy}},
+			query: url.Values{
+				"redirect_uri":  {"https://foo.bar/cb"},
+				"client_id":     {"1234"},
+				"response_type": {"code"},
+				"state":         {"strong-state"},
+				"scope":         {"openid"},
+				"claims":        {`{"id_token":{"acr":{"essential":true,"values":["silver"]}}}`},
+				"acr_values":    {"silver bronze"},
+			},
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "1234").Return(&DefaultClient{
+					ResponseTypes: []string{"code"},
+					RedirectURIs:  []string{"https://foo.bar/cb"},
+					Scopes:        []string{"openid"},
+				}, nil)
+			},
+			expect: &AuthorizeRequest{
+				RedirectURI:        redir,
+				ResponseTypes:      []string{"code"},
+				State:              "strong-state",
+				RequestedClaims:    &ClaimsRequest{IDToken: ClaimRequests{"acr": {Essential: true, Values: []interface{}{"silver"}}}},
+				RequestedACRValues: Arguments{"silver", "bronze"},
+				Request: Request{
+					Client: &DefaultClient{
+						ResponseTypes: []string{"code"},
+						RedirectURIs:  []string{"https://foo.bar/cb"},
+						Scopes:        []string{"openid"},
+					},
+					RequestedScope: []string{"openid"},
+				},
+			},
+		},
+		/* invalid claims parameter */
+		{
+			desc: "invalid claims parameter fails",
+			conf: &Fosite{Store: store, Config: &Config{ScopeStrategy: ExactScopeStrategy, AudienceMatchingStrategy: DefaultAudienceMatchingStrategy}},
+			query: url.Values{
+				"redirect_uri":  {"https://foo.bar/cb"},
+				"client_id":     {"1234"},
+				"response_type": {"code"},
+				"state":         {"strong-state"},
+				"scope":         {"openid"},
+				"claims":        {"not json"},
+			},
+			expectedError: ErrInvalidRequest,
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "1234").Return(&DefaultClient{
+					ResponseTypes: []string{"code"},
+					RedirectURIs:  []string{"https://foo.bar/cb"},
+					Scopes:        []string{"openid"},
+				}, nil)
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
 			ctrl := gomock.NewController(t)
@@ -554,7 +611,7 @@ func TestNewAuthorizeRequest(t *testing.T) {
 				AssertObjectKeysEqual(t, &AuthorizeRequest{State: c.query.Get("state")}, ar, "State")
 			} else {
 				require.NoError(t, err)
-				AssertObjectKeysEqual(t, c.expect, ar, "ResponseTypes", "RequestedAudience", "RequestedScope", "Client", "RedirectURI", "State")
+				AssertObjectKeysEqual(t, c.expect, ar, "ResponseTypes", "RequestedAudience", "RequestedScope", "RequestedClaims", "RequestedACRValues", "Client", "RedirectURI", "State")
 				assert.NotNil(t, ar.GetRequestedAt())
 			}
 		})
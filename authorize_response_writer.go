@@ -26,6 +26,10 @@ func (f *Fosite) NewAuthorizeResponse(ctx context.Context, ar AuthorizeRequester
 	ctx = context.WithValue(ctx, AuthorizeResponseContextKey, resp)
 
 	ar.SetSession(session)
+	if s, ok := session.(ClaimsRequestSession); ok && ar.GetRequestedClaims() != nil {
+		s.SetRequestedClaims(ar.GetRequestedClaims())
+	}
+
 	for _, h := range f.Config.GetAuthorizeEndpointHandlers(ctx) {
 		if err := h.HandleAuthorizeEndpointRequest(ctx, ar, resp); err != nil {
 			return nil, err
@@ -36,9 +40,22 @@ func (f *Fosite) NewAuthorizeResponse(ctx context.Context, ar AuthorizeRequester
 		return nil, errorsx.WithStack(ErrUnsupportedResponseType)
 	}
 
-	if ar.GetDefaultResponseMode() == ResponseModeFragment && ar.GetResponseMode() == ResponseModeQuery {
+	if ar.GetDefaultResponseMode() == ResponseModeFragment && isInsecureQueryResponseMode(ar) {
 		return nil, ErrUnsupportedResponseMode.WithHintf("Insecure response_mode '%s' for the response_type '%s'.", ar.GetResponseMode(), ar.GetResponseTypes())
 	}
 
 	return resp, nil
 }
+
+// isInsecureQueryResponseMode returns true if the response mode delivers the response in the query component, which
+// must not be used for response types containing "token" or "id_token". Response mode "query.jwt" is allowed if the
+// response is encrypted, see https://openid.net/specs/oauth-v2-jarm.html#section-2.3.1
+func isInsecureQueryResponseMode(ar AuthorizeRequester) bool {
+	switch ar.GetResponseMode() {
+	case ResponseModeQuery:
+		return true
+	case ResponseModeQueryJWT:
+		return !isJWTSecuredResponseEncrypted(ar.GetClient())
+	}
+	return false
+}
@@ -19,6 +19,11 @@ func (f *Fosite) WriteAuthorizeResponse(ctx context.Context, rw http.ResponseWri
 	wh.Set("Cache-Control", "no-store")
 	wh.Set("Pragma", "no-cache")
 
+	// Identifies the authorization server to prevent mix-up attacks: https://www.rfc-editor.org/rfc/rfc9207#section-2
+	if iss := f.authorizationResponseIssuer(ctx); iss != "" {
+		resp.AddParameter("iss", iss)
+	}
+
 	redir := ar.GetRedirectURI()
 	switch rm := ar.GetResponseMode(); rm {
 	case ResponseModeFormPost:
@@ -52,10 +57,26 @@ func (f *Fosite) WriteAuthorizeResponse(ctx context.Context, rw http.ResponseWri
 		if f.ResponseModeHandler(ctx).ResponseModes().Has(rm) {
 			f.ResponseModeHandler(ctx).WriteAuthorizeResponse(ctx, rw, ar, resp)
 			return
+		} else if isJWTSecuredResponseMode(rm) {
+			if err := f.writeJWTSecuredAuthorizeResponse(ctx, rw, ar, resp.GetParameters()); err != nil {
+				f.WriteAuthorizeError(ctx, rw, ar, err)
+			}
+			return
 		}
 	}
 }
 
+// authorizationResponseIssuer returns the issuer identifier of authorization responses, which is the issuer of the
+// authorization server metadata, see https://www.rfc-editor.org/rfc/rfc9207#section-2
+func (f *Fosite) authorizationResponseIssuer(ctx context.Context) string {
+	if overrides := f.authorizationServerMetadataOverrides(ctx); overrides != nil && overrides.Issuer != "" {
+		return overrides.Issuer
+	} else if iss := f.Config.GetAccessTokenIssuer(ctx); iss != "" {
+		return iss
+	}
+	return f.Config.GetIDTokenIssuer(ctx)
+}
+
 // https://tools.ietf.org/html/rfc6749#section-4.1.1
 // When a decision is established, the authorization server directs the
 // user-agent to the provided client redirection URI using an HTTP
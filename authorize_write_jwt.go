@@ -0,0 +1,112 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite/token/jwt"
+)
+
+// isJWTSecuredResponseMode returns true if the response mode is one of the JWT Secured Authorization Response Modes
+// of https://openid.net/specs/oauth-v2-jarm.html#section-2.3.
+func isJWTSecuredResponseMode(rm ResponseModeType) bool {
+	switch rm {
+	case ResponseModeJWT, ResponseModeQueryJWT, ResponseModeFragmentJWT, ResponseModeFormPostJWT:
+		return true
+	}
+	return false
+}
+
+// jwtSecuredResponseMode resolves the "jwt" response mode to "query.jwt" or "fragment.jwt", depending on the default
+// response mode of the requested response type.
+func jwtSecuredResponseMode(ar AuthorizeRequester) ResponseModeType {
+	if rm := ar.GetResponseMode(); rm != ResponseModeJWT {
+		return rm
+	}
+
+	if responseTypes := ar.GetResponseTypes(); len(responseTypes) == 0 || responseTypes.ExactOne("code") || responseTypes.ExactOne("none") {
+		return ResponseModeQueryJWT
+	}
+	return ResponseModeFragmentJWT
+}
+
+// isJWTSecuredResponseEncrypted returns true if authorization responses sent to the client are encrypted.
+func isJWTSecuredResponseEncrypted(client Client) bool {
+	jarmClient, ok := client.(JARMClient)
+	return ok && jarmClient.GetAuthorizationEncryptedResponseAlgorithm() != ""
+}
+
+// jwtSecuredAuthorizeResponseModeSigner returns the signer of JWT secured authorization responses, or nil if the
+// configuration does not implement JWTSecuredAuthorizeResponseModeProvider.
+func (f *Fosite) jwtSecuredAuthorizeResponseModeSigner(ctx context.Context) jwt.Signer {
+	if c, ok := f.Config.(JWTSecuredAuthorizeResponseModeProvider); ok {
+		return c.GetJWTSecuredAuthorizeResponseModeSigner(ctx)
+	}
+	return nil
+}
+
+// writeJWTSecuredAuthorizeResponse encodes the authorization response parameters as a JWT and delivers it to the
+// client according to https://openid.net/specs/oauth-v2-jarm.html#section-2.3
+func (f *Fosite) writeJWTSecuredAuthorizeResponse(ctx context.Context, rw http.ResponseWriter, ar AuthorizeRequester, parameters url.Values) error {
+	token, err := f.generateJWTSecuredAuthorizeResponse(ctx, ar, parameters)
+	if err != nil {
+		return err
+	}
+
+	redir := ar.GetRedirectURI()
+	switch jwtSecuredResponseMode(ar) {
+	case ResponseModeFormPostJWT:
+		rw.Header().Set("Content-Type", "text/html;charset=UTF-8")
+		WriteAuthorizeFormPostResponse(redir.String(), url.Values{"response": {token}}, GetPostFormHTMLTemplate(ctx, f), rw)
+	case ResponseModeFragmentJWT:
+		// The endpoint URI MUST NOT include a fragment component.
+		redir.Fragment = ""
+		sendRedirect(redir.String()+"#"+url.Values{"response": {token}}.Encode(), rw)
+	default:
+		q := redir.Query()
+		q.Set("response", token)
+		redir.RawQuery = q.Encode()
+		sendRedirect(redir.String(), rw)
+	}
+
+	return nil
+}
+
+// generateJWTSecuredAuthorizeResponse returns the signed, and optionally encrypted, JWT containing the authorization
+// response parameters as specified in https://openid.net/specs/oauth-v2-jarm.html#section-2.1
+func (f *Fosite) generateJWTSecuredAuthorizeResponse(ctx context.Context, ar AuthorizeRequester, parameters url.Values) (string, error) {
+	config, ok := f.Config.(JWTSecuredAuthorizeResponseModeProvider)
+	if !ok || config.GetJWTSecuredAuthorizeResponseModeSigner(ctx) == nil {
+		return "", errorsx.WithStack(ErrServerError.WithDebug("The JWT Secured Authorization Response Mode is used but no signer is configured."))
+	}
+	signer := config.GetJWTSecuredAuthorizeResponseModeSigner(ctx)
+
+	claims := jwt.MapClaims{}
+	for key := range parameters {
+		claims[key] = parameters.Get(key)
+	}
+	claims["iss"] = config.GetJWTSecuredAuthorizeResponseModeIssuer(ctx)
+	claims["aud"] = ar.GetClient().GetID()
+	claims["exp"] = time.Now().UTC().Add(config.GetJWTSecuredAuthorizeResponseModeLifespan(ctx)).Unix()
+
+	jarmClient, ok := ar.GetClient().(JARMClient)
+	if !ok {
+		return generateSignedResponse(ctx, signer, claims, jwt.NewHeaders(), "")
+	}
+
+	token, err := generateSignedResponse(ctx, signer, claims, jwt.NewHeaders(), jarmClient.GetAuthorizationSignedResponseAlgorithm())
+	if err != nil {
+		return "", err
+	} else if jarmClient.GetAuthorizationEncryptedResponseAlgorithm() == "" {
+		return token, nil
+	}
+
+	return f.encryptResponse(ctx, jarmClient, token, jarmClient.GetAuthorizationEncryptedResponseAlgorithm(), jarmClient.GetAuthorizationEncryptedResponseEncryption())
+}
@@ -0,0 +1,156 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestWriteJWTSecuredAuthorizeResponse(t *testing.T) {
+	ctx := context.Background()
+	key := gen.MustRSAKey()
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return key, nil }}
+	f := &Fosite{Config: &Config{JWTSecuredAuthorizeResponseModeSigner: signer, JWTSecuredAuthorizeResponseModeIssuer: "https://auth.example.com"}}
+
+	newRequest := func(client Client, rm ResponseModeType, responseTypes ...string) *AuthorizeRequest {
+		ar := NewAuthorizeRequest()
+		ar.Client = client
+		ar.RedirectURI, _ = url.Parse("https://client.example.com/callback?foo=bar")
+		ar.ResponseMode = rm
+		ar.ResponseTypes = responseTypes
+		ar.State = "some-state"
+		return ar
+	}
+
+	newClient := func() *DefaultOpenIDConnectClient {
+		return &DefaultOpenIDConnectClient{
+			DefaultClient: &DefaultClient{ID: "foo", RedirectURIs: []string{"https://client.example.com/callback?foo=bar"}},
+		}
+	}
+
+	decode := func(t *testing.T, token string) jwt.MapClaims {
+		parsed, err := signer.Decode(ctx, token)
+		require.NoError(t, err)
+		return parsed.Claims
+	}
+
+	t.Run("case=should write the response to the query", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		resp := &AuthorizeResponse{Header: http.Header{}, Parameters: url.Values{"code": {"some-code"}, "state": {"some-state"}}}
+		f.WriteAuthorizeResponse(ctx, rw, newRequest(newClient(), ResponseModeJWT, "code"), resp)
+
+		require.Equal(t, http.StatusSeeOther, rw.Code)
+		location, err := url.Parse(rw.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, "bar", location.Query().Get("foo"))
+		assert.Empty(t, location.Query().Get("code"))
+
+		claims := decode(t, location.Query().Get("response"))
+		assert.Equal(t, "some-code", claims["code"])
+		assert.Equal(t, "some-state", claims["state"])
+		assert.Equal(t, "https://auth.example.com", claims["iss"])
+		assert.Equal(t, "foo", claims["aud"])
+		assert.NotEmpty(t, claims["exp"])
+	})
+
+	t.Run("case=should write the response to the fragment for implicit response types", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		resp := &AuthorizeResponse{Header: http.Header{}, Parameters: url.Values{"access_token": {"some-token"}}}
+		f.WriteAuthorizeResponse(ctx, rw, newRequest(newClient(), ResponseModeJWT, "token", "id_token"), resp)
+
+		location, err := url.Parse(rw.Header().Get("Location"))
+		require.NoError(t, err)
+		fragment, err := url.ParseQuery(location.Fragment)
+		require.NoError(t, err)
+		assert.Equal(t, "some-token", decode(t, fragment.Get("response"))["access_token"])
+	})
+
+	t.Run("case=should write the response as form post", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		resp := &AuthorizeResponse{Header: http.Header{}, Parameters: url.Values{"code": {"some-code"}}}
+		f.WriteAuthorizeResponse(ctx, rw, newRequest(newClient(), ResponseModeFormPostJWT, "code"), resp)
+
+		assert.Equal(t, "text/html;charset=UTF-8", rw.Header().Get("Content-Type"))
+		assert.Contains(t, rw.Body.String(), `name="response"`)
+		assert.NotContains(t, rw.Body.String(), "some-code")
+	})
+
+	t.Run("case=should write errors as JWT", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		f.WriteAuthorizeError(ctx, rw, newRequest(newClient(), ResponseModeFragmentJWT, "code"), ErrInvalidScope)
+
+		location, err := url.Parse(rw.Header().Get("Location"))
+		require.NoError(t, err)
+		fragment, err := url.ParseQuery(location.Fragment)
+		require.NoError(t, err)
+
+		claims := decode(t, fragment.Get("response"))
+		assert.Equal(t, ErrInvalidScope.ErrorField, claims["error"])
+		assert.Equal(t, "some-state", claims["state"])
+	})
+
+	t.Run("case=should encrypt the response", func(t *testing.T) {
+		encryptionKey := gen.MustRSAKey()
+		client := newClient()
+		client.AuthorizationEncryptedResponseAlgorithm = string(jose.RSA_OAEP)
+		client.JSONWebKeys = &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{KeyID: "sig", Use: "sig", Key: &gen.MustRSAKey().PublicKey},
+			{KeyID: "enc", Use: "enc", Key: &encryptionKey.PublicKey},
+		}}
+
+		rw := httptest.NewRecorder()
+		resp := &AuthorizeResponse{Header: http.Header{}, Parameters: url.Values{"code": {"some-code"}}}
+		f.WriteAuthorizeResponse(ctx, rw, newRequest(client, ResponseModeQueryJWT, "code"), resp)
+
+		location, err := url.Parse(rw.Header().Get("Location"))
+		require.NoError(t, err)
+		jwe, err := jose.ParseEncrypted(location.Query().Get("response"))
+		require.NoError(t, err)
+		assert.Equal(t, "enc", jwe.Header.KeyID)
+		assert.Equal(t, jose.A128CBC_HS256, jose.ContentEncryption(jwe.Header.ExtraHeaders["enc"].(string)))
+
+		nested, err := jwe.Decrypt(encryptionKey)
+		require.NoError(t, err)
+		assert.Equal(t, "some-code", decode(t, string(nested))["code"])
+	})
+
+	t.Run("case=should fail if the client requires another signing algorithm", func(t *testing.T) {
+		client := newClient()
+		client.AuthorizationSignedResponseAlgorithm = "ES256"
+
+		rw := httptest.NewRecorder()
+		resp := &AuthorizeResponse{Header: http.Header{}, Parameters: url.Values{"code": {"some-code"}}}
+		f.WriteAuthorizeResponse(ctx, rw, newRequest(client, ResponseModeQueryJWT, "code"), resp)
+
+		assert.Equal(t, http.StatusInternalServerError, rw.Code)
+		assert.Empty(t, rw.Header().Get("Location"))
+		assert.True(t, strings.Contains(rw.Body.String(), "server_error"))
+	})
+}
+
+func TestParseJWTSecuredResponseMode(t *testing.T) {
+	ctx := context.Background()
+	r := &http.Request{Form: url.Values{"response_mode": {"query.jwt"}}}
+
+	ar := NewAuthorizeRequest()
+	err := (&Fosite{Config: &Config{}}).ParseResponseMode(ctx, r, ar)
+	assert.ErrorIs(t, err, ErrUnsupportedResponseMode)
+
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return gen.MustRSAKey(), nil }}
+	require.NoError(t, (&Fosite{Config: &Config{JWTSecuredAuthorizeResponseModeSigner: signer}}).ParseResponseMode(ctx, r, ar))
+	assert.Equal(t, ResponseModeQueryJWT, ar.GetResponseMode())
+}
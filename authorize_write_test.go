@@ -6,6 +6,7 @@ package fosite_test
 import (
 	"context"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
@@ -211,3 +212,49 @@ func TestWriteAuthorizeResponse(t *testing.T) {
 		t.Logf("Passed test case %d", k)
 	}
 }
+
+type capturingResponseModeHandler struct {
+	parameters url.Values
+	issuer     interface{}
+}
+
+func (h *capturingResponseModeHandler) ResponseModes() ResponseModeTypes {
+	return ResponseModeTypes{"custom"}
+}
+
+func (h *capturingResponseModeHandler) WriteAuthorizeResponse(_ context.Context, _ http.ResponseWriter, _ AuthorizeRequester, resp AuthorizeResponder) {
+	h.parameters = resp.GetParameters()
+}
+
+func (h *capturingResponseModeHandler) WriteAuthorizeError(ctx context.Context, _ http.ResponseWriter, _ AuthorizeRequester, _ error) {
+	h.issuer = ctx.Value(AuthorizeResponseIssuerContextKey)
+}
+
+func TestWriteAuthorizeResponseIssuer(t *testing.T) {
+	extension := &capturingResponseModeHandler{}
+	f := &Fosite{Config: &Config{AccessTokenIssuer: "https://auth.example.com", ResponseModeHandlerExtension: extension}}
+
+	write := func(rm ResponseModeType) *httptest.ResponseRecorder {
+		redir, _ := url.Parse("https://client.example.com/cb")
+		ar := NewAuthorizeRequest()
+		ar.RedirectURI = redir
+		ar.ResponseMode = rm
+		resp := &AuthorizeResponse{Header: http.Header{}, Parameters: url.Values{"code": {"foo"}}}
+
+		rw := httptest.NewRecorder()
+		f.WriteAuthorizeResponse(context.Background(), rw, ar, resp)
+		return rw
+	}
+
+	rw := write(ResponseModeQuery)
+	assert.Equal(t, "https://client.example.com/cb?code=foo&iss=https%3A%2F%2Fauth.example.com", rw.Header().Get("Location"))
+
+	rw = write(ResponseModeFragment)
+	assert.Equal(t, "https://client.example.com/cb#code=foo&iss=https%3A%2F%2Fauth.example.com", rw.Header().Get("Location"))
+
+	rw = write(ResponseModeFormPost)
+	assert.Contains(t, rw.Body.String(), `name="iss" value="https://auth.example.com"`)
+
+	write("custom")
+	assert.Equal(t, "https://auth.example.com", extension.parameters.Get("iss"))
+}
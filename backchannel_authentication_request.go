@@ -0,0 +1,98 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import "time"
+
+// BackchannelAuthenticationRequest is an implementation of BackchannelAuthenticationRequester
+type BackchannelAuthenticationRequest struct {
+	Status                  BackchannelAuthenticationRequestStatus `json:"status" gorethink:"status"`
+	AuthReqIDSignature      string                                 `json:"authReqIdSignature" gorethink:"authReqIdSignature"`
+	LastPolledAt            time.Time                              `json:"lastPolledAt" gorethink:"lastPolledAt"`
+	PollingInterval         time.Duration                          `json:"pollingInterval" gorethink:"pollingInterval"`
+	LoginHint               string                                 `json:"loginHint" gorethink:"loginHint"`
+	LoginHintToken          string                                 `json:"loginHintToken" gorethink:"loginHintToken"`
+	IDTokenHintClaims       map[string]interface{}                 `json:"idTokenHintClaims" gorethink:"idTokenHintClaims"`
+	BindingMessage          string                                 `json:"bindingMessage" gorethink:"bindingMessage"`
+	UserCode                string                                 `json:"userCode" gorethink:"userCode"`
+	RequestedExpiry         time.Duration                          `json:"requestedExpiry" gorethink:"requestedExpiry"`
+	ClientNotificationToken string                                 `json:"clientNotificationToken" gorethink:"clientNotificationToken"`
+
+	Request
+}
+
+func NewBackchannelAuthenticationRequest() *BackchannelAuthenticationRequest {
+	return &BackchannelAuthenticationRequest{
+		Status:  BackchannelAuthenticationRequestStatusPending,
+		Request: *NewRequest(),
+	}
+}
+
+func (b *BackchannelAuthenticationRequest) GetStatus() BackchannelAuthenticationRequestStatus {
+	return b.Status
+}
+
+func (b *BackchannelAuthenticationRequest) SetStatus(status BackchannelAuthenticationRequestStatus) {
+	b.Status = status
+}
+
+func (b *BackchannelAuthenticationRequest) GetAuthReqIDSignature() string {
+	return b.AuthReqIDSignature
+}
+
+func (b *BackchannelAuthenticationRequest) SetAuthReqIDSignature(signature string) {
+	b.AuthReqIDSignature = signature
+}
+
+func (b *BackchannelAuthenticationRequest) GetLastPolledAt() time.Time {
+	return b.LastPolledAt
+}
+
+func (b *BackchannelAuthenticationRequest) SetLastPolledAt(t time.Time) {
+	b.LastPolledAt = t
+}
+
+func (b *BackchannelAuthenticationRequest) GetPollingInterval() time.Duration {
+	return b.PollingInterval
+}
+
+func (b *BackchannelAuthenticationRequest) SetPollingInterval(interval time.Duration) {
+	b.PollingInterval = interval
+}
+
+func (b *BackchannelAuthenticationRequest) GetLoginHint() string {
+	return b.LoginHint
+}
+
+func (b *BackchannelAuthenticationRequest) GetLoginHintToken() string {
+	return b.LoginHintToken
+}
+
+func (b *BackchannelAuthenticationRequest) GetIDTokenHintClaims() map[string]interface{} {
+	return b.IDTokenHintClaims
+}
+
+func (b *BackchannelAuthenticationRequest) GetBindingMessage() string {
+	return b.BindingMessage
+}
+
+func (b *BackchannelAuthenticationRequest) GetUserCode() string {
+	return b.UserCode
+}
+
+func (b *BackchannelAuthenticationRequest) GetRequestedExpiry() time.Duration {
+	return b.RequestedExpiry
+}
+
+func (b *BackchannelAuthenticationRequest) GetClientNotificationToken() string {
+	return b.ClientNotificationToken
+}
+
+// Sanitize returns a sanitized clone of the request which keeps the backchannel authentication state.
+func (b *BackchannelAuthenticationRequest) Sanitize(allowedParameters []string) Requester {
+	c := new(BackchannelAuthenticationRequest)
+	*c = *b
+	c.Request = *b.Request.Sanitize(allowedParameters).(*Request)
+	return c
+}
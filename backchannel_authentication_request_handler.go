@@ -0,0 +1,283 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/stringslice"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/fosite/i18n"
+	"github.com/ory/fosite/token/jwt"
+)
+
+const (
+	ErrorBackchannelAuthenticationNotSupported    = "The OpenID Provider does not support Client-Initiated Backchannel Authentication"
+	DebugBackchannelAuthenticationHandlersMissing = "'BackchannelAuthenticationEndpointHandlersProvider' not implemented"
+
+	// BackchannelTokenDeliveryModePoll is the token delivery mode in which the client polls the token endpoint.
+	BackchannelTokenDeliveryModePoll = "poll"
+	// BackchannelTokenDeliveryModePing is the token delivery mode in which the client is notified at its client
+	// notification endpoint once the end user authenticated, after which it calls the token endpoint.
+	BackchannelTokenDeliveryModePing = "ping"
+)
+
+// backchannelRequestObjectClaims are the JWT claims of signed authentication requests which are not authentication
+// request parameters.
+var backchannelRequestObjectClaims = []string{"iss", "aud", "exp", "iat", "nbf", "jti"}
+
+// backchannelAuthenticationBindingMessageMaxLength returns the maximum length of the binding_message, or the default
+// length if the configuration does not implement BackchannelAuthenticationProvider.
+func (f *Fosite) backchannelAuthenticationBindingMessageMaxLength(ctx context.Context) int {
+	if c, ok := f.Config.(BackchannelAuthenticationProvider); ok {
+		return c.GetBackchannelAuthenticationBindingMessageMaxLength(ctx)
+	}
+	return defaultBackchannelAuthenticationBindingMessageLength
+}
+
+// NewBackchannelAuthenticationRequest validates the request at the backchannel authentication endpoint as
+// specified in https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#auth_request
+// and produces a BackchannelAuthenticationRequester object.
+//
+// The application is responsible for resolving the end user identified by the login_hint or login_hint_token, and
+// must return ErrUnknownUserID or ErrExpiredLoginHintToken if that fails.
+func (f *Fosite) NewBackchannelAuthenticationRequest(ctx context.Context, r *http.Request) (_ BackchannelAuthenticationRequester, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewBackchannelAuthenticationRequest")
+	defer otelx.End(span, &err)
+
+	request := NewBackchannelAuthenticationRequest()
+	request.Lang = i18n.GetLangFromRequest(f.Config.GetMessageCatalog(ctx), r)
+
+	if r.Method != "POST" {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'POST'.", r.Method))
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to parse HTTP body, make sure to send a properly formatted form request body.").WithWrap(err).WithDebug(err.Error()))
+	}
+	request.Form = r.PostForm
+
+	// The Client MUST authenticate to the Backchannel Authentication Endpoint using the authentication method
+	// registered for its client_id.
+	client, err := f.AuthenticateClient(ctx, r, r.PostForm)
+	if err != nil {
+		var rfcerr *RFC6749Error
+		if errors.As(err, &rfcerr) && rfcerr.ErrorField != ErrInvalidClient.ErrorField {
+			return request, errorsx.WithStack(ErrInvalidClient.WithHint("The requested OAuth 2.0 Client could not be authenticated.").WithWrap(err).WithDebug(err.Error()))
+		}
+
+		return request, err
+	}
+	request.Client = client
+
+	cibaClient, ok := client.(BackchannelAuthenticationClient)
+	if !ok || !client.GetGrantTypes().Has(string(GrantTypeCIBA)) {
+		return request, errorsx.WithStack(ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant '%s'.", GrantTypeCIBA))
+	}
+
+	if err := f.backchannelAuthenticationParametersFromRequestObject(ctx, request, cibaClient); err != nil {
+		return request, err
+	}
+
+	scope := RemoveEmpty(strings.Split(request.Form.Get("scope"), " "))
+	if !Arguments(scope).Has("openid") {
+		return request, errorsx.WithStack(ErrInvalidScope.WithHint("Backchannel authentication requests must contain the 'openid' scope."))
+	}
+	for _, permission := range scope {
+		if !f.Config.GetScopeStrategy(ctx)(client.GetScopes(), permission) {
+			return request, errorsx.WithStack(ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", permission))
+		}
+	}
+	request.SetRequestedScopes(scope)
+
+	audience := GetAudiences(request.Form)
+	if err := f.Config.GetAudienceStrategy(ctx)(client.GetAudience(), audience); err != nil {
+		return request, err
+	}
+	request.SetRequestedAudience(audience)
+
+	if err := f.validateBackchannelAuthenticationHint(ctx, request); err != nil {
+		return request, err
+	}
+
+	request.BindingMessage = request.Form.Get("binding_message")
+	if max := f.backchannelAuthenticationBindingMessageMaxLength(ctx); utf8.RuneCountInString(request.BindingMessage) > max {
+		return request, errorsx.WithStack(ErrInvalidBindingMessage.WithHintf("The 'binding_message' must not be longer than %d characters.", max))
+	}
+
+	if cibaClient.GetBackchannelUserCodeParameter() {
+		request.UserCode = request.Form.Get("user_code")
+	}
+
+	if raw := request.Form.Get("requested_expiry"); raw != "" {
+		expiry, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || expiry <= 0 {
+			return request, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'requested_expiry' parameter must be a positive integer."))
+		}
+		request.RequestedExpiry = time.Duration(expiry) * time.Second
+	}
+
+	switch mode := cibaClient.GetBackchannelTokenDeliveryMode(); mode {
+	case "", BackchannelTokenDeliveryModePoll:
+	case BackchannelTokenDeliveryModePing:
+		if cibaClient.GetBackchannelClientNotificationEndpoint() == "" {
+			return request, errorsx.WithStack(ErrUnauthorizedClient.WithHint("The OAuth 2.0 Client uses token delivery mode 'ping' but has not registered a client notification endpoint."))
+		}
+
+		request.ClientNotificationToken = request.Form.Get("client_notification_token")
+		if request.ClientNotificationToken == "" {
+			return request, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'client_notification_token' parameter is required in token delivery mode 'ping'."))
+		}
+	default:
+		return request, errorsx.WithStack(ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client uses token delivery mode '%s' which is not supported.", mode))
+	}
+
+	return request, nil
+}
+
+// validateBackchannelAuthenticationHint checks that the request identifies the end user with exactly one hint and
+// verifies the id_token_hint.
+func (f *Fosite) validateBackchannelAuthenticationHint(ctx context.Context, request *BackchannelAuthenticationRequest) error {
+	var hints int
+	for _, hint := range []string{"login_hint", "login_hint_token", "id_token_hint"} {
+		if request.Form.Get(hint) != "" {
+			hints++
+		}
+	}
+	if hints != 1 {
+		return errorsx.WithStack(ErrInvalidRequest.WithHint("Exactly one of the parameters 'login_hint', 'login_hint_token' and 'id_token_hint' must be set."))
+	}
+
+	request.LoginHint = request.Form.Get("login_hint")
+	request.LoginHintToken = request.Form.Get("login_hint_token")
+	if idTokenHint := request.Form.Get("id_token_hint"); idTokenHint != "" {
+		claims, err := f.decodeIDTokenHint(ctx, idTokenHint)
+		if err != nil {
+			return err
+		}
+		request.IDTokenHintClaims = claims
+	}
+
+	return nil
+}
+
+// backchannelAuthenticationParametersFromRequestObject verifies the signed authentication request as specified in
+// https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#signed_auth_request and
+// replaces the form with its claims. Parameters outside of the signed authentication request are ignored.
+func (f *Fosite) backchannelAuthenticationParametersFromRequestObject(ctx context.Context, request *BackchannelAuthenticationRequest, client BackchannelAuthenticationClient) error {
+	assertion := request.Form.Get("request")
+	if assertion == "" {
+		if client.GetBackchannelAuthenticationRequestSigningAlgorithm() != "" {
+			return errorsx.WithStack(ErrInvalidRequest.WithHint("The OAuth 2.0 Client requires signed authentication requests, but the 'request' parameter is missing."))
+		}
+		return nil
+	}
+
+	if client.GetJSONWebKeys() == nil && len(client.GetJSONWebKeysURI()) == 0 {
+		return errorsx.WithStack(ErrInvalidRequest.WithHint("The 'request' parameter was given, but the OAuth 2.0 Client does not have any JSON Web Keys registered."))
+	}
+
+	token, err := jwt.ParseWithClaims(assertion, jwt.MapClaims{}, func(t *jwt.Token) (interface{}, error) {
+		if alg := client.GetBackchannelAuthenticationRequestSigningAlgorithm(); alg != "" && alg != fmt.Sprintf("%s", t.Header["alg"]) {
+			return nil, errorsx.WithStack(ErrInvalidRequestObject.WithHintf("The signed authentication request uses signing algorithm '%s', but the requested OAuth 2.0 Client enforces signing algorithm '%s'.", t.Header["alg"], alg))
+		}
+
+		// The signed authentication request MUST be signed with an asymmetric algorithm, "none" and MAC based
+		// algorithms are not allowed.
+		switch t.Method {
+		case jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.PS384, jose.PS512:
+			key, err := f.findClientPublicJWK(ctx, client, t, true)
+			if err != nil {
+				return nil, wrapSigningKeyFailure(
+					ErrInvalidRequestObject.WithHint("Unable to retrieve RSA signing key from OAuth 2.0 Client."), err)
+			}
+			return key, nil
+		case jose.ES256, jose.ES384, jose.ES512:
+			key, err := f.findClientPublicJWK(ctx, client, t, false)
+			if err != nil {
+				return nil, wrapSigningKeyFailure(
+					ErrInvalidRequestObject.WithHint("Unable to retrieve ECDSA signing key from OAuth 2.0 Client."), err)
+			}
+			return key, nil
+		default:
+			return nil, errorsx.WithStack(ErrInvalidRequestObject.WithHintf("The signed authentication request uses unsupported signing algorithm '%s'.", t.Header["alg"]))
+		}
+	})
+	if err != nil {
+		// Do not re-process already enhanced errors
+		var e *jwt.ValidationError
+		if errors.As(err, &e) {
+			if e.Inner != nil {
+				return e.Inner
+			}
+			return errorsx.WithStack(ErrInvalidRequestObject.WithHint("Unable to verify the signed authentication request's signature.").WithWrap(err).WithDebug(err.Error()))
+		}
+		return err
+	} else if err := token.Claims.Valid(); err != nil {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("Unable to verify the signed authentication request because its claims could not be validated.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	claims := token.Claims
+	for _, claim := range backchannelRequestObjectClaims {
+		if _, ok := claims[claim]; !ok {
+			return errorsx.WithStack(ErrInvalidRequestObject.WithHintf("The signed authentication request must contain the '%s' claim.", claim))
+		}
+	}
+
+	issuer := f.Config.GetIDTokenIssuer(ctx)
+	var jti string
+	var ok bool
+	if !claims.VerifyIssuer(client.GetID(), true) {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("Claim 'iss' of the signed authentication request must match the 'client_id' of the OAuth 2.0 Client."))
+	} else if issuer == "" {
+		return errorsx.WithStack(ErrMisconfiguration.WithHint("The OpenID Provider's issuer has not been set."))
+	} else if !claims.VerifyAudience(issuer, true) {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHintf("Claim 'aud' of the signed authentication request must contain the issuer '%s'.", issuer))
+	} else if jti, ok = claims["jti"].(string); !ok || jti == "" {
+		return errorsx.WithStack(ErrInvalidRequestObject.WithHint("Claim 'jti' of the signed authentication request must be a non-empty string."))
+	} else if f.Store.ClientAssertionJWTValid(ctx, jti) != nil {
+		return errorsx.WithStack(ErrJTIKnown.WithHint("Claim 'jti' of the signed authentication request MUST only be used once."))
+	}
+
+	// type conversion according to jwt.MapClaims.VerifyExpiresAt
+	var expiry int64
+	switch exp := claims["exp"].(type) {
+	case float64:
+		expiry = int64(exp)
+	case int64:
+		expiry = exp
+	case json.Number:
+		expiry, err = exp.Int64()
+	default:
+		err = ErrInvalidRequestObject.WithHint("Unable to type assert the expiry time from claims. This should not happen as we validate the expiry time already earlier with token.Claims.Valid()")
+	}
+	if err != nil {
+		return errorsx.WithStack(err)
+	}
+	if err := f.Store.SetClientAssertionJWT(ctx, jti, time.Unix(expiry, 0)); err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	for k, v := range claims {
+		if !stringslice.Has(backchannelRequestObjectClaims, k) {
+			form.Set(k, requestObjectParameterValue(v))
+		}
+	}
+	request.Form = form
+	return nil
+}
@@ -0,0 +1,246 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestNewBackchannelAuthenticationRequest(t *testing.T) {
+	ctx := context.Background()
+	idTokenKey := gen.MustRSAKey()
+	idTokenSigner := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return idTokenKey, nil }}
+	clientKey := gen.MustRSAKey()
+	clientSigner := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return clientKey, nil }}
+
+	newClient := func(id, mode, signingAlg string) *DefaultOpenIDConnectClient {
+		return &DefaultOpenIDConnectClient{
+			DefaultClient: &DefaultClient{
+				ID:         id,
+				Public:     true,
+				GrantTypes: []string{string(GrantTypeCIBA)},
+				Scopes:     []string{"openid", "foo"},
+			},
+			TokenEndpointAuthMethod: "none",
+			JSONWebKeys: &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{Key: &clientKey.PublicKey, KeyID: "client-key", Algorithm: "RS256", Use: "sig"},
+			}},
+			BackchannelTokenDeliveryMode:                     mode,
+			BackchannelClientNotificationEndpoint:            "https://client.example.com/cb",
+			BackchannelAuthenticationRequestSigningAlgorithm: signingAlg,
+		}
+	}
+
+	store := storage.NewMemoryStore()
+	store.Clients["poll"] = newClient("poll", "poll", "")
+	store.Clients["ping"] = newClient("ping", "ping", "")
+	store.Clients["push"] = newClient("push", "push", "")
+	store.Clients["signed"] = newClient("signed", "poll", "RS256")
+	store.Clients["no-ciba"] = &DefaultClient{ID: "no-ciba", Public: true, GrantTypes: []string{string(GrantTypeCIBA)}, Scopes: []string{"openid"}}
+
+	f := &Fosite{Store: store, Config: &Config{
+		IDTokenIssuer:            "https://auth.example.com",
+		IDTokenSigner:            idTokenSigner,
+		ScopeStrategy:            ExactScopeStrategy,
+		AudienceMatchingStrategy: DefaultAudienceMatchingStrategy,
+	}}
+
+	sign := func(t *testing.T, s jwt.Signer, claims jwt.MapClaims) string {
+		headers := jwt.NewHeaders()
+		headers.Add("kid", "client-key")
+		token, _, err := s.Generate(ctx, claims, headers)
+		require.NoError(t, err)
+		return token
+	}
+
+	requestObject := func(t *testing.T, jti string, override jwt.MapClaims) string {
+		claims := jwt.MapClaims{
+			"iss":        "signed",
+			"aud":        "https://auth.example.com",
+			"iat":        time.Now().Unix(),
+			"nbf":        time.Now().Add(-time.Minute).Unix(),
+			"exp":        time.Now().Add(time.Minute).Unix(),
+			"jti":        jti,
+			"scope":      "openid foo",
+			"login_hint": "peter@example.com",
+		}
+		for k, v := range override {
+			claims[k] = v
+		}
+		return sign(t, clientSigner, claims)
+	}
+
+	idTokenHint := sign(t, idTokenSigner, jwt.MapClaims{"sub": "peter", "iss": "https://auth.example.com", "aud": "poll", "exp": time.Now().Add(-time.Hour).Unix()})
+
+	for k, c := range []struct {
+		desc          string
+		method        string
+		form          url.Values
+		expectedError error
+		expect        func(t *testing.T, br BackchannelAuthenticationRequester)
+	}{
+		{
+			desc:          "should fail because the method is not POST",
+			method:        "GET",
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc:          "should fail because the client is unknown",
+			form:          url.Values{"client_id": {"unknown"}},
+			expectedError: ErrInvalidClient,
+		},
+		{
+			desc:          "should fail because the client does not support CIBA",
+			form:          url.Values{"client_id": {"no-ciba"}, "scope": {"openid"}, "login_hint": {"peter"}},
+			expectedError: ErrUnauthorizedClient,
+		},
+		{
+			desc:          "should fail because the openid scope is missing",
+			form:          url.Values{"client_id": {"poll"}, "scope": {"foo"}, "login_hint": {"peter"}},
+			expectedError: ErrInvalidScope,
+		},
+		{
+			desc:          "should fail because no hint is given",
+			form:          url.Values{"client_id": {"poll"}, "scope": {"openid"}},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc:          "should fail because several hints are given",
+			form:          url.Values{"client_id": {"poll"}, "scope": {"openid"}, "login_hint": {"peter"}, "login_hint_token": {"token"}},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc:          "should fail because the id_token_hint was not issued by us",
+			form:          url.Values{"client_id": {"poll"}, "scope": {"openid"}, "id_token_hint": {sign(t, clientSigner, jwt.MapClaims{"sub": "peter", "iss": "https://auth.example.com"})}},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc:          "should fail because the binding message is too long",
+			form:          url.Values{"client_id": {"poll"}, "scope": {"openid"}, "login_hint": {"peter"}, "binding_message": {strings.Repeat("a", 65)}},
+			expectedError: ErrInvalidBindingMessage,
+		},
+		{
+			desc:          "should fail because the requested expiry is invalid",
+			form:          url.Values{"client_id": {"poll"}, "scope": {"openid"}, "login_hint": {"peter"}, "requested_expiry": {"-1"}},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc:          "should fail because the client notification token is missing in ping mode",
+			form:          url.Values{"client_id": {"ping"}, "scope": {"openid"}, "login_hint": {"peter"}},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc:          "should fail because the push mode is not supported",
+			form:          url.Values{"client_id": {"push"}, "scope": {"openid"}, "login_hint": {"peter"}},
+			expectedError: ErrUnauthorizedClient,
+		},
+		{
+			desc:          "should fail because the client requires signed requests",
+			form:          url.Values{"client_id": {"signed"}, "scope": {"openid"}, "login_hint": {"peter"}},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc:          "should fail because the request object has the wrong audience",
+			form:          url.Values{"client_id": {"signed"}, "request": {requestObject(t, "jti-1", jwt.MapClaims{"aud": "https://other.example.com"})}},
+			expectedError: ErrInvalidRequestObject,
+		},
+		{
+			desc:          "should fail because the request object lacks the jti",
+			form:          url.Values{"client_id": {"signed"}, "request": {requestObject(t, "", nil)}},
+			expectedError: ErrInvalidRequestObject,
+		},
+		{
+			desc:          "should fail because the request object is not signed",
+			form:          url.Values{"client_id": {"signed"}, "request": {"eyJhbGciOiJub25lIn0.eyJpc3MiOiJzaWduZWQifQ."}},
+			expectedError: ErrInvalidRequestObject,
+		},
+		{
+			desc: "should pass with a login hint in poll mode",
+			form: url.Values{"client_id": {"poll"}, "scope": {"openid foo"}, "login_hint": {"peter"}, "binding_message": {"W4SCT"}, "requested_expiry": {"120"}},
+			expect: func(t *testing.T, br BackchannelAuthenticationRequester) {
+				assert.Equal(t, "poll", br.GetClient().GetID())
+				assert.EqualValues(t, Arguments{"openid", "foo"}, br.GetRequestedScopes())
+				assert.Equal(t, "peter", br.GetLoginHint())
+				assert.Equal(t, "W4SCT", br.GetBindingMessage())
+				assert.Equal(t, 2*time.Minute, br.GetRequestedExpiry())
+				assert.Equal(t, BackchannelAuthenticationRequestStatusPending, br.GetStatus())
+			},
+		},
+		{
+			desc: "should pass with an expired id_token_hint in ping mode",
+			form: url.Values{"client_id": {"ping"}, "scope": {"openid"}, "id_token_hint": {idTokenHint}, "client_notification_token": {"notification-token"}},
+			expect: func(t *testing.T, br BackchannelAuthenticationRequester) {
+				assert.Equal(t, "peter", br.GetIDTokenHintClaims()["sub"])
+				assert.Equal(t, "notification-token", br.GetClientNotificationToken())
+			},
+		},
+		{
+			desc: "should pass with a signed request and ignore parameters outside of it",
+			form: url.Values{"client_id": {"signed"}, "binding_message": {"ignored"}, "request": {requestObject(t, "jti-2", jwt.MapClaims{"requested_expiry": 300})}},
+			expect: func(t *testing.T, br BackchannelAuthenticationRequester) {
+				assert.Equal(t, "peter@example.com", br.GetLoginHint())
+				assert.Empty(t, br.GetBindingMessage())
+				assert.Equal(t, 5*time.Minute, br.GetRequestedExpiry())
+				assert.EqualValues(t, Arguments{"openid", "foo"}, br.GetRequestedScopes())
+			},
+		},
+		{
+			desc:          "should fail because the request object was already used",
+			form:          url.Values{"client_id": {"signed"}, "request": {requestObject(t, "jti-2", nil)}},
+			expectedError: ErrJTIKnown,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.desc), func(t *testing.T) {
+			method := c.method
+			if method == "" {
+				method = "POST"
+			}
+			r := httptest.NewRequest(method, "https://auth.example.com/oauth2/bc-authorize", strings.NewReader(c.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			br, err := f.NewBackchannelAuthenticationRequest(ctx, r)
+			if c.expectedError != nil {
+				require.ErrorIs(t, err, c.expectedError)
+
+				rw := httptest.NewRecorder()
+				f.WriteBackchannelAuthenticationError(ctx, rw, br, err)
+				assert.Contains(t, rw.Body.String(), ErrorToRFC6749Error(c.expectedError).ErrorField)
+				return
+			}
+
+			require.NoError(t, err)
+			c.expect(t, br)
+		})
+	}
+}
+
+func TestWriteBackchannelAuthenticationResponse(t *testing.T) {
+	f := &Fosite{Config: new(Config)}
+	resp := NewBackchannelAuthenticationResponse()
+	resp.SetAuthReqID("1c266114-a1be-4252-8ad1-04986c5b9ac1")
+	resp.SetExpiresIn(120)
+	resp.SetInterval(2)
+
+	rw := httptest.NewRecorder()
+	f.WriteBackchannelAuthenticationResponse(context.Background(), rw, NewBackchannelAuthenticationRequest(), resp)
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "no-store", rw.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"auth_req_id":"1c266114-a1be-4252-8ad1-04986c5b9ac1","expires_in":120,"interval":2}`, rw.Body.String())
+}
@@ -0,0 +1,82 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import "net/http"
+
+// BackchannelAuthenticationResponse is the response object of the backchannel authentication endpoint
+type BackchannelAuthenticationResponse struct {
+	AuthReqID string `json:"auth_req_id"`
+	ExpiresIn int64  `json:"expires_in"`
+	Interval  int    `json:"interval,omitempty"`
+	Header    http.Header
+	Extra     map[string]interface{}
+}
+
+func NewBackchannelAuthenticationResponse() *BackchannelAuthenticationResponse {
+	return &BackchannelAuthenticationResponse{
+		Header: http.Header{},
+		Extra:  map[string]interface{}{},
+	}
+}
+
+// GetAuthReqID gets
+func (b *BackchannelAuthenticationResponse) GetAuthReqID() string {
+	return b.AuthReqID
+}
+
+// SetAuthReqID sets
+func (b *BackchannelAuthenticationResponse) SetAuthReqID(id string) {
+	b.AuthReqID = id
+}
+
+// GetExpiresIn gets
+func (b *BackchannelAuthenticationResponse) GetExpiresIn() int64 {
+	return b.ExpiresIn
+}
+
+// SetExpiresIn sets
+func (b *BackchannelAuthenticationResponse) SetExpiresIn(seconds int64) {
+	b.ExpiresIn = seconds
+}
+
+// GetInterval gets
+func (b *BackchannelAuthenticationResponse) GetInterval() int {
+	return b.Interval
+}
+
+// SetInterval sets
+func (b *BackchannelAuthenticationResponse) SetInterval(seconds int) {
+	b.Interval = seconds
+}
+
+// GetHeader gets
+func (b *BackchannelAuthenticationResponse) GetHeader() http.Header {
+	return b.Header
+}
+
+// AddHeader adds
+func (b *BackchannelAuthenticationResponse) AddHeader(key, value string) {
+	b.Header.Add(key, value)
+}
+
+// SetExtra sets
+func (b *BackchannelAuthenticationResponse) SetExtra(key string, value interface{}) {
+	b.Extra[key] = value
+}
+
+// GetExtra gets
+func (b *BackchannelAuthenticationResponse) GetExtra(key string) interface{} {
+	return b.Extra[key]
+}
+
+// ToMap converts to a map
+func (b *BackchannelAuthenticationResponse) ToMap() map[string]interface{} {
+	b.Extra["auth_req_id"] = b.AuthReqID
+	b.Extra["expires_in"] = b.ExpiresIn
+	if b.Interval > 0 {
+		b.Extra["interval"] = b.Interval
+	}
+	return b.Extra
+}
@@ -0,0 +1,43 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/otelx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewBackchannelAuthenticationResponse executes the backchannel authentication endpoint handlers and builds the
+// response
+func (f *Fosite) NewBackchannelAuthenticationResponse(ctx context.Context, br BackchannelAuthenticationRequester, session Session) (_ BackchannelAuthenticationResponder, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewBackchannelAuthenticationResponse")
+	defer otelx.End(span, &err)
+
+	// Get handlers. If no handlers are defined, this is considered a misconfigured Fosite instance.
+	handlersProvider, ok := f.Config.(BackchannelAuthenticationEndpointHandlersProvider)
+	if !ok {
+		return nil, errorsx.WithStack(ErrServerError.WithHint(ErrorBackchannelAuthenticationNotSupported).WithDebug(DebugBackchannelAuthenticationHandlersMissing))
+	}
+
+	var resp = NewBackchannelAuthenticationResponse()
+
+	ctx = context.WithValue(ctx, BackchannelAuthenticationRequestContextKey, br)
+	ctx = context.WithValue(ctx, BackchannelAuthenticationResponseContextKey, resp)
+
+	br.SetSession(session)
+	for _, h := range handlersProvider.GetBackchannelAuthenticationEndpointHandlers(ctx) {
+		if err := h.HandleBackchannelAuthenticationEndpointRequest(ctx, br, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.GetAuthReqID() == "" {
+		return nil, errorsx.WithStack(ErrServerError.WithHint(ErrorBackchannelAuthenticationNotSupported).WithDebug("No backchannel authentication endpoint handler issued an auth_req_id."))
+	}
+
+	return resp, nil
+}
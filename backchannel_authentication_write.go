@@ -0,0 +1,61 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WriteBackchannelAuthenticationResponse writes the backchannel authentication response as specified in
+// https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#successful_authentication_request_acknowdlegment
+func (f *Fosite) WriteBackchannelAuthenticationResponse(ctx context.Context, rw http.ResponseWriter, br BackchannelAuthenticationRequester, resp BackchannelAuthenticationResponder) {
+	// Set custom headers, e.g. "X-MySuperCoolCustomHeader" or "X-DONT-CACHE-ME"...
+	wh := rw.Header()
+	rh := resp.GetHeader()
+	for k := range rh {
+		wh.Set(k, rh.Get(k))
+	}
+
+	wh.Set("Cache-Control", "no-store")
+	wh.Set("Pragma", "no-cache")
+	wh.Set("Content-Type", "application/json;charset=UTF-8")
+
+	js, err := json.Marshal(resp.ToMap())
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(js)
+}
+
+// WriteBackchannelAuthenticationError writes the backchannel authentication error as specified in
+// https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#auth_error_response
+func (f *Fosite) WriteBackchannelAuthenticationError(ctx context.Context, rw http.ResponseWriter, br BackchannelAuthenticationRequester, err error) {
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+
+	sendDebugMessagesToClient := f.Config.GetSendDebugMessagesToClients(ctx)
+	rfcerr := ErrorToRFC6749Error(err).WithLegacyFormat(f.Config.GetUseLegacyErrorFormat(ctx)).
+		WithExposeDebug(sendDebugMessagesToClient).WithLocalizer(f.Config.GetMessageCatalog(ctx), getLangFromRequester(br))
+
+	js, err := json.Marshal(rfcerr)
+	if err != nil {
+		if sendDebugMessagesToClient {
+			errorMessage := EscapeJSONString(err.Error())
+			http.Error(rw, fmt.Sprintf(`{"error":"server_error","error_description":"%s"}`, errorMessage), http.StatusInternalServerError)
+		} else {
+			http.Error(rw, `{"error":"server_error"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	rw.WriteHeader(rfcerr.CodeField)
+	_, _ = rw.Write(js)
+}
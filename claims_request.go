@@ -0,0 +1,115 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/ory/x/errorsx"
+)
+
+// ClaimsRequest is the claims authorization request parameter, which requests individual claims to be returned in
+// the ID token or from the UserInfo endpoint, see https://openid.net/specs/openid-connect-core-1_0.html#ClaimsParameter
+type ClaimsRequest struct {
+	UserInfo ClaimRequests `json:"userinfo,omitempty"`
+	IDToken  ClaimRequests `json:"id_token,omitempty"`
+}
+
+// ClaimRequests maps the names of the requested claims to how they are requested. A nil ClaimRequest requests the
+// claim in the default manner.
+type ClaimRequests map[string]*ClaimRequest
+
+// ClaimRequest describes how an individual claim is requested, see
+// https://openid.net/specs/openid-connect-core-1_0.html#IndividualClaimsRequests
+type ClaimRequest struct {
+	// Essential indicates whether the claim is necessary for the authorization the end-user requested.
+	Essential bool `json:"essential,omitempty"`
+
+	// Value requests the claim to be returned with this value.
+	Value interface{} `json:"value,omitempty"`
+
+	// Values requests the claim to be returned with one of these values, in order of preference.
+	Values []interface{} `json:"values,omitempty"`
+}
+
+// ClaimsRequestSession is implemented by sessions which carry the claims requested through the claims parameter from
+// the authorization request to the token and UserInfo endpoints, such as openid.DefaultSession.
+type ClaimsRequestSession interface {
+	// GetRequestedClaims returns the claims requested through the claims parameter, or nil.
+	GetRequestedClaims() *ClaimsRequest
+
+	// SetRequestedClaims sets the claims requested through the claims parameter.
+	SetRequestedClaims(claims *ClaimsRequest)
+}
+
+// ParseClaimsRequest parses the claims parameter. It returns nil if the parameter is empty.
+func ParseClaimsRequest(raw string) (*ClaimsRequest, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var claims ClaimsRequest
+	if err := json.Unmarshal([]byte(raw), &claims); err != nil {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to parse the 'claims' parameter, make sure it is a JSON object as specified by OpenID Connect Core 1.0.").WithWrap(err).WithDebug(err.Error()))
+	}
+	return &claims, nil
+}
+
+// GetRequestedClaims returns the claims requested through the claims parameter which is stored in the session, or nil.
+func GetRequestedClaims(session Session) *ClaimsRequest {
+	if s, ok := session.(ClaimsRequestSession); ok {
+		return s.GetRequestedClaims()
+	}
+	return nil
+}
+
+// IsEssential returns true if the claim is requested as essential claim.
+func (r *ClaimRequest) IsEssential() bool {
+	return r != nil && r.Essential
+}
+
+// Matches returns true if the value is the requested value or one of the requested values. Claims requested without
+// a value match any value.
+func (r *ClaimRequest) Matches(value interface{}) bool {
+	if r == nil || (r.Value == nil && len(r.Values) == 0) {
+		return true
+	}
+
+	if r.Value != nil && claimValuesEqual(r.Value, value) {
+		return true
+	}
+	for _, v := range r.Values {
+		if claimValuesEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the claims without those which are requested with a value or values they do not match.
+func (c ClaimRequests) Filter(claims map[string]interface{}) map[string]interface{} {
+	filtered := make(map[string]interface{}, len(claims))
+	for k, v := range claims {
+		if r, ok := c[k]; ok && !r.Matches(v) {
+			continue
+		}
+		filtered[k] = v
+	}
+	return filtered
+}
+
+// claimValuesEqual compares claim values by their JSON representation, so that numbers decoded from the claims
+// parameter match the integers of the session.
+func claimValuesEqual(a, b interface{}) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
@@ -0,0 +1,57 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+)
+
+func TestParseClaimsRequest(t *testing.T) {
+	claims, err := ParseClaimsRequest("")
+	require.NoError(t, err)
+	assert.Nil(t, claims)
+
+	_, err = ParseClaimsRequest("not json")
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	claims, err = ParseClaimsRequest(`{"userinfo":{"email":null,"email_verified":{"essential":true}},"id_token":{"sub":{"value":"peter"},"acr":{"essential":true,"values":["urn:mace:incommon:iap:silver","2"]}}}`)
+	require.NoError(t, err)
+
+	require.Contains(t, claims.UserInfo, "email")
+	assert.Nil(t, claims.UserInfo["email"])
+	assert.False(t, claims.UserInfo["email"].IsEssential())
+	assert.True(t, claims.UserInfo["email_verified"].IsEssential())
+
+	assert.True(t, claims.IDToken["sub"].Matches("peter"))
+	assert.False(t, claims.IDToken["sub"].Matches("alice"))
+	assert.True(t, claims.IDToken["acr"].IsEssential())
+	assert.True(t, claims.IDToken["acr"].Matches("urn:mace:incommon:iap:silver"))
+	assert.True(t, claims.IDToken["acr"].Matches("2"))
+	assert.False(t, claims.IDToken["acr"].Matches("0"))
+	assert.True(t, claims.IDToken["unknown"].Matches("anything"))
+}
+
+func TestClaimRequestsFilter(t *testing.T) {
+	requests := ClaimRequests{
+		"email":  nil,
+		"locale": {Value: "de"},
+		"age":    {Values: []interface{}{18, 21}},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"email": "peter@example.org",
+		"age":   21,
+		"other": "value",
+	}, requests.Filter(map[string]interface{}{
+		"email":  "peter@example.org",
+		"locale": "en",
+		"age":    21,
+		"other":  "value",
+	}))
+}
@@ -4,6 +4,8 @@
 package fosite
 
 import (
+	"time"
+
 	"github.com/go-jose/go-jose/v3"
 )
 
@@ -63,12 +65,34 @@ type OpenIDConnectClient interface {
 	GetRequestObjectSigningAlgorithm() string
 
 	// Requested Client Authentication method for the Token Endpoint. The options are client_secret_post,
-	// client_secret_basic, private_key_jwt, and none.
+	// client_secret_basic, client_secret_jwt, private_key_jwt, and none.
 	GetTokenEndpointAuthMethod() string
 
 	// JWS [JWS] alg algorithm [JWA] that MUST be used for signing the JWT [JWT] used to authenticate the
-	// Client at the Token Endpoint for the private_key_jwt authentication method.
+	// Client at the Token Endpoint for the private_key_jwt and client_secret_jwt authentication methods.
 	GetTokenEndpointAuthSigningAlgorithm() string
+
+	// GetPostLogoutRedirectURIs returns the URLs the End-User may be redirected to after a logout requested by the
+	// client, see https://openid.net/specs/openid-connect-rpinitiated-1_0.html#ClientMetadata
+	GetPostLogoutRedirectURIs() []string
+}
+
+// JWTSecuredAuthorizeRequestClient is implemented by clients which send JWT-Secured Authorization Requests as specified
+// in https://www.rfc-editor.org/rfc/rfc9101.
+type JWTSecuredAuthorizeRequestClient interface {
+	// GetRequireSignedRequestObject returns true if all authorization requests of the client must be passed as signed
+	// request objects, see https://www.rfc-editor.org/rfc/rfc9101#section-10.5.
+	GetRequireSignedRequestObject() bool
+
+	// GetRequestObjectEncryptionAlgorithm returns the JWE alg the client encrypts request objects with. If set,
+	// unencrypted request objects of the client are rejected.
+	GetRequestObjectEncryptionAlgorithm() string
+
+	// GetRequestObjectEncryptionEncryption returns the JWE enc the client encrypts request objects with. Defaults to
+	// A128CBC-HS256 if an encryption algorithm is set.
+	GetRequestObjectEncryptionEncryption() string
+
+	OpenIDConnectClient
 }
 
 // ResponseModeClient represents a client capable of handling response_mode
@@ -77,6 +101,193 @@ type ResponseModeClient interface {
 	GetResponseModes() []ResponseModeType
 }
 
+// JARMClient is implemented by clients which receive JWT secured authorization responses as specified in
+// https://openid.net/specs/oauth-v2-jarm.html.
+type JARMClient interface {
+	// GetAuthorizationSignedResponseAlgorithm returns the JWS alg the authorization responses sent to the client must
+	// be signed with. If empty, the algorithm of the configured signer is used.
+	GetAuthorizationSignedResponseAlgorithm() string
+
+	// GetAuthorizationEncryptedResponseAlgorithm returns the JWE alg the authorization responses sent to the client
+	// are encrypted with. If empty, the responses are not encrypted.
+	GetAuthorizationEncryptedResponseAlgorithm() string
+
+	// GetAuthorizationEncryptedResponseEncryption returns the JWE enc the authorization responses sent to the client
+	// are encrypted with. Defaults to A128CBC-HS256 if an encryption algorithm is set.
+	GetAuthorizationEncryptedResponseEncryption() string
+
+	ResponseModeClient
+	OpenIDConnectClient
+}
+
+// JWTIntrospectionClient is implemented by clients, usually resource servers, which receive JWT-formatted token
+// introspection responses as specified in https://www.rfc-editor.org/rfc/rfc9701.
+type JWTIntrospectionClient interface {
+	// GetIntrospectionSignedResponseAlgorithm returns the JWS alg the introspection responses sent to the client must
+	// be signed with. If empty, the algorithm of the configured signer is used.
+	GetIntrospectionSignedResponseAlgorithm() string
+
+	// GetIntrospectionEncryptedResponseAlgorithm returns the JWE alg the introspection responses sent to the client
+	// are encrypted with. If empty, the responses are not encrypted.
+	GetIntrospectionEncryptedResponseAlgorithm() string
+
+	// GetIntrospectionEncryptedResponseEncryption returns the JWE enc the introspection responses sent to the client
+	// are encrypted with. Defaults to A128CBC-HS256 if an encryption algorithm is set.
+	GetIntrospectionEncryptedResponseEncryption() string
+
+	OpenIDConnectClient
+}
+
+// UserinfoClient is implemented by OpenID Connect clients which receive signed, and optionally encrypted, UserInfo
+// responses as specified in https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata.
+type UserinfoClient interface {
+	// GetUserinfoSignedResponseAlgorithm returns the JWS alg the UserInfo responses sent to the client must be signed
+	// with. If empty, the responses are plain JSON unless they are encrypted.
+	GetUserinfoSignedResponseAlgorithm() string
+
+	// GetUserinfoEncryptedResponseAlgorithm returns the JWE alg the UserInfo responses sent to the client are
+	// encrypted with. If empty, the responses are not encrypted.
+	GetUserinfoEncryptedResponseAlgorithm() string
+
+	// GetUserinfoEncryptedResponseEncryption returns the JWE enc the UserInfo responses sent to the client are
+	// encrypted with. Defaults to A128CBC-HS256 if an encryption algorithm is set.
+	GetUserinfoEncryptedResponseEncryption() string
+
+	OpenIDConnectClient
+}
+
+// IDTokenEncryptionClient is implemented by OpenID Connect clients which receive encrypted ID tokens as specified in
+// https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata.
+type IDTokenEncryptionClient interface {
+	// GetIDTokenEncryptedResponseAlgorithm returns the JWE alg the ID tokens sent to the client are encrypted with.
+	// If empty, the ID tokens are only signed.
+	GetIDTokenEncryptedResponseAlgorithm() string
+
+	// GetIDTokenEncryptedResponseEncryption returns the JWE enc the ID tokens sent to the client are encrypted with.
+	// Defaults to A128CBC-HS256 if an encryption algorithm is set.
+	GetIDTokenEncryptedResponseEncryption() string
+
+	OpenIDConnectClient
+	Client
+}
+
+// SubjectTypeClient is implemented by OpenID Connect clients which register the subject identifier type and sector
+// identifier as specified in https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata.
+type SubjectTypeClient interface {
+	// GetSubjectType returns the subject identifier type, which is either "public" or "pairwise". If empty, the
+	// client receives public subject identifiers.
+	GetSubjectType() string
+
+	// GetSectorIdentifierURI returns the URL of the JSON array of redirect URIs whose host is the sector identifier
+	// of pairwise subject identifiers. If empty, the host of the redirect URIs is the sector identifier.
+	GetSectorIdentifierURI() string
+
+	OpenIDConnectClient
+	Client
+}
+
+// BackChannelLogoutClient is implemented by OpenID Connect clients which are notified about logouts through the
+// back-channel as specified in https://openid.net/specs/openid-connect-backchannel-1_0.html#BCRegistration.
+type BackChannelLogoutClient interface {
+	// GetBackChannelLogoutURI returns the URL logout tokens are sent to. If empty, the client is not notified.
+	GetBackChannelLogoutURI() string
+
+	// GetBackChannelLogoutSessionRequired returns true if the client requires the sid claim in logout tokens.
+	GetBackChannelLogoutSessionRequired() bool
+
+	Client
+}
+
+// FrontChannelLogoutClient is implemented by OpenID Connect clients which are notified about logouts through the
+// End-User's browser as specified in https://openid.net/specs/openid-connect-frontchannel-1_0.html#RPLogout.
+type FrontChannelLogoutClient interface {
+	// GetFrontChannelLogoutURI returns the URL which is rendered in an iframe to log the End-User out. If empty, the
+	// client is not notified.
+	GetFrontChannelLogoutURI() string
+
+	// GetFrontChannelLogoutSessionRequired returns true if the client requires the iss and sid query parameters in
+	// the front-channel logout URI and the sid claim in ID tokens.
+	GetFrontChannelLogoutSessionRequired() bool
+
+	Client
+}
+
+// BackchannelAuthenticationClient is implemented by OpenID Connect clients which use Client-Initiated Backchannel
+// Authentication as specified in
+// https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#registration.
+type BackchannelAuthenticationClient interface {
+	// GetBackchannelTokenDeliveryMode returns the token delivery mode, which is either "poll" or "ping".
+	GetBackchannelTokenDeliveryMode() string
+
+	// GetBackchannelClientNotificationEndpoint returns the URL the client is pinged at in ping mode.
+	GetBackchannelClientNotificationEndpoint() string
+
+	// GetBackchannelAuthenticationRequestSigningAlgorithm returns the algorithm signed authentication requests must
+	// use. If empty, the client may send unsigned authentication requests.
+	GetBackchannelAuthenticationRequestSigningAlgorithm() string
+
+	// GetBackchannelUserCodeParameter returns true if the client supports the user_code parameter.
+	GetBackchannelUserCodeParameter() bool
+
+	OpenIDConnectClient
+	Client
+}
+
+// RegisteredClient is implemented by clients which were registered through the Dynamic Client Registration endpoint
+// as specified in https://www.rfc-editor.org/rfc/rfc7591 and can be managed through the client configuration endpoint
+// as specified in https://www.rfc-editor.org/rfc/rfc7592.
+type RegisteredClient interface {
+	// GetClientMetadata returns the metadata the client was registered with.
+	GetClientMetadata() ClientMetadata
+
+	// GetRegistrationAccessTokenSignature returns the signature of the registration access token which grants access
+	// to the client configuration endpoint.
+	GetRegistrationAccessTokenSignature() string
+
+	// GetClientIDIssuedAt returns the time the client ID was issued.
+	GetClientIDIssuedAt() time.Time
+
+	Client
+	OpenIDConnectClient
+}
+
+// TLSClient is implemented by clients which authenticate using mutual TLS or receive certificate-bound access tokens
+// as specified in https://www.rfc-editor.org/rfc/rfc8705.
+type TLSClient interface {
+	// GetTLSClientAuthSubjectDN returns the expected subject distinguished name of the client certificate
+	// when using the "tls_client_auth" authentication method.
+	GetTLSClientAuthSubjectDN() string
+
+	// GetTLSClientAuthSANDNS returns the expected dNSName SAN entry of the client certificate.
+	GetTLSClientAuthSANDNS() string
+
+	// GetTLSClientAuthSANURI returns the expected uniformResourceIdentifier SAN entry of the client certificate.
+	GetTLSClientAuthSANURI() string
+
+	// GetTLSClientAuthSANIP returns the expected iPAddress SAN entry of the client certificate.
+	GetTLSClientAuthSANIP() string
+
+	// GetTLSClientAuthSANEmail returns the expected rfc822Name SAN entry of the client certificate.
+	GetTLSClientAuthSANEmail() string
+
+	// GetTLSClientCertificateBoundAccessTokens returns true if access tokens issued to the client are bound to
+	// its certificate.
+	GetTLSClientCertificateBoundAccessTokens() bool
+
+	OpenIDConnectClient
+}
+
+// ClientSecretJWTClient is implemented by clients which authenticate using the client_secret_jwt method of
+// https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication. As the client secret is only stored
+// hashed, the client must provide the symmetric key its client assertions are signed with.
+type ClientSecretJWTClient interface {
+	// GetClientSecretJWTKey returns the symmetric key used to verify the HMAC signature of client assertions, which
+	// usually is the plaintext client secret.
+	GetClientSecretJWTKey() ([]byte, error)
+
+	OpenIDConnectClient
+}
+
 // DefaultClient is a simple default implementation of the Client interface.
 type DefaultClient struct {
 	ID             string   `json:"id"`
@@ -90,14 +301,57 @@ type DefaultClient struct {
 	Public         bool     `json:"public"`
 }
 
+// DefaultOpenIDConnectClient is a default implementation of the OpenIDConnectClient interface. It also implements the
+// optional client interfaces, such as JARMClient or BackChannelLogoutClient, whose features are enabled by setting
+// the corresponding metadata.
 type DefaultOpenIDConnectClient struct {
 	*DefaultClient
-	JSONWebKeysURI                    string              `json:"jwks_uri"`
-	JSONWebKeys                       *jose.JSONWebKeySet `json:"jwks"`
-	TokenEndpointAuthMethod           string              `json:"token_endpoint_auth_method"`
-	RequestURIs                       []string            `json:"request_uris"`
-	RequestObjectSigningAlgorithm     string              `json:"request_object_signing_alg"`
-	TokenEndpointAuthSigningAlgorithm string              `json:"token_endpoint_auth_signing_alg"`
+	JSONWebKeysURI                                   string              `json:"jwks_uri"`
+	JSONWebKeys                                      *jose.JSONWebKeySet `json:"jwks"`
+	TokenEndpointAuthMethod                          string              `json:"token_endpoint_auth_method"`
+	RequestURIs                                      []string            `json:"request_uris"`
+	RequestObjectSigningAlgorithm                    string              `json:"request_object_signing_alg"`
+	TokenEndpointAuthSigningAlgorithm                string              `json:"token_endpoint_auth_signing_alg"`
+	PostLogoutRedirectURIs                           []string            `json:"post_logout_redirect_uris"`
+	RequireSignedRequestObject                       bool                `json:"require_signed_request_object"`
+	RequestObjectEncryptionAlgorithm                 string              `json:"request_object_encryption_alg"`
+	RequestObjectEncryptionEncryption                string              `json:"request_object_encryption_enc"`
+	SubjectType                                      string              `json:"subject_type"`
+	SectorIdentifierURI                              string              `json:"sector_identifier_uri"`
+	ClientSecretJWTKey                               []byte              `json:"-"`
+	TLSClientAuthSubjectDN                           string              `json:"tls_client_auth_subject_dn"`
+	TLSClientAuthSANDNS                              string              `json:"tls_client_auth_san_dns"`
+	TLSClientAuthSANURI                              string              `json:"tls_client_auth_san_uri"`
+	TLSClientAuthSANIP                               string              `json:"tls_client_auth_san_ip"`
+	TLSClientAuthSANEmail                            string              `json:"tls_client_auth_san_email"`
+	TLSClientCertificateBoundAccessTokens            bool                `json:"tls_client_certificate_bound_access_tokens"`
+	ResponseModes                                    []ResponseModeType  `json:"response_modes"`
+	AuthorizationSignedResponseAlgorithm             string              `json:"authorization_signed_response_alg"`
+	AuthorizationEncryptedResponseAlgorithm          string              `json:"authorization_encrypted_response_alg"`
+	AuthorizationEncryptedResponseEncryption         string              `json:"authorization_encrypted_response_enc"`
+	IntrospectionSignedResponseAlgorithm             string              `json:"introspection_signed_response_alg"`
+	IntrospectionEncryptedResponseAlgorithm          string              `json:"introspection_encrypted_response_alg"`
+	IntrospectionEncryptedResponseEncryption         string              `json:"introspection_encrypted_response_enc"`
+	UserinfoSignedResponseAlgorithm                  string              `json:"userinfo_signed_response_alg"`
+	UserinfoEncryptedResponseAlgorithm               string              `json:"userinfo_encrypted_response_alg"`
+	UserinfoEncryptedResponseEncryption              string              `json:"userinfo_encrypted_response_enc"`
+	IDTokenEncryptedResponseAlgorithm                string              `json:"id_token_encrypted_response_alg"`
+	IDTokenEncryptedResponseEncryption               string              `json:"id_token_encrypted_response_enc"`
+	BackChannelLogoutURI                             string              `json:"backchannel_logout_uri"`
+	BackChannelLogoutSessionRequired                 bool                `json:"backchannel_logout_session_required"`
+	FrontChannelLogoutURI                            string              `json:"frontchannel_logout_uri"`
+	FrontChannelLogoutSessionRequired                bool                `json:"frontchannel_logout_session_required"`
+	BackchannelTokenDeliveryMode                     string              `json:"backchannel_token_delivery_mode"`
+	BackchannelClientNotificationEndpoint            string              `json:"backchannel_client_notification_endpoint"`
+	BackchannelAuthenticationRequestSigningAlgorithm string              `json:"backchannel_authentication_request_signing_alg"`
+	BackchannelUserCodeParameter                     bool                `json:"backchannel_user_code_parameter"`
+}
+
+type DefaultRegisteredClient struct {
+	*DefaultOpenIDConnectClient
+	Metadata                         ClientMetadata `json:"metadata"`
+	RegistrationAccessTokenSignature string         `json:"registration_access_token_signature"`
+	ClientIDIssuedAt                 time.Time      `json:"client_id_issued_at"`
 }
 
 type DefaultResponseModeClient struct {
@@ -185,6 +439,150 @@ func (c *DefaultOpenIDConnectClient) GetRequestURIs() []string {
 	return c.RequestURIs
 }
 
+func (c *DefaultOpenIDConnectClient) GetPostLogoutRedirectURIs() []string {
+	return c.PostLogoutRedirectURIs
+}
+
+func (c *DefaultOpenIDConnectClient) GetRequireSignedRequestObject() bool {
+	return c.RequireSignedRequestObject
+}
+
+func (c *DefaultOpenIDConnectClient) GetRequestObjectEncryptionAlgorithm() string {
+	return c.RequestObjectEncryptionAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetRequestObjectEncryptionEncryption() string {
+	return c.RequestObjectEncryptionEncryption
+}
+
+func (c *DefaultOpenIDConnectClient) GetSubjectType() string {
+	return c.SubjectType
+}
+
+func (c *DefaultOpenIDConnectClient) GetSectorIdentifierURI() string {
+	return c.SectorIdentifierURI
+}
+
 func (c *DefaultResponseModeClient) GetResponseModes() []ResponseModeType {
 	return c.ResponseModes
 }
+
+func (c *DefaultOpenIDConnectClient) GetResponseModes() []ResponseModeType {
+	return c.ResponseModes
+}
+
+func (c *DefaultOpenIDConnectClient) GetAuthorizationSignedResponseAlgorithm() string {
+	return c.AuthorizationSignedResponseAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetAuthorizationEncryptedResponseAlgorithm() string {
+	return c.AuthorizationEncryptedResponseAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetAuthorizationEncryptedResponseEncryption() string {
+	return c.AuthorizationEncryptedResponseEncryption
+}
+
+func (c *DefaultOpenIDConnectClient) GetIntrospectionSignedResponseAlgorithm() string {
+	return c.IntrospectionSignedResponseAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetIntrospectionEncryptedResponseAlgorithm() string {
+	return c.IntrospectionEncryptedResponseAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetIntrospectionEncryptedResponseEncryption() string {
+	return c.IntrospectionEncryptedResponseEncryption
+}
+
+func (c *DefaultOpenIDConnectClient) GetUserinfoSignedResponseAlgorithm() string {
+	return c.UserinfoSignedResponseAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetUserinfoEncryptedResponseAlgorithm() string {
+	return c.UserinfoEncryptedResponseAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetUserinfoEncryptedResponseEncryption() string {
+	return c.UserinfoEncryptedResponseEncryption
+}
+
+func (c *DefaultOpenIDConnectClient) GetIDTokenEncryptedResponseAlgorithm() string {
+	return c.IDTokenEncryptedResponseAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetIDTokenEncryptedResponseEncryption() string {
+	return c.IDTokenEncryptedResponseEncryption
+}
+
+func (c *DefaultOpenIDConnectClient) GetBackChannelLogoutURI() string {
+	return c.BackChannelLogoutURI
+}
+
+func (c *DefaultOpenIDConnectClient) GetBackChannelLogoutSessionRequired() bool {
+	return c.BackChannelLogoutSessionRequired
+}
+
+func (c *DefaultOpenIDConnectClient) GetFrontChannelLogoutURI() string {
+	return c.FrontChannelLogoutURI
+}
+
+func (c *DefaultOpenIDConnectClient) GetFrontChannelLogoutSessionRequired() bool {
+	return c.FrontChannelLogoutSessionRequired
+}
+
+func (c *DefaultOpenIDConnectClient) GetBackchannelTokenDeliveryMode() string {
+	return c.BackchannelTokenDeliveryMode
+}
+
+func (c *DefaultOpenIDConnectClient) GetBackchannelClientNotificationEndpoint() string {
+	return c.BackchannelClientNotificationEndpoint
+}
+
+func (c *DefaultOpenIDConnectClient) GetBackchannelAuthenticationRequestSigningAlgorithm() string {
+	return c.BackchannelAuthenticationRequestSigningAlgorithm
+}
+
+func (c *DefaultOpenIDConnectClient) GetBackchannelUserCodeParameter() bool {
+	return c.BackchannelUserCodeParameter
+}
+
+func (c *DefaultRegisteredClient) GetClientMetadata() ClientMetadata {
+	return c.Metadata
+}
+
+func (c *DefaultRegisteredClient) GetRegistrationAccessTokenSignature() string {
+	return c.RegistrationAccessTokenSignature
+}
+
+func (c *DefaultRegisteredClient) GetClientIDIssuedAt() time.Time {
+	return c.ClientIDIssuedAt
+}
+
+func (c *DefaultOpenIDConnectClient) GetTLSClientAuthSubjectDN() string {
+	return c.TLSClientAuthSubjectDN
+}
+
+func (c *DefaultOpenIDConnectClient) GetTLSClientAuthSANDNS() string {
+	return c.TLSClientAuthSANDNS
+}
+
+func (c *DefaultOpenIDConnectClient) GetTLSClientAuthSANURI() string {
+	return c.TLSClientAuthSANURI
+}
+
+func (c *DefaultOpenIDConnectClient) GetTLSClientAuthSANIP() string {
+	return c.TLSClientAuthSANIP
+}
+
+func (c *DefaultOpenIDConnectClient) GetTLSClientAuthSANEmail() string {
+	return c.TLSClientAuthSANEmail
+}
+
+func (c *DefaultOpenIDConnectClient) GetTLSClientCertificateBoundAccessTokens() bool {
+	return c.TLSClientCertificateBoundAccessTokens
+}
+
+func (c *DefaultOpenIDConnectClient) GetClientSecretJWTKey() ([]byte, error) {
+	return c.ClientSecretJWTKey, nil
+}
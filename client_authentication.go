@@ -54,6 +54,30 @@ func (f *Fosite) findClientPublicJWK(ctx context.Context, oidcClient OpenIDConne
 	return nil, errorsx.WithStack(ErrInvalidClient.WithHint("The OAuth 2.0 Client has no JSON Web Keys set registered, but they are needed to complete the request."))
 }
 
+// findClientSymmetricKey returns the key used to verify a client_assertion signed with a HMAC algorithm
+// according to the client_secret_jwt method of https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication.
+// The hashed client secret can not be used as the HMAC key, which is why the client must implement ClientSecretJWTClient.
+func findClientSymmetricKey(oidcClient OpenIDConnectClient) (interface{}, error) {
+	if oidcClient.GetTokenEndpointAuthMethod() != "client_secret_jwt" {
+		return nil, errorsx.WithStack(ErrInvalidClient.WithHintf("The 'client_assertion' uses a symmetric signing algorithm but the requested OAuth 2.0 Client uses client authentication method '%s'.", oidcClient.GetTokenEndpointAuthMethod()))
+	}
+
+	secretClient, ok := oidcClient.(ClientSecretJWTClient)
+	if !ok {
+		return nil, errorsx.WithStack(ErrInvalidClient.WithHint("This authorization server does not support client authentication method 'client_secret_jwt'."))
+	}
+
+	key, err := secretClient.GetClientSecretJWTKey()
+	if err != nil {
+		return nil, errorsx.WithStack(ErrInvalidClient.WithWrap(err).WithDebug(err.Error()))
+	} else if len(key) == 0 {
+		return nil, errorsx.WithStack(ErrInvalidClient.WithHint("The OAuth 2.0 Client has no symmetric key registered, but it is needed to complete the request."))
+	}
+
+	// A raw byte slice would be turned into a pointer by the JWT parser, which go-jose does not accept as an HMAC key.
+	return &jose.JSONWebKey{Key: key}, nil
+}
+
 // AuthenticateClient authenticates client requests using the configured strategy
 // `Fosite.ClientAuthenticationStrategy`, if nil it uses `Fosite.DefaultClientAuthenticationStrategy`
 func (f *Fosite) AuthenticateClient(ctx context.Context, r *http.Request, form url.Values) (Client, error) {
@@ -64,7 +88,7 @@ func (f *Fosite) AuthenticateClient(ctx context.Context, r *http.Request, form u
 }
 
 // DefaultClientAuthenticationStrategy provides the fosite's default client authentication strategy,
-// HTTP Basic Authentication and JWT Bearer
+// HTTP Basic Authentication, JWT Bearer (private_key_jwt and client_secret_jwt) and mutual TLS
 func (f *Fosite) DefaultClientAuthenticationStrategy(ctx context.Context, r *http.Request, form url.Values) (Client, error) {
 	if assertionType := form.Get("client_assertion_type"); assertionType == clientAssertionJWTBearerType {
 		assertion := form.Get("client_assertion")
@@ -102,16 +126,12 @@ func (f *Fosite) DefaultClientAuthenticationStrategy(ctx context.Context, r *htt
 			}
 
 			switch oidcClient.GetTokenEndpointAuthMethod() {
-			case "private_key_jwt":
+			case "private_key_jwt", "client_secret_jwt":
 				break
 			case "none":
 				return nil, errorsx.WithStack(ErrInvalidClient.WithHint("This requested OAuth 2.0 client does not support client authentication, however 'client_assertion' was provided in the request."))
-			case "client_secret_post":
-				fallthrough
-			case "client_secret_basic":
+			case "client_secret_post", "client_secret_basic", TLSClientAuthMethod, SelfSignedTLSClientAuthMethod:
 				return nil, errorsx.WithStack(ErrInvalidClient.WithHintf("This requested OAuth 2.0 client only supports client authentication method '%s', however 'client_assertion' was provided in the request.", oidcClient.GetTokenEndpointAuthMethod()))
-			case "client_secret_jwt":
-				fallthrough
 			default:
 				return nil, errorsx.WithStack(ErrInvalidClient.WithHintf("This requested OAuth 2.0 client only supports client authentication method '%s', however that method is not supported by this server.", oidcClient.GetTokenEndpointAuthMethod()))
 			}
@@ -119,6 +139,15 @@ func (f *Fosite) DefaultClientAuthenticationStrategy(ctx context.Context, r *htt
 			if oidcClient.GetTokenEndpointAuthSigningAlgorithm() != fmt.Sprintf("%s", t.Header["alg"]) {
 				return nil, errorsx.WithStack(ErrInvalidClient.WithHintf("The 'client_assertion' uses signing algorithm '%s' but the requested OAuth 2.0 Client enforces signing algorithm '%s'.", t.Header["alg"], oidcClient.GetTokenEndpointAuthSigningAlgorithm()))
 			}
+			switch t.Method {
+			case jose.HS256, jose.HS384, jose.HS512:
+				return findClientSymmetricKey(oidcClient)
+			}
+
+			if oidcClient.GetTokenEndpointAuthMethod() != "private_key_jwt" {
+				return nil, errorsx.WithStack(ErrInvalidClient.WithHintf("The 'client_assertion' uses asymmetric signing algorithm '%s' but the requested OAuth 2.0 Client uses client authentication method '%s'.", t.Header["alg"], oidcClient.GetTokenEndpointAuthMethod()))
+			}
+
 			switch t.Method {
 			case jose.RS256, jose.RS384, jose.RS512:
 				return f.findClientPublicJWK(ctx, oidcClient, t, true)
@@ -126,8 +155,6 @@ func (f *Fosite) DefaultClientAuthenticationStrategy(ctx context.Context, r *htt
 				return f.findClientPublicJWK(ctx, oidcClient, t, false)
 			case jose.PS256, jose.PS384, jose.PS512:
 				return f.findClientPublicJWK(ctx, oidcClient, t, true)
-			case jose.HS256, jose.HS384, jose.HS512:
-				return nil, errorsx.WithStack(ErrInvalidClient.WithHint("This authorization server does not support client authentication method 'client_secret_jwt'."))
 			default:
 				return nil, errorsx.WithStack(ErrInvalidClient.WithHintf("The 'client_assertion' request parameter uses unsupported signing algorithm '%s'.", t.Header["alg"]))
 			}
@@ -212,6 +239,16 @@ func (f *Fosite) DefaultClientAuthenticationStrategy(ctx context.Context, r *htt
 		return nil, errorsx.WithStack(ErrInvalidClient.WithHintf("The OAuth 2.0 Client supports client authentication method '%s', but method 'none' was requested. You must configure the OAuth 2.0 client's 'token_endpoint_auth_method' value to accept 'none'.", oidcClient.GetTokenEndpointAuthMethod()))
 	}
 
+	if oidcClient, ok := client.(OpenIDConnectClient); ok {
+		switch oidcClient.GetTokenEndpointAuthMethod() {
+		case TLSClientAuthMethod, SelfSignedTLSClientAuthMethod:
+			if err := f.authenticateTLSClient(ctx, r, oidcClient); err != nil {
+				return nil, err
+			}
+			return client, nil
+		}
+	}
+
 	if client.IsPublic() {
 		return client, nil
 	}
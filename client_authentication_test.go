@@ -595,3 +595,106 @@ func TestAuthenticateClientTwice(t *testing.T) {
 	assert.EqualError(t, err, ErrJTIKnown.Error())
 	assert.Nil(t, c)
 }
+
+func TestClientSecretJWTAuthentication(t *testing.T) {
+	const at = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	key := []byte("aaaaaaaaaaaaaaabbbbbbbbbbbbbbbbbbbbbbbcccccccccccccccccccccddddddddddddddddddddddd")
+
+	newClient := func(method, alg string, key []byte) *DefaultOpenIDConnectClient {
+		return &DefaultOpenIDConnectClient{
+			DefaultClient:                     &DefaultClient{ID: "bar"},
+			TokenEndpointAuthMethod:           method,
+			TokenEndpointAuthSigningAlgorithm: alg,
+			ClientSecretJWTKey:                key,
+		}
+	}
+
+	newForm := func(claims jwt.MapClaims) url.Values {
+		return url.Values{"client_assertion": {mustGenerateHSAssertion(t, claims, nil, "")}, "client_assertion_type": {at}}
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "bar",
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"iss": "bar",
+			"jti": randomJTI(t),
+			"aud": "token-url",
+		}
+	}
+
+	for k, tc := range []struct {
+		d         string
+		client    Client
+		form      url.Values
+		expectErr error
+	}{
+		{
+			d:      "should pass with a valid HS256 assertion",
+			client: newClient("client_secret_jwt", "HS256", key),
+			form:   newForm(validClaims()),
+		},
+		{
+			d:         "should fail because the assertion is signed with another key",
+			client:    newClient("client_secret_jwt", "HS256", []byte("some-other-key-which-is-long-enough-for-hs256")),
+			form:      newForm(validClaims()),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:         "should fail because the client has no symmetric key",
+			client:    newClient("client_secret_jwt", "HS256", nil),
+			form:      newForm(validClaims()),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:         "should fail because the client enforces another signing algorithm",
+			client:    newClient("client_secret_jwt", "HS512", key),
+			form:      newForm(validClaims()),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:         "should fail because the client uses private_key_jwt",
+			client:    newClient("private_key_jwt", "HS256", key),
+			form:      newForm(validClaims()),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d: "should fail because the client does not provide a symmetric key",
+			client: &DefaultOpenIDConnectClient{
+				DefaultClient:                     &DefaultClient{ID: "bar"},
+				TokenEndpointAuthMethod:           "client_secret_jwt",
+				TokenEndpointAuthSigningAlgorithm: "HS256",
+			},
+			form:      newForm(validClaims()),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d:      "should fail because the audience does not match",
+			client: newClient("client_secret_jwt", "HS256", key),
+			form: newForm(func() jwt.MapClaims {
+				claims := validClaims()
+				claims["aud"] = "not-token-url"
+				return claims
+			}()),
+			expectErr: ErrInvalidClient,
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+			store := storage.NewMemoryStore()
+			store.Clients["bar"] = tc.client
+			f := &Fosite{Store: store, Config: &Config{TokenURL: "token-url"}}
+
+			c, err := f.AuthenticateClient(context.Background(), new(http.Request), tc.form)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err, "%#v", err)
+			assert.Equal(t, tc.client, c)
+
+			// replay the request and expect it to fail
+			_, err = f.AuthenticateClient(context.Background(), new(http.Request), tc.form)
+			assert.EqualError(t, err, ErrJTIKnown.Error())
+		})
+	}
+}
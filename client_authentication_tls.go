@@ -0,0 +1,305 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+)
+
+const (
+	// TLSClientAuthMethod is the PKI mutual TLS client authentication method of
+	// https://www.rfc-editor.org/rfc/rfc8705#section-2.1
+	TLSClientAuthMethod = "tls_client_auth"
+
+	// SelfSignedTLSClientAuthMethod is the self-signed certificate mutual TLS client authentication method of
+	// https://www.rfc-editor.org/rfc/rfc8705#section-2.2
+	SelfSignedTLSClientAuthMethod = "self_signed_tls_client_auth"
+)
+
+// TLSClientCertificateExtractor returns the client certificate chain of a request, leaf certificate first, or an
+// empty slice if the client did not present a certificate.
+type TLSClientCertificateExtractor func(r *http.Request) ([]*x509.Certificate, error)
+
+// DefaultTLSClientCertificateExtractor returns the certificates the client presented during the TLS handshake with
+// this server.
+func DefaultTLSClientCertificateExtractor(r *http.Request) ([]*x509.Certificate, error) {
+	if r.TLS == nil {
+		return nil, nil
+	}
+	return r.TLS.PeerCertificates, nil
+}
+
+// NewHeaderTLSClientCertificateExtractor returns a TLSClientCertificateExtractor which reads the client certificate
+// from a request header set by a TLS-terminating proxy. The header must contain the URL-encoded PEM certificate, or
+// the base64 encoded DER certificate.
+//
+// The proxy MUST remove this header from incoming requests. As the certificate chain is not verified during the TLS
+// handshake with this server, tls_client_auth requires the TLSClientCertificateAuthorities to be configured.
+func NewHeaderTLSClientCertificateExtractor(header string) TLSClientCertificateExtractor {
+	return func(r *http.Request) ([]*x509.Certificate, error) {
+		value := r.Header.Get(header)
+		if value == "" {
+			return nil, nil
+		}
+
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+
+		var der []byte
+		if block, _ := pem.Decode([]byte(value)); block != nil {
+			der = block.Bytes
+		} else if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+			der = decoded
+		} else {
+			return nil, errors.Errorf("unable to decode the client certificate from header %s", header)
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return []*x509.Certificate{cert}, nil
+	}
+}
+
+// CertificateBoundSession is implemented by sessions which can bind access tokens to a client certificate as
+// specified in https://www.rfc-editor.org/rfc/rfc8705#section-3.
+type CertificateBoundSession interface {
+	// SetCertificateThumbprint sets the base64url encoded SHA-256 thumbprint of the certificate the tokens are bound to.
+	SetCertificateThumbprint(x5t string)
+
+	// GetCertificateThumbprint returns the SHA-256 thumbprint of the certificate the tokens are bound to, or an
+	// empty string if the tokens are not bound.
+	GetCertificateThumbprint() string
+}
+
+// GetCertificateThumbprint returns the certificate thumbprint the session is bound to, or an empty string if the
+// session is not bound or does not support certificate binding.
+func GetCertificateThumbprint(session Session) string {
+	if s, ok := session.(CertificateBoundSession); ok {
+		return s.GetCertificateThumbprint()
+	}
+	return ""
+}
+
+// CertificateThumbprint returns the base64url encoded SHA-256 thumbprint of the DER encoding of the certificate, as
+// used in the "x5t#S256" confirmation method.
+func CertificateThumbprint(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// ValidateCertificateBinding verifies that a request to a protected resource was made over a mutual TLS connection
+// using the certificate the access token is bound to, if it is bound to one, as specified in
+// https://www.rfc-editor.org/rfc/rfc8705#section-3.
+func (f *Fosite) ValidateCertificateBinding(ctx context.Context, r *http.Request, session Session) error {
+	x5t := GetCertificateThumbprint(session)
+	if x5t == "" {
+		return nil
+	}
+
+	certs, err := f.tlsClientCertificateExtractor(ctx)(r)
+	if err != nil {
+		return errorsx.WithStack(ErrRequestUnauthorized.WithHint("Unable to read the client certificate.").WithWrap(err).WithDebug(err.Error()))
+	} else if len(certs) == 0 {
+		return errorsx.WithStack(ErrRequestUnauthorized.WithHint("The access token is bound to a client certificate, but no client certificate was presented."))
+	} else if CertificateThumbprint(certs[0]) != x5t {
+		return errorsx.WithStack(ErrRequestUnauthorized.WithHint("The access token is bound to a different client certificate than the one presented."))
+	}
+
+	return nil
+}
+
+// certificateThumbprintForClient returns the thumbprint of the client certificate access tokens issued to the client
+// are bound to, or an empty string if the client does not use certificate-bound access tokens.
+func (f *Fosite) certificateThumbprintForClient(ctx context.Context, r *http.Request, client Client) (string, error) {
+	tlsClient, ok := client.(TLSClient)
+	if !ok || !tlsClient.GetTLSClientCertificateBoundAccessTokens() {
+		return "", nil
+	}
+
+	certs, err := f.tlsClientCertificateExtractor(ctx)(r)
+	if err != nil {
+		return "", errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to read the client certificate.").WithWrap(err).WithDebug(err.Error()))
+	} else if len(certs) == 0 {
+		return "", errorsx.WithStack(ErrInvalidRequest.WithHint("The OAuth 2.0 Client requires certificate-bound access tokens, but no client certificate was presented."))
+	}
+
+	return CertificateThumbprint(certs[0]), nil
+}
+
+// tlsClientCertificateExtractor returns the configured client certificate extractor, or
+// DefaultTLSClientCertificateExtractor if the configuration does not implement TLSClientCertificateExtractorProvider.
+func (f *Fosite) tlsClientCertificateExtractor(ctx context.Context) TLSClientCertificateExtractor {
+	if c, ok := f.Config.(TLSClientCertificateExtractorProvider); ok {
+		return c.GetTLSClientCertificateExtractor(ctx)
+	}
+	return DefaultTLSClientCertificateExtractor
+}
+
+// tlsClientCertificateAuthorities returns the configured certificate authorities, or nil if there are none or the
+// configuration does not implement TLSClientCertificateAuthoritiesProvider.
+func (f *Fosite) tlsClientCertificateAuthorities(ctx context.Context) *x509.CertPool {
+	if c, ok := f.Config.(TLSClientCertificateAuthoritiesProvider); ok {
+		return c.GetTLSClientCertificateAuthorities(ctx)
+	}
+	return nil
+}
+
+// authenticateTLSClient authenticates the client using the certificate presented in the request according to
+// https://www.rfc-editor.org/rfc/rfc8705#section-2
+func (f *Fosite) authenticateTLSClient(ctx context.Context, r *http.Request, client OpenIDConnectClient) error {
+	certs, err := f.tlsClientCertificateExtractor(ctx)(r)
+	if err != nil {
+		return errorsx.WithStack(ErrInvalidClient.WithHint("Unable to read the client certificate.").WithWrap(err).WithDebug(err.Error()))
+	} else if len(certs) == 0 {
+		return errorsx.WithStack(ErrInvalidClient.WithHintf("The OAuth 2.0 Client supports client authentication method '%s', but no client certificate was presented.", client.GetTokenEndpointAuthMethod()))
+	}
+
+	switch client.GetTokenEndpointAuthMethod() {
+	case TLSClientAuthMethod:
+		tlsClient, ok := client.(TLSClient)
+		if !ok {
+			return errorsx.WithStack(ErrInvalidRequest.WithHint("The server configuration does not support mutual TLS client authentication."))
+		}
+		if err := f.verifyTLSClientCertificateChain(ctx, r, certs); err != nil {
+			return err
+		}
+		return matchTLSClientCertificate(tlsClient, certs[0])
+	case SelfSignedTLSClientAuthMethod:
+		return f.matchSelfSignedTLSClientCertificate(ctx, client, certs[0])
+	}
+
+	return errorsx.WithStack(ErrInvalidClient.WithHintf("The OAuth 2.0 Client does not support mutual TLS client authentication but '%s'.", client.GetTokenEndpointAuthMethod()))
+}
+
+// verifyTLSClientCertificateChain verifies that the client certificate was issued by a trusted certificate authority,
+// because PKI mutual TLS client authentication trusts the subject of the certificate. The chain is verified against
+// the configured certificate authorities or, if none are configured, must have been verified during the TLS
+// handshake with this server. Servers supporting self_signed_tls_client_auth accept any client certificate during
+// the handshake, so a certificate without verified chain is rejected here.
+func (f *Fosite) verifyTLSClientCertificateChain(ctx context.Context, r *http.Request, certs []*x509.Certificate) error {
+	if roots := f.tlsClientCertificateAuthorities(ctx); roots != nil {
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		if _, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}); err != nil {
+			return errorsx.WithStack(ErrInvalidClient.WithHint("The client certificate was not issued by a trusted certificate authority.").WithWrap(err).WithDebug(err.Error()))
+		}
+		return nil
+	}
+
+	if r.TLS != nil {
+		for _, chain := range r.TLS.VerifiedChains {
+			if len(chain) > 0 && chain[0].Equal(certs[0]) {
+				return nil
+			}
+		}
+	}
+
+	return errorsx.WithStack(ErrInvalidClient.WithHint("The certificate chain of the client certificate has not been verified."))
+}
+
+// matchTLSClientCertificate matches the certificate against the single subject metadata value registered for the
+// client, see https://www.rfc-editor.org/rfc/rfc8705#section-2.1.2
+func matchTLSClientCertificate(client TLSClient, cert *x509.Certificate) error {
+	var matches bool
+	switch {
+	case client.GetTLSClientAuthSubjectDN() != "":
+		matches = normalizeDN(cert.Subject.String()) == normalizeDN(client.GetTLSClientAuthSubjectDN())
+	case client.GetTLSClientAuthSANDNS() != "":
+		for _, name := range cert.DNSNames {
+			if strings.EqualFold(name, client.GetTLSClientAuthSANDNS()) {
+				matches = true
+			}
+		}
+	case client.GetTLSClientAuthSANURI() != "":
+		for _, uri := range cert.URIs {
+			if uri.String() == client.GetTLSClientAuthSANURI() {
+				matches = true
+			}
+		}
+	case client.GetTLSClientAuthSANIP() != "":
+		expected := net.ParseIP(client.GetTLSClientAuthSANIP())
+		for _, ip := range cert.IPAddresses {
+			if expected != nil && ip.Equal(expected) {
+				matches = true
+			}
+		}
+	case client.GetTLSClientAuthSANEmail() != "":
+		for _, email := range cert.EmailAddresses {
+			if email == client.GetTLSClientAuthSANEmail() {
+				matches = true
+			}
+		}
+	default:
+		return errorsx.WithStack(ErrInvalidClient.WithHint("The OAuth 2.0 Client uses client authentication method 'tls_client_auth' but has no expected certificate subject registered."))
+	}
+
+	if !matches {
+		return errorsx.WithStack(ErrInvalidClient.WithHint("The client certificate does not match the certificate subject registered for the OAuth 2.0 Client."))
+	}
+	return nil
+}
+
+// matchSelfSignedTLSClientCertificate matches the certificate against the certificates in the "x5c" parameter of
+// the keys registered for the client, see https://www.rfc-editor.org/rfc/rfc8705#section-2.2.2
+func (f *Fosite) matchSelfSignedTLSClientCertificate(ctx context.Context, client OpenIDConnectClient, cert *x509.Certificate) error {
+	if set := client.GetJSONWebKeys(); set != nil {
+		if containsCertificate(set, cert) {
+			return nil
+		}
+	} else if location := client.GetJSONWebKeysURI(); location != "" {
+		for _, forceRefresh := range []bool{false, true} {
+			set, err := f.Config.GetJWKSFetcherStrategy(ctx).Resolve(ctx, location, forceRefresh)
+			if err != nil {
+				return err
+			} else if containsCertificate(set, cert) {
+				return nil
+			}
+		}
+	} else {
+		return errorsx.WithStack(ErrInvalidClient.WithHint("The OAuth 2.0 Client has no JSON Web Keys set registered, but they are needed to complete the request."))
+	}
+
+	return errorsx.WithStack(ErrInvalidClient.WithHint("The client certificate does not match any certificate registered for the OAuth 2.0 Client."))
+}
+
+func containsCertificate(set *jose.JSONWebKeySet, cert *x509.Certificate) bool {
+	for _, key := range set.Keys {
+		if len(key.Certificates) > 0 && bytes.Equal(key.Certificates[0].Raw, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDN removes insignificant whitespace between the attributes of a distinguished name.
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return strings.Join(parts, ",")
+}
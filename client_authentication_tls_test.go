@@ -0,0 +1,271 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/storage"
+)
+
+func newTestCertificate(t *testing.T, commonName string, dnsNames ...string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Ory"}},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func newTestCertificateAuthority(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca", Organization: []string{"Ory"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return ca, key
+}
+
+func newIssuedTestCertificate(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, commonName string, dnsNames ...string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Ory"}},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestTLSClientAuthentication(t *testing.T) {
+	ctx := context.Background()
+	ca, caKey := newTestCertificateAuthority(t)
+	issuedCert := newIssuedTestCertificate(t, ca, caKey, "client", "client.example.com")
+	cert := newTestCertificate(t, "client", "client.example.com")
+	otherCert := newTestCertificate(t, "other", "other.example.com")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	newClient := func(method string) *DefaultOpenIDConnectClient {
+		return &DefaultOpenIDConnectClient{
+			DefaultClient:           &DefaultClient{ID: "foo", GrantTypes: []string{"client_credentials"}},
+			TokenEndpointAuthMethod: method,
+		}
+	}
+
+	newRequest := func(certs ...*x509.Certificate) *http.Request {
+		r := &http.Request{Header: http.Header{}, PostForm: url.Values{"client_id": {"foo"}}}
+		if len(certs) > 0 {
+			r.TLS = &tls.ConnectionState{PeerCertificates: certs}
+		}
+		return r
+	}
+
+	newVerifiedRequest := func(certs ...*x509.Certificate) *http.Request {
+		r := newRequest(certs...)
+		r.TLS.VerifiedChains = [][]*x509.Certificate{append(certs, ca)}
+		return r
+	}
+
+	for k, tc := range []struct {
+		d         string
+		client    func() *DefaultOpenIDConnectClient
+		config    *Config
+		request   *http.Request
+		expectErr error
+	}{
+		{
+			d: "should pass with a matching subject DN",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(TLSClientAuthMethod)
+				c.TLSClientAuthSubjectDN = "CN=client, O=Ory"
+				return c
+			},
+			request: newVerifiedRequest(issuedCert),
+		},
+		{
+			d: "should fail with a mismatching subject DN",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(TLSClientAuthMethod)
+				c.TLSClientAuthSubjectDN = "CN=client,O=Ory"
+				return c
+			},
+			request:   newVerifiedRequest(otherCert),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d: "should pass with a matching DNS SAN",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(TLSClientAuthMethod)
+				c.TLSClientAuthSANDNS = "client.example.com"
+				return c
+			},
+			request: newVerifiedRequest(issuedCert),
+		},
+		{
+			d: "should fail with a forged self-signed certificate carrying the registered subject DN",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(TLSClientAuthMethod)
+				c.TLSClientAuthSubjectDN = "CN=client,O=Ory"
+				return c
+			},
+			request:   newRequest(cert),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d: "should pass with a certificate issued by the configured certificate authorities",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(TLSClientAuthMethod)
+				c.TLSClientAuthSubjectDN = "CN=client,O=Ory"
+				return c
+			},
+			config:  &Config{TLSClientCertificateAuthorities: roots},
+			request: newRequest(issuedCert),
+		},
+		{
+			d: "should fail with a forged certificate and configured certificate authorities",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(TLSClientAuthMethod)
+				c.TLSClientAuthSubjectDN = "CN=client,O=Ory"
+				return c
+			},
+			config:    &Config{TLSClientCertificateAuthorities: roots},
+			request:   newRequest(cert),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d: "should fail without a registered subject",
+			client: func() *DefaultOpenIDConnectClient {
+				return newClient(TLSClientAuthMethod)
+			},
+			request:   newVerifiedRequest(issuedCert),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d: "should fail without a certificate",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(TLSClientAuthMethod)
+				c.TLSClientAuthSANDNS = "client.example.com"
+				return c
+			},
+			request:   newRequest(),
+			expectErr: ErrInvalidClient,
+		},
+		{
+			d: "should pass with a registered self-signed certificate",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(SelfSignedTLSClientAuthMethod)
+				c.JSONWebKeys = &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: cert.PublicKey, Certificates: []*x509.Certificate{cert}}}}
+				return c
+			},
+			request: newRequest(cert),
+		},
+		{
+			d: "should fail with an unregistered self-signed certificate",
+			client: func() *DefaultOpenIDConnectClient {
+				c := newClient(SelfSignedTLSClientAuthMethod)
+				c.JSONWebKeys = &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: cert.PublicKey, Certificates: []*x509.Certificate{cert}}}}
+				return c
+			},
+			request:   newRequest(otherCert),
+			expectErr: ErrInvalidClient,
+		},
+	} {
+		t.Run("case="+tc.d, func(t *testing.T) {
+			store := storage.NewMemoryStore()
+			store.Clients["foo"] = tc.client()
+			config := tc.config
+			if config == nil {
+				config = &Config{}
+			}
+			f := &Fosite{Store: store, Config: config}
+
+			c, err := f.DefaultClientAuthenticationStrategy(ctx, tc.request, tc.request.PostForm)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr, "%d", k)
+				return
+			}
+			require.NoError(t, err, "%d", k)
+			assert.Equal(t, "foo", c.GetID())
+		})
+	}
+}
+
+func TestHeaderTLSClientCertificateExtractor(t *testing.T) {
+	cert := newTestCertificate(t, "client")
+	extractor := NewHeaderTLSClientCertificateExtractor("X-Client-Cert")
+
+	r := &http.Request{Header: http.Header{}}
+	certs, err := extractor(r)
+	require.NoError(t, err)
+	assert.Empty(t, certs)
+
+	r.Header.Set("X-Client-Cert", url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))))
+	certs, err = extractor(r)
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+	assert.Equal(t, cert.Raw, certs[0].Raw)
+
+	r.Header.Set("X-Client-Cert", "not a certificate")
+	_, err = extractor(r)
+	assert.Error(t, err)
+}
+
+func TestValidateCertificateBinding(t *testing.T) {
+	ctx := context.Background()
+	cert := newTestCertificate(t, "client")
+	otherCert := newTestCertificate(t, "other")
+	f := &Fosite{Store: storage.NewMemoryStore(), Config: &Config{}}
+
+	bound := &DefaultSession{CertificateThumbprint: CertificateThumbprint(cert)}
+	assert.NoError(t, f.ValidateCertificateBinding(ctx, &http.Request{TLS: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}, bound))
+	assert.ErrorIs(t, f.ValidateCertificateBinding(ctx, &http.Request{TLS: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{otherCert}}}, bound), ErrRequestUnauthorized)
+	assert.ErrorIs(t, f.ValidateCertificateBinding(ctx, &http.Request{}, bound), ErrRequestUnauthorized)
+	assert.NoError(t, f.ValidateCertificateBinding(ctx, &http.Request{}, &DefaultSession{}))
+}
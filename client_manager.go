@@ -22,3 +22,18 @@ type ClientManager interface {
 	// not be replayed due to the expiry.
 	SetClientAssertionJWT(ctx context.Context, jti string, exp time.Time) error
 }
+
+// ClientRegistrationStorage extends the ClientManager with the write operations needed by the Dynamic Client
+// Registration (RFC 7591) and Management (RFC 7592) endpoints.
+type ClientRegistrationStorage interface {
+	ClientManager
+
+	// CreateClient stores a new client. It returns an error if a client with the same ID exists.
+	CreateClient(ctx context.Context, client Client) error
+
+	// UpdateClient replaces the client with the same ID or returns ErrNotFound if it does not exist.
+	UpdateClient(ctx context.Context, client Client) error
+
+	// DeleteClient deletes the client or returns ErrNotFound if it does not exist.
+	DeleteClient(ctx context.Context, id string) error
+}
@@ -0,0 +1,444 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/ory/x/errorsx"
+	"golang.org/x/text/language"
+
+	"github.com/ory/fosite/token/jwt"
+)
+
+// ClientMetadata contains the client metadata of https://www.rfc-editor.org/rfc/rfc7591#section-2
+type ClientMetadata struct {
+	RedirectURIs            []string            `json:"redirect_uris,omitempty"`
+	TokenEndpointAuthMethod string              `json:"token_endpoint_auth_method,omitempty"`
+	GrantTypes              []string            `json:"grant_types,omitempty"`
+	ResponseTypes           []string            `json:"response_types,omitempty"`
+	ClientName              string              `json:"client_name,omitempty"`
+	ClientURI               string              `json:"client_uri,omitempty"`
+	LogoURI                 string              `json:"logo_uri,omitempty"`
+	Scope                   string              `json:"scope,omitempty"`
+	Contacts                []string            `json:"contacts,omitempty"`
+	TermsOfServiceURI       string              `json:"tos_uri,omitempty"`
+	PolicyURI               string              `json:"policy_uri,omitempty"`
+	JSONWebKeysURI          string              `json:"jwks_uri,omitempty"`
+	JSONWebKeys             *jose.JSONWebKeySet `json:"jwks,omitempty"`
+	SoftwareID              string              `json:"software_id,omitempty"`
+	SoftwareVersion         string              `json:"software_version,omitempty"`
+	SoftwareStatement       string              `json:"software_statement,omitempty"`
+	SubjectType             string              `json:"subject_type,omitempty"`
+	SectorIdentifierURI     string              `json:"sector_identifier_uri,omitempty"`
+
+	// The client metadata of https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata and the
+	// specifications which extend it.
+	TokenEndpointAuthSigningAlgorithm                string   `json:"token_endpoint_auth_signing_alg,omitempty"`
+	IDTokenSignedResponseAlgorithm                   string   `json:"id_token_signed_response_alg,omitempty"`
+	IDTokenEncryptedResponseAlgorithm                string   `json:"id_token_encrypted_response_alg,omitempty"`
+	IDTokenEncryptedResponseEncryption               string   `json:"id_token_encrypted_response_enc,omitempty"`
+	UserinfoSignedResponseAlgorithm                  string   `json:"userinfo_signed_response_alg,omitempty"`
+	UserinfoEncryptedResponseAlgorithm               string   `json:"userinfo_encrypted_response_alg,omitempty"`
+	UserinfoEncryptedResponseEncryption              string   `json:"userinfo_encrypted_response_enc,omitempty"`
+	AuthorizationSignedResponseAlgorithm             string   `json:"authorization_signed_response_alg,omitempty"`
+	AuthorizationEncryptedResponseAlgorithm          string   `json:"authorization_encrypted_response_alg,omitempty"`
+	AuthorizationEncryptedResponseEncryption         string   `json:"authorization_encrypted_response_enc,omitempty"`
+	IntrospectionSignedResponseAlgorithm             string   `json:"introspection_signed_response_alg,omitempty"`
+	IntrospectionEncryptedResponseAlgorithm          string   `json:"introspection_encrypted_response_alg,omitempty"`
+	IntrospectionEncryptedResponseEncryption         string   `json:"introspection_encrypted_response_enc,omitempty"`
+	RequestURIs                                      []string `json:"request_uris,omitempty"`
+	RequestObjectSigningAlgorithm                    string   `json:"request_object_signing_alg,omitempty"`
+	RequestObjectEncryptionAlgorithm                 string   `json:"request_object_encryption_alg,omitempty"`
+	RequestObjectEncryptionEncryption                string   `json:"request_object_encryption_enc,omitempty"`
+	RequireSignedRequestObject                       bool     `json:"require_signed_request_object,omitempty"`
+	PostLogoutRedirectURIs                           []string `json:"post_logout_redirect_uris,omitempty"`
+	BackChannelLogoutURI                             string   `json:"backchannel_logout_uri,omitempty"`
+	BackChannelLogoutSessionRequired                 bool     `json:"backchannel_logout_session_required,omitempty"`
+	FrontChannelLogoutURI                            string   `json:"frontchannel_logout_uri,omitempty"`
+	FrontChannelLogoutSessionRequired                bool     `json:"frontchannel_logout_session_required,omitempty"`
+	BackchannelTokenDeliveryMode                     string   `json:"backchannel_token_delivery_mode,omitempty"`
+	BackchannelClientNotificationEndpoint            string   `json:"backchannel_client_notification_endpoint,omitempty"`
+	BackchannelAuthenticationRequestSigningAlgorithm string   `json:"backchannel_authentication_request_signing_alg,omitempty"`
+	BackchannelUserCodeParameter                     bool     `json:"backchannel_user_code_parameter,omitempty"`
+	TLSClientAuthSubjectDN                           string   `json:"tls_client_auth_subject_dn,omitempty"`
+	TLSClientAuthSANDNS                              string   `json:"tls_client_auth_san_dns,omitempty"`
+	TLSClientAuthSANURI                              string   `json:"tls_client_auth_san_uri,omitempty"`
+	TLSClientAuthSANIP                               string   `json:"tls_client_auth_san_ip,omitempty"`
+	TLSClientAuthSANEmail                            string   `json:"tls_client_auth_san_email,omitempty"`
+	TLSClientCertificateBoundAccessTokens            bool     `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+}
+
+// ClientRegistrationRequest is a request to the client registration endpoint of
+// https://www.rfc-editor.org/rfc/rfc7591#section-3.1 or to the client configuration endpoint of
+// https://www.rfc-editor.org/rfc/rfc7592#section-2.
+type ClientRegistrationRequest struct {
+	// Method is the HTTP method of the request, which selects the operation at the client configuration endpoint.
+	Method string
+
+	// Metadata is the validated client metadata of registration and update requests.
+	Metadata ClientMetadata
+
+	// Client is the registered client which is read, updated or deleted at the client configuration endpoint.
+	Client RegisteredClient
+
+	Lang language.Tag
+}
+
+// ClientRegistrationResponse is the client information response of
+// https://www.rfc-editor.org/rfc/rfc7591#section-3.2.1 and https://www.rfc-editor.org/rfc/rfc7592#section-3.
+type ClientRegistrationResponse struct {
+	Metadata                ClientMetadata
+	ClientID                string
+	ClientSecret            string
+	ClientIDIssuedAt        time.Time
+	RegistrationAccessToken string
+	RegistrationClientURI   string
+
+	// StatusCode is the HTTP status code of the response. Deleted clients are answered without a response body.
+	StatusCode int
+}
+
+// ToMap returns the members of the client information response.
+func (r *ClientRegistrationResponse) ToMap() map[string]interface{} {
+	response := map[string]interface{}{}
+	if raw, err := json.Marshal(r.Metadata); err == nil {
+		_ = json.Unmarshal(raw, &response)
+	}
+
+	response["client_id"] = r.ClientID
+	if !r.ClientIDIssuedAt.IsZero() {
+		response["client_id_issued_at"] = r.ClientIDIssuedAt.Unix()
+	}
+	if r.ClientSecret != "" {
+		response["client_secret"] = r.ClientSecret
+		// Client secrets do not expire.
+		response["client_secret_expires_at"] = 0
+	}
+	if r.RegistrationAccessToken != "" {
+		response["registration_access_token"] = r.RegistrationAccessToken
+	}
+	if r.RegistrationClientURI != "" {
+		response["registration_client_uri"] = r.RegistrationClientURI
+	}
+	return response
+}
+
+// registrableTokenEndpointAuthMethods are the client authentication methods of dynamically registered clients. The
+// client_secret_jwt method is not supported, as it requires the plaintext client secret while fosite only stores its
+// hash.
+var registrableTokenEndpointAuthMethods = map[string]bool{
+	"none":                        true,
+	"client_secret_basic":         true,
+	"client_secret_post":          true,
+	"private_key_jwt":             true,
+	TLSClientAuthMethod:           true,
+	SelfSignedTLSClientAuthMethod: true,
+}
+
+// validateClientMetadata validates the client metadata and sets the default values of
+// https://www.rfc-editor.org/rfc/rfc7591#section-2
+func (f *Fosite) validateClientMetadata(ctx context.Context, metadata *ClientMetadata) error {
+	if err := f.applySoftwareStatement(ctx, metadata); err != nil {
+		return err
+	}
+
+	if len(metadata.GrantTypes) == 0 {
+		metadata.GrantTypes = []string{"authorization_code"}
+	}
+	if len(metadata.ResponseTypes) == 0 && Arguments(metadata.GrantTypes).Has("authorization_code") {
+		metadata.ResponseTypes = []string{"code"}
+	}
+	if metadata.TokenEndpointAuthMethod == "" {
+		metadata.TokenEndpointAuthMethod = "client_secret_basic"
+	}
+
+	grantTypes := Arguments(metadata.GrantTypes)
+	if grantTypes.HasOneOf("authorization_code", "implicit") && len(metadata.RedirectURIs) == 0 {
+		return errorsx.WithStack(ErrInvalidRedirectURI.WithHint("The 'redirect_uris' field is required for the 'authorization_code' and 'implicit' grant types."))
+	}
+	for _, raw := range metadata.RedirectURIs {
+		redirectURI, err := url.Parse(raw)
+		if err != nil {
+			return errorsx.WithStack(ErrInvalidRedirectURI.WithHintf("Unable to parse redirect URI '%s'.", raw).WithWrap(err).WithDebug(err.Error()))
+		} else if !IsValidRedirectURI(redirectURI) {
+			return errorsx.WithStack(ErrInvalidRedirectURI.WithHintf("The redirect URI '%s' must be an absolute URI without a fragment component.", raw))
+		} else if !f.Config.GetRedirectSecureChecker(ctx)(ctx, redirectURI) {
+			return errorsx.WithStack(ErrInvalidRedirectURI.WithHintf("The redirect URI '%s' is not secure.", raw))
+		}
+	}
+
+	// The grant types and response types must be consistent: https://www.rfc-editor.org/rfc/rfc7591#section-2.1
+	var hasCode, hasImplicit bool
+	for _, responseType := range metadata.ResponseTypes {
+		for _, rt := range strings.Split(responseType, " ") {
+			switch rt {
+			case "code":
+				hasCode = true
+			case "token", "id_token":
+				hasImplicit = true
+			case "none":
+			default:
+				return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The response type '%s' is not supported.", responseType))
+			}
+		}
+	}
+	if hasCode && !grantTypes.Has("authorization_code") {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The response type 'code' requires the grant type 'authorization_code'."))
+	} else if hasImplicit && !grantTypes.Has("implicit") {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The response types 'token' and 'id_token' require the grant type 'implicit'."))
+	} else if grantTypes.Has("authorization_code") && !hasCode {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The grant type 'authorization_code' requires the response type 'code'."))
+	} else if grantTypes.Has("implicit") && !hasImplicit {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The grant type 'implicit' requires the response type 'token' or 'id_token'."))
+	}
+
+	if !registrableTokenEndpointAuthMethods[metadata.TokenEndpointAuthMethod] {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The token endpoint authentication method '%s' is not supported.", metadata.TokenEndpointAuthMethod))
+	}
+
+	if metadata.JSONWebKeys != nil && metadata.JSONWebKeysURI != "" {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The 'jwks' and 'jwks_uri' fields must not both be present."))
+	} else if metadata.JSONWebKeysURI != "" {
+		if u, err := url.Parse(metadata.JSONWebKeysURI); err != nil || u.Scheme != "https" || u.Host == "" {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The 'jwks_uri' field must be an HTTPS URL."))
+		}
+	}
+
+	switch metadata.TokenEndpointAuthMethod {
+	case "private_key_jwt", SelfSignedTLSClientAuthMethod:
+		if metadata.JSONWebKeys == nil && metadata.JSONWebKeysURI == "" {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The token endpoint authentication method '%s' requires the 'jwks' or 'jwks_uri' field.", metadata.TokenEndpointAuthMethod))
+		}
+	case TLSClientAuthMethod:
+		// https://www.rfc-editor.org/rfc/rfc8705#section-2.1.2
+		var n int
+		for _, v := range []string{metadata.TLSClientAuthSubjectDN, metadata.TLSClientAuthSANDNS, metadata.TLSClientAuthSANURI, metadata.TLSClientAuthSANIP, metadata.TLSClientAuthSANEmail} {
+			if v != "" {
+				n++
+			}
+		}
+		if n != 1 {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The token endpoint authentication method '%s' requires exactly one of the 'tls_client_auth_subject_dn', 'tls_client_auth_san_dns', 'tls_client_auth_san_uri', 'tls_client_auth_san_ip' and 'tls_client_auth_san_email' fields.", TLSClientAuthMethod))
+		}
+	}
+
+	if metadata.IDTokenSignedResponseAlgorithm != "" {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The 'id_token_signed_response_alg' field is not supported, ID tokens are signed with the algorithm of the authorization server."))
+	}
+	for _, e := range []struct{ field, alg, enc string }{
+		{"id_token_encrypted_response", metadata.IDTokenEncryptedResponseAlgorithm, metadata.IDTokenEncryptedResponseEncryption},
+		{"userinfo_encrypted_response", metadata.UserinfoEncryptedResponseAlgorithm, metadata.UserinfoEncryptedResponseEncryption},
+		{"authorization_encrypted_response", metadata.AuthorizationEncryptedResponseAlgorithm, metadata.AuthorizationEncryptedResponseEncryption},
+		{"introspection_encrypted_response", metadata.IntrospectionEncryptedResponseAlgorithm, metadata.IntrospectionEncryptedResponseEncryption},
+	} {
+		if e.enc != "" && e.alg == "" {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The '%s_enc' field requires the '%s_alg' field.", e.field, e.field))
+		} else if e.alg != "" && metadata.JSONWebKeys == nil && metadata.JSONWebKeysURI == "" {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The '%s_alg' field requires the 'jwks' or 'jwks_uri' field.", e.field))
+		}
+	}
+	if metadata.RequestObjectEncryptionEncryption != "" && metadata.RequestObjectEncryptionAlgorithm == "" {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The 'request_object_encryption_enc' field requires the 'request_object_encryption_alg' field."))
+	}
+
+	for _, raw := range metadata.RequestURIs {
+		// Request URIs may contain the hash of the request object as the fragment component.
+		if u, err := url.Parse(raw); err != nil || u.Scheme != "https" || u.Host == "" {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The URL '%s' of the 'request_uris' field must be an HTTPS URL.", raw))
+		}
+	}
+	for _, raw := range metadata.PostLogoutRedirectURIs {
+		if err := validateClientMetadataURL("post_logout_redirect_uris", raw, false); err != nil {
+			return err
+		}
+	}
+	if metadata.BackChannelLogoutURI != "" {
+		if err := validateClientMetadataURL("backchannel_logout_uri", metadata.BackChannelLogoutURI, false); err != nil {
+			return err
+		}
+	}
+	if metadata.FrontChannelLogoutURI != "" {
+		if err := validateClientMetadataURL("frontchannel_logout_uri", metadata.FrontChannelLogoutURI, false); err != nil {
+			return err
+		}
+	}
+
+	// https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#registration
+	switch metadata.BackchannelTokenDeliveryMode {
+	case "":
+		if grantTypes.Has(string(GrantTypeCIBA)) {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The grant type '%s' requires the 'backchannel_token_delivery_mode' field.", GrantTypeCIBA))
+		}
+	case BackchannelTokenDeliveryModePoll:
+	case BackchannelTokenDeliveryModePing:
+		if metadata.BackchannelClientNotificationEndpoint == "" {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The token delivery mode '%s' requires the 'backchannel_client_notification_endpoint' field.", BackchannelTokenDeliveryModePing))
+		}
+	default:
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The token delivery mode '%s' is not supported.", metadata.BackchannelTokenDeliveryMode))
+	}
+	if metadata.BackchannelClientNotificationEndpoint != "" {
+		if err := validateClientMetadataURL("backchannel_client_notification_endpoint", metadata.BackchannelClientNotificationEndpoint, true); err != nil {
+			return err
+		}
+	}
+
+	// https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata
+	switch metadata.SubjectType {
+	case "", SubjectTypePublic:
+	case SubjectTypePairwise:
+		if pairwiseSubjectAlgorithm(ctx, f.Config) == nil {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The subject type 'pairwise' is not supported."))
+		} else if _, err := SectorIdentifier(newRegisteredClient("", *metadata)); err != nil {
+			return err
+		}
+	default:
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The subject type '%s' is not supported.", metadata.SubjectType))
+	}
+	if metadata.SectorIdentifierURI != "" {
+		if err := ValidateSectorIdentifierURI(ctx, f.Config, metadata.SectorIdentifierURI, metadata.RedirectURIs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateClientMetadataURL returns an error if the URL of the client metadata field is not an absolute URL without
+// a fragment component, or if requireHTTPS is true and it does not use the https scheme.
+func validateClientMetadataURL(field string, raw string, requireHTTPS bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || !IsValidRedirectURI(u) {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The URL '%s' of the '%s' field must be an absolute URL without a fragment component.", raw, field))
+	} else if requireHTTPS && u.Scheme != "https" {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The URL '%s' of the '%s' field must use the https scheme.", raw, field))
+	}
+	return nil
+}
+
+// applySoftwareStatement verifies the software statement and applies the client metadata it contains, which take
+// precedence over the metadata of the request: https://www.rfc-editor.org/rfc/rfc7591#section-2.3
+func (f *Fosite) applySoftwareStatement(ctx context.Context, metadata *ClientMetadata) error {
+	statement := metadata.SoftwareStatement
+	if statement == "" {
+		return nil
+	}
+
+	var keys *jose.JSONWebKeySet
+	if c, ok := f.Config.(ClientRegistrationProvider); ok {
+		keys = c.GetSoftwareStatementKeys(ctx)
+	}
+	if keys == nil {
+		return errorsx.WithStack(ErrUnapprovedSoftwareStatement.WithHint("This authorization server does not accept software statements."))
+	}
+
+	token, err := jwt.ParseWithClaims(statement, jwt.MapClaims{}, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method {
+		case jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.PS384, jose.PS512:
+			return findPublicKey(t, keys, true)
+		case jose.ES256, jose.ES384, jose.ES512:
+			return findPublicKey(t, keys, false)
+		default:
+			return nil, errorsx.WithStack(ErrInvalidSoftwareStatement.WithHintf("The software statement uses unsupported signing algorithm '%s'.", t.Header["alg"]))
+		}
+	})
+	if err != nil {
+		return errorsx.WithStack(ErrInvalidSoftwareStatement.WithHint("Unable to verify the software statement.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	raw, err := json.Marshal(token.Claims)
+	if err != nil {
+		return errorsx.WithStack(ErrInvalidSoftwareStatement.WithWrap(err).WithDebug(err.Error()))
+	} else if err := json.Unmarshal(raw, metadata); err != nil {
+		return errorsx.WithStack(ErrInvalidSoftwareStatement.WithHint("The software statement contains malformed client metadata.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	metadata.SoftwareStatement = statement
+	return nil
+}
+
+// newRegisteredClient returns the client described by the client metadata.
+func newRegisteredClient(id string, metadata ClientMetadata) *DefaultRegisteredClient {
+	return &DefaultRegisteredClient{
+		DefaultOpenIDConnectClient: &DefaultOpenIDConnectClient{
+			DefaultClient: &DefaultClient{
+				ID:            id,
+				RedirectURIs:  metadata.RedirectURIs,
+				GrantTypes:    metadata.GrantTypes,
+				ResponseTypes: metadata.ResponseTypes,
+				Scopes:        RemoveEmpty(strings.Split(metadata.Scope, " ")),
+				Public:        metadata.TokenEndpointAuthMethod == "none",
+			},
+			JSONWebKeysURI:                                   metadata.JSONWebKeysURI,
+			JSONWebKeys:                                      metadata.JSONWebKeys,
+			TokenEndpointAuthMethod:                          metadata.TokenEndpointAuthMethod,
+			TokenEndpointAuthSigningAlgorithm:                metadata.TokenEndpointAuthSigningAlgorithm,
+			SubjectType:                                      metadata.SubjectType,
+			SectorIdentifierURI:                              metadata.SectorIdentifierURI,
+			RequestURIs:                                      metadata.RequestURIs,
+			RequestObjectSigningAlgorithm:                    metadata.RequestObjectSigningAlgorithm,
+			RequestObjectEncryptionAlgorithm:                 metadata.RequestObjectEncryptionAlgorithm,
+			RequestObjectEncryptionEncryption:                metadata.RequestObjectEncryptionEncryption,
+			RequireSignedRequestObject:                       metadata.RequireSignedRequestObject,
+			PostLogoutRedirectURIs:                           metadata.PostLogoutRedirectURIs,
+			IDTokenEncryptedResponseAlgorithm:                metadata.IDTokenEncryptedResponseAlgorithm,
+			IDTokenEncryptedResponseEncryption:               metadata.IDTokenEncryptedResponseEncryption,
+			UserinfoSignedResponseAlgorithm:                  metadata.UserinfoSignedResponseAlgorithm,
+			UserinfoEncryptedResponseAlgorithm:               metadata.UserinfoEncryptedResponseAlgorithm,
+			UserinfoEncryptedResponseEncryption:              metadata.UserinfoEncryptedResponseEncryption,
+			AuthorizationSignedResponseAlgorithm:             metadata.AuthorizationSignedResponseAlgorithm,
+			AuthorizationEncryptedResponseAlgorithm:          metadata.AuthorizationEncryptedResponseAlgorithm,
+			AuthorizationEncryptedResponseEncryption:         metadata.AuthorizationEncryptedResponseEncryption,
+			IntrospectionSignedResponseAlgorithm:             metadata.IntrospectionSignedResponseAlgorithm,
+			IntrospectionEncryptedResponseAlgorithm:          metadata.IntrospectionEncryptedResponseAlgorithm,
+			IntrospectionEncryptedResponseEncryption:         metadata.IntrospectionEncryptedResponseEncryption,
+			BackChannelLogoutURI:                             metadata.BackChannelLogoutURI,
+			BackChannelLogoutSessionRequired:                 metadata.BackChannelLogoutSessionRequired,
+			FrontChannelLogoutURI:                            metadata.FrontChannelLogoutURI,
+			FrontChannelLogoutSessionRequired:                metadata.FrontChannelLogoutSessionRequired,
+			BackchannelTokenDeliveryMode:                     metadata.BackchannelTokenDeliveryMode,
+			BackchannelClientNotificationEndpoint:            metadata.BackchannelClientNotificationEndpoint,
+			BackchannelAuthenticationRequestSigningAlgorithm: metadata.BackchannelAuthenticationRequestSigningAlgorithm,
+			BackchannelUserCodeParameter:                     metadata.BackchannelUserCodeParameter,
+			TLSClientAuthSubjectDN:                           metadata.TLSClientAuthSubjectDN,
+			TLSClientAuthSANDNS:                              metadata.TLSClientAuthSANDNS,
+			TLSClientAuthSANURI:                              metadata.TLSClientAuthSANURI,
+			TLSClientAuthSANIP:                               metadata.TLSClientAuthSANIP,
+			TLSClientAuthSANEmail:                            metadata.TLSClientAuthSANEmail,
+			TLSClientCertificateBoundAccessTokens:            metadata.TLSClientCertificateBoundAccessTokens,
+		},
+		Metadata: metadata,
+	}
+}
+
+// requiresClientSecret returns true if the client authenticates using its client secret.
+func requiresClientSecret(metadata ClientMetadata) bool {
+	return metadata.TokenEndpointAuthMethod == "client_secret_basic" || metadata.TokenEndpointAuthMethod == "client_secret_post"
+}
+
+// generateClientCredential returns a random credential, such as a client secret or registration access token.
+func generateClientCredential() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// RegistrationAccessTokenSignature returns the signature of a registration access token, which is stored instead of
+// the token itself.
+func RegistrationAccessTokenSignature(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,283 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/otelx"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/fosite/i18n"
+)
+
+const (
+	ErrorClientRegistrationNotSupported   = "The OAuth 2.0 provider does not support Dynamic Client Registration"
+	DebugClientRegistrationStorageInvalid = "'ClientRegistrationStorage' not implemented"
+)
+
+// NewClientRegistrationRequest parses and validates a request to the client registration endpoint as specified in
+// https://www.rfc-editor.org/rfc/rfc7591#section-3.1
+func (f *Fosite) NewClientRegistrationRequest(ctx context.Context, r *http.Request) (_ *ClientRegistrationRequest, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewClientRegistrationRequest")
+	defer otelx.End(span, &err)
+
+	request := &ClientRegistrationRequest{Method: r.Method, Lang: i18n.GetLangFromRequest(f.Config.GetMessageCatalog(ctx), r)}
+	if r.Method != "POST" {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'POST'.", r.Method))
+	}
+
+	metadata, _, err := parseClientMetadata(r)
+	if err != nil {
+		return request, err
+	} else if err := f.validateClientMetadata(ctx, &metadata); err != nil {
+		return request, err
+	}
+
+	request.Metadata = metadata
+	return request, nil
+}
+
+// NewClientRegistrationResponse registers the client and issues its client ID, client secret and registration access
+// token as specified in https://www.rfc-editor.org/rfc/rfc7591#section-3.2.1
+func (f *Fosite) NewClientRegistrationResponse(ctx context.Context, request *ClientRegistrationRequest) (_ *ClientRegistrationResponse, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewClientRegistrationResponse")
+	defer otelx.End(span, &err)
+
+	storage, ok := f.Store.(ClientRegistrationStorage)
+	if !ok {
+		return nil, errorsx.WithStack(ErrServerError.WithHint(ErrorClientRegistrationNotSupported).WithDebug(DebugClientRegistrationStorageInvalid))
+	}
+
+	client := newRegisteredClient(uuid.New().String(), request.Metadata)
+	client.ClientIDIssuedAt = time.Now().UTC().Round(time.Second)
+
+	response := &ClientRegistrationResponse{
+		Metadata:              request.Metadata,
+		ClientID:              client.GetID(),
+		ClientIDIssuedAt:      client.ClientIDIssuedAt,
+		RegistrationClientURI: f.registrationClientURI(ctx, client.GetID()),
+		StatusCode:            http.StatusCreated,
+	}
+
+	if requiresClientSecret(request.Metadata) {
+		if response.ClientSecret, client.Secret, err = f.generateClientSecret(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if response.RegistrationAccessToken, err = generateClientCredential(); err != nil {
+		return nil, err
+	}
+	client.RegistrationAccessTokenSignature = RegistrationAccessTokenSignature(response.RegistrationAccessToken)
+
+	if err := storage.CreateClient(ctx, client); err != nil {
+		return nil, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	request.Client = client
+	return response, nil
+}
+
+// NewClientConfigurationRequest authenticates a request to the client configuration endpoint of the given client
+// using its registration access token, and parses and validates the client metadata of update requests, see
+// https://www.rfc-editor.org/rfc/rfc7592#section-2
+func (f *Fosite) NewClientConfigurationRequest(ctx context.Context, r *http.Request, clientID string) (_ *ClientRegistrationRequest, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewClientConfigurationRequest")
+	defer otelx.End(span, &err)
+
+	request := &ClientRegistrationRequest{Method: r.Method, Lang: i18n.GetLangFromRequest(f.Config.GetMessageCatalog(ctx), r)}
+	switch r.Method {
+	case "GET", "PUT", "DELETE":
+	default:
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'GET', 'PUT' or 'DELETE'.", r.Method))
+	}
+
+	client, err := f.authenticateRegistrationAccessToken(ctx, r, clientID)
+	if err != nil {
+		return request, err
+	}
+	request.Client = client
+
+	if r.Method != "PUT" {
+		return request, nil
+	}
+
+	metadata, fields, err := parseClientMetadata(r)
+	if err != nil {
+		return request, err
+	}
+
+	// https://www.rfc-editor.org/rfc/rfc7592#section-2.2
+	var id string
+	if err := json.Unmarshal(fields["client_id"], &id); err != nil || id != clientID {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'client_id' field must match the client ID of the client configuration endpoint."))
+	}
+	for _, field := range []string{"registration_access_token", "registration_client_uri", "client_id_issued_at", "client_secret_expires_at"} {
+		if _, ok := fields[field]; ok {
+			return request, errorsx.WithStack(ErrInvalidRequest.WithHintf("The field '%s' must not be included in update requests.", field))
+		}
+	}
+	if raw, ok := fields["client_secret"]; ok {
+		var secret string
+		if err := json.Unmarshal(raw, &secret); err != nil {
+			return request, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'client_secret' field must be a string.").WithWrap(err).WithDebug(err.Error()))
+		} else if err := f.checkClientSecret(ctx, client, []byte(secret)); err != nil {
+			return request, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'client_secret' field does not match the client secret.").WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	if err := f.validateClientMetadata(ctx, &metadata); err != nil {
+		return request, err
+	}
+
+	request.Metadata = metadata
+	return request, nil
+}
+
+// NewClientConfigurationResponse reads, updates or deletes the client of a client configuration request as
+// specified in https://www.rfc-editor.org/rfc/rfc7592#section-2
+func (f *Fosite) NewClientConfigurationResponse(ctx context.Context, request *ClientRegistrationRequest) (_ *ClientRegistrationResponse, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewClientConfigurationResponse")
+	defer otelx.End(span, &err)
+
+	storage, ok := f.Store.(ClientRegistrationStorage)
+	if !ok {
+		return nil, errorsx.WithStack(ErrServerError.WithHint(ErrorClientRegistrationNotSupported).WithDebug(DebugClientRegistrationStorageInvalid))
+	}
+
+	client := request.Client
+	switch request.Method {
+	case "DELETE":
+		if err := storage.DeleteClient(ctx, client.GetID()); err != nil {
+			return nil, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+		return &ClientRegistrationResponse{ClientID: client.GetID(), StatusCode: http.StatusNoContent}, nil
+	case "PUT":
+		updated := newRegisteredClient(client.GetID(), request.Metadata)
+		updated.Secret = client.GetHashedSecret()
+		updated.RegistrationAccessTokenSignature = client.GetRegistrationAccessTokenSignature()
+		updated.ClientIDIssuedAt = client.GetClientIDIssuedAt()
+
+		response := &ClientRegistrationResponse{
+			Metadata:              request.Metadata,
+			ClientID:              client.GetID(),
+			ClientIDIssuedAt:      client.GetClientIDIssuedAt(),
+			RegistrationClientURI: f.registrationClientURI(ctx, client.GetID()),
+			StatusCode:            http.StatusOK,
+		}
+
+		// Clients which switch to a secret based authentication method are issued a client secret.
+		if !requiresClientSecret(request.Metadata) {
+			updated.Secret = nil
+		} else if len(updated.Secret) == 0 {
+			if response.ClientSecret, updated.Secret, err = f.generateClientSecret(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := storage.UpdateClient(ctx, updated); err != nil {
+			return nil, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+
+		request.Client = updated
+		return response, nil
+	default:
+		return &ClientRegistrationResponse{
+			Metadata:              client.GetClientMetadata(),
+			ClientID:              client.GetID(),
+			ClientIDIssuedAt:      client.GetClientIDIssuedAt(),
+			RegistrationClientURI: f.registrationClientURI(ctx, client.GetID()),
+			StatusCode:            http.StatusOK,
+		}, nil
+	}
+}
+
+// authenticateRegistrationAccessToken returns the registered client if the request contains its registration
+// access token. Unknown clients are rejected in the same way as invalid tokens, so that the existence of a client is
+// not revealed: https://www.rfc-editor.org/rfc/rfc7592#section-2
+func (f *Fosite) authenticateRegistrationAccessToken(ctx context.Context, r *http.Request, clientID string) (RegisteredClient, error) {
+	auth := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(auth) != 2 || !strings.EqualFold(auth[0], "bearer") || auth[1] == "" {
+		return nil, errorsx.WithStack(ErrRequestUnauthorized.WithHint("The request must contain the registration access token in the Authorization header."))
+	}
+
+	client, err := f.Store.GetClient(ctx, clientID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, errorsx.WithStack(ErrRequestUnauthorized.WithHint("The registration access token is invalid."))
+	} else if err != nil {
+		return nil, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	registered, ok := client.(RegisteredClient)
+	if !ok || subtle.ConstantTimeCompare([]byte(RegistrationAccessTokenSignature(auth[1])), []byte(registered.GetRegistrationAccessTokenSignature())) != 1 {
+		return nil, errorsx.WithStack(ErrRequestUnauthorized.WithHint("The registration access token is invalid."))
+	}
+
+	return registered, nil
+}
+
+// generateClientSecret returns a new client secret and its hash.
+func (f *Fosite) generateClientSecret(ctx context.Context) (string, []byte, error) {
+	secret, err := generateClientCredential()
+	if err != nil {
+		return "", nil, err
+	}
+
+	hash, err := f.Config.GetSecretsHasher(ctx).Hash(ctx, []byte(secret))
+	if err != nil {
+		return "", nil, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return secret, hash, nil
+}
+
+// clientRegistrationURL returns the URL of the client registration endpoint, or an empty string if the configuration
+// does not implement ClientRegistrationProvider.
+func (f *Fosite) clientRegistrationURL(ctx context.Context) string {
+	if c, ok := f.Config.(ClientRegistrationProvider); ok {
+		return c.GetClientRegistrationURL(ctx)
+	}
+	return ""
+}
+
+// registrationClientURI returns the URL of the client configuration endpoint of the client.
+func (f *Fosite) registrationClientURI(ctx context.Context, clientID string) string {
+	registrationURL := f.clientRegistrationURL(ctx)
+	if registrationURL == "" {
+		return ""
+	}
+	return strings.TrimRight(registrationURL, "/") + "/" + url.PathEscape(clientID)
+}
+
+// parseClientMetadata decodes the JSON encoded client metadata of the request body. It also returns the raw fields,
+// so that fields which are not client metadata can be inspected.
+func parseClientMetadata(r *http.Request) (ClientMetadata, map[string]json.RawMessage, error) {
+	var metadata ClientMetadata
+	var fields map[string]json.RawMessage
+
+	if r.Body == nil {
+		return metadata, nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The request body must contain the client metadata."))
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return metadata, nil, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to read the request body.").WithWrap(err).WithDebug(err.Error()))
+	} else if err := json.Unmarshal(body, &fields); err != nil {
+		return metadata, nil, errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The request body must be a JSON object containing the client metadata.").WithWrap(err).WithDebug(err.Error()))
+	} else if err := json.Unmarshal(body, &metadata); err != nil {
+		return metadata, nil, errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The request body contains malformed client metadata.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return metadata, fields, nil
+}
@@ -0,0 +1,75 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/text/language"
+)
+
+// WriteClientRegistrationResponse writes the client information response of the client registration and client
+// configuration endpoints.
+func (f *Fosite) WriteClientRegistrationResponse(ctx context.Context, rw http.ResponseWriter, resp *ClientRegistrationResponse) {
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+
+	if resp.StatusCode == http.StatusNoContent {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	js, err := json.Marshal(resp.ToMap())
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := resp.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(js)
+}
+
+// WriteClientRegistrationError writes the error of the client registration and client configuration endpoints as
+// specified in https://www.rfc-editor.org/rfc/rfc7591#section-3.2.2
+func (f *Fosite) WriteClientRegistrationError(ctx context.Context, rw http.ResponseWriter, req *ClientRegistrationRequest, err error) {
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+
+	lang := language.English
+	if req != nil {
+		lang = req.Lang
+	}
+
+	sendDebugMessagesToClient := f.Config.GetSendDebugMessagesToClients(ctx)
+	rfcerr := ErrorToRFC6749Error(err).WithLegacyFormat(f.Config.GetUseLegacyErrorFormat(ctx)).
+		WithExposeDebug(sendDebugMessagesToClient).WithLocalizer(f.Config.GetMessageCatalog(ctx), lang)
+
+	js, err := json.Marshal(rfcerr)
+	if err != nil {
+		if sendDebugMessagesToClient {
+			errorMessage := EscapeJSONString(err.Error())
+			http.Error(rw, fmt.Sprintf(`{"error":"server_error","error_description":"%s"}`, errorMessage), http.StatusInternalServerError)
+		} else {
+			http.Error(rw, `{"error":"server_error"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if rfcerr.CodeField == http.StatusUnauthorized {
+		rw.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+
+	rw.WriteHeader(rfcerr.CodeField)
+	_, _ = rw.Write(js)
+}
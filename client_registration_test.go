@@ -0,0 +1,249 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestClientRegistration(t *testing.T) {
+	ctx := context.Background()
+	statementKey := gen.MustRSAKey()
+	store := storage.NewMemoryStore()
+	f := &Fosite{Store: store, Config: &Config{
+		ClientRegistrationURL: "https://auth.example.com/oauth2/register",
+		SoftwareStatementKeys: &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "statement", Use: "sig", Key: &statementKey.PublicKey}}},
+	}}
+
+	newRequest := func(method, body, token string) *http.Request {
+		r := httptest.NewRequest(method, "https://auth.example.com/oauth2/register", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		return r
+	}
+
+	register := func(t *testing.T, body string) *ClientRegistrationResponse {
+		req, err := f.NewClientRegistrationRequest(ctx, newRequest("POST", body, ""))
+		require.NoError(t, err)
+		resp, err := f.NewClientRegistrationResponse(ctx, req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("case=should validate the client metadata", func(t *testing.T) {
+		for k, tc := range []struct {
+			d         string
+			body      string
+			expectErr error
+		}{
+			{d: "malformed body", body: `[]`, expectErr: ErrInvalidClientMetadata},
+			{d: "missing redirect uris", body: `{}`, expectErr: ErrInvalidRedirectURI},
+			{d: "redirect uri with fragment", body: `{"redirect_uris":["https://client.example.com/cb#foo"]}`, expectErr: ErrInvalidRedirectURI},
+			{d: "insecure redirect uri", body: `{"redirect_uris":["http://client.example.com/cb"]}`, expectErr: ErrInvalidRedirectURI},
+			{d: "response type without grant type", body: `{"redirect_uris":["https://client.example.com/cb"],"response_types":["code","token"]}`, expectErr: ErrInvalidClientMetadata},
+			{d: "grant type without response type", body: `{"redirect_uris":["https://client.example.com/cb"],"grant_types":["implicit"],"response_types":["code"]}`, expectErr: ErrInvalidClientMetadata},
+			{d: "unsupported auth method", body: `{"grant_types":["client_credentials"],"token_endpoint_auth_method":"client_secret_jwt"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "private_key_jwt without keys", body: `{"grant_types":["client_credentials"],"token_endpoint_auth_method":"private_key_jwt"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "jwks and jwks_uri", body: `{"grant_types":["client_credentials"],"jwks":{"keys":[]},"jwks_uri":"https://client.example.com/jwks"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "invalid software statement", body: `{"grant_types":["client_credentials"],"software_statement":"foo"}`, expectErr: ErrInvalidSoftwareStatement},
+			{d: "unsupported subject type", body: `{"redirect_uris":["https://client.example.com/cb"],"subject_type":"foo"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "pairwise subject type without pairwise algorithm", body: `{"redirect_uris":["https://client.example.com/cb"],"subject_type":"pairwise"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "tls_client_auth without subject", body: `{"grant_types":["client_credentials"],"token_endpoint_auth_method":"tls_client_auth"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "tls_client_auth with two subjects", body: `{"grant_types":["client_credentials"],"token_endpoint_auth_method":"tls_client_auth","tls_client_auth_subject_dn":"CN=client","tls_client_auth_san_dns":"client.example.com"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "id token signing algorithm", body: `{"redirect_uris":["https://client.example.com/cb"],"id_token_signed_response_alg":"ES256"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "encryption without keys", body: `{"redirect_uris":["https://client.example.com/cb"],"userinfo_encrypted_response_alg":"RSA-OAEP-256"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "encryption enc without alg", body: `{"redirect_uris":["https://client.example.com/cb"],"jwks_uri":"https://client.example.com/jwks","authorization_encrypted_response_enc":"A256GCM"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "post logout redirect uri with fragment", body: `{"redirect_uris":["https://client.example.com/cb"],"post_logout_redirect_uris":["https://client.example.com/logout#foo"]}`, expectErr: ErrInvalidClientMetadata},
+			{d: "relative back-channel logout uri", body: `{"redirect_uris":["https://client.example.com/cb"],"backchannel_logout_uri":"/logout"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "ciba grant without delivery mode", body: `{"grant_types":["urn:openid:params:grant-type:ciba"]}`, expectErr: ErrInvalidClientMetadata},
+			{d: "unsupported delivery mode", body: `{"grant_types":["urn:openid:params:grant-type:ciba"],"backchannel_token_delivery_mode":"push"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "ping delivery mode without endpoint", body: `{"grant_types":["urn:openid:params:grant-type:ciba"],"backchannel_token_delivery_mode":"ping"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "insecure notification endpoint", body: `{"grant_types":["urn:openid:params:grant-type:ciba"],"backchannel_token_delivery_mode":"ping","backchannel_client_notification_endpoint":"http://client.example.com/cb"}`, expectErr: ErrInvalidClientMetadata},
+			{d: "valid metadata", body: `{"redirect_uris":["https://client.example.com/cb"]}`},
+			{d: "valid tls_client_auth client", body: `{"grant_types":["client_credentials"],"token_endpoint_auth_method":"tls_client_auth","tls_client_auth_subject_dn":"CN=client"}`},
+			{d: "valid private_key_jwt client", body: `{"grant_types":["client_credentials"],"token_endpoint_auth_method":"private_key_jwt","jwks_uri":"https://client.example.com/jwks"}`},
+		} {
+			t.Run("case="+tc.d, func(t *testing.T) {
+				_, err := f.NewClientRegistrationRequest(ctx, newRequest("POST", tc.body, ""))
+				if tc.expectErr != nil {
+					require.ErrorIs(t, err, tc.expectErr, "%d", k)
+					return
+				}
+				require.NoError(t, err, "%d", k)
+			})
+		}
+	})
+
+	t.Run("case=should register the client", func(t *testing.T) {
+		resp := register(t, `{"redirect_uris":["https://client.example.com/cb"],"client_name":"Foo","scope":"foo bar"}`)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.NotEmpty(t, resp.ClientSecret)
+		assert.NotEmpty(t, resp.RegistrationAccessToken)
+		assert.Equal(t, "https://auth.example.com/oauth2/register/"+resp.ClientID, resp.RegistrationClientURI)
+
+		client, err := store.GetClient(ctx, resp.ClientID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"authorization_code"}, []string(client.GetGrantTypes()))
+		assert.Equal(t, []string{"code"}, []string(client.GetResponseTypes()))
+		assert.Equal(t, []string{"foo", "bar"}, []string(client.GetScopes()))
+		require.NoError(t, f.Config.GetSecretsHasher(ctx).Compare(ctx, client.GetHashedSecret(), []byte(resp.ClientSecret)))
+
+		rw := httptest.NewRecorder()
+		f.WriteClientRegistrationResponse(ctx, rw, resp)
+		assert.Equal(t, http.StatusCreated, rw.Code)
+		assert.Equal(t, "no-store", rw.Header().Get("Cache-Control"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+		assert.Equal(t, resp.ClientID, body["client_id"])
+		assert.Equal(t, "Foo", body["client_name"])
+		assert.Equal(t, "client_secret_basic", body["token_endpoint_auth_method"])
+		assert.EqualValues(t, 0, body["client_secret_expires_at"])
+	})
+
+	t.Run("case=should register the OpenID Connect client metadata", func(t *testing.T) {
+		resp := register(t, `{
+			"redirect_uris":["https://client.example.com/cb"],
+			"jwks_uri":"https://client.example.com/jwks",
+			"post_logout_redirect_uris":["https://client.example.com/logged-out"],
+			"id_token_encrypted_response_alg":"RSA-OAEP-256",
+			"userinfo_signed_response_alg":"ES256",
+			"authorization_signed_response_alg":"PS256",
+			"authorization_encrypted_response_alg":"RSA-OAEP",
+			"authorization_encrypted_response_enc":"A256GCM",
+			"introspection_signed_response_alg":"RS384",
+			"require_signed_request_object":true,
+			"request_object_signing_alg":"RS256",
+			"backchannel_logout_uri":"https://client.example.com/backchannel-logout",
+			"backchannel_logout_session_required":true,
+			"frontchannel_logout_uri":"https://client.example.com/frontchannel-logout",
+			"backchannel_token_delivery_mode":"ping",
+			"backchannel_client_notification_endpoint":"https://client.example.com/ciba",
+			"tls_client_certificate_bound_access_tokens":true
+		}`)
+		assert.Equal(t, "https://client.example.com/backchannel-logout", resp.ToMap()["backchannel_logout_uri"])
+
+		client, err := store.GetClient(ctx, resp.ClientID)
+		require.NoError(t, err)
+
+		require.Implements(t, (*OpenIDConnectClient)(nil), client)
+		assert.Equal(t, []string{"https://client.example.com/logged-out"}, client.(OpenIDConnectClient).GetPostLogoutRedirectURIs())
+		assert.Equal(t, "RSA-OAEP-256", client.(IDTokenEncryptionClient).GetIDTokenEncryptedResponseAlgorithm())
+		assert.Equal(t, "ES256", client.(UserinfoClient).GetUserinfoSignedResponseAlgorithm())
+		assert.Equal(t, "PS256", client.(JARMClient).GetAuthorizationSignedResponseAlgorithm())
+		assert.Equal(t, "RSA-OAEP", client.(JARMClient).GetAuthorizationEncryptedResponseAlgorithm())
+		assert.Equal(t, "A256GCM", client.(JARMClient).GetAuthorizationEncryptedResponseEncryption())
+		assert.Equal(t, "RS384", client.(JWTIntrospectionClient).GetIntrospectionSignedResponseAlgorithm())
+		assert.True(t, client.(JWTSecuredAuthorizeRequestClient).GetRequireSignedRequestObject())
+		assert.Equal(t, "RS256", client.(JWTSecuredAuthorizeRequestClient).GetRequestObjectSigningAlgorithm())
+		assert.Equal(t, "https://client.example.com/backchannel-logout", client.(BackChannelLogoutClient).GetBackChannelLogoutURI())
+		assert.True(t, client.(BackChannelLogoutClient).GetBackChannelLogoutSessionRequired())
+		assert.Equal(t, "https://client.example.com/frontchannel-logout", client.(FrontChannelLogoutClient).GetFrontChannelLogoutURI())
+		assert.Equal(t, "ping", client.(BackchannelAuthenticationClient).GetBackchannelTokenDeliveryMode())
+		assert.Equal(t, "https://client.example.com/ciba", client.(BackchannelAuthenticationClient).GetBackchannelClientNotificationEndpoint())
+		assert.True(t, client.(TLSClient).GetTLSClientCertificateBoundAccessTokens())
+	})
+
+	t.Run("case=should register tls_client_auth clients", func(t *testing.T) {
+		resp := register(t, `{"grant_types":["client_credentials"],"token_endpoint_auth_method":"tls_client_auth","tls_client_auth_san_dns":"client.example.com"}`)
+		assert.Empty(t, resp.ClientSecret)
+
+		client, err := store.GetClient(ctx, resp.ClientID)
+		require.NoError(t, err)
+		assert.Equal(t, "tls_client_auth", client.(OpenIDConnectClient).GetTokenEndpointAuthMethod())
+		assert.Equal(t, "client.example.com", client.(TLSClient).GetTLSClientAuthSANDNS())
+	})
+
+	t.Run("case=should apply the software statement", func(t *testing.T) {
+		statement, err := jwt.NewWithClaims(jose.RS256, jwt.MapClaims{
+			"software_id": "4NRB1-0XZABZI9E6-5SM3R",
+			"client_name": "Example Statement-based Client",
+		}).SignedString(statementKey)
+		require.NoError(t, err)
+
+		resp := register(t, `{"grant_types":["client_credentials"],"client_name":"Foo","software_statement":"`+statement+`"}`)
+		assert.Equal(t, "Example Statement-based Client", resp.Metadata.ClientName)
+		assert.Equal(t, "4NRB1-0XZABZI9E6-5SM3R", resp.Metadata.SoftwareID)
+		assert.Equal(t, statement, resp.Metadata.SoftwareStatement)
+
+		statement, err = jwt.NewWithClaims(jose.RS256, jwt.MapClaims{"client_name": "Foo"}).SignedString(gen.MustRSAKey())
+		require.NoError(t, err)
+		_, err = f.NewClientRegistrationRequest(ctx, newRequest("POST", `{"grant_types":["client_credentials"],"software_statement":"`+statement+`"}`, ""))
+		require.ErrorIs(t, err, ErrInvalidSoftwareStatement)
+	})
+
+	t.Run("case=should manage the client", func(t *testing.T) {
+		registered := register(t, `{"redirect_uris":["https://client.example.com/cb"]}`)
+		token := registered.RegistrationAccessToken
+
+		_, err := f.NewClientConfigurationRequest(ctx, newRequest("GET", "", ""), registered.ClientID)
+		require.ErrorIs(t, err, ErrRequestUnauthorized)
+		_, err = f.NewClientConfigurationRequest(ctx, newRequest("GET", "", "invalid"), registered.ClientID)
+		require.ErrorIs(t, err, ErrRequestUnauthorized)
+		_, err = f.NewClientConfigurationRequest(ctx, newRequest("GET", "", token), "unknown")
+		require.ErrorIs(t, err, ErrRequestUnauthorized)
+
+		req, err := f.NewClientConfigurationRequest(ctx, newRequest("GET", "", token), registered.ClientID)
+		require.NoError(t, err)
+		resp, err := f.NewClientConfigurationResponse(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, registered.Metadata, resp.Metadata)
+		assert.Empty(t, resp.ClientSecret)
+
+		_, err = f.NewClientConfigurationRequest(ctx, newRequest("PUT", `{"client_id":"other","redirect_uris":["https://client.example.com/cb"]}`, token), registered.ClientID)
+		require.ErrorIs(t, err, ErrInvalidRequest)
+		_, err = f.NewClientConfigurationRequest(ctx, newRequest("PUT", `{"client_id":"`+registered.ClientID+`","registration_access_token":"`+token+`"}`, token), registered.ClientID)
+		require.ErrorIs(t, err, ErrInvalidRequest)
+		_, err = f.NewClientConfigurationRequest(ctx, newRequest("PUT", `{"client_id":"`+registered.ClientID+`","client_secret":"wrong","redirect_uris":["https://client.example.com/cb"]}`, token), registered.ClientID)
+		require.ErrorIs(t, err, ErrInvalidRequest)
+
+		req, err = f.NewClientConfigurationRequest(ctx, newRequest("PUT", `{"client_id":"`+registered.ClientID+`","client_secret":"`+registered.ClientSecret+`","redirect_uris":["https://client.example.com/other"]}`, token), registered.ClientID)
+		require.NoError(t, err)
+		resp, err = f.NewClientConfigurationResponse(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://client.example.com/other"}, resp.Metadata.RedirectURIs)
+
+		client, err := store.GetClient(ctx, registered.ClientID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://client.example.com/other"}, client.GetRedirectURIs())
+		require.NoError(t, f.Config.GetSecretsHasher(ctx).Compare(ctx, client.GetHashedSecret(), []byte(registered.ClientSecret)))
+
+		req, err = f.NewClientConfigurationRequest(ctx, newRequest("DELETE", "", token), registered.ClientID)
+		require.NoError(t, err)
+		resp, err = f.NewClientConfigurationResponse(ctx, req)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		f.WriteClientRegistrationResponse(ctx, rw, resp)
+		assert.Equal(t, http.StatusNoContent, rw.Code)
+		assert.Empty(t, rw.Body.Bytes())
+
+		_, err = store.GetClient(ctx, registered.ClientID)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("case=should write errors", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		f.WriteClientRegistrationError(ctx, rw, nil, ErrInvalidRedirectURI)
+		assert.Equal(t, http.StatusBadRequest, rw.Code)
+		assert.Contains(t, rw.Body.String(), "invalid_redirect_uri")
+	})
+}
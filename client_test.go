@@ -40,3 +40,30 @@ func TestDefaultResponseModeClient_GetResponseMode(t *testing.T) {
 	rc := &DefaultResponseModeClient{ResponseModes: []ResponseModeType{ResponseModeFragment}}
 	assert.Equal(t, []ResponseModeType{ResponseModeFragment}, rc.GetResponseModes())
 }
+
+func TestDefaultOpenIDConnectClient(t *testing.T) {
+	c := &DefaultOpenIDConnectClient{
+		DefaultClient:                        &DefaultClient{ID: "1"},
+		SubjectType:                          "pairwise",
+		ResponseModes:                        []ResponseModeType{ResponseModeQueryJWT},
+		AuthorizationSignedResponseAlgorithm: "ES256",
+		BackChannelLogoutURI:                 "https://client.example.com/logout",
+	}
+
+	assert.Equal(t, "pairwise", c.GetSubjectType())
+	assert.Equal(t, []ResponseModeType{ResponseModeQueryJWT}, c.GetResponseModes())
+	assert.Equal(t, "ES256", c.GetAuthorizationSignedResponseAlgorithm())
+	assert.Equal(t, "https://client.example.com/logout", c.GetBackChannelLogoutURI())
+
+	var _ JWTSecuredAuthorizeRequestClient = c
+	var _ JARMClient = c
+	var _ JWTIntrospectionClient = c
+	var _ UserinfoClient = c
+	var _ IDTokenEncryptionClient = c
+	var _ SubjectTypeClient = c
+	var _ BackChannelLogoutClient = c
+	var _ FrontChannelLogoutClient = c
+	var _ BackchannelAuthenticationClient = c
+	var _ TLSClient = c
+	var _ ClientSecretJWTClient = c
+}
@@ -29,6 +29,9 @@ type ClientLifespanConfig struct {
 	AuthorizationCodeGrantIDTokenLifespan      *time.Duration `json:"authorization_code_grant_id_token_lifespan"`
 	AuthorizationCodeGrantRefreshTokenLifespan *time.Duration `json:"authorization_code_grant_refresh_token_lifespan"`
 	ClientCredentialsGrantAccessTokenLifespan  *time.Duration `json:"client_credentials_grant_access_token_lifespan"`
+	DeviceCodeGrantAccessTokenLifespan         *time.Duration `json:"device_code_grant_access_token_lifespan"`
+	DeviceCodeGrantIDTokenLifespan             *time.Duration `json:"device_code_grant_id_token_lifespan"`
+	DeviceCodeGrantRefreshTokenLifespan        *time.Duration `json:"device_code_grant_refresh_token_lifespan"`
 	ImplicitGrantAccessTokenLifespan           *time.Duration `json:"implicit_grant_access_token_lifespan"`
 	ImplicitGrantIDTokenLifespan               *time.Duration `json:"implicit_grant_id_token_lifespan"`
 	JwtBearerGrantAccessTokenLifespan          *time.Duration `json:"jwt_bearer_grant_access_token_lifespan"`
@@ -37,6 +40,7 @@ type ClientLifespanConfig struct {
 	RefreshTokenGrantIDTokenLifespan           *time.Duration `json:"refresh_token_grant_id_token_lifespan"`
 	RefreshTokenGrantAccessTokenLifespan       *time.Duration `json:"refresh_token_grant_access_token_lifespan"`
 	RefreshTokenGrantRefreshTokenLifespan      *time.Duration `json:"refresh_token_grant_refresh_token_lifespan"`
+	TokenExchangeGrantAccessTokenLifespan      *time.Duration `json:"token_exchange_grant_access_token_lifespan"`
 	//Hybrid grant tokens are not independently configurable, see the comment above.
 }
 
@@ -71,6 +75,14 @@ func (c *DefaultClientWithCustomTokenLifespans) GetEffectiveLifespan(gt GrantTyp
 		if tt == AccessToken {
 			cl = c.TokenLifespans.ClientCredentialsGrantAccessTokenLifespan
 		}
+	} else if gt == GrantTypeDeviceCode {
+		if tt == AccessToken {
+			cl = c.TokenLifespans.DeviceCodeGrantAccessTokenLifespan
+		} else if tt == IDToken {
+			cl = c.TokenLifespans.DeviceCodeGrantIDTokenLifespan
+		} else if tt == RefreshToken {
+			cl = c.TokenLifespans.DeviceCodeGrantRefreshTokenLifespan
+		}
 	} else if gt == GrantTypeImplicit {
 		if tt == AccessToken {
 			cl = c.TokenLifespans.ImplicitGrantAccessTokenLifespan
@@ -95,6 +107,10 @@ func (c *DefaultClientWithCustomTokenLifespans) GetEffectiveLifespan(gt GrantTyp
 		} else if tt == RefreshToken {
 			cl = c.TokenLifespans.RefreshTokenGrantRefreshTokenLifespan
 		}
+	} else if gt == GrantTypeTokenExchange {
+		if tt == AccessToken {
+			cl = c.TokenLifespans.TokenExchangeGrantAccessTokenLifespan
+		}
 	}
 
 	if cl == nil {
@@ -53,6 +53,12 @@ func Compose(config *fosite.Config, storage interface{}, strategy interface{}, f
 		if ph, ok := res.(fosite.PushedAuthorizeEndpointHandler); ok {
 			config.PushedAuthorizeEndpointHandlers.Append(ph)
 		}
+		if dh, ok := res.(fosite.DeviceEndpointHandler); ok {
+			config.DeviceEndpointHandlers.Append(dh)
+		}
+		if bh, ok := res.(fosite.BackchannelAuthenticationEndpointHandler); ok {
+			config.BackchannelAuthenticationEndpointHandlers.Append(bh)
+		}
 	}
 
 	return f
@@ -70,6 +76,8 @@ func ComposeAllEnabled(config *fosite.Config, storage interface{}, key interface
 			CoreStrategy:               NewOAuth2HMACStrategy(config),
 			OpenIDConnectTokenStrategy: NewOpenIDConnectStrategy(keyGetter, config),
 			Signer:                     &jwt.DefaultSigner{GetPrivateKey: keyGetter},
+			RFC8628CodeStrategy:        NewDeviceStrategy(config),
+			AuthReqIDStrategy:          NewCIBAStrategy(config),
 		},
 		OAuth2AuthorizeExplicitFactory,
 		OAuth2AuthorizeImplicitFactory,
@@ -77,6 +85,7 @@ func ComposeAllEnabled(config *fosite.Config, storage interface{}, key interface
 		OAuth2RefreshTokenGrantFactory,
 		OAuth2ResourceOwnerPasswordCredentialsFactory,
 		RFC7523AssertionGrantFactory,
+		RFC8693TokenExchangeFactory,
 
 		OpenIDConnectExplicitFactory,
 		OpenIDConnectImplicitFactory,
@@ -88,5 +97,11 @@ func ComposeAllEnabled(config *fosite.Config, storage interface{}, key interface
 
 		OAuth2PKCEFactory,
 		PushedAuthorizeHandlerFactory,
+
+		RFC8628DeviceFactory,
+		RFC8628DeviceAuthorizationTokenFactory,
+
+		OpenIDConnectCIBAFactory,
+		OpenIDConnectCIBATokenFactory,
 	)
 }
@@ -0,0 +1,44 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package compose
+
+import (
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/ciba"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/openid"
+)
+
+// OpenIDConnectCIBAConfigurator is the configuration required by the backchannel authentication handlers. The
+// configuration passed to OpenIDConnectCIBAFactory and OpenIDConnectCIBATokenFactory must implement it.
+type OpenIDConnectCIBAConfigurator interface {
+	fosite.Configurator
+	fosite.BackchannelAuthenticationProvider
+}
+
+// OpenIDConnectCIBAFactory creates an OpenID Connect backchannel authentication endpoint handler which issues
+// auth_req_id values.
+func OpenIDConnectCIBAFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &ciba.BackchannelAuthenticationHandler{
+		Strategy: strategy.(ciba.AuthReqIDStrategy),
+		Storage:  storage.(ciba.BackchannelAuthenticationStorage),
+		Config:   config.(OpenIDConnectCIBAConfigurator),
+	}
+}
+
+// OpenIDConnectCIBATokenFactory creates an OpenID Connect CIBA grant handler which exchanges approved auth_req_id
+// values for access, refresh and ID tokens in the poll and ping token delivery modes.
+func OpenIDConnectCIBATokenFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &ciba.CIBATokenHandler{
+		AuthReqIDStrategy:      strategy.(ciba.AuthReqIDStrategy),
+		AccessTokenStrategy:    strategy.(oauth2.AccessTokenStrategy),
+		RefreshTokenStrategy:   strategy.(oauth2.RefreshTokenStrategy),
+		CoreStorage:            storage.(ciba.CIBACoreStorage),
+		TokenRevocationStorage: storage.(oauth2.TokenRevocationStorage),
+		Config:                 config.(OpenIDConnectCIBAConfigurator),
+		IDTokenHandleHelper: &openid.IDTokenHandleHelper{
+			IDTokenStrategy: strategy.(openid.OpenIDConnectTokenStrategy),
+		},
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package compose
+
+import (
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/rfc8628"
+)
+
+// RFC8628Configurator is the configuration required by the device authorization grant handlers. The configuration
+// passed to RFC8628DeviceFactory and RFC8628DeviceAuthorizationTokenFactory must implement it.
+type RFC8628Configurator interface {
+	fosite.Configurator
+	fosite.DeviceAndUserCodeLifespanProvider
+	fosite.DeviceProvider
+}
+
+// RFC8628DeviceFactory creates an OAuth2 device authorization endpoint handler which issues device and user codes.
+func RFC8628DeviceFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &rfc8628.DeviceAuthHandler{
+		Strategy: strategy.(rfc8628.RFC8628CodeStrategy),
+		Storage:  storage.(rfc8628.DeviceAuthStorage),
+		Config:   config.(RFC8628Configurator),
+	}
+}
+
+// RFC8628DeviceAuthorizationTokenFactory creates an OAuth2 device code grant handler which exchanges approved
+// device codes for access and refresh tokens.
+func RFC8628DeviceAuthorizationTokenFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &rfc8628.DeviceCodeTokenHandler{
+		DeviceCodeStrategy:     strategy.(rfc8628.DeviceCodeStrategy),
+		AccessTokenStrategy:    strategy.(oauth2.AccessTokenStrategy),
+		RefreshTokenStrategy:   strategy.(oauth2.RefreshTokenStrategy),
+		CoreStorage:            storage.(rfc8628.RFC8628CoreStorage),
+		TokenRevocationStorage: storage.(oauth2.TokenRevocationStorage),
+		Config:                 config.(RFC8628Configurator),
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package compose
+
+import (
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/rfc7523"
+	"github.com/ory/fosite/handler/rfc8693"
+	"github.com/ory/fosite/token/jwt"
+)
+
+// RFC8693TokenExchangeFactory creates an OAuth2 Token Exchange handler which accepts access tokens, refresh tokens,
+// ID tokens and JWTs of trusted issuers as subject and actor tokens.
+func RFC8693TokenExchangeFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &rfc8693.Handler{
+		TokenIntrospector: &oauth2.CoreValidator{
+			CoreStrategy: strategy.(oauth2.CoreStrategy),
+			CoreStorage:  storage.(oauth2.CoreStorage),
+			Config:       config,
+		},
+		IDTokenSigner: strategy.(jwt.Signer),
+		JWTKeyStorage: storage.(rfc7523.RFC7523KeyStorage),
+		HandleHelper: &oauth2.HandleHelper{
+			AccessTokenStrategy: strategy.(oauth2.AccessTokenStrategy),
+			AccessTokenStorage:  storage.(oauth2.AccessTokenStorage),
+			Config:              config,
+		},
+		Config: config,
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"context"
 
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/ciba"
 	"github.com/ory/fosite/handler/oauth2"
 	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/handler/rfc8628"
 	"github.com/ory/fosite/token/hmac"
 	"github.com/ory/fosite/token/jwt"
 )
@@ -17,6 +19,8 @@ type CommonStrategy struct {
 	oauth2.CoreStrategy
 	openid.OpenIDConnectTokenStrategy
 	jwt.Signer
+	rfc8628.RFC8628CodeStrategy
+	ciba.AuthReqIDStrategy
 }
 
 type HMACSHAStrategyConfigurator interface {
@@ -36,6 +40,36 @@ func NewOAuth2HMACStrategy(config HMACSHAStrategyConfigurator) *oauth2.HMACSHASt
 	}
 }
 
+type DeviceStrategyConfigurator interface {
+	fosite.DeviceAndUserCodeLifespanProvider
+	fosite.TokenEntropyProvider
+	fosite.GlobalSecretProvider
+	fosite.RotatedGlobalSecretsProvider
+	fosite.HMACHashingProvider
+}
+
+func NewDeviceStrategy(config DeviceStrategyConfigurator) *rfc8628.DefaultDeviceStrategy {
+	return &rfc8628.DefaultDeviceStrategy{
+		Enigma: &hmac.HMACStrategy{Config: config},
+		Config: config,
+	}
+}
+
+type CIBAStrategyConfigurator interface {
+	fosite.BackchannelAuthenticationProvider
+	fosite.TokenEntropyProvider
+	fosite.GlobalSecretProvider
+	fosite.RotatedGlobalSecretsProvider
+	fosite.HMACHashingProvider
+}
+
+func NewCIBAStrategy(config CIBAStrategyConfigurator) *ciba.DefaultAuthReqIDStrategy {
+	return &ciba.DefaultAuthReqIDStrategy{
+		Enigma: &hmac.HMACStrategy{Config: config},
+		Config: config,
+	}
+}
+
 func NewOAuth2JWTStrategy(keyGetter func(context.Context) (interface{}, error), strategy *oauth2.HMACSHAStrategy, config fosite.Configurator) *oauth2.DefaultJWTStrategy {
 	return &oauth2.DefaultJWTStrategy{
 		Signer:          &jwt.DefaultSigner{GetPrivateKey: keyGetter},
@@ -50,3 +84,22 @@ func NewOpenIDConnectStrategy(keyGetter func(context.Context) (interface{}, erro
 		Config: config,
 	}
 }
+
+// NewOAuth2JWTStrategyWithKeyManager returns a JWT access token strategy which signs tokens with the active key of
+// the key manager and verifies them with all keys which are not retired.
+func NewOAuth2JWTStrategyWithKeyManager(keys *jwt.KeyManager, strategy *oauth2.HMACSHAStrategy, config fosite.Configurator) *oauth2.DefaultJWTStrategy {
+	return &oauth2.DefaultJWTStrategy{
+		Signer:          keys.NewSigner(),
+		HMACSHAStrategy: strategy,
+		Config:          config,
+	}
+}
+
+// NewOpenIDConnectStrategyWithKeyManager returns an ID token strategy which signs tokens with the active key of the
+// key manager.
+func NewOpenIDConnectStrategyWithKeyManager(keys *jwt.KeyManager, config fosite.Configurator) *openid.DefaultStrategy {
+	return &openid.DefaultStrategy{
+		Signer: keys.NewSigner(),
+		Config: config,
+	}
+}
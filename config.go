@@ -5,11 +5,13 @@ package fosite
 
 import (
 	"context"
+	"crypto/x509"
 	"hash"
 	"html/template"
 	"net/url"
 	"time"
 
+	"github.com/go-jose/go-jose/v3"
 	"github.com/hashicorp/go-retryablehttp"
 
 	"github.com/ory/fosite/i18n"
@@ -246,6 +248,12 @@ type FormPostHTMLTemplateProvider interface {
 	GetFormPostHTMLTemplate(ctx context.Context) *template.Template
 }
 
+// FrontChannelLogoutHTMLTemplateProvider returns the provider for configuring the front-channel logout HTML template.
+type FrontChannelLogoutHTMLTemplateProvider interface {
+	// GetFrontChannelLogoutHTMLTemplate returns the front-channel logout HTML template.
+	GetFrontChannelLogoutHTMLTemplate(ctx context.Context) *template.Template
+}
+
 type TokenURLProvider interface {
 	// GetTokenURLs returns the token URL.
 	GetTokenURLs(ctx context.Context) []string
@@ -281,6 +289,155 @@ type PushedAuthorizeRequestHandlersProvider interface {
 	GetPushedAuthorizeEndpointHandlers(ctx context.Context) PushedAuthorizeEndpointHandlers
 }
 
+// DeviceEndpointHandlersProvider returns the provider for configuring the device authorization endpoint handlers.
+type DeviceEndpointHandlersProvider interface {
+	// GetDeviceEndpointHandlers returns the handlers.
+	GetDeviceEndpointHandlers(ctx context.Context) DeviceEndpointHandlers
+}
+
+// DeviceAndUserCodeLifespanProvider returns the provider for configuring the device and user code lifespan.
+type DeviceAndUserCodeLifespanProvider interface {
+	// GetDeviceAndUserCodeLifespan returns the device and user code lifespan.
+	GetDeviceAndUserCodeLifespan(ctx context.Context) time.Duration
+}
+
+// DeviceProvider returns the provider for configuring the device authorization grant.
+type DeviceProvider interface {
+	// GetDeviceVerificationURL returns the URL of the verification page the end user visits to enter the user code.
+	GetDeviceVerificationURL(ctx context.Context) string
+
+	// GetDeviceAuthTokenPollingInterval returns the minimum amount of time the client should wait between polling requests.
+	GetDeviceAuthTokenPollingInterval(ctx context.Context) time.Duration
+}
+
+// BackchannelAuthenticationEndpointHandlersProvider returns the provider for configuring the backchannel
+// authentication endpoint handlers.
+type BackchannelAuthenticationEndpointHandlersProvider interface {
+	// GetBackchannelAuthenticationEndpointHandlers returns the handlers.
+	GetBackchannelAuthenticationEndpointHandlers(ctx context.Context) BackchannelAuthenticationEndpointHandlers
+}
+
+// BackchannelAuthenticationProvider returns the provider for configuring OpenID Connect Client-Initiated Backchannel
+// Authentication (CIBA).
+type BackchannelAuthenticationProvider interface {
+	// GetBackchannelAuthenticationURL returns the URL of the backchannel authentication endpoint.
+	GetBackchannelAuthenticationURL(ctx context.Context) string
+
+	// GetBackchannelAuthenticationRequestLifespan returns how long an auth_req_id is valid if the client did not
+	// request a shorter expiry.
+	GetBackchannelAuthenticationRequestLifespan(ctx context.Context) time.Duration
+
+	// GetBackchannelAuthenticationPollingInterval returns the minimum amount of time the client should wait between
+	// polling requests.
+	GetBackchannelAuthenticationPollingInterval(ctx context.Context) time.Duration
+
+	// GetBackchannelAuthenticationBindingMessageMaxLength returns the maximum length of the binding_message.
+	GetBackchannelAuthenticationBindingMessageMaxLength(ctx context.Context) int
+}
+
+// TLSClientCertificateExtractorProvider returns the provider for configuring how client certificates are extracted
+// from requests.
+type TLSClientCertificateExtractorProvider interface {
+	// GetTLSClientCertificateExtractor returns the client certificate extractor.
+	GetTLSClientCertificateExtractor(ctx context.Context) TLSClientCertificateExtractor
+}
+
+// TLSClientCertificateAuthoritiesProvider returns the provider for configuring the certificate authorities which
+// issue client certificates for PKI mutual TLS client authentication.
+type TLSClientCertificateAuthoritiesProvider interface {
+	// GetTLSClientCertificateAuthorities returns the certificate authorities client certificates are verified
+	// against, or nil if the certificate chain is verified during the TLS handshake.
+	GetTLSClientCertificateAuthorities(ctx context.Context) *x509.CertPool
+}
+
+// DPoPProvider returns the provider for configuring DPoP (Demonstrating Proof of Possession).
+type DPoPProvider interface {
+	// GetDPoPProofLifespan returns how far the "iat" claim of a DPoP proof may deviate from the current time.
+	GetDPoPProofLifespan(ctx context.Context) time.Duration
+
+	// GetDPoPSigningAlgorithms returns the JWS algorithms accepted for DPoP proofs.
+	GetDPoPSigningAlgorithms(ctx context.Context) []string
+}
+
+// JWTSecuredAuthorizeRequestProvider returns the provider for configuring JWT-Secured Authorization Requests
+// (RFC 9101).
+type JWTSecuredAuthorizeRequestProvider interface {
+	// GetJWTSecuredAuthorizeRequestAudience returns the identifier the "aud" claim of request objects must contain.
+	GetJWTSecuredAuthorizeRequestAudience(ctx context.Context) string
+
+	// GetDisableRemoteRequestURIs returns true if only request_uri values issued by the authorization server itself,
+	// for example through pushed authorization requests, are accepted. Request objects are then never fetched from
+	// client-hosted request URIs.
+	GetDisableRemoteRequestURIs(ctx context.Context) bool
+
+	// GetJWTSecuredAuthorizeRequestDecrypter returns the decrypter of encrypted request objects. If nil, encrypted
+	// request objects are not supported.
+	GetJWTSecuredAuthorizeRequestDecrypter(ctx context.Context) jwt.Decrypter
+}
+
+// JWTSecuredAuthorizeResponseModeProvider returns the provider for configuring the JWT Secured Authorization
+// Response Mode (JARM).
+type JWTSecuredAuthorizeResponseModeProvider interface {
+	// GetJWTSecuredAuthorizeResponseModeSigner returns the signer of JWT secured authorization responses. If nil,
+	// the JWT secured response modes are not supported.
+	GetJWTSecuredAuthorizeResponseModeSigner(ctx context.Context) jwt.Signer
+
+	// GetJWTSecuredAuthorizeResponseModeIssuer returns the issuer of JWT secured authorization responses.
+	GetJWTSecuredAuthorizeResponseModeIssuer(ctx context.Context) string
+
+	// GetJWTSecuredAuthorizeResponseModeLifespan returns how long a JWT secured authorization response is valid.
+	GetJWTSecuredAuthorizeResponseModeLifespan(ctx context.Context) time.Duration
+}
+
+// AuthorizationDetailValidatorsProvider returns the provider for configuring the validators of Rich Authorization
+// Requests (RFC 9396).
+type AuthorizationDetailValidatorsProvider interface {
+	// GetAuthorizationDetailValidators returns the validators of the supported authorization details types.
+	GetAuthorizationDetailValidators(ctx context.Context) AuthorizationDetailValidators
+}
+
+// JWTIntrospectionResponseProvider returns the provider for configuring JWT-formatted token introspection responses
+// (RFC 9701).
+type JWTIntrospectionResponseProvider interface {
+	// GetJWTIntrospectionResponseSigner returns the signer of JWT-formatted introspection responses. If nil,
+	// introspection responses are always written as JSON.
+	GetJWTIntrospectionResponseSigner(ctx context.Context) jwt.Signer
+
+	// GetJWTIntrospectionResponseIssuer returns the issuer of JWT-formatted introspection responses.
+	GetJWTIntrospectionResponseIssuer(ctx context.Context) string
+}
+
+// ClientRegistrationProvider returns the provider for configuring Dynamic Client Registration (RFC 7591) and
+// Management (RFC 7592).
+type ClientRegistrationProvider interface {
+	// GetClientRegistrationURL returns the URL of the client registration endpoint. The client configuration endpoint
+	// of a registered client is this URL followed by a slash and the client ID.
+	GetClientRegistrationURL(ctx context.Context) string
+
+	// GetSoftwareStatementKeys returns the public keys of the issuers whose software statements are trusted. If nil,
+	// software statements are rejected.
+	GetSoftwareStatementKeys(ctx context.Context) *jose.JSONWebKeySet
+}
+
+// AuthorizationServerMetadataProvider returns the provider for configuring the authorization server metadata
+// (RFC 8414) and OpenID Connect Discovery document.
+type AuthorizationServerMetadataProvider interface {
+	// GetAuthorizationServerMetadataOverrides returns the metadata which overrides the metadata generated by fosite,
+	// for example the URLs of endpoints fosite does not know about. Only fields which are set are overridden.
+	GetAuthorizationServerMetadataOverrides(ctx context.Context) *AuthorizationServerMetadata
+}
+
+// JWTProfileAccessTokenProvider returns the provider for configuring the JWT Profile for OAuth 2.0 Access Tokens
+// (RFC 9068).
+type JWTProfileAccessTokenProvider interface {
+	// GetUseJWTProfileAccessTokens returns true if JWT access tokens are issued and validated according to RFC 9068.
+	GetUseJWTProfileAccessTokens(ctx context.Context) bool
+
+	// GetJWTProfileAccessTokenDefaultAudience returns the audience of JWT access tokens for which no audience was
+	// granted.
+	GetJWTProfileAccessTokenDefaultAudience(ctx context.Context) []string
+}
+
 // UseLegacyErrorFormatProvider returns the provider for configuring whether to use the legacy error format.
 //
 // DEPRECATED: Do not use this flag anymore.
@@ -306,3 +463,32 @@ type PushedAuthorizeRequestConfigProvider interface {
 	// must contain the PAR request_uri.
 	EnforcePushedAuthorize(ctx context.Context) bool
 }
+
+// UserinfoProvider returns the provider for configuring the OpenID Connect UserInfo endpoint.
+type UserinfoProvider interface {
+	// GetUserinfoURL returns the URL of the UserInfo endpoint.
+	GetUserinfoURL(ctx context.Context) string
+
+	// GetUserinfoSigner returns the signer of signed UserInfo responses. If nil, only plain JSON responses are
+	// supported.
+	GetUserinfoSigner(ctx context.Context) jwt.Signer
+}
+
+// EndSessionProvider returns the provider for configuring OpenID Connect RP-Initiated Logout.
+type EndSessionProvider interface {
+	// GetEndSessionURL returns the URL of the end session endpoint.
+	GetEndSessionURL(ctx context.Context) string
+
+	// GetIDTokenSigner returns the signer of ID tokens, which verifies the id_token_hint of logout requests.
+	GetIDTokenSigner(ctx context.Context) jwt.Signer
+
+	// GetLogoutConfirmationHTMLTemplate returns the HTML template rendered after a logout without redirect.
+	GetLogoutConfirmationHTMLTemplate(ctx context.Context) *template.Template
+}
+
+// PairwiseSubjectProvider returns the provider for configuring pairwise subject identifiers.
+type PairwiseSubjectProvider interface {
+	// GetPairwiseSubjectAlgorithm returns the algorithm which calculates pairwise subject identifiers. If nil,
+	// clients can not use the pairwise subject type.
+	GetPairwiseSubjectAlgorithm(ctx context.Context) PairwiseSubjectAlgorithm
+}
@@ -5,11 +5,13 @@ package fosite
 
 import (
 	"context"
+	"crypto/x509"
 	"hash"
 	"html/template"
 	"net/url"
 	"time"
 
+	"github.com/go-jose/go-jose/v3"
 	"github.com/hashicorp/go-retryablehttp"
 
 	"github.com/ory/fosite/token/jwt"
@@ -20,48 +22,80 @@ import (
 const (
 	defaultPARPrefix          = "urn:ietf:params:oauth:request_uri:"
 	defaultPARContextLifetime = 5 * time.Minute
+
+	defaultDeviceAndUserCodeLifespan      = 10 * time.Minute
+	defaultDeviceAuthTokenPollingInterval = 5 * time.Second
+
+	defaultBackchannelAuthenticationRequestLifespan      = 10 * time.Minute
+	defaultBackchannelAuthenticationPollingInterval      = 5 * time.Second
+	defaultBackchannelAuthenticationBindingMessageLength = 64
+
+	defaultDPoPProofLifespan = 5 * time.Minute
+
+	defaultJWTSecuredAuthorizeResponseModeLifespan = 10 * time.Minute
 )
 
+var defaultDPoPSigningAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
 var (
-	_ AuthorizeCodeLifespanProvider                = (*Config)(nil)
-	_ RefreshTokenLifespanProvider                 = (*Config)(nil)
-	_ AccessTokenLifespanProvider                  = (*Config)(nil)
-	_ ScopeStrategyProvider                        = (*Config)(nil)
-	_ AudienceStrategyProvider                     = (*Config)(nil)
-	_ RedirectSecureCheckerProvider                = (*Config)(nil)
-	_ RefreshTokenScopesProvider                   = (*Config)(nil)
-	_ DisableRefreshTokenValidationProvider        = (*Config)(nil)
-	_ AccessTokenIssuerProvider                    = (*Config)(nil)
-	_ JWTScopeFieldProvider                        = (*Config)(nil)
-	_ AllowedPromptsProvider                       = (*Config)(nil)
-	_ OmitRedirectScopeParamProvider               = (*Config)(nil)
-	_ MinParameterEntropyProvider                  = (*Config)(nil)
-	_ SanitationAllowedProvider                    = (*Config)(nil)
-	_ EnforcePKCEForPublicClientsProvider          = (*Config)(nil)
-	_ EnablePKCEPlainChallengeMethodProvider       = (*Config)(nil)
-	_ EnforcePKCEProvider                          = (*Config)(nil)
-	_ GrantTypeJWTBearerCanSkipClientAuthProvider  = (*Config)(nil)
-	_ GrantTypeJWTBearerIDOptionalProvider         = (*Config)(nil)
-	_ GrantTypeJWTBearerIssuedDateOptionalProvider = (*Config)(nil)
-	_ GetJWTMaxDurationProvider                    = (*Config)(nil)
-	_ IDTokenLifespanProvider                      = (*Config)(nil)
-	_ IDTokenIssuerProvider                        = (*Config)(nil)
-	_ JWKSFetcherStrategyProvider                  = (*Config)(nil)
-	_ ClientAuthenticationStrategyProvider         = (*Config)(nil)
-	_ SendDebugMessagesToClientsProvider           = (*Config)(nil)
-	_ ResponseModeHandlerExtensionProvider         = (*Config)(nil)
-	_ MessageCatalogProvider                       = (*Config)(nil)
-	_ FormPostHTMLTemplateProvider                 = (*Config)(nil)
-	_ TokenURLProvider                             = (*Config)(nil)
-	_ GetSecretsHashingProvider                    = (*Config)(nil)
-	_ HTTPClientProvider                           = (*Config)(nil)
-	_ HMACHashingProvider                          = (*Config)(nil)
-	_ AuthorizeEndpointHandlersProvider            = (*Config)(nil)
-	_ TokenEndpointHandlersProvider                = (*Config)(nil)
-	_ TokenIntrospectionHandlersProvider           = (*Config)(nil)
-	_ RevocationHandlersProvider                   = (*Config)(nil)
-	_ PushedAuthorizeRequestHandlersProvider       = (*Config)(nil)
-	_ PushedAuthorizeRequestConfigProvider         = (*Config)(nil)
+	_ AuthorizeCodeLifespanProvider                     = (*Config)(nil)
+	_ RefreshTokenLifespanProvider                      = (*Config)(nil)
+	_ AccessTokenLifespanProvider                       = (*Config)(nil)
+	_ ScopeStrategyProvider                             = (*Config)(nil)
+	_ AudienceStrategyProvider                          = (*Config)(nil)
+	_ RedirectSecureCheckerProvider                     = (*Config)(nil)
+	_ RefreshTokenScopesProvider                        = (*Config)(nil)
+	_ DisableRefreshTokenValidationProvider             = (*Config)(nil)
+	_ AccessTokenIssuerProvider                         = (*Config)(nil)
+	_ JWTScopeFieldProvider                             = (*Config)(nil)
+	_ AllowedPromptsProvider                            = (*Config)(nil)
+	_ OmitRedirectScopeParamProvider                    = (*Config)(nil)
+	_ MinParameterEntropyProvider                       = (*Config)(nil)
+	_ SanitationAllowedProvider                         = (*Config)(nil)
+	_ EnforcePKCEForPublicClientsProvider               = (*Config)(nil)
+	_ EnablePKCEPlainChallengeMethodProvider            = (*Config)(nil)
+	_ EnforcePKCEProvider                               = (*Config)(nil)
+	_ GrantTypeJWTBearerCanSkipClientAuthProvider       = (*Config)(nil)
+	_ GrantTypeJWTBearerIDOptionalProvider              = (*Config)(nil)
+	_ GrantTypeJWTBearerIssuedDateOptionalProvider      = (*Config)(nil)
+	_ GetJWTMaxDurationProvider                         = (*Config)(nil)
+	_ IDTokenLifespanProvider                           = (*Config)(nil)
+	_ IDTokenIssuerProvider                             = (*Config)(nil)
+	_ JWKSFetcherStrategyProvider                       = (*Config)(nil)
+	_ ClientAuthenticationStrategyProvider              = (*Config)(nil)
+	_ SendDebugMessagesToClientsProvider                = (*Config)(nil)
+	_ ResponseModeHandlerExtensionProvider              = (*Config)(nil)
+	_ MessageCatalogProvider                            = (*Config)(nil)
+	_ FormPostHTMLTemplateProvider                      = (*Config)(nil)
+	_ FrontChannelLogoutHTMLTemplateProvider            = (*Config)(nil)
+	_ TokenURLProvider                                  = (*Config)(nil)
+	_ GetSecretsHashingProvider                         = (*Config)(nil)
+	_ HTTPClientProvider                                = (*Config)(nil)
+	_ HMACHashingProvider                               = (*Config)(nil)
+	_ AuthorizeEndpointHandlersProvider                 = (*Config)(nil)
+	_ TokenEndpointHandlersProvider                     = (*Config)(nil)
+	_ TokenIntrospectionHandlersProvider                = (*Config)(nil)
+	_ RevocationHandlersProvider                        = (*Config)(nil)
+	_ PushedAuthorizeRequestHandlersProvider            = (*Config)(nil)
+	_ PushedAuthorizeRequestConfigProvider              = (*Config)(nil)
+	_ DeviceEndpointHandlersProvider                    = (*Config)(nil)
+	_ DeviceAndUserCodeLifespanProvider                 = (*Config)(nil)
+	_ DeviceProvider                                    = (*Config)(nil)
+	_ BackchannelAuthenticationEndpointHandlersProvider = (*Config)(nil)
+	_ BackchannelAuthenticationProvider                 = (*Config)(nil)
+	_ DPoPProvider                                      = (*Config)(nil)
+	_ TLSClientCertificateExtractorProvider             = (*Config)(nil)
+	_ TLSClientCertificateAuthoritiesProvider           = (*Config)(nil)
+	_ JWTSecuredAuthorizeRequestProvider                = (*Config)(nil)
+	_ JWTSecuredAuthorizeResponseModeProvider           = (*Config)(nil)
+	_ AuthorizationDetailValidatorsProvider             = (*Config)(nil)
+	_ JWTProfileAccessTokenProvider                     = (*Config)(nil)
+	_ JWTIntrospectionResponseProvider                  = (*Config)(nil)
+	_ ClientRegistrationProvider                        = (*Config)(nil)
+	_ AuthorizationServerMetadataProvider               = (*Config)(nil)
+	_ UserinfoProvider                                  = (*Config)(nil)
+	_ EndSessionProvider                                = (*Config)(nil)
+	_ PairwiseSubjectProvider                           = (*Config)(nil)
 )
 
 type Config struct {
@@ -163,6 +197,9 @@ type Config struct {
 	// FormPostHTMLTemplate sets html template for rendering the authorization response when the request has response_mode=form_post.
 	FormPostHTMLTemplate *template.Template
 
+	// FrontChannelLogoutHTMLTemplate sets html template for rendering the iframes of front-channel logout.
+	FrontChannelLogoutHTMLTemplate *template.Template
+
 	// OmitRedirectScopeParam indicates whether the "scope" parameter should be omitted from the redirect URL.
 	OmitRedirectScopeParam bool
 
@@ -215,6 +252,133 @@ type Config struct {
 
 	// IsPushedAuthorizeEnforced enforces pushed authorization request for /authorize
 	IsPushedAuthorizeEnforced bool
+
+	// DeviceEndpointHandlers is a list of handlers that are called before the device authorization endpoint is served.
+	DeviceEndpointHandlers DeviceEndpointHandlers
+
+	// DeviceAndUserCodeLifespan sets how long the device and user codes are going to be valid. Defaults to ten minutes.
+	DeviceAndUserCodeLifespan time.Duration
+
+	// DeviceAuthTokenPollingInterval sets the minimum amount of time a client must wait between polling
+	// requests to the token endpoint. Defaults to five seconds.
+	DeviceAuthTokenPollingInterval time.Duration
+
+	// DeviceVerificationURL is the URL of the page where the end user enters the user code.
+	DeviceVerificationURL string
+
+	// BackchannelAuthenticationEndpointHandlers is a list of handlers that are called before the backchannel
+	// authentication endpoint is served.
+	BackchannelAuthenticationEndpointHandlers BackchannelAuthenticationEndpointHandlers
+
+	// BackchannelAuthenticationURL is the URL of the CIBA backchannel authentication endpoint.
+	BackchannelAuthenticationURL string
+
+	// BackchannelAuthenticationRequestLifespan sets how long an auth_req_id is valid if the client did not request a
+	// shorter expiry. Defaults to ten minutes.
+	BackchannelAuthenticationRequestLifespan time.Duration
+
+	// BackchannelAuthenticationPollingInterval sets the minimum amount of time a client must wait between polling
+	// requests to the token endpoint. Defaults to five seconds.
+	BackchannelAuthenticationPollingInterval time.Duration
+
+	// BackchannelAuthenticationBindingMessageMaxLength sets the maximum length of the binding_message which is shown
+	// on the authentication device. Defaults to 64 characters.
+	BackchannelAuthenticationBindingMessageMaxLength int
+
+	// DPoPProofLifespan sets how far the "iat" claim of a DPoP proof may deviate from the current time. Defaults to
+	// five minutes.
+	DPoPProofLifespan time.Duration
+
+	// DPoPSigningAlgorithms sets the JWS algorithms accepted for DPoP proofs. Defaults to all asymmetric algorithms.
+	DPoPSigningAlgorithms []string
+
+	// TLSClientCertificateExtractor extracts the client certificate chain from requests. Use
+	// NewHeaderTLSClientCertificateExtractor when TLS is terminated by a proxy. Defaults to
+	// fosite.DefaultTLSClientCertificateExtractor.
+	TLSClientCertificateExtractor TLSClientCertificateExtractor
+
+	// TLSClientCertificateAuthorities sets the certificate authorities client certificates are verified against for
+	// the tls_client_auth client authentication method. If not set, the certificate chain must have been verified
+	// during the TLS handshake, for example using tls.VerifyClientCertIfGiven. It must be set if the TLS handshake
+	// accepts any certificate, as needed for self_signed_tls_client_auth, or if TLS is terminated by a proxy. The
+	// authorization server metadata only advertises mutual TLS if it is set.
+	TLSClientCertificateAuthorities *x509.CertPool
+
+	// JWTSecuredAuthorizeRequestAudience sets the identifier the "aud" claim of request objects must contain.
+	// Defaults to the AccessTokenIssuer, or the IDTokenIssuer if that is not set either.
+	JWTSecuredAuthorizeRequestAudience string
+
+	// DisableRemoteRequestURIs rejects request_uri values which were not issued by the authorization server itself,
+	// for example through pushed authorization requests, instead of fetching the request object from the client.
+	DisableRemoteRequestURIs bool
+
+	// JWTSecuredAuthorizeRequestDecrypter decrypts request objects which were encrypted to keys of the authorization
+	// server. If nil, encrypted request objects are not supported.
+	JWTSecuredAuthorizeRequestDecrypter jwt.Decrypter
+
+	// JWTSecuredAuthorizeResponseModeSigner signs JWT secured authorization responses (JARM). If nil, the response
+	// modes "jwt", "query.jwt", "fragment.jwt" and "form_post.jwt" are not supported.
+	JWTSecuredAuthorizeResponseModeSigner jwt.Signer
+
+	// JWTSecuredAuthorizeResponseModeIssuer sets the issuer of JWT secured authorization responses. Defaults to
+	// the IDTokenIssuer.
+	JWTSecuredAuthorizeResponseModeIssuer string
+
+	// JWTSecuredAuthorizeResponseModeLifespan sets how long a JWT secured authorization response is valid. Defaults
+	// to ten minutes.
+	JWTSecuredAuthorizeResponseModeLifespan time.Duration
+
+	// AuthorizationDetailValidators registers the supported authorization details types of Rich Authorization
+	// Requests (RFC 9396) and their validators. Requests with authorization details of other types are rejected.
+	AuthorizationDetailValidators AuthorizationDetailValidators
+
+	// UseJWTProfileAccessTokens issues JWT access tokens according to the JWT Profile for OAuth 2.0 Access Tokens
+	// (RFC 9068) and rejects JWT access tokens which do not follow it.
+	UseJWTProfileAccessTokens bool
+
+	// JWTProfileAccessTokenDefaultAudience sets the audience of RFC 9068 access tokens for which no audience was
+	// granted.
+	JWTProfileAccessTokenDefaultAudience []string
+
+	// JWTIntrospectionResponseSigner signs JWT-formatted token introspection responses (RFC 9701). If nil,
+	// introspection responses are always written as JSON.
+	JWTIntrospectionResponseSigner jwt.Signer
+
+	// JWTIntrospectionResponseIssuer sets the issuer of JWT-formatted token introspection responses. Defaults to
+	// the AccessTokenIssuer.
+	JWTIntrospectionResponseIssuer string
+
+	// ClientRegistrationURL sets the URL of the Dynamic Client Registration endpoint (RFC 7591). The client
+	// configuration endpoint of a registered client is this URL followed by a slash and the client ID.
+	ClientRegistrationURL string
+
+	// SoftwareStatementKeys sets the public keys of the issuers whose software statements are trusted. If nil,
+	// software statements are rejected.
+	SoftwareStatementKeys *jose.JSONWebKeySet
+
+	// AuthorizationServerMetadataOverrides sets the authorization server metadata which overrides the metadata
+	// generated by fosite, for example the URLs of endpoints fosite does not know about.
+	AuthorizationServerMetadataOverrides *AuthorizationServerMetadata
+
+	// UserinfoURL sets the URL of the OpenID Connect UserInfo endpoint.
+	UserinfoURL string
+
+	// UserinfoSigner sets the signer of signed UserInfo responses. If nil, only plain JSON responses are supported.
+	UserinfoSigner jwt.Signer
+
+	// EndSessionURL sets the URL of the OpenID Connect end session endpoint.
+	EndSessionURL string
+
+	// IDTokenSigner sets the signer of ID tokens, which verifies the id_token_hint of logout requests.
+	IDTokenSigner jwt.Signer
+
+	// LogoutConfirmationHTMLTemplate sets the HTML template rendered after a logout without redirect. Defaults to
+	// DefaultLogoutConfirmationTemplate.
+	LogoutConfirmationHTMLTemplate *template.Template
+
+	// PairwiseSubjectAlgorithm sets the algorithm which calculates the subject identifiers of clients using the
+	// pairwise subject type, for example an HMACPairwiseSubjectAlgorithm. If nil, pairwise subjects are not supported.
+	PairwiseSubjectAlgorithm PairwiseSubjectAlgorithm
 }
 
 func (c *Config) GetGlobalSecret(ctx context.Context) ([]byte, error) {
@@ -271,6 +435,10 @@ func (c *Config) GetFormPostHTMLTemplate(ctx context.Context) *template.Template
 	return c.FormPostHTMLTemplate
 }
 
+func (c *Config) GetFrontChannelLogoutHTMLTemplate(ctx context.Context) *template.Template {
+	return c.FrontChannelLogoutHTMLTemplate
+}
+
 func (c *Config) GetMessageCatalog(ctx context.Context) i18n.MessageCatalog {
 	return c.MessageCatalog
 }
@@ -499,3 +667,215 @@ func (c *Config) GetPushedAuthorizeContextLifespan(ctx context.Context) time.Dur
 func (c *Config) EnforcePushedAuthorize(ctx context.Context) bool {
 	return c.IsPushedAuthorizeEnforced
 }
+
+// GetDeviceEndpointHandlers returns the handlers.
+func (c *Config) GetDeviceEndpointHandlers(ctx context.Context) DeviceEndpointHandlers {
+	return c.DeviceEndpointHandlers
+}
+
+// GetDeviceAndUserCodeLifespan returns how long the device and user codes are valid. Defaults to ten minutes.
+func (c *Config) GetDeviceAndUserCodeLifespan(_ context.Context) time.Duration {
+	if c.DeviceAndUserCodeLifespan == 0 {
+		return defaultDeviceAndUserCodeLifespan
+	}
+	return c.DeviceAndUserCodeLifespan
+}
+
+// GetDeviceAuthTokenPollingInterval returns the minimum polling interval. Defaults to five seconds.
+func (c *Config) GetDeviceAuthTokenPollingInterval(_ context.Context) time.Duration {
+	if c.DeviceAuthTokenPollingInterval == 0 {
+		return defaultDeviceAuthTokenPollingInterval
+	}
+	return c.DeviceAuthTokenPollingInterval
+}
+
+// GetDeviceVerificationURL returns the URL of the page where the end user enters the user code.
+func (c *Config) GetDeviceVerificationURL(_ context.Context) string {
+	return c.DeviceVerificationURL
+}
+
+// GetBackchannelAuthenticationEndpointHandlers returns the handlers.
+func (c *Config) GetBackchannelAuthenticationEndpointHandlers(_ context.Context) BackchannelAuthenticationEndpointHandlers {
+	return c.BackchannelAuthenticationEndpointHandlers
+}
+
+// GetBackchannelAuthenticationURL returns the URL of the backchannel authentication endpoint.
+func (c *Config) GetBackchannelAuthenticationURL(_ context.Context) string {
+	return c.BackchannelAuthenticationURL
+}
+
+// GetBackchannelAuthenticationRequestLifespan returns how long an auth_req_id is valid. Defaults to ten minutes.
+func (c *Config) GetBackchannelAuthenticationRequestLifespan(_ context.Context) time.Duration {
+	if c.BackchannelAuthenticationRequestLifespan == 0 {
+		return defaultBackchannelAuthenticationRequestLifespan
+	}
+	return c.BackchannelAuthenticationRequestLifespan
+}
+
+// GetBackchannelAuthenticationPollingInterval returns the minimum polling interval. Defaults to five seconds.
+func (c *Config) GetBackchannelAuthenticationPollingInterval(_ context.Context) time.Duration {
+	if c.BackchannelAuthenticationPollingInterval == 0 {
+		return defaultBackchannelAuthenticationPollingInterval
+	}
+	return c.BackchannelAuthenticationPollingInterval
+}
+
+// GetBackchannelAuthenticationBindingMessageMaxLength returns the maximum length of the binding_message. Defaults
+// to 64 characters.
+func (c *Config) GetBackchannelAuthenticationBindingMessageMaxLength(_ context.Context) int {
+	if c.BackchannelAuthenticationBindingMessageMaxLength == 0 {
+		return defaultBackchannelAuthenticationBindingMessageLength
+	}
+	return c.BackchannelAuthenticationBindingMessageMaxLength
+}
+
+// GetDPoPProofLifespan returns how far the "iat" claim of a DPoP proof may deviate from the current time. Defaults to
+// five minutes.
+func (c *Config) GetDPoPProofLifespan(_ context.Context) time.Duration {
+	if c.DPoPProofLifespan == 0 {
+		return defaultDPoPProofLifespan
+	}
+	return c.DPoPProofLifespan
+}
+
+// GetDPoPSigningAlgorithms returns the JWS algorithms accepted for DPoP proofs. Defaults to all asymmetric algorithms.
+func (c *Config) GetDPoPSigningAlgorithms(_ context.Context) []string {
+	if len(c.DPoPSigningAlgorithms) == 0 {
+		return defaultDPoPSigningAlgorithms
+	}
+	return c.DPoPSigningAlgorithms
+}
+
+// GetTLSClientCertificateExtractor returns the client certificate extractor. Defaults to
+// fosite.DefaultTLSClientCertificateExtractor.
+func (c *Config) GetTLSClientCertificateExtractor(_ context.Context) TLSClientCertificateExtractor {
+	if c.TLSClientCertificateExtractor == nil {
+		return DefaultTLSClientCertificateExtractor
+	}
+	return c.TLSClientCertificateExtractor
+}
+
+// GetTLSClientCertificateAuthorities returns the certificate authorities client certificates are verified against.
+func (c *Config) GetTLSClientCertificateAuthorities(_ context.Context) *x509.CertPool {
+	return c.TLSClientCertificateAuthorities
+}
+
+// GetJWTSecuredAuthorizeRequestAudience returns the identifier the "aud" claim of request objects must contain.
+// Defaults to the AccessTokenIssuer, or the IDTokenIssuer if that is not set either.
+func (c *Config) GetJWTSecuredAuthorizeRequestAudience(ctx context.Context) string {
+	if c.JWTSecuredAuthorizeRequestAudience != "" {
+		return c.JWTSecuredAuthorizeRequestAudience
+	} else if issuer := c.GetAccessTokenIssuer(ctx); issuer != "" {
+		return issuer
+	}
+	return c.GetIDTokenIssuer(ctx)
+}
+
+// GetDisableRemoteRequestURIs returns true if request objects must not be fetched from client-hosted request URIs.
+func (c *Config) GetDisableRemoteRequestURIs(_ context.Context) bool {
+	return c.DisableRemoteRequestURIs
+}
+
+// GetJWTSecuredAuthorizeRequestDecrypter returns the decrypter of encrypted request objects.
+func (c *Config) GetJWTSecuredAuthorizeRequestDecrypter(_ context.Context) jwt.Decrypter {
+	return c.JWTSecuredAuthorizeRequestDecrypter
+}
+
+// GetJWTSecuredAuthorizeResponseModeSigner returns the signer of JWT secured authorization responses.
+func (c *Config) GetJWTSecuredAuthorizeResponseModeSigner(_ context.Context) jwt.Signer {
+	return c.JWTSecuredAuthorizeResponseModeSigner
+}
+
+// GetJWTSecuredAuthorizeResponseModeIssuer returns the issuer of JWT secured authorization responses. Defaults to
+// the IDTokenIssuer.
+func (c *Config) GetJWTSecuredAuthorizeResponseModeIssuer(ctx context.Context) string {
+	if c.JWTSecuredAuthorizeResponseModeIssuer == "" {
+		return c.GetIDTokenIssuer(ctx)
+	}
+	return c.JWTSecuredAuthorizeResponseModeIssuer
+}
+
+// GetJWTSecuredAuthorizeResponseModeLifespan returns how long a JWT secured authorization response is valid.
+// Defaults to ten minutes.
+func (c *Config) GetJWTSecuredAuthorizeResponseModeLifespan(_ context.Context) time.Duration {
+	if c.JWTSecuredAuthorizeResponseModeLifespan == 0 {
+		return defaultJWTSecuredAuthorizeResponseModeLifespan
+	}
+	return c.JWTSecuredAuthorizeResponseModeLifespan
+}
+
+// GetAuthorizationDetailValidators returns the validators of the supported authorization details types.
+func (c *Config) GetAuthorizationDetailValidators(_ context.Context) AuthorizationDetailValidators {
+	return c.AuthorizationDetailValidators
+}
+
+// GetUseJWTProfileAccessTokens returns true if JWT access tokens follow the JWT Profile for OAuth 2.0 Access Tokens.
+func (c *Config) GetUseJWTProfileAccessTokens(_ context.Context) bool {
+	return c.UseJWTProfileAccessTokens
+}
+
+// GetJWTProfileAccessTokenDefaultAudience returns the audience of RFC 9068 access tokens for which no audience was
+// granted.
+func (c *Config) GetJWTProfileAccessTokenDefaultAudience(_ context.Context) []string {
+	return c.JWTProfileAccessTokenDefaultAudience
+}
+
+// GetJWTIntrospectionResponseSigner returns the signer of JWT-formatted introspection responses.
+func (c *Config) GetJWTIntrospectionResponseSigner(_ context.Context) jwt.Signer {
+	return c.JWTIntrospectionResponseSigner
+}
+
+// GetJWTIntrospectionResponseIssuer returns the issuer of JWT-formatted introspection responses. Defaults to the
+// AccessTokenIssuer.
+func (c *Config) GetJWTIntrospectionResponseIssuer(ctx context.Context) string {
+	if c.JWTIntrospectionResponseIssuer == "" {
+		return c.GetAccessTokenIssuer(ctx)
+	}
+	return c.JWTIntrospectionResponseIssuer
+}
+
+// GetClientRegistrationURL returns the URL of the client registration endpoint.
+func (c *Config) GetClientRegistrationURL(_ context.Context) string {
+	return c.ClientRegistrationURL
+}
+
+// GetSoftwareStatementKeys returns the public keys of the issuers whose software statements are trusted.
+func (c *Config) GetSoftwareStatementKeys(_ context.Context) *jose.JSONWebKeySet {
+	return c.SoftwareStatementKeys
+}
+
+// GetAuthorizationServerMetadataOverrides returns the metadata which overrides the generated authorization server
+// metadata.
+func (c *Config) GetAuthorizationServerMetadataOverrides(_ context.Context) *AuthorizationServerMetadata {
+	return c.AuthorizationServerMetadataOverrides
+}
+
+// GetUserinfoURL returns the URL of the UserInfo endpoint.
+func (c *Config) GetUserinfoURL(_ context.Context) string {
+	return c.UserinfoURL
+}
+
+// GetUserinfoSigner returns the signer of signed UserInfo responses.
+func (c *Config) GetUserinfoSigner(_ context.Context) jwt.Signer {
+	return c.UserinfoSigner
+}
+
+// GetEndSessionURL returns the URL of the end session endpoint.
+func (c *Config) GetEndSessionURL(_ context.Context) string {
+	return c.EndSessionURL
+}
+
+// GetIDTokenSigner returns the signer of ID tokens.
+func (c *Config) GetIDTokenSigner(_ context.Context) jwt.Signer {
+	return c.IDTokenSigner
+}
+
+// GetLogoutConfirmationHTMLTemplate returns the HTML template rendered after a logout without redirect.
+func (c *Config) GetLogoutConfirmationHTMLTemplate(_ context.Context) *template.Template {
+	return c.LogoutConfirmationHTMLTemplate
+}
+
+// GetPairwiseSubjectAlgorithm returns the algorithm which calculates pairwise subject identifiers.
+func (c *Config) GetPairwiseSubjectAlgorithm(_ context.Context) PairwiseSubjectAlgorithm {
+	return c.PairwiseSubjectAlgorithm
+}
@@ -19,4 +19,15 @@ const (
 	AuthorizeResponseContextKey = ContextKey("authorizeResponse")
 	// PushedAuthorizeResponseContextKey is the response context
 	PushedAuthorizeResponseContextKey = ContextKey("pushedAuthorizeResponse")
+	// DeviceRequestContextKey is the device authorization request context
+	DeviceRequestContextKey = ContextKey("deviceRequest")
+	// DeviceResponseContextKey is the device authorization response context
+	DeviceResponseContextKey = ContextKey("deviceResponse")
+	// BackchannelAuthenticationRequestContextKey is the backchannel authentication request context
+	BackchannelAuthenticationRequestContextKey = ContextKey("backchannelAuthenticationRequest")
+	// BackchannelAuthenticationResponseContextKey is the backchannel authentication response context
+	BackchannelAuthenticationResponseContextKey = ContextKey("backchannelAuthenticationResponse")
+	// AuthorizeResponseIssuerContextKey is the issuer identifier ResponseModeHandler extensions add to authorization
+	// error responses as the "iss" parameter
+	AuthorizeResponseIssuerContextKey = ContextKey("authorizeResponseIssuer")
 )
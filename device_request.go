@@ -0,0 +1,63 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import "time"
+
+// DeviceRequest is an implementation of DeviceRequester
+type DeviceRequest struct {
+	Status              DeviceRequestStatus `json:"status" gorethink:"status"`
+	DeviceCodeSignature string              `json:"deviceCodeSignature" gorethink:"deviceCodeSignature"`
+	LastPolledAt        time.Time           `json:"lastPolledAt" gorethink:"lastPolledAt"`
+	PollingInterval     time.Duration       `json:"pollingInterval" gorethink:"pollingInterval"`
+
+	Request
+}
+
+func NewDeviceRequest() *DeviceRequest {
+	return &DeviceRequest{
+		Status:  DeviceRequestStatusPending,
+		Request: *NewRequest(),
+	}
+}
+
+func (d *DeviceRequest) GetStatus() DeviceRequestStatus {
+	return d.Status
+}
+
+func (d *DeviceRequest) SetStatus(status DeviceRequestStatus) {
+	d.Status = status
+}
+
+func (d *DeviceRequest) GetDeviceCodeSignature() string {
+	return d.DeviceCodeSignature
+}
+
+func (d *DeviceRequest) SetDeviceCodeSignature(signature string) {
+	d.DeviceCodeSignature = signature
+}
+
+func (d *DeviceRequest) GetLastPolledAt() time.Time {
+	return d.LastPolledAt
+}
+
+func (d *DeviceRequest) SetLastPolledAt(t time.Time) {
+	d.LastPolledAt = t
+}
+
+func (d *DeviceRequest) GetPollingInterval() time.Duration {
+	return d.PollingInterval
+}
+
+func (d *DeviceRequest) SetPollingInterval(interval time.Duration) {
+	d.PollingInterval = interval
+}
+
+// Sanitize returns a sanitized clone of the request which keeps the device authorization state.
+func (d *DeviceRequest) Sanitize(allowedParameters []string) Requester {
+	b := new(DeviceRequest)
+	*b = *d
+	b.Request = *d.Request.Sanitize(allowedParameters).(*Request)
+	return b
+}
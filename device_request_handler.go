@@ -0,0 +1,77 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ory/fosite/i18n"
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/otelx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ErrorDeviceNotSupported           = "The OAuth 2.0 provider does not support the Device Authorization Grant"
+	DebugDeviceRequestHandlersMissing = "'DeviceEndpointHandlersProvider' not implemented"
+)
+
+// NewDeviceRequest validates the request at the device authorization endpoint as specified in
+// https://www.rfc-editor.org/rfc/rfc8628#section-3.1 and produces a DeviceRequester object.
+func (f *Fosite) NewDeviceRequest(ctx context.Context, r *http.Request) (_ DeviceRequester, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewDeviceRequest")
+	defer otelx.End(span, &err)
+
+	request := NewDeviceRequest()
+	request.Lang = i18n.GetLangFromRequest(f.Config.GetMessageCatalog(ctx), r)
+
+	if r.Method != "POST" {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'POST'.", r.Method))
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to parse HTTP body, make sure to send a properly formatted form request body.").WithWrap(err).WithDebug(err.Error()))
+	}
+	request.Form = r.PostForm
+
+	// The client authentication requirements of Section 3.2.1 of [RFC6749]
+	// apply to requests on this endpoint, which means that confidential
+	// clients (those that have established client credentials) authenticate
+	// in the same manner as when making requests to the token endpoint, and
+	// public clients provide the "client_id" parameter to identify
+	// themselves.
+	client, err := f.AuthenticateClient(ctx, r, r.PostForm)
+	if err != nil {
+		var rfcerr *RFC6749Error
+		if errors.As(err, &rfcerr) && rfcerr.ErrorField != ErrInvalidClient.ErrorField {
+			return request, errorsx.WithStack(ErrInvalidClient.WithHint("The requested OAuth 2.0 Client could not be authenticated.").WithWrap(err).WithDebug(err.Error()))
+		}
+
+		return request, err
+	}
+	request.Client = client
+
+	if !client.GetGrantTypes().Has(string(GrantTypeDeviceCode)) {
+		return request, errorsx.WithStack(ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant '%s'.", GrantTypeDeviceCode))
+	}
+
+	scope := RemoveEmpty(strings.Split(request.Form.Get("scope"), " "))
+	for _, permission := range scope {
+		if !f.Config.GetScopeStrategy(ctx)(client.GetScopes(), permission) {
+			return request, errorsx.WithStack(ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", permission))
+		}
+	}
+	request.SetRequestedScopes(scope)
+
+	audience := GetAudiences(request.Form)
+	if err := f.Config.GetAudienceStrategy(ctx)(client.GetAudience(), audience); err != nil {
+		return request, err
+	}
+	request.SetRequestedAudience(audience)
+
+	return request, nil
+}
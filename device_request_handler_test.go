@@ -0,0 +1,121 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/internal"
+)
+
+func TestNewDeviceRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := internal.NewMockStorage(ctrl)
+	defer ctrl.Finish()
+
+	fosite := &Fosite{
+		Store: store,
+		Config: &Config{
+			ScopeStrategy:            ExactScopeStrategy,
+			AudienceMatchingStrategy: DefaultAudienceMatchingStrategy,
+		},
+	}
+
+	deviceClient := &DefaultClient{
+		ID:         "device-client",
+		Public:     true,
+		GrantTypes: []string{string(GrantTypeDeviceCode)},
+		Scopes:     []string{"foo", "bar"},
+		Audience:   []string{"https://www.ory.sh/api"},
+	}
+
+	for _, c := range []struct {
+		desc          string
+		method        string
+		form          url.Values
+		mock          func()
+		expectedError error
+		expect        func(t *testing.T, dr DeviceRequester)
+	}{
+		{
+			desc:          "should fail because the method is not POST",
+			method:        "GET",
+			mock:          func() {},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc:          "should fail because the client is missing",
+			form:          url.Values{},
+			mock:          func() {},
+			expectedError: ErrInvalidClient,
+		},
+		{
+			desc: "should fail because the client may not use the device code grant",
+			form: url.Values{"client_id": {"device-client"}},
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "device-client").Return(&DefaultClient{ID: "device-client", Public: true, GrantTypes: []string{"authorization_code"}}, nil)
+			},
+			expectedError: ErrUnauthorizedClient,
+		},
+		{
+			desc: "should fail because the client may not request scope baz",
+			form: url.Values{"client_id": {"device-client"}, "scope": {"foo baz"}},
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "device-client").Return(deviceClient, nil)
+			},
+			expectedError: ErrInvalidScope,
+		},
+		{
+			desc: "should fail because the client may not request the audience",
+			form: url.Values{"client_id": {"device-client"}, "audience": {"https://cloud.ory.sh/api"}},
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "device-client").Return(deviceClient, nil)
+			},
+			expectedError: ErrInvalidRequest,
+		},
+		{
+			desc: "should pass",
+			form: url.Values{"client_id": {"device-client"}, "scope": {"foo bar"}, "audience": {"https://www.ory.sh/api"}},
+			mock: func() {
+				store.EXPECT().GetClient(gomock.Any(), "device-client").Return(deviceClient, nil)
+			},
+			expect: func(t *testing.T, dr DeviceRequester) {
+				assert.Equal(t, deviceClient, dr.GetClient())
+				assert.EqualValues(t, Arguments{"foo", "bar"}, dr.GetRequestedScopes())
+				assert.EqualValues(t, Arguments{"https://www.ory.sh/api"}, dr.GetRequestedAudience())
+				assert.Equal(t, DeviceRequestStatusPending, dr.GetStatus())
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("case=%s", c.desc), func(t *testing.T) {
+			c.mock()
+
+			method := c.method
+			if method == "" {
+				method = "POST"
+			}
+			r, err := http.NewRequest(method, "https://www.ory.sh/device/auth", strings.NewReader(c.form.Encode()))
+			require.NoError(t, err)
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			dr, err := fosite.NewDeviceRequest(NewContext(), r)
+			if c.expectedError != nil {
+				require.ErrorIs(t, err, c.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			c.expect(t, dr)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import "net/http"
+
+// DeviceResponse is the response object of the device authorization endpoint
+type DeviceResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+	Header                  http.Header
+	Extra                   map[string]interface{}
+}
+
+func NewDeviceResponse() *DeviceResponse {
+	return &DeviceResponse{
+		Header: http.Header{},
+		Extra:  map[string]interface{}{},
+	}
+}
+
+// GetDeviceCode gets
+func (d *DeviceResponse) GetDeviceCode() string {
+	return d.DeviceCode
+}
+
+// SetDeviceCode sets
+func (d *DeviceResponse) SetDeviceCode(code string) {
+	d.DeviceCode = code
+}
+
+// GetUserCode gets
+func (d *DeviceResponse) GetUserCode() string {
+	return d.UserCode
+}
+
+// SetUserCode sets
+func (d *DeviceResponse) SetUserCode(code string) {
+	d.UserCode = code
+}
+
+// GetVerificationURI gets
+func (d *DeviceResponse) GetVerificationURI() string {
+	return d.VerificationURI
+}
+
+// SetVerificationURI sets
+func (d *DeviceResponse) SetVerificationURI(uri string) {
+	d.VerificationURI = uri
+}
+
+// GetVerificationURIComplete gets
+func (d *DeviceResponse) GetVerificationURIComplete() string {
+	return d.VerificationURIComplete
+}
+
+// SetVerificationURIComplete sets
+func (d *DeviceResponse) SetVerificationURIComplete(uri string) {
+	d.VerificationURIComplete = uri
+}
+
+// GetExpiresIn gets
+func (d *DeviceResponse) GetExpiresIn() int64 {
+	return d.ExpiresIn
+}
+
+// SetExpiresIn sets
+func (d *DeviceResponse) SetExpiresIn(seconds int64) {
+	d.ExpiresIn = seconds
+}
+
+// GetInterval gets
+func (d *DeviceResponse) GetInterval() int {
+	return d.Interval
+}
+
+// SetInterval sets
+func (d *DeviceResponse) SetInterval(seconds int) {
+	d.Interval = seconds
+}
+
+// GetHeader gets
+func (d *DeviceResponse) GetHeader() http.Header {
+	return d.Header
+}
+
+// AddHeader adds
+func (d *DeviceResponse) AddHeader(key, value string) {
+	d.Header.Add(key, value)
+}
+
+// SetExtra sets
+func (d *DeviceResponse) SetExtra(key string, value interface{}) {
+	d.Extra[key] = value
+}
+
+// GetExtra gets
+func (d *DeviceResponse) GetExtra(key string) interface{} {
+	return d.Extra[key]
+}
+
+// ToMap converts to a map
+func (d *DeviceResponse) ToMap() map[string]interface{} {
+	d.Extra["device_code"] = d.DeviceCode
+	d.Extra["user_code"] = d.UserCode
+	d.Extra["verification_uri"] = d.VerificationURI
+	if d.VerificationURIComplete != "" {
+		d.Extra["verification_uri_complete"] = d.VerificationURIComplete
+	}
+	d.Extra["expires_in"] = d.ExpiresIn
+	if d.Interval > 0 {
+		d.Extra["interval"] = d.Interval
+	}
+	return d.Extra
+}
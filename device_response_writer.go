@@ -0,0 +1,42 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/otelx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewDeviceResponse executes the device endpoint handlers and builds the response
+func (f *Fosite) NewDeviceResponse(ctx context.Context, dr DeviceRequester, session Session) (_ DeviceResponder, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewDeviceResponse")
+	defer otelx.End(span, &err)
+
+	// Get handlers. If no handlers are defined, this is considered a misconfigured Fosite instance.
+	handlersProvider, ok := f.Config.(DeviceEndpointHandlersProvider)
+	if !ok {
+		return nil, errorsx.WithStack(ErrServerError.WithHint(ErrorDeviceNotSupported).WithDebug(DebugDeviceRequestHandlersMissing))
+	}
+
+	var resp = NewDeviceResponse()
+
+	ctx = context.WithValue(ctx, DeviceRequestContextKey, dr)
+	ctx = context.WithValue(ctx, DeviceResponseContextKey, resp)
+
+	dr.SetSession(session)
+	for _, h := range handlersProvider.GetDeviceEndpointHandlers(ctx) {
+		if err := h.HandleDeviceEndpointRequest(ctx, dr, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.GetDeviceCode() == "" {
+		return nil, errorsx.WithStack(ErrServerError.WithHint(ErrorDeviceNotSupported).WithDebug("No device endpoint handler issued a device code."))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,115 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	. "github.com/ory/fosite/internal"
+)
+
+func TestNewDeviceResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler := NewMockDeviceEndpointHandler(ctrl)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	oauth2 := &Fosite{
+		Config: &Config{
+			DeviceEndpointHandlers: DeviceEndpointHandlers{handler},
+		},
+	}
+
+	fooErr := errors.New("foo")
+	for k, c := range []struct {
+		mock      func()
+		expectErr error
+	}{
+		{
+			mock: func() {
+				handler.EXPECT().HandleDeviceEndpointRequest(gomock.Any(), gomock.Any(), gomock.Any()).Return(fooErr)
+			},
+			expectErr: fooErr,
+		},
+		{
+			mock: func() {
+				handler.EXPECT().HandleDeviceEndpointRequest(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectErr: ErrServerError,
+		},
+		{
+			mock: func() {
+				handler.EXPECT().HandleDeviceEndpointRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ DeviceRequester, resp DeviceResponder) error {
+					resp.SetDeviceCode("device-code")
+					return nil
+				})
+			},
+		},
+	} {
+		c.mock()
+		dr := NewDeviceRequest()
+		responder, err := oauth2.NewDeviceResponse(ctx, dr, new(DefaultSession))
+		if c.expectErr != nil {
+			assert.ErrorIs(t, err, c.expectErr, "%d", k)
+			assert.Nil(t, responder, "%d", k)
+		} else {
+			require.NoError(t, err, "%d", k)
+			assert.Equal(t, "device-code", responder.GetDeviceCode(), "%d", k)
+			assert.Equal(t, new(DefaultSession), dr.GetSession(), "%d", k)
+		}
+	}
+}
+
+func TestWriteDeviceResponse(t *testing.T) {
+	oauth2 := &Fosite{Config: new(Config)}
+	resp := NewDeviceResponse()
+	resp.SetDeviceCode("device-code")
+	resp.SetUserCode("BCDF-GHJK")
+	resp.SetVerificationURI("https://www.ory.sh/device")
+	resp.SetVerificationURIComplete("https://www.ory.sh/device?user_code=BCDF-GHJK")
+	resp.SetExpiresIn(600)
+	resp.SetInterval(5)
+	resp.AddHeader("X-Foo", "bar")
+
+	rw := httptest.NewRecorder()
+	oauth2.WriteDeviceResponse(context.Background(), rw, NewDeviceRequest(), resp)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "no-store", rw.Header().Get("Cache-Control"))
+	assert.Equal(t, "bar", rw.Header().Get("X-Foo"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+	assert.Equal(t, map[string]interface{}{
+		"device_code":               "device-code",
+		"user_code":                 "BCDF-GHJK",
+		"verification_uri":          "https://www.ory.sh/device",
+		"verification_uri_complete": "https://www.ory.sh/device?user_code=BCDF-GHJK",
+		"expires_in":                float64(600),
+		"interval":                  float64(5),
+	}, body)
+}
+
+func TestWriteDeviceError(t *testing.T) {
+	oauth2 := &Fosite{Config: new(Config)}
+	rw := httptest.NewRecorder()
+	oauth2.WriteDeviceError(context.Background(), rw, NewDeviceRequest(), ErrInvalidClient)
+
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+	assert.Equal(t, "no-store", rw.Header().Get("Cache-Control"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+	assert.Equal(t, "invalid_client", body["error"])
+}
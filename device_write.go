@@ -0,0 +1,61 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WriteDeviceResponse writes the device authorization response as specified in
+// https://www.rfc-editor.org/rfc/rfc8628#section-3.2
+func (f *Fosite) WriteDeviceResponse(ctx context.Context, rw http.ResponseWriter, dr DeviceRequester, resp DeviceResponder) {
+	// Set custom headers, e.g. "X-MySuperCoolCustomHeader" or "X-DONT-CACHE-ME"...
+	wh := rw.Header()
+	rh := resp.GetHeader()
+	for k := range rh {
+		wh.Set(k, rh.Get(k))
+	}
+
+	wh.Set("Cache-Control", "no-store")
+	wh.Set("Pragma", "no-cache")
+	wh.Set("Content-Type", "application/json;charset=UTF-8")
+
+	js, err := json.Marshal(resp.ToMap())
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(js)
+}
+
+// WriteDeviceError writes the device authorization error as specified in
+// https://www.rfc-editor.org/rfc/rfc8628#section-3.2
+func (f *Fosite) WriteDeviceError(ctx context.Context, rw http.ResponseWriter, dr DeviceRequester, err error) {
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+
+	sendDebugMessagesToClient := f.Config.GetSendDebugMessagesToClients(ctx)
+	rfcerr := ErrorToRFC6749Error(err).WithLegacyFormat(f.Config.GetUseLegacyErrorFormat(ctx)).
+		WithExposeDebug(sendDebugMessagesToClient).WithLocalizer(f.Config.GetMessageCatalog(ctx), getLangFromRequester(dr))
+
+	js, err := json.Marshal(rfcerr)
+	if err != nil {
+		if sendDebugMessagesToClient {
+			errorMessage := EscapeJSONString(err.Error())
+			http.Error(rw, fmt.Sprintf(`{"error":"server_error","error_description":"%s"}`, errorMessage), http.StatusInternalServerError)
+		} else {
+			http.Error(rw, `{"error":"server_error"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	rw.WriteHeader(rfcerr.CodeField)
+	_, _ = rw.Write(js)
+}
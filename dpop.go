@@ -0,0 +1,273 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/ory/x/errorsx"
+)
+
+const (
+	// DPoPHeader is the HTTP header carrying the DPoP proof.
+	DPoPHeader = "DPoP"
+
+	dpopProofType = "dpop+jwt"
+)
+
+// DPoPSession is implemented by sessions which can bind tokens to the key of a DPoP proof as specified in
+// https://www.rfc-editor.org/rfc/rfc9449.
+type DPoPSession interface {
+	// SetDPoPJWKThumbprint sets the JWK SHA-256 thumbprint of the key the tokens are bound to.
+	SetDPoPJWKThumbprint(jkt string)
+
+	// GetDPoPJWKThumbprint returns the JWK SHA-256 thumbprint of the key the tokens are bound to, or an empty
+	// string if the tokens are not bound.
+	GetDPoPJWKThumbprint() string
+}
+
+// DPoPProofStorage is used to prevent the replay of DPoP proofs. If the store does not implement it, the client
+// assertion JTI methods of the ClientManager are used instead.
+type DPoPProofStorage interface {
+	// DPoPProofJTIValid returns an error if the JTI of a DPoP proof is known or the check failed and nil if the
+	// JTI is not known.
+	DPoPProofJTIValid(ctx context.Context, jti string) error
+
+	// SetDPoPProofJTI marks the JTI of a DPoP proof as known for the given expiry time.
+	SetDPoPProofJTI(ctx context.Context, jti string, exp time.Time) error
+}
+
+// GetDPoPJWKThumbprint returns the JWK thumbprint the session is bound to, or an empty string if the session is not
+// bound or does not support DPoP.
+func GetDPoPJWKThumbprint(session Session) string {
+	if s, ok := session.(DPoPSession); ok {
+		return s.GetDPoPJWKThumbprint()
+	}
+	return ""
+}
+
+// GetAccessTokenType returns the token type of access tokens issued for the session, which is "DPoP" if the session
+// is bound to a DPoP key and "bearer" otherwise.
+func GetAccessTokenType(session Session) string {
+	if GetDPoPJWKThumbprint(session) != "" {
+		return DPoPAccessToken
+	}
+	return BearerAccessToken
+}
+
+type dpopProofClaims struct {
+	JTI             string `json:"jti"`
+	HTTPMethod      string `json:"htm"`
+	HTTPURI         string `json:"htu"`
+	IssuedAt        int64  `json:"iat"`
+	AccessTokenHash string `json:"ath"`
+}
+
+// ValidateDPoPProof validates the DPoP proof of a request to a protected resource which presented the given access
+// token, and verifies that the proof was signed by the key with the given JWK thumbprint the access token is bound to.
+// The "htu" claim is compared against requestURI, or, if empty, the URL the request was received at.
+func (f *Fosite) ValidateDPoPProof(ctx context.Context, r *http.Request, requestURI string, accessToken string, jkt string) error {
+	uris := []string{requestURI}
+	if requestURI == "" {
+		uris = []string{dpopRequestURL(r)}
+	}
+
+	proofJKT, err := f.validateDPoPProof(ctx, r, uris, accessToken)
+	if err != nil {
+		return err
+	} else if proofJKT == "" {
+		return errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The request is missing the DPoP proof required for DPoP-bound access tokens."))
+	} else if proofJKT != jkt {
+		return errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The DPoP proof was not signed with the key the access token is bound to."))
+	}
+
+	return nil
+}
+
+// validateDPoPProof validates the DPoP proof of the request, if any, according to
+// https://www.rfc-editor.org/rfc/rfc9449#section-4.3 and returns the JWK thumbprint of its key. If the request does
+// not contain a DPoP proof, an empty string is returned.
+func (f *Fosite) validateDPoPProof(ctx context.Context, r *http.Request, uris []string, accessToken string) (string, error) {
+	proofs := r.Header.Values(DPoPHeader)
+	if len(proofs) == 0 {
+		return "", nil
+	} else if len(proofs) > 1 {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The request must not contain more than one DPoP proof."))
+	}
+
+	jws, err := jose.ParseSigned(proofs[0])
+	if err != nil {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("Unable to parse the DPoP proof.").WithWrap(err).WithDebug(err.Error()))
+	} else if len(jws.Signatures) != 1 {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The DPoP proof must contain exactly one signature."))
+	}
+
+	header := jws.Signatures[0].Header
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != dpopProofType {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHintf("The DPoP proof must have the 'typ' header '%s'.", dpopProofType))
+	}
+
+	if !StringInSlice(header.Algorithm, f.dpopSigningAlgorithms(ctx)) {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHintf("The DPoP proof is signed with the unsupported algorithm '%s'.", header.Algorithm))
+	}
+
+	key := header.JSONWebKey
+	if key == nil || !key.Valid() || !key.IsPublic() {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The DPoP proof must contain a valid public key in the 'jwk' header."))
+	}
+
+	payload, err := jws.Verify(key)
+	if err != nil {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("Unable to verify the signature of the DPoP proof.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	var claims dpopProofClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("Unable to decode the claims of the DPoP proof.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if claims.JTI == "" || claims.HTTPMethod == "" || claims.HTTPURI == "" || claims.IssuedAt == 0 {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The DPoP proof must contain the 'jti', 'htm', 'htu' and 'iat' claims."))
+	}
+
+	if claims.HTTPMethod != r.Method {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHintf("The 'htm' claim of the DPoP proof does not match the HTTP method '%s'.", r.Method))
+	}
+
+	if !dpopURIMatches(claims.HTTPURI, uris) {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The 'htu' claim of the DPoP proof does not match the URI of the request."))
+	}
+
+	lifespan := f.dpopProofLifespan(ctx)
+	issuedAt := time.Unix(claims.IssuedAt, 0)
+	if now := time.Now(); issuedAt.Before(now.Add(-lifespan)) || issuedAt.After(now.Add(lifespan)) {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The 'iat' claim of the DPoP proof is outside of the acceptable time window."))
+	}
+
+	if accessToken != "" {
+		hash := sha256.Sum256([]byte(accessToken))
+		if claims.AccessTokenHash != base64.RawURLEncoding.EncodeToString(hash[:]) {
+			return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The 'ath' claim of the DPoP proof does not match the access token."))
+		}
+	}
+
+	if err := f.markDPoPProofUsed(ctx, claims.JTI, issuedAt.Add(lifespan)); err != nil {
+		return "", err
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", errorsx.WithStack(ErrInvalidDPoPProof.WithHint("Unable to compute the thumbprint of the DPoP proof key.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
+
+func (f *Fosite) markDPoPProofUsed(ctx context.Context, jti string, exp time.Time) error {
+	if store, ok := f.Store.(DPoPProofStorage); ok {
+		if err := store.DPoPProofJTIValid(ctx, jti); err != nil {
+			return errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The 'jti' of the DPoP proof was already used.").WithWrap(err).WithDebug(err.Error()))
+		} else if err := store.SetDPoPProofJTI(ctx, jti, exp); err != nil {
+			return errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+		return nil
+	}
+
+	if err := f.Store.ClientAssertionJWTValid(ctx, jti); err != nil {
+		return errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The 'jti' of the DPoP proof was already used.").WithWrap(err).WithDebug(err.Error()))
+	} else if err := f.Store.SetClientAssertionJWT(ctx, jti, exp); err != nil {
+		return errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return nil
+}
+
+// dpopURIMatches compares the "htu" claim against the allowed URIs, ignoring query and fragment.
+func dpopURIMatches(htu string, uris []string) bool {
+	claimed, err := url.Parse(htu)
+	if err != nil {
+		return false
+	}
+	claimed.RawQuery, claimed.Fragment = "", ""
+
+	for _, uri := range uris {
+		if uri == "" {
+			continue
+		}
+		expected, err := url.Parse(uri)
+		if err != nil {
+			continue
+		}
+		expected.RawQuery, expected.Fragment = "", ""
+		if strings.EqualFold(claimed.Scheme, expected.Scheme) && strings.EqualFold(claimed.Host, expected.Host) && claimed.EscapedPath() == expected.EscapedPath() {
+			return true
+		}
+	}
+	return false
+}
+
+// dpopRequestURL reconstructs the URL the request was received at.
+func dpopRequestURL(r *http.Request) string {
+	if r.URL == nil {
+		return ""
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return (&url.URL{Scheme: scheme, Host: r.Host, Path: r.URL.Path}).String()
+}
+
+// dpopTokenEndpointURIs returns the URIs a DPoP proof sent to the token endpoint may be issued for.
+func (f *Fosite) dpopTokenEndpointURIs(ctx context.Context, r *http.Request) []string {
+	var uris []string
+	for _, uri := range f.Config.GetTokenURLs(ctx) {
+		if uri != "" {
+			uris = append(uris, uri)
+		}
+	}
+	if len(uris) == 0 {
+		return []string{dpopRequestURL(r)}
+	}
+	return uris
+}
+
+// validateDPoPJKT validates the format of the "dpop_jkt" authorization request parameter, which is the base64url
+// encoded JWK SHA-256 thumbprint of the key the authorization code is bound to.
+func validateDPoPJKT(jkt string) error {
+	if jkt == "" {
+		return nil
+	}
+	if decoded, err := base64.RawURLEncoding.DecodeString(jkt); err != nil || len(decoded) != sha256.Size {
+		return errorsx.WithStack(ErrInvalidRequest.WithHint("The 'dpop_jkt' parameter must be a base64url encoded JWK SHA-256 thumbprint."))
+	}
+	return nil
+}
+
+// dpopSigningAlgorithms returns the JWS algorithms accepted for DPoP proofs. These are all asymmetric algorithms if
+// the configuration does not implement DPoPProvider.
+func (f *Fosite) dpopSigningAlgorithms(ctx context.Context) []string {
+	if c, ok := f.Config.(DPoPProvider); ok {
+		return c.GetDPoPSigningAlgorithms(ctx)
+	}
+	return defaultDPoPSigningAlgorithms
+}
+
+// dpopProofLifespan returns how far the "iat" claim of a DPoP proof may deviate from the current time. This is five
+// minutes if the configuration does not implement DPoPProvider.
+func (f *Fosite) dpopProofLifespan(ctx context.Context) time.Duration {
+	if c, ok := f.Config.(DPoPProvider); ok {
+		return c.GetDPoPProofLifespan(ctx)
+	}
+	return defaultDPoPProofLifespan
+}
@@ -0,0 +1,296 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	josejwt "github.com/go-jose/go-jose/v3/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/internal"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+)
+
+type dpopProof struct {
+	JTI             string `json:"jti,omitempty"`
+	HTTPMethod      string `json:"htm,omitempty"`
+	HTTPURI         string `json:"htu,omitempty"`
+	IssuedAt        int64  `json:"iat,omitempty"`
+	AccessTokenHash string `json:"ath,omitempty"`
+}
+
+func newDPoPProof(t *testing.T, key *ecdsa.PrivateKey, typ string, claims dpopProof) string {
+	opts := (&jose.SignerOptions{EmbedJWK: true}).WithType(jose.ContentType(typ))
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, opts)
+	require.NoError(t, err)
+	proof, err := josejwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+	return proof
+}
+
+func dpopThumbprint(t *testing.T, key *ecdsa.PrivateKey) string {
+	thumbprint, err := (&jose.JSONWebKey{Key: &key.PublicKey}).Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(thumbprint)
+}
+
+func TestValidateDPoPProof(t *testing.T) {
+	ctx := context.Background()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jkt := dpopThumbprint(t, key)
+
+	const resource = "https://api.example.com/resource"
+	accessToken := "access-token"
+	hash := sha256.Sum256([]byte(accessToken))
+	ath := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	newRequest := func(proofs ...string) *http.Request {
+		r, err := http.NewRequest("GET", resource+"?foo=bar", nil)
+		require.NoError(t, err)
+		for _, proof := range proofs {
+			r.Header.Add(DPoPHeader, proof)
+		}
+		return r
+	}
+
+	valid := func() dpopProof {
+		return dpopProof{JTI: randomJTI(t), HTTPMethod: "GET", HTTPURI: resource, IssuedAt: time.Now().Unix(), AccessTokenHash: ath}
+	}
+
+	for k, tc := range []struct {
+		d         string
+		request   func() *http.Request
+		jkt       string
+		expectErr error
+	}{
+		{
+			d:       "should pass with a valid proof",
+			request: func() *http.Request { return newRequest(newDPoPProof(t, key, "dpop+jwt", valid())) },
+			jkt:     jkt,
+		},
+		{
+			d:         "should fail without a proof",
+			request:   func() *http.Request { return newRequest() },
+			jkt:       jkt,
+			expectErr: ErrInvalidDPoPProof,
+		},
+		{
+			d: "should fail with multiple proofs",
+			request: func() *http.Request {
+				return newRequest(newDPoPProof(t, key, "dpop+jwt", valid()), newDPoPProof(t, key, "dpop+jwt", valid()))
+			},
+			jkt:       jkt,
+			expectErr: ErrInvalidDPoPProof,
+		},
+		{
+			d:         "should fail with the wrong type",
+			request:   func() *http.Request { return newRequest(newDPoPProof(t, key, "JWT", valid())) },
+			jkt:       jkt,
+			expectErr: ErrInvalidDPoPProof,
+		},
+		{
+			d: "should fail with the wrong method",
+			request: func() *http.Request {
+				claims := valid()
+				claims.HTTPMethod = "POST"
+				return newRequest(newDPoPProof(t, key, "dpop+jwt", claims))
+			},
+			jkt:       jkt,
+			expectErr: ErrInvalidDPoPProof,
+		},
+		{
+			d: "should fail with the wrong uri",
+			request: func() *http.Request {
+				claims := valid()
+				claims.HTTPURI = "https://api.example.com/other"
+				return newRequest(newDPoPProof(t, key, "dpop+jwt", claims))
+			},
+			jkt:       jkt,
+			expectErr: ErrInvalidDPoPProof,
+		},
+		{
+			d: "should fail with an outdated iat",
+			request: func() *http.Request {
+				claims := valid()
+				claims.IssuedAt = time.Now().Add(-time.Hour).Unix()
+				return newRequest(newDPoPProof(t, key, "dpop+jwt", claims))
+			},
+			jkt:       jkt,
+			expectErr: ErrInvalidDPoPProof,
+		},
+		{
+			d: "should fail with the wrong access token hash",
+			request: func() *http.Request {
+				claims := valid()
+				claims.AccessTokenHash = "foo"
+				return newRequest(newDPoPProof(t, key, "dpop+jwt", claims))
+			},
+			jkt:       jkt,
+			expectErr: ErrInvalidDPoPProof,
+		},
+		{
+			d:         "should fail if the token is bound to another key",
+			request:   func() *http.Request { return newRequest(newDPoPProof(t, key, "dpop+jwt", valid())) },
+			jkt:       "other",
+			expectErr: ErrInvalidDPoPProof,
+		},
+	} {
+		t.Run("case="+tc.d, func(t *testing.T) {
+			f := &Fosite{Store: storage.NewMemoryStore(), Config: &Config{}}
+			err := f.ValidateDPoPProof(ctx, tc.request(), resource, accessToken, tc.jkt)
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr, "%d", k)
+				return
+			}
+			require.NoError(t, err, "%d", k)
+		})
+	}
+
+	t.Run("case=should fail if the proof is replayed", func(t *testing.T) {
+		f := &Fosite{Store: storage.NewMemoryStore(), Config: &Config{}}
+		proof := newDPoPProof(t, key, "dpop+jwt", valid())
+		require.NoError(t, f.ValidateDPoPProof(ctx, newRequest(proof), resource, accessToken, jkt))
+		assert.ErrorIs(t, f.ValidateDPoPProof(ctx, newRequest(proof), resource, accessToken, jkt), ErrInvalidDPoPProof)
+	})
+}
+
+func TestNewAccessRequestWithDPoP(t *testing.T) {
+	ctx := context.Background()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	const tokenURL = "https://auth.example.com/oauth2/token"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := internal.NewMockTokenEndpointHandler(ctrl)
+	handler.EXPECT().CanHandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().CanSkipClientAuth(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+	handler.EXPECT().HandleTokenEndpointRequest(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	f := &Fosite{Store: storage.NewMemoryStore(), Config: &Config{TokenURL: tokenURL, TokenEndpointHandlers: TokenEndpointHandlers{handler}}}
+
+	newRequest := func(proof string) *http.Request {
+		r, err := http.NewRequest("POST", tokenURL, strings.NewReader(url.Values{"grant_type": {"client_credentials"}}.Encode()))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if proof != "" {
+			r.Header.Set(DPoPHeader, proof)
+		}
+		return r
+	}
+
+	t.Run("case=should bind the session to the key of the proof", func(t *testing.T) {
+		proof := newDPoPProof(t, key, "dpop+jwt", dpopProof{JTI: randomJTI(t), HTTPMethod: "POST", HTTPURI: tokenURL, IssuedAt: time.Now().Unix()})
+		ar, err := f.NewAccessRequest(ctx, newRequest(proof), new(DefaultSession))
+		require.NoError(t, err)
+		assert.Equal(t, dpopThumbprint(t, key), GetDPoPJWKThumbprint(ar.GetSession()))
+		assert.Equal(t, DPoPAccessToken, GetAccessTokenType(ar.GetSession()))
+	})
+
+	t.Run("case=should not bind the session without a proof", func(t *testing.T) {
+		ar, err := f.NewAccessRequest(ctx, newRequest(""), new(DefaultSession))
+		require.NoError(t, err)
+		assert.Empty(t, GetDPoPJWKThumbprint(ar.GetSession()))
+		assert.Equal(t, BearerAccessToken, GetAccessTokenType(ar.GetSession()))
+	})
+
+	t.Run("case=should fail with a proof for another endpoint", func(t *testing.T) {
+		proof := newDPoPProof(t, key, "dpop+jwt", dpopProof{JTI: randomJTI(t), HTTPMethod: "POST", HTTPURI: "https://auth.example.com/other", IssuedAt: time.Now().Unix()})
+		_, err := f.NewAccessRequest(ctx, newRequest(proof), new(DefaultSession))
+		assert.ErrorIs(t, err, ErrInvalidDPoPProof)
+	})
+}
+
+func TestAuthorizeCodeDPoPBinding(t *testing.T) {
+	ctx := context.Background()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	const tokenURL = "https://auth.example.com/oauth2/token"
+	const redirectURI = "http://localhost:3846/callback"
+	config := &Config{TokenURL: tokenURL, GlobalSecret: []byte("some-secret-thats-random-some-secret-thats-random-")}
+	f := compose.ComposeAllEnabled(config, storage.NewExampleStore(), gen.MustRSAKey())
+
+	authorize := func(t *testing.T, authorizeURL string) string {
+		r, err := http.NewRequest("GET", authorizeURL, nil)
+		require.NoError(t, err)
+		ar, err := f.NewAuthorizeRequest(ctx, r)
+		require.NoError(t, err)
+		ar.GrantScope("fosite")
+		resp, err := f.NewAuthorizeResponse(ctx, ar, new(DefaultSession))
+		require.NoError(t, err)
+		return resp.GetCode()
+	}
+
+	exchange := func(t *testing.T, code string, proofKey *ecdsa.PrivateKey) (AccessRequester, error) {
+		form := url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {redirectURI}}
+		r, err := http.NewRequest("POST", tokenURL, strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.SetBasicAuth("my-client", "foobar")
+		r.Header.Set(DPoPHeader, newDPoPProof(t, proofKey, "dpop+jwt", dpopProof{JTI: randomJTI(t), HTTPMethod: "POST", HTTPURI: tokenURL, IssuedAt: time.Now().Unix()}))
+		return f.NewAccessRequest(ctx, r, new(DefaultSession))
+	}
+
+	t.Run("case=should enforce the dpop_jkt binding of the authorization code", func(t *testing.T) {
+		code := authorize(t, "https://auth.example.com/oauth2/auth?"+url.Values{
+			"client_id":     {"my-client"},
+			"response_type": {"code"},
+			"redirect_uri":  {redirectURI},
+			"scope":         {"fosite"},
+			"state":         {"12345678901234567890"},
+			"dpop_jkt":      {dpopThumbprint(t, key)},
+		}.Encode())
+
+		_, err := exchange(t, code, otherKey)
+		assert.ErrorIs(t, err, ErrInvalidGrant)
+
+		ar, err := exchange(t, code, key)
+		require.NoError(t, err)
+		assert.Equal(t, dpopThumbprint(t, key), GetDPoPJWKThumbprint(ar.GetSession()))
+	})
+
+	t.Run("case=should allow any key for an unbound authorization code", func(t *testing.T) {
+		code := authorize(t, "https://auth.example.com/oauth2/auth?"+url.Values{
+			"client_id":     {"my-client"},
+			"response_type": {"code"},
+			"redirect_uri":  {redirectURI},
+			"scope":         {"fosite"},
+			"state":         {"12345678901234567890"},
+		}.Encode())
+
+		ar, err := exchange(t, code, otherKey)
+		require.NoError(t, err)
+		assert.Equal(t, dpopThumbprint(t, otherKey), GetDPoPJWKThumbprint(ar.GetSession()))
+	})
+}
+
+func randomJTI(t *testing.T) string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
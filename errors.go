@@ -23,6 +23,12 @@ var (
 	// ErrInvalidatedAuthorizeCode is an error indicating that an authorization code has been
 	// used previously.
 	ErrInvalidatedAuthorizeCode = errors.New("Authorization code has ben invalidated")
+	// ErrInvalidatedDeviceCode is an error indicating that a device code has been
+	// used previously.
+	ErrInvalidatedDeviceCode = errors.New("Device code has been invalidated")
+	// ErrInvalidatedAuthReqID is an error indicating that an auth_req_id has been
+	// used previously.
+	ErrInvalidatedAuthReqID = errors.New("Backchannel authentication request ID has been invalidated")
 	// ErrSerializationFailure is an error indicating that the transactional capable storage could not guarantee
 	// consistency of Update & Delete operations on the same rows between multiple sessions.
 	ErrSerializationFailure = errors.New("The request could not be completed due to concurrent access")
@@ -202,6 +208,91 @@ var (
 		ErrorField:       errJTIKnownName,
 		CodeField:        http.StatusBadRequest,
 	}
+	ErrAuthorizationPending = &RFC6749Error{
+		DescriptionField: "The authorization request is still pending as the end user hasn't yet completed the user-interaction steps.",
+		ErrorField:       errAuthorizationPendingName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrSlowDown = &RFC6749Error{
+		DescriptionField: "The authorization request is still pending and polling should continue, but the interval MUST be increased by 5 seconds for this and all subsequent requests.",
+		ErrorField:       errSlowDownName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrDeviceExpiredToken = &RFC6749Error{
+		DescriptionField: "The device_code has expired, and the device authorization session has concluded.",
+		ErrorField:       errDeviceExpiredTokenName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrExpiredAuthReqID = &RFC6749Error{
+		DescriptionField: "The auth_req_id has expired, and the backchannel authentication session has concluded.",
+		ErrorField:       errDeviceExpiredTokenName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInvalidBindingMessage = &RFC6749Error{
+		DescriptionField: "The binding message is invalid or unacceptable for use in the context of the given request.",
+		ErrorField:       errInvalidBindingMessageName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrUnknownUserID = &RFC6749Error{
+		DescriptionField: "The OpenID Provider is not able to identify which end-user the Client wishes to be authenticated by means of the hint provided in the request.",
+		ErrorField:       errUnknownUserIDName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrExpiredLoginHintToken = &RFC6749Error{
+		DescriptionField: "The login_hint_token provided in the authentication request is not valid because it has expired.",
+		ErrorField:       errExpiredLoginHintTokenName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInvalidDPoPProof = &RFC6749Error{
+		DescriptionField: "The DPoP proof is invalid.",
+		ErrorField:       errInvalidDPoPProofName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInvalidAuthorizationDetails = &RFC6749Error{
+		DescriptionField: "The authorization details are invalid, unknown, or malformed.",
+		ErrorField:       errInvalidAuthorizationDetailsName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInvalidTarget = &RFC6749Error{
+		DescriptionField: "The requested resource is invalid, missing, unknown, or malformed.",
+		ErrorField:       errInvalidTargetName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInvalidRedirectURI = &RFC6749Error{
+		DescriptionField: "The value of one or more redirection URIs is invalid.",
+		ErrorField:       errInvalidRedirectURIName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInvalidClientMetadata = &RFC6749Error{
+		DescriptionField: "The value of one of the client metadata fields is invalid.",
+		ErrorField:       errInvalidClientMetadataName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInvalidSoftwareStatement = &RFC6749Error{
+		DescriptionField: "The software statement presented is invalid.",
+		ErrorField:       errInvalidSoftwareStatementName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrUnapprovedSoftwareStatement = &RFC6749Error{
+		DescriptionField: "The software statement presented is not approved for use by this authorization server.",
+		ErrorField:       errUnapprovedSoftwareStatementName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInsufficientScope = &RFC6749Error{
+		DescriptionField: "The request requires higher privileges than provided by the access token.",
+		ErrorField:       errInsufficientScopeName,
+		CodeField:        http.StatusForbidden,
+	}
+	ErrUnmetAuthenticationRequirements = &RFC6749Error{
+		DescriptionField: "The Authorization Server is unable to meet the requirements of the Relying Party for the authentication of the End-User.",
+		ErrorField:       errUnmetAuthenticationRequirementsName,
+		CodeField:        http.StatusBadRequest,
+	}
+	ErrInsufficientUserAuthentication = &RFC6749Error{
+		DescriptionField: "The authentication event associated with the access token presented with the request does not meet the authentication requirements of the protected resource.",
+		ErrorField:       errInsufficientUserAuthenticationName,
+		CodeField:        http.StatusUnauthorized,
+	}
 )
 
 const (
@@ -234,11 +325,30 @@ const (
 	errTokenClaimName              = "token_claim"
 	errTokenInactiveName           = "token_inactive"
 	// errAuthorizationCodeInactiveName = "authorization_code_inactive"
-	errUnknownErrorName             = "error"
-	errRequestNotSupportedName      = "request_not_supported"
-	errRequestURINotSupportedName   = "request_uri_not_supported"
-	errRegistrationNotSupportedName = "registration_not_supported"
-	errJTIKnownName                 = "jti_known"
+	errUnknownErrorName                = "error"
+	errRequestNotSupportedName         = "request_not_supported"
+	errRequestURINotSupportedName      = "request_uri_not_supported"
+	errRegistrationNotSupportedName    = "registration_not_supported"
+	errJTIKnownName                    = "jti_known"
+	errAuthorizationPendingName        = "authorization_pending"
+	errSlowDownName                    = "slow_down"
+	errDeviceExpiredTokenName          = "expired_token"
+	errInvalidBindingMessageName       = "invalid_binding_message"
+	errUnknownUserIDName               = "unknown_user_id"
+	errExpiredLoginHintTokenName       = "expired_login_hint_token"
+	errInvalidDPoPProofName            = "invalid_dpop_proof"
+	errInvalidAuthorizationDetailsName = "invalid_authorization_details"
+	errInvalidTargetName               = "invalid_target"
+	errInvalidRedirectURIName          = "invalid_redirect_uri"
+	errInvalidClientMetadataName       = "invalid_client_metadata"
+	errInvalidSoftwareStatementName    = "invalid_software_statement"
+	errUnapprovedSoftwareStatementName = "unapproved_software_statement"
+	errInsufficientScopeName           = "insufficient_scope" // https://tools.ietf.org/html/rfc6750#section-3.1
+
+	// https://openid.net/specs/openid-connect-unmet-authentication-requirements-1_0.html
+	errUnmetAuthenticationRequirementsName = "unmet_authentication_requirements"
+	// https://www.rfc-editor.org/rfc/rfc9470#section-3
+	errInsufficientUserAuthenticationName = "insufficient_user_authentication"
 )
 
 type (
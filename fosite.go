@@ -82,6 +82,34 @@ func (a *PushedAuthorizeEndpointHandlers) Append(h PushedAuthorizeEndpointHandle
 	*a = append(*a, h)
 }
 
+// DeviceEndpointHandlers is a list of DeviceEndpointHandler
+type DeviceEndpointHandlers []DeviceEndpointHandler
+
+// Append adds a DeviceEndpointHandler to this list. Ignores duplicates based on reflect.TypeOf.
+func (a *DeviceEndpointHandlers) Append(h DeviceEndpointHandler) {
+	for _, this := range *a {
+		if reflect.TypeOf(this) == reflect.TypeOf(h) {
+			return
+		}
+	}
+
+	*a = append(*a, h)
+}
+
+// BackchannelAuthenticationEndpointHandlers is a list of BackchannelAuthenticationEndpointHandler
+type BackchannelAuthenticationEndpointHandlers []BackchannelAuthenticationEndpointHandler
+
+// Append adds a BackchannelAuthenticationEndpointHandler to this list. Ignores duplicates based on reflect.TypeOf.
+func (a *BackchannelAuthenticationEndpointHandlers) Append(h BackchannelAuthenticationEndpointHandler) {
+	for _, this := range *a {
+		if reflect.TypeOf(this) == reflect.TypeOf(h) {
+			return
+		}
+	}
+
+	*a = append(*a, h)
+}
+
 var _ OAuth2Provider = (*Fosite)(nil)
 
 type Configurator interface {
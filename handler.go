@@ -66,3 +66,27 @@ type PushedAuthorizeEndpointHandler interface {
 	// the pushed authorize request, he must return nil and NOT modify session nor responder neither requester.
 	HandlePushedAuthorizeEndpointRequest(ctx context.Context, requester AuthorizeRequester, responder PushedAuthorizeResponder) error
 }
+
+// DeviceEndpointHandler is the interface that handles the device authorization endpoint (https://www.rfc-editor.org/rfc/rfc8628#section-3.1)
+type DeviceEndpointHandler interface {
+	// HandleDeviceEndpointRequest handles a device authorization endpoint request. If the handler feels that he is not
+	// responsible for the device request, he must return nil and NOT modify session nor responder neither requester.
+	HandleDeviceEndpointRequest(ctx context.Context, requester DeviceRequester, responder DeviceResponder) error
+}
+
+// BackchannelAuthenticationEndpointHandler is the interface that handles the backchannel authentication endpoint
+// (https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#auth_request)
+type BackchannelAuthenticationEndpointHandler interface {
+	// HandleBackchannelAuthenticationEndpointRequest handles a backchannel authentication endpoint request. If the
+	// handler feels that he is not responsible for the request, he must return nil and NOT modify session nor
+	// responder neither requester.
+	HandleBackchannelAuthenticationEndpointRequest(ctx context.Context, requester BackchannelAuthenticationRequester, responder BackchannelAuthenticationResponder) error
+}
+
+// AuthorizationServerMetadataHandler is implemented by handlers which contribute the capabilities they implement,
+// for example the grant types or response types they support, to the authorization server metadata. Handlers can be
+// registered for several endpoints, which is why PopulateAuthorizationServerMetadata must be idempotent.
+type AuthorizationServerMetadataHandler interface {
+	// PopulateAuthorizationServerMetadata adds the capabilities of the handler to the metadata.
+	PopulateAuthorizationServerMetadata(ctx context.Context, metadata *AuthorizationServerMetadata)
+}
@@ -0,0 +1,76 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciba
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+)
+
+// BackchannelAuthenticationHandler handles the backchannel authentication endpoint as specified in
+// https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#auth_request
+type BackchannelAuthenticationHandler struct {
+	Strategy AuthReqIDStrategy
+	Storage  BackchannelAuthenticationStorage
+	Config   interface {
+		fosite.BackchannelAuthenticationProvider
+	}
+}
+
+var _ fosite.BackchannelAuthenticationEndpointHandler = (*BackchannelAuthenticationHandler)(nil)
+
+// HandleBackchannelAuthenticationEndpointRequest issues the auth_req_id and stores the backchannel authentication
+// request.
+func (c *BackchannelAuthenticationHandler) HandleBackchannelAuthenticationEndpointRequest(ctx context.Context, br fosite.BackchannelAuthenticationRequester, resp fosite.BackchannelAuthenticationResponder) error {
+	if br.GetSession() == nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithDebug("The backchannel authentication request session must not be nil."))
+	}
+
+	id, signature, err := c.Strategy.GenerateAuthReqID(ctx)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	// The requested expiry may shorten, but never extend, the configured lifespan.
+	lifespan := c.Config.GetBackchannelAuthenticationRequestLifespan(ctx)
+	if requested := br.GetRequestedExpiry(); requested > 0 && requested < lifespan {
+		lifespan = requested
+	}
+	expiresAt := time.Now().UTC().Add(lifespan).Round(time.Second)
+	br.GetSession().SetExpiresAt(fosite.AuthReqID, expiresAt)
+	br.SetAuthReqIDSignature(signature)
+	br.SetStatus(fosite.BackchannelAuthenticationRequestStatusPending)
+
+	if err := c.Storage.CreateBackchannelAuthenticationSession(ctx, signature, sanitize(br)); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithHint("Unable to store the backchannel authentication session.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	resp.SetAuthReqID(id)
+	resp.SetExpiresIn(int64(time.Until(expiresAt).Round(time.Second).Seconds()))
+	if deliveryMode(br.GetClient()) == fosite.BackchannelTokenDeliveryModePoll {
+		resp.SetInterval(int(c.Config.GetBackchannelAuthenticationPollingInterval(ctx).Seconds()))
+	}
+
+	return nil
+}
+
+// sanitize removes all non-default form parameters from the request before it is stored.
+func sanitize(br fosite.BackchannelAuthenticationRequester) fosite.BackchannelAuthenticationRequester {
+	if sanitized, ok := br.Sanitize(nil).(fosite.BackchannelAuthenticationRequester); ok {
+		return sanitized
+	}
+	return br
+}
+
+// deliveryMode returns the token delivery mode of the client, which defaults to poll.
+func deliveryMode(client fosite.Client) string {
+	if c, ok := client.(fosite.BackchannelAuthenticationClient); ok && c.GetBackchannelTokenDeliveryMode() != "" {
+		return c.GetBackchannelTokenDeliveryMode()
+	}
+	return fosite.BackchannelTokenDeliveryModePoll
+}
@@ -0,0 +1,120 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciba
+
+import (
+	"context"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite"
+)
+
+// BackchannelAuthenticationUserHandler is used by the application after it authenticated the end user on the
+// authentication device to look up the backchannel authentication request by its auth_req_id and to record the end
+// user's decision. Clients using the ping token delivery mode are notified through the Notifier.
+type BackchannelAuthenticationUserHandler struct {
+	Strategy AuthReqIDStrategy
+	Storage  BackchannelAuthenticationStorage
+	Notifier BackchannelAuthenticationNotifier
+}
+
+// GetBackchannelAuthenticationRequest returns the pending backchannel authentication request which belongs to the
+// auth_req_id.
+func (c *BackchannelAuthenticationUserHandler) GetBackchannelAuthenticationRequest(ctx context.Context, authReqID string, session fosite.Session) (fosite.BackchannelAuthenticationRequester, error) {
+	br, err := c.getRequest(ctx, authReqID, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if br.GetStatus() != fosite.BackchannelAuthenticationRequestStatusPending {
+		return nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The backchannel authentication request has already been completed."))
+	}
+
+	return br, nil
+}
+
+// ApproveBackchannelAuthenticationRequest marks the backchannel authentication request as approved. The granted
+// scopes, granted audience and the session of the given request are stored and used when the client redeems the
+// auth_req_id.
+//
+// Clients using the ping token delivery mode are notified after the decision was stored. If the notification fails,
+// an error is returned but the decision remains stored. Calling this method again for the same request then only
+// retries the notification.
+func (c *BackchannelAuthenticationUserHandler) ApproveBackchannelAuthenticationRequest(ctx context.Context, authReqID string, br fosite.BackchannelAuthenticationRequester) error {
+	return c.finish(ctx, authReqID, br, fosite.BackchannelAuthenticationRequestStatusApproved)
+}
+
+// DenyBackchannelAuthenticationRequest marks the backchannel authentication request as denied. The client receives
+// the "access_denied" error when it calls the token endpoint.
+//
+// Notifications are sent and retried as described for ApproveBackchannelAuthenticationRequest.
+func (c *BackchannelAuthenticationUserHandler) DenyBackchannelAuthenticationRequest(ctx context.Context, authReqID string, br fosite.BackchannelAuthenticationRequester) error {
+	return c.finish(ctx, authReqID, br, fosite.BackchannelAuthenticationRequestStatusDenied)
+}
+
+// getRequest returns the backchannel authentication request which belongs to the auth_req_id, regardless of its
+// status.
+func (c *BackchannelAuthenticationUserHandler) getRequest(ctx context.Context, authReqID string, session fosite.Session) (fosite.BackchannelAuthenticationRequester, error) {
+	signature, err := c.Strategy.AuthReqIDSignature(ctx, authReqID)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	br, err := c.Storage.GetBackchannelAuthenticationSession(ctx, signature, session)
+	if errors.Is(err, fosite.ErrNotFound) || errors.Is(err, fosite.ErrInvalidatedAuthReqID) {
+		return nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The auth_req_id is unknown or has already been used.").WithWrap(err).WithDebug(err.Error()))
+	} else if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if err := c.Strategy.ValidateAuthReqID(ctx, br, authReqID); err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return br, nil
+}
+
+// finish stores the decision of the end user and notifies clients using the ping token delivery mode. A request
+// which was already completed with the same decision is not stored again, so that a failed notification can be
+// retried.
+func (c *BackchannelAuthenticationUserHandler) finish(ctx context.Context, authReqID string, br fosite.BackchannelAuthenticationRequester, status fosite.BackchannelAuthenticationRequestStatus) error {
+	stored, err := c.getRequest(ctx, authReqID, br.GetSession())
+	if err != nil {
+		return err
+	}
+
+	if stored.GetID() != br.GetID() {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The auth_req_id does not belong to this backchannel authentication request."))
+	}
+
+	client, ok := stored.GetClient().(fosite.BackchannelAuthenticationClient)
+	ping := ok && deliveryMode(client) == fosite.BackchannelTokenDeliveryModePing
+	if ping && c.Notifier == nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithDebug("The client uses the ping token delivery mode but no notifier is configured."))
+	}
+
+	switch stored.GetStatus() {
+	case fosite.BackchannelAuthenticationRequestStatusPending:
+		br.SetStatus(status)
+		if err := c.Storage.UpdateBackchannelAuthenticationSession(ctx, stored.GetAuthReqIDSignature(), br); err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+	case status:
+		// The decision was already stored, only the notification is retried.
+	default:
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The backchannel authentication request has already been completed."))
+	}
+
+	if !ping {
+		return nil
+	}
+
+	if err := c.Notifier.NotifyBackchannelAuthenticationResult(ctx, client, stored.GetClientNotificationToken(), authReqID); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithHint("Unable to notify the OAuth 2.0 Client.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return nil
+}
@@ -0,0 +1,291 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciba
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/storage"
+)
+
+// CIBATokenHandler handles the token request of the Client-Initiated Backchannel Authentication grant in the poll
+// and ping token delivery modes as specified in
+// https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#token_request
+type CIBATokenHandler struct {
+	AuthReqIDStrategy      AuthReqIDStrategy
+	AccessTokenStrategy    oauth2.AccessTokenStrategy
+	RefreshTokenStrategy   oauth2.RefreshTokenStrategy
+	CoreStorage            CIBACoreStorage
+	TokenRevocationStorage oauth2.TokenRevocationStorage
+	Config                 interface {
+		fosite.AccessTokenLifespanProvider
+		fosite.RefreshTokenLifespanProvider
+		fosite.RefreshTokenScopesProvider
+		fosite.IDTokenLifespanProvider
+		fosite.BackchannelAuthenticationProvider
+	}
+
+	*openid.IDTokenHandleHelper
+}
+
+var _ fosite.TokenEndpointHandler = (*CIBATokenHandler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*CIBATokenHandler)(nil)
+
+// HandleTokenEndpointRequest implements
+// * https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#token_request
+// * https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#token_error_response
+func (c *CIBATokenHandler) HandleTokenEndpointRequest(ctx context.Context, request fosite.AccessRequester) error {
+	if !c.CanHandleTokenEndpointRequest(ctx, request) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	if !request.GetClient().GetGrantTypes().Has(string(fosite.GrantTypeCIBA)) {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant \"%s\".", fosite.GrantTypeCIBA))
+	}
+
+	id := request.GetRequestForm().Get("auth_req_id")
+	if id == "" {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The \"auth_req_id\" parameter is missing."))
+	}
+
+	signature, err := c.AuthReqIDStrategy.AuthReqIDSignature(ctx, id)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	br, err := c.CoreStorage.GetBackchannelAuthenticationSession(ctx, signature, request.GetSession())
+	if errors.Is(err, fosite.ErrInvalidatedAuthReqID) {
+		if br == nil {
+			return fosite.ErrServerError.
+				WithHint("Misconfigured code lead to an error that prohibited the OAuth 2.0 Framework from processing this request.").
+				WithDebug("GetBackchannelAuthenticationSession must return a value for \"fosite.BackchannelAuthenticationRequester\" when returning \"ErrInvalidatedAuthReqID\".")
+		}
+
+		// If an auth_req_id is used twice, we revoke all refresh and access tokens associated with this request.
+		reqID := br.GetID()
+		hint := "The auth_req_id has already been used."
+		debug := ""
+		if revErr := c.TokenRevocationStorage.RevokeAccessToken(ctx, reqID); revErr != nil {
+			hint += " Additionally, an error occurred during processing the access token revocation."
+			debug += "Revocation of access_token lead to error " + revErr.Error() + "."
+		}
+		if revErr := c.TokenRevocationStorage.RevokeRefreshToken(ctx, reqID); revErr != nil {
+			hint += " Additionally, an error occurred during processing the refresh token revocation."
+			debug += "Revocation of refresh_token lead to error " + revErr.Error() + "."
+		}
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint(hint).WithDebug(debug))
+	} else if err != nil && errors.Is(err, fosite.ErrNotFound) {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithWrap(err).WithDebug(err.Error()))
+	} else if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	// The auth_req_id must have been issued to the authenticated client.
+	if br.GetClient().GetID() != request.GetClient().GetID() {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client ID from this request does not match the one from the backchannel authentication request."))
+	}
+
+	if err := c.AuthReqIDStrategy.ValidateAuthReqID(ctx, br, id); errors.Is(err, fosite.ErrExpiredAuthReqID) {
+		return err
+	} else if err != nil {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	switch br.GetStatus() {
+	case fosite.BackchannelAuthenticationRequestStatusApproved:
+	case fosite.BackchannelAuthenticationRequestStatusDenied:
+		return errorsx.WithStack(fosite.ErrAccessDenied.WithHint("The end user denied the backchannel authentication request."))
+	default:
+		return c.handlePendingRequest(ctx, signature, br)
+	}
+
+	request.SetRequestedScopes(br.GetRequestedScopes())
+	request.SetRequestedAudience(br.GetRequestedAudience())
+	jkt := fosite.GetDPoPJWKThumbprint(request.GetSession())
+	request.SetSession(br.GetSession())
+	request.SetID(br.GetID())
+	if s, ok := request.GetSession().(fosite.DPoPSession); ok {
+		s.SetDPoPJWKThumbprint(jkt)
+	}
+
+	atLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeCIBA, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
+	request.GetSession().SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(atLifespan).Round(time.Second))
+
+	rtLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeCIBA, fosite.RefreshToken, c.Config.GetRefreshTokenLifespan(ctx))
+	if rtLifespan > -1 {
+		request.GetSession().SetExpiresAt(fosite.RefreshToken, time.Now().UTC().Add(rtLifespan).Round(time.Second))
+	}
+
+	return nil
+}
+
+// handlePendingRequest records the poll and tells the client to either keep polling or, in the poll mode, to slow
+// down.
+func (c *CIBATokenHandler) handlePendingRequest(ctx context.Context, signature string, br fosite.BackchannelAuthenticationRequester) error {
+	// The polling interval only applies to the poll mode: https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#rfc.section.11
+	var interval time.Duration
+	if deliveryMode(br.GetClient()) == fosite.BackchannelTokenDeliveryModePoll {
+		interval = c.Config.GetBackchannelAuthenticationPollingInterval(ctx)
+	}
+
+	pollErr := fosite.CheckPollingInterval(br, interval)
+	if err := c.CoreStorage.UpdateBackchannelAuthenticationPollingState(ctx, signature, br.GetLastPolledAt(), br.GetPollingInterval()); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return pollErr
+}
+
+func (c *CIBATokenHandler) canIssueRefreshToken(ctx context.Context, request fosite.Requester) bool {
+	scope := c.Config.GetRefreshTokenScopes(ctx)
+	// Require one of the refresh token scopes, if set.
+	if len(scope) > 0 && !request.GetGrantedScopes().HasOneOf(scope...) {
+		return false
+	}
+	// Do not issue a refresh token to clients that cannot use the refresh token grant type.
+	if !request.GetClient().GetGrantTypes().Has("refresh_token") {
+		return false
+	}
+	return true
+}
+
+func (c *CIBATokenHandler) PopulateTokenEndpointResponse(ctx context.Context, requester fosite.AccessRequester, responder fosite.AccessResponder) (err error) {
+	if !c.CanHandleTokenEndpointRequest(ctx, requester) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	id := requester.GetRequestForm().Get("auth_req_id")
+	signature, err := c.AuthReqIDStrategy.AuthReqIDSignature(ctx, id)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	br, err := c.CoreStorage.GetBackchannelAuthenticationSession(ctx, signature, requester.GetSession())
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if err := c.AuthReqIDStrategy.ValidateAuthReqID(ctx, br, id); err != nil {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithWrap(err).WithDebug(err.Error()))
+	} else if br.GetStatus() != fosite.BackchannelAuthenticationRequestStatusApproved {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The backchannel authentication request has not been approved."))
+	}
+
+	for _, scope := range br.GetGrantedScopes() {
+		requester.GrantScope(scope)
+	}
+
+	if err := oauth2.GrantAudience(requester, br); err != nil {
+		return err
+	}
+
+	if err := oauth2.GrantAuthorizationDetails(requester, br); err != nil {
+		return err
+	}
+
+	access, accessSignature, err := c.AccessTokenStrategy.GenerateAccessToken(ctx, requester)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	var refresh, refreshSignature string
+	if c.canIssueRefreshToken(ctx, br) {
+		refresh, refreshSignature, err = c.RefreshTokenStrategy.GenerateRefreshToken(ctx, requester)
+		if err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	ctx, err = storage.MaybeBeginTx(ctx, c.CoreStorage)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	defer func() {
+		if err != nil {
+			if rollBackTxnErr := storage.MaybeRollbackTx(ctx, c.CoreStorage); rollBackTxnErr != nil {
+				err = errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebugf("error: %s; rollback error: %s", err, rollBackTxnErr))
+			}
+		}
+	}()
+
+	if err = c.CoreStorage.InvalidateBackchannelAuthenticationSession(ctx, signature); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if err = c.CoreStorage.CreateAccessTokenSession(ctx, accessSignature, requester.Sanitize([]string{})); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if refreshSignature != "" {
+		if err = c.CoreStorage.CreateRefreshTokenSession(ctx, refreshSignature, oauth2.SanitizeRefreshTokenRequest(requester, br)); err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	responder.SetAccessToken(access)
+	responder.SetTokenType(fosite.GetAccessTokenType(requester.GetSession()))
+	atLifespan := fosite.GetEffectiveLifespan(requester.GetClient(), fosite.GrantTypeCIBA, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
+	responder.SetExpiresIn(getExpiresIn(requester, fosite.AccessToken, atLifespan, time.Now().UTC()))
+	responder.SetScopes(requester.GetGrantedScopes())
+	if refresh != "" {
+		responder.SetExtra("refresh_token", refresh)
+	}
+
+	if requester.GetGrantedScopes().Has("openid") {
+		if err = c.issueIDToken(ctx, requester, responder); err != nil {
+			return err
+		}
+	}
+
+	if err = storage.MaybeCommitTx(ctx, c.CoreStorage); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return nil
+}
+
+// issueIDToken adds the ID token to the token response, which is required for the openid scope.
+func (c *CIBATokenHandler) issueIDToken(ctx context.Context, requester fosite.AccessRequester, responder fosite.AccessResponder) error {
+	if c.IDTokenHandleHelper == nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithDebug("The CIBA token handler can not issue ID tokens because no ID token strategy is configured."))
+	}
+
+	session, ok := requester.GetSession().(openid.Session)
+	if !ok {
+		return errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to generate id token because session must be of type fosite/handler/openid.Session."))
+	}
+
+	claims := session.IDTokenClaims()
+	if claims.Subject == "" {
+		return errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to generate id token because subject is an empty string."))
+	}
+	claims.AccessTokenHash = c.GetAccessTokenHash(ctx, requester, responder)
+
+	idTokenLifespan := fosite.GetEffectiveLifespan(requester.GetClient(), fosite.GrantTypeCIBA, fosite.IDToken, c.Config.GetIDTokenLifespan(ctx))
+	return c.IssueExplicitIDToken(ctx, idTokenLifespan, requester, responder)
+}
+
+func (c *CIBATokenHandler) CanSkipClientAuth(ctx context.Context, requester fosite.AccessRequester) bool {
+	return false
+}
+
+func (c *CIBATokenHandler) CanHandleTokenEndpointRequest(ctx context.Context, requester fosite.AccessRequester) bool {
+	// grant_type REQUIRED.
+	// Value MUST be set to "urn:openid:params:grant-type:ciba"
+	return requester.GetGrantTypes().ExactOne(string(fosite.GrantTypeCIBA))
+}
+
+func getExpiresIn(r fosite.Requester, key fosite.TokenType, defaultLifespan time.Duration, now time.Time) time.Duration {
+	if r.GetSession().GetExpiresAt(key).IsZero() {
+		return defaultLifespan
+	}
+	return time.Duration(r.GetSession().GetExpiresAt(key).UnixNano() - now.UnixNano())
+}
+
+// PopulateAuthorizationServerMetadata adds the CIBA grant and the supported token delivery modes.
+func (c *CIBATokenHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddGrantTypes(string(fosite.GrantTypeCIBA))
+	metadata.BackchannelTokenDeliveryModesSupported = []string{fosite.BackchannelTokenDeliveryModePoll, fosite.BackchannelTokenDeliveryModePing}
+}
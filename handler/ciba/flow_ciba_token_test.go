@@ -0,0 +1,315 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciba_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	. "github.com/ory/fosite/handler/ciba"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/hmac"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestCIBATokenHandler(t *testing.T) {
+	ctx := context.Background()
+	key := gen.MustRSAKey()
+	config := &fosite.Config{
+		GlobalSecret:                             []byte("foobarfoobarfoobarfoobarfoobarfoobarfoobarfoobar"),
+		AccessTokenLifespan:                      time.Hour,
+		RefreshTokenLifespan:                     24 * time.Hour,
+		IDTokenIssuer:                            "https://auth.example.com",
+		BackchannelAuthenticationRequestLifespan: 10 * time.Minute,
+		BackchannelAuthenticationPollingInterval: time.Hour,
+	}
+	coreStrategy := &oauth2.HMACSHAStrategy{
+		Enigma: &hmac.HMACStrategy{Config: config},
+		Config: config,
+	}
+	idTokenStrategy := &openid.DefaultStrategy{
+		Signer: &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return key, nil }},
+		Config: config,
+	}
+	authReqIDStrategy := &DefaultAuthReqIDStrategy{
+		Enigma: &hmac.HMACStrategy{Config: config},
+		Config: config,
+	}
+	newClient := func(mode, notificationEndpoint string) *fosite.DefaultOpenIDConnectClient {
+		return &fosite.DefaultOpenIDConnectClient{
+			DefaultClient: &fosite.DefaultClient{
+				ID:         "ciba-client",
+				GrantTypes: []string{string(fosite.GrantTypeCIBA), "refresh_token"},
+				Scopes:     []string{"openid", "offline"},
+			},
+			BackchannelTokenDeliveryMode:          mode,
+			BackchannelClientNotificationEndpoint: notificationEndpoint,
+		}
+	}
+	client := newClient("poll", "")
+
+	setup := func(t *testing.T, c fosite.Client, notifier BackchannelAuthenticationNotifier) (*storage.MemoryStore, *CIBATokenHandler, *BackchannelAuthenticationUserHandler, fosite.BackchannelAuthenticationResponder) {
+		store := storage.NewMemoryStore()
+		authHandler := &BackchannelAuthenticationHandler{Strategy: authReqIDStrategy, Storage: store, Config: config}
+		tokenHandler := &CIBATokenHandler{
+			AuthReqIDStrategy:      authReqIDStrategy,
+			AccessTokenStrategy:    coreStrategy,
+			RefreshTokenStrategy:   coreStrategy,
+			CoreStorage:            store,
+			TokenRevocationStorage: store,
+			Config:                 config,
+			IDTokenHandleHelper:    &openid.IDTokenHandleHelper{IDTokenStrategy: idTokenStrategy},
+		}
+		userHandler := &BackchannelAuthenticationUserHandler{Strategy: authReqIDStrategy, Storage: store, Notifier: notifier}
+
+		br := fosite.NewBackchannelAuthenticationRequest()
+		br.Client = c
+		br.LoginHint = "peter"
+		br.ClientNotificationToken = "notification-token"
+		br.RequestedExpiry = 2 * time.Minute
+		br.SetSession(openid.NewDefaultSession())
+		br.SetRequestedScopes(fosite.Arguments{"openid", "offline"})
+		resp := fosite.NewBackchannelAuthenticationResponse()
+		require.NoError(t, authHandler.HandleBackchannelAuthenticationEndpointRequest(ctx, br, resp))
+
+		return store, tokenHandler, userHandler, resp
+	}
+
+	newAccessRequest := func(authReqID string, c fosite.Client) *fosite.AccessRequest {
+		ar := fosite.NewAccessRequest(openid.NewDefaultSession())
+		ar.GrantTypes = fosite.Arguments{string(fosite.GrantTypeCIBA)}
+		ar.Client = c
+		ar.Form = url.Values{"auth_req_id": {authReqID}}
+		return ar
+	}
+
+	approve := func(t *testing.T, u *BackchannelAuthenticationUserHandler, authReqID string) fosite.BackchannelAuthenticationRequester {
+		br, err := u.GetBackchannelAuthenticationRequest(ctx, authReqID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "peter", br.GetLoginHint())
+
+		br.GrantScope("openid")
+		br.GrantScope("offline")
+		session := br.GetSession().(*openid.DefaultSession)
+		session.Subject = "peter"
+		session.Claims.Subject = "peter"
+		require.NoError(t, u.ApproveBackchannelAuthenticationRequest(ctx, authReqID, br))
+		return br
+	}
+
+	t.Run("case=should issue an auth_req_id limited by the requested expiry", func(t *testing.T) {
+		_, _, _, resp := setup(t, client, nil)
+		assert.NotEmpty(t, resp.GetAuthReqID())
+		assert.InDelta(t, 120, resp.GetExpiresIn(), 1)
+		assert.Equal(t, 3600, resp.GetInterval())
+	})
+
+	t.Run("case=should not handle other grant types", func(t *testing.T) {
+		_, h, _, _ := setup(t, client, nil)
+		ar := fosite.NewAccessRequest(new(fosite.DefaultSession))
+		ar.GrantTypes = fosite.Arguments{"authorization_code"}
+		assert.False(t, h.CanHandleTokenEndpointRequest(ctx, ar))
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnknownRequest)
+	})
+
+	t.Run("case=should fail because the auth_req_id is missing, unknown or belongs to another client", func(t *testing.T) {
+		_, h, _, resp := setup(t, client, nil)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest("", client)), fosite.ErrInvalidRequest)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest("ory_ar_foo.bar", client)), fosite.ErrInvalidGrant)
+
+		other := newClient("poll", "")
+		other.ID = "other-client"
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), other)), fosite.ErrInvalidGrant)
+	})
+
+	t.Run("case=should ask to keep polling and then to slow down", func(t *testing.T) {
+		store, h, _, resp := setup(t, client, nil)
+		signature, err := authReqIDStrategy.AuthReqIDSignature(ctx, resp.GetAuthReqID())
+		require.NoError(t, err)
+
+		getBackchannelAuthenticationSession := func(t *testing.T) fosite.BackchannelAuthenticationRequester {
+			stored, err := store.GetBackchannelAuthenticationSession(ctx, signature, nil)
+			require.NoError(t, err)
+			return stored
+		}
+
+		// pollAfter stores a last poll which happened the given duration ago.
+		pollAfter := func(t *testing.T, d time.Duration) {
+			stored := getBackchannelAuthenticationSession(t)
+			stored.SetLastPolledAt(time.Now().UTC().Add(-d))
+			require.NoError(t, store.UpdateBackchannelAuthenticationSession(ctx, signature, stored))
+		}
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), client)), fosite.ErrAuthorizationPending)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), client)), fosite.ErrSlowDown)
+		assert.Equal(t, time.Hour+fosite.SlowDownIntervalIncrease, getBackchannelAuthenticationSession(t).GetPollingInterval())
+
+		// Polling at the original interval is too fast once the interval was increased.
+		pollAfter(t, time.Hour+time.Second)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), client)), fosite.ErrSlowDown)
+		assert.Equal(t, time.Hour+2*fosite.SlowDownIntervalIncrease, getBackchannelAuthenticationSession(t).GetPollingInterval())
+
+		pollAfter(t, 2*time.Hour)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), client)), fosite.ErrAuthorizationPending)
+	})
+
+	t.Run("case=should not overwrite a concurrent approval when recording a poll", func(t *testing.T) {
+		store, h, u, resp := setup(t, client, nil)
+		h.CoreStorage = &staleBackchannelAuthenticationStorage{MemoryStore: store, afterGet: func() {
+			approve(t, u, resp.GetAuthReqID())
+		}}
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), client)), fosite.ErrAuthorizationPending)
+
+		signature, err := authReqIDStrategy.AuthReqIDSignature(ctx, resp.GetAuthReqID())
+		require.NoError(t, err)
+		stored, err := store.GetBackchannelAuthenticationSession(ctx, signature, nil)
+		require.NoError(t, err)
+		assert.Equal(t, fosite.BackchannelAuthenticationRequestStatusApproved, stored.GetStatus())
+		assert.False(t, stored.GetLastPolledAt().IsZero())
+	})
+
+	t.Run("case=should fail because the auth_req_id expired", func(t *testing.T) {
+		store, h, _, resp := setup(t, client, nil)
+		signature, err := authReqIDStrategy.AuthReqIDSignature(ctx, resp.GetAuthReqID())
+		require.NoError(t, err)
+		stored, err := store.GetBackchannelAuthenticationSession(ctx, signature, nil)
+		require.NoError(t, err)
+		stored.GetSession().SetExpiresAt(fosite.AuthReqID, time.Now().UTC().Add(-time.Minute))
+		require.NoError(t, store.UpdateBackchannelAuthenticationSession(ctx, signature, stored))
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), client)), fosite.ErrExpiredAuthReqID)
+	})
+
+	t.Run("case=should fail because the end user denied the request", func(t *testing.T) {
+		_, h, u, resp := setup(t, client, nil)
+		br, err := u.GetBackchannelAuthenticationRequest(ctx, resp.GetAuthReqID(), nil)
+		require.NoError(t, err)
+		require.NoError(t, u.DenyBackchannelAuthenticationRequest(ctx, resp.GetAuthReqID(), br))
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), client)), fosite.ErrAccessDenied)
+
+		_, err = u.GetBackchannelAuthenticationRequest(ctx, resp.GetAuthReqID(), nil)
+		assert.ErrorIs(t, err, fosite.ErrInvalidGrant)
+	})
+
+	t.Run("case=should issue tokens once the end user approved the request", func(t *testing.T) {
+		store, h, u, resp := setup(t, client, nil)
+		br := approve(t, u, resp.GetAuthReqID())
+
+		ar := newAccessRequest(resp.GetAuthReqID(), client)
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		assert.Equal(t, br.GetID(), ar.GetID())
+		assert.Equal(t, "peter", ar.GetSession().GetSubject())
+
+		aresp := fosite.NewAccessResponse()
+		require.NoError(t, h.PopulateTokenEndpointResponse(ctx, ar, aresp))
+		assert.NotEmpty(t, aresp.GetAccessToken())
+		assert.NotEmpty(t, aresp.GetExtra("refresh_token"))
+		assert.Equal(t, "openid offline", aresp.GetExtra("scope"))
+
+		idToken, err := idTokenStrategy.Decode(ctx, aresp.GetExtra("id_token").(string))
+		require.NoError(t, err)
+		assert.Equal(t, "peter", idToken.Claims["sub"])
+		assert.NotEmpty(t, idToken.Claims["at_hash"])
+
+		// Using the auth_req_id a second time must fail and revoke the issued tokens.
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), client)), fosite.ErrInvalidGrant)
+		_, err = store.GetAccessTokenSession(ctx, coreStrategy.AccessTokenSignature(ctx, aresp.GetAccessToken()), nil)
+		assert.ErrorIs(t, err, fosite.ErrNotFound)
+	})
+
+	t.Run("case=should ping the client in ping mode", func(t *testing.T) {
+		var pinged map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer notification-token", r.Header.Get("Authorization"))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&pinged))
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		pingClient := newClient("ping", server.URL)
+		_, h, u, resp := setup(t, pingClient, &DefaultBackchannelAuthenticationNotifier{Config: &fosite.Config{HTTPClient: retryablehttp.NewClient()}})
+		assert.Zero(t, resp.GetInterval())
+
+		// Clients in ping mode are never asked to slow down.
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), pingClient)), fosite.ErrAuthorizationPending)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetAuthReqID(), pingClient)), fosite.ErrAuthorizationPending)
+
+		approve(t, u, resp.GetAuthReqID())
+		assert.Equal(t, map[string]string{"auth_req_id": resp.GetAuthReqID()}, pinged)
+
+		ar := newAccessRequest(resp.GetAuthReqID(), pingClient)
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		require.NoError(t, h.PopulateTokenEndpointResponse(ctx, ar, fosite.NewAccessResponse()))
+	})
+
+	t.Run("case=should retry a failed ping", func(t *testing.T) {
+		var pings int
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if pings++; pings == 1 {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		pingClient := newClient("ping", server.URL)
+		httpClient := retryablehttp.NewClient()
+		httpClient.RetryMax = 0
+		_, h, u, resp := setup(t, pingClient, &DefaultBackchannelAuthenticationNotifier{Config: &fosite.Config{HTTPClient: httpClient}})
+
+		br, err := u.GetBackchannelAuthenticationRequest(ctx, resp.GetAuthReqID(), nil)
+		require.NoError(t, err)
+		assert.ErrorIs(t, u.ApproveBackchannelAuthenticationRequest(ctx, resp.GetAuthReqID(), br), fosite.ErrServerError)
+
+		// The decision is stored even though the client was not notified, and it can not be changed by a retry.
+		assert.ErrorIs(t, u.DenyBackchannelAuthenticationRequest(ctx, resp.GetAuthReqID(), br), fosite.ErrInvalidGrant)
+		require.NoError(t, u.ApproveBackchannelAuthenticationRequest(ctx, resp.GetAuthReqID(), br))
+		assert.Equal(t, 2, pings)
+
+		ar := newAccessRequest(resp.GetAuthReqID(), pingClient)
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+	})
+
+	t.Run("case=should fail to approve in ping mode without notifier", func(t *testing.T) {
+		_, _, u, resp := setup(t, newClient("ping", "https://client.example.com/cb"), nil)
+		br, err := u.GetBackchannelAuthenticationRequest(ctx, resp.GetAuthReqID(), nil)
+		require.NoError(t, err)
+		assert.ErrorIs(t, u.ApproveBackchannelAuthenticationRequest(ctx, resp.GetAuthReqID(), br), fosite.ErrServerError)
+	})
+}
+
+// staleBackchannelAuthenticationStorage returns copies of the stored backchannel authentication requests, as
+// persistent storages do, and calls afterGet once a request was read to simulate a concurrent update.
+type staleBackchannelAuthenticationStorage struct {
+	*storage.MemoryStore
+	afterGet func()
+}
+
+func (s *staleBackchannelAuthenticationStorage) GetBackchannelAuthenticationSession(ctx context.Context, signature string, session fosite.Session) (fosite.BackchannelAuthenticationRequester, error) {
+	br, err := s.MemoryStore.GetBackchannelAuthenticationSession(ctx, signature, session)
+	if err != nil {
+		return br, err
+	}
+
+	stale := *br.(*fosite.BackchannelAuthenticationRequest)
+	s.afterGet()
+	return &stale, nil
+}
@@ -0,0 +1,63 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciba
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite"
+)
+
+// BackchannelAuthenticationNotifier notifies clients using the ping token delivery mode that the result of a
+// backchannel authentication request is available at the token endpoint.
+type BackchannelAuthenticationNotifier interface {
+	// NotifyBackchannelAuthenticationResult sends the auth_req_id to the client notification endpoint of the client,
+	// authenticated with the client_notification_token of the request.
+	NotifyBackchannelAuthenticationResult(ctx context.Context, client fosite.BackchannelAuthenticationClient, clientNotificationToken, authReqID string) error
+}
+
+// DefaultBackchannelAuthenticationNotifier sends ping callbacks as specified in
+// https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#ping_callback
+//
+// Failed requests are retried according to the retry policy of the HTTP client returned by GetHTTPClient.
+type DefaultBackchannelAuthenticationNotifier struct {
+	Config interface {
+		fosite.HTTPClientProvider
+	}
+}
+
+var _ BackchannelAuthenticationNotifier = (*DefaultBackchannelAuthenticationNotifier)(nil)
+
+func (n *DefaultBackchannelAuthenticationNotifier) NotifyBackchannelAuthenticationResult(ctx context.Context, client fosite.BackchannelAuthenticationClient, clientNotificationToken, authReqID string) error {
+	body, err := json.Marshal(map[string]string{"auth_req_id": authReqID})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, "POST", client.GetBackchannelClientNotificationEndpoint(), bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+clientNotificationToken)
+
+	resp, err := n.Config.GetHTTPClient(ctx).Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("the client notification endpoint of client '%s' responded with status code %d", client.GetID(), resp.StatusCode)
+	}
+	return nil
+}
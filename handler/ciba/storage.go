@@ -0,0 +1,47 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciba
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+)
+
+// CIBACoreStorage is the storage needed by the Client-Initiated Backchannel Authentication grant handlers.
+type CIBACoreStorage interface {
+	BackchannelAuthenticationStorage
+	oauth2.AccessTokenStorage
+	oauth2.RefreshTokenStorage
+}
+
+// BackchannelAuthenticationStorage stores the backchannel authentication requests by their auth_req_id signatures.
+type BackchannelAuthenticationStorage interface {
+	// CreateBackchannelAuthenticationSession stores the backchannel authentication request.
+	CreateBackchannelAuthenticationSession(ctx context.Context, signature string, request fosite.BackchannelAuthenticationRequester) (err error)
+
+	// GetBackchannelAuthenticationSession hydrates the session based on the given auth_req_id signature and returns
+	// the backchannel authentication request. If the auth_req_id has been invalidated with
+	// `InvalidateBackchannelAuthenticationSession`, this method should return the ErrInvalidatedAuthReqID error.
+	//
+	// Make sure to also return the fosite.Requester value when returning the fosite.ErrInvalidatedAuthReqID error!
+	GetBackchannelAuthenticationSession(ctx context.Context, signature string, session fosite.Session) (request fosite.BackchannelAuthenticationRequester, err error)
+
+	// UpdateBackchannelAuthenticationSession updates the backchannel authentication request stored for the given
+	// auth_req_id signature, for example once the end user approved or denied the request.
+	UpdateBackchannelAuthenticationSession(ctx context.Context, signature string, request fosite.BackchannelAuthenticationRequester) (err error)
+
+	// UpdateBackchannelAuthenticationPollingState only updates the last poll time and the polling interval of the
+	// backchannel authentication request stored for the given auth_req_id signature when the client polled the token
+	// endpoint. It must not overwrite the rest of the request, as the end user may approve or deny the request
+	// concurrently.
+	UpdateBackchannelAuthenticationPollingState(ctx context.Context, signature string, lastPolledAt time.Time, interval time.Duration) (err error)
+
+	// InvalidateBackchannelAuthenticationSession is called when an auth_req_id is being used. The state of the
+	// auth_req_id should be set to invalid and consecutive requests to GetBackchannelAuthenticationSession should
+	// return the ErrInvalidatedAuthReqID error.
+	InvalidateBackchannelAuthenticationSession(ctx context.Context, signature string) (err error)
+}
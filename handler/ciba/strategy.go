@@ -0,0 +1,23 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciba
+
+import (
+	"context"
+
+	"github.com/ory/fosite"
+)
+
+// AuthReqIDStrategy handles the auth_req_id which the client uses to redeem the result of a backchannel
+// authentication request at the token endpoint.
+type AuthReqIDStrategy interface {
+	// AuthReqIDSignature returns the signature of the auth_req_id which is used as the storage key.
+	AuthReqIDSignature(ctx context.Context, id string) (signature string, err error)
+
+	// GenerateAuthReqID generates a new auth_req_id and its signature.
+	GenerateAuthReqID(ctx context.Context) (id string, signature string, err error)
+
+	// ValidateAuthReqID validates the auth_req_id and checks that it has not expired.
+	ValidateAuthReqID(ctx context.Context, r fosite.Requester, id string) (err error)
+}
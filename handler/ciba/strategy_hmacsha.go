@@ -0,0 +1,53 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciba
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	enigma "github.com/ory/fosite/token/hmac"
+)
+
+const authReqIDPrefix = "ory_ar_"
+
+// DefaultAuthReqIDStrategy generates auth_req_id values with the HMAC strategy.
+type DefaultAuthReqIDStrategy struct {
+	Enigma *enigma.HMACStrategy
+	Config interface {
+		fosite.BackchannelAuthenticationProvider
+	}
+}
+
+var _ AuthReqIDStrategy = (*DefaultAuthReqIDStrategy)(nil)
+
+func (h *DefaultAuthReqIDStrategy) AuthReqIDSignature(ctx context.Context, id string) (string, error) {
+	return h.Enigma.Signature(id), nil
+}
+
+func (h *DefaultAuthReqIDStrategy) GenerateAuthReqID(ctx context.Context) (id string, signature string, err error) {
+	token, sig, err := h.Enigma.Generate(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	return authReqIDPrefix + token, sig, nil
+}
+
+func (h *DefaultAuthReqIDStrategy) ValidateAuthReqID(ctx context.Context, r fosite.Requester, id string) (err error) {
+	var exp = r.GetSession().GetExpiresAt(fosite.AuthReqID)
+	if exp.IsZero() {
+		exp = r.GetRequestedAt().Add(h.Config.GetBackchannelAuthenticationRequestLifespan(ctx))
+	}
+
+	if exp.Before(time.Now().UTC()) {
+		return errorsx.WithStack(fosite.ErrExpiredAuthReqID.WithHintf("The auth_req_id expired at '%s'.", exp))
+	}
+
+	return h.Enigma.Validate(ctx, strings.TrimPrefix(id, authReqIDPrefix))
+}
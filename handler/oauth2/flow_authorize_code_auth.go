@@ -16,6 +16,7 @@ import (
 
 var _ fosite.AuthorizeEndpointHandler = (*AuthorizeExplicitGrantHandler)(nil)
 var _ fosite.TokenEndpointHandler = (*AuthorizeExplicitGrantHandler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*AuthorizeExplicitGrantHandler)(nil)
 
 // AuthorizeExplicitGrantHandler is a response handler for the Authorize Code grant using the explicit grant type
 // as defined in https://tools.ietf.org/html/rfc6749#section-4.1
@@ -97,10 +98,19 @@ func (c *AuthorizeExplicitGrantHandler) IssueAuthorizeCode(ctx context.Context,
 	return nil
 }
 
+// GetSanitationWhiteList returns the request parameters stored with the authorization code. The "dpop_jkt" parameter
+// is always kept, because the token endpoint uses it to enforce the DPoP binding of the authorization code.
 func (c *AuthorizeExplicitGrantHandler) GetSanitationWhiteList(ctx context.Context) []string {
-	if allowedList := c.Config.GetSanitationWhiteList(ctx); len(allowedList) > 0 {
-		return allowedList
+	allowedList := []string{"code", "redirect_uri"}
+	if configured := c.Config.GetSanitationWhiteList(ctx); len(configured) > 0 {
+		allowedList = append([]string{}, configured...)
 	}
 
-	return []string{"code", "redirect_uri"}
+	return append(allowedList, "dpop_jkt")
+}
+
+// PopulateAuthorizationServerMetadata adds the authorization code grant.
+func (c *AuthorizeExplicitGrantHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddResponseTypes("code")
+	metadata.AddGrantTypes("authorization_code")
 }
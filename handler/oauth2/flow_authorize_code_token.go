@@ -84,6 +84,13 @@ func (c *AuthorizeExplicitGrantHandler) HandleTokenEndpointRequest(ctx context.C
 		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The \"redirect_uri\" from this request does not match the one from the authorize request."))
 	}
 
+	// If the authorization code is bound to a DPoP key using the "dpop_jkt" parameter, the token request must
+	// contain a DPoP proof signed with this key: https://www.rfc-editor.org/rfc/rfc9449#section-10.1
+	jkt := fosite.GetDPoPJWKThumbprint(request.GetSession())
+	if bound := authorizeRequest.GetRequestForm().Get("dpop_jkt"); bound != "" && bound != jkt {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The authorization code is bound to a DPoP key and the request does not contain a DPoP proof signed with this key."))
+	}
+
 	// Checking of POST client_id skipped, because:
 	// If the client type is confidential or the client was issued client
 	// credentials (or assigned other authentication requirements), the
@@ -91,6 +98,9 @@ func (c *AuthorizeExplicitGrantHandler) HandleTokenEndpointRequest(ctx context.C
 	// in Section 3.2.1.
 	request.SetSession(authorizeRequest.GetSession())
 	request.SetID(authorizeRequest.GetID())
+	if s, ok := request.GetSession().(fosite.DPoPSession); ok {
+		s.SetDPoPJWKThumbprint(jkt)
+	}
 
 	atLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeAuthorizationCode, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
 	request.GetSession().SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(atLifespan).Round(time.Second))
@@ -135,8 +145,12 @@ func (c *AuthorizeExplicitGrantHandler) PopulateTokenEndpointResponse(ctx contex
 		requester.GrantScope(scope)
 	}
 
-	for _, audience := range authorizeRequest.GetGrantedAudience() {
-		requester.GrantAudience(audience)
+	if err := GrantAudience(requester, authorizeRequest); err != nil {
+		return err
+	}
+
+	if err := GrantAuthorizationDetails(requester, authorizeRequest); err != nil {
+		return err
 	}
 
 	access, accessSignature, err := c.AccessTokenStrategy.GenerateAccessToken(ctx, requester)
@@ -169,13 +183,13 @@ func (c *AuthorizeExplicitGrantHandler) PopulateTokenEndpointResponse(ctx contex
 	} else if err = c.CoreStorage.CreateAccessTokenSession(ctx, accessSignature, requester.Sanitize([]string{})); err != nil {
 		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
 	} else if refreshSignature != "" {
-		if err = c.CoreStorage.CreateRefreshTokenSession(ctx, refreshSignature, requester.Sanitize([]string{})); err != nil {
+		if err = c.CoreStorage.CreateRefreshTokenSession(ctx, refreshSignature, SanitizeRefreshTokenRequest(requester, authorizeRequest)); err != nil {
 			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
 		}
 	}
 
 	responder.SetAccessToken(access)
-	responder.SetTokenType("bearer")
+	responder.SetTokenType(fosite.GetAccessTokenType(requester.GetSession()))
 	atLifespan := fosite.GetEffectiveLifespan(requester.GetClient(), fosite.GrantTypeAuthorizationCode, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
 	responder.SetExpiresIn(getExpiresIn(requester, fosite.AccessToken, atLifespan, time.Now().UTC()))
 	responder.SetScopes(requester.GetGrantedScopes())
@@ -15,6 +15,7 @@ import (
 )
 
 var _ fosite.AuthorizeEndpointHandler = (*AuthorizeImplicitGrantTypeHandler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*AuthorizeImplicitGrantTypeHandler)(nil)
 
 // AuthorizeImplicitGrantTypeHandler is a response handler for the Authorize Code grant using the implicit grant type
 // as defined in https://tools.ietf.org/html/rfc6749#section-4.2
@@ -90,3 +91,9 @@ func (c *AuthorizeImplicitGrantTypeHandler) IssueImplicitAccessToken(ctx context
 
 	return nil
 }
+
+// PopulateAuthorizationServerMetadata adds the implicit grant.
+func (c *AuthorizeImplicitGrantTypeHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddResponseTypes("token")
+	metadata.AddGrantTypes("implicit")
+}
@@ -13,6 +13,7 @@ import (
 )
 
 var _ fosite.TokenEndpointHandler = (*ClientCredentialsGrantHandler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*ClientCredentialsGrantHandler)(nil)
 
 type ClientCredentialsGrantHandler struct {
 	*HandleHelper
@@ -76,3 +77,8 @@ func (c *ClientCredentialsGrantHandler) CanHandleTokenEndpointRequest(ctx contex
 	// Value MUST be set to "client_credentials".
 	return requester.GetGrantTypes().ExactOne("client_credentials")
 }
+
+// PopulateAuthorizationServerMetadata adds the client credentials grant.
+func (c *ClientCredentialsGrantHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddGrantTypes("client_credentials")
+}
@@ -18,6 +18,7 @@ import (
 )
 
 var _ fosite.TokenEndpointHandler = (*RefreshTokenGrantHandler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*RefreshTokenGrantHandler)(nil)
 
 type RefreshTokenGrantHandler struct {
 	AccessTokenStrategy    AccessTokenStrategy
@@ -77,8 +78,18 @@ func (c *RefreshTokenGrantHandler) HandleTokenEndpointRequest(ctx context.Contex
 		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client ID from this request does not match the ID during the initial token issuance."))
 	}
 
+	// Refresh tokens issued to public clients are bound to the DPoP key used when they were issued:
+	// https://www.rfc-editor.org/rfc/rfc9449#section-5-9
+	jkt := fosite.GetDPoPJWKThumbprint(request.GetSession())
+	if bound := fosite.GetDPoPJWKThumbprint(originalRequest.GetSession()); bound != "" && request.GetClient().IsPublic() && bound != jkt {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The refresh token is bound to a DPoP key and the request does not contain a DPoP proof signed with this key."))
+	}
+
 	request.SetID(originalRequest.GetID())
 	request.SetSession(originalRequest.GetSession().Clone())
+	if s, ok := request.GetSession().(fosite.DPoPSession); ok {
+		s.SetDPoPJWKThumbprint(jkt)
+	}
 	request.SetRequestedScopes(originalRequest.GetRequestedScopes())
 	request.SetRequestedAudience(originalRequest.GetRequestedAudience())
 
@@ -93,8 +104,15 @@ func (c *RefreshTokenGrantHandler) HandleTokenEndpointRequest(ctx context.Contex
 		return err
 	}
 
-	for _, audience := range originalRequest.GetGrantedAudience() {
-		request.GrantAudience(audience)
+	if err := GrantAudience(request, originalRequest); err != nil {
+		return err
+	}
+
+	if err := GrantAuthorizationDetails(request, originalRequest); err != nil {
+		return err
+	}
+	if len(request.GetRequestedAuthorizationDetails()) == 0 {
+		request.SetRequestedAuthorizationDetails(originalRequest.GetRequestedAuthorizationDetails())
 	}
 
 	atLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeRefreshToken, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
@@ -152,12 +170,15 @@ func (c *RefreshTokenGrantHandler) PopulateTokenEndpointResponse(ctx context.Con
 		return err
 	}
 
-	if err = c.TokenRevocationStorage.CreateRefreshTokenSession(ctx, refreshSignature, storeReq); err != nil {
+	refreshStoreReq := SanitizeRefreshTokenRequest(requester, ts)
+	refreshStoreReq.SetID(ts.GetID())
+
+	if err = c.TokenRevocationStorage.CreateRefreshTokenSession(ctx, refreshSignature, refreshStoreReq); err != nil {
 		return err
 	}
 
 	responder.SetAccessToken(accessToken)
-	responder.SetTokenType("bearer")
+	responder.SetTokenType(fosite.GetAccessTokenType(requester.GetSession()))
 	atLifespan := fosite.GetEffectiveLifespan(requester.GetClient(), fosite.GrantTypeRefreshToken, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
 	responder.SetExpiresIn(getExpiresIn(requester, fosite.AccessToken, atLifespan, time.Now().UTC()))
 	responder.SetScopes(requester.GetGrantedScopes())
@@ -242,3 +263,8 @@ func (c *RefreshTokenGrantHandler) CanHandleTokenEndpointRequest(ctx context.Con
 	// Value MUST be set to "refresh_token".
 	return requester.GetGrantTypes().ExactOne("refresh_token")
 }
+
+// PopulateAuthorizationServerMetadata adds the refresh token grant.
+func (c *RefreshTokenGrantHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddGrantTypes("refresh_token")
+}
@@ -348,6 +348,64 @@ func TestRefreshFlow_HandleTokenEndpointRequest(t *testing.T) {
 	}
 }
 
+func TestRefreshFlow_HandleTokenEndpointRequestWithDPoP(t *testing.T) {
+	ctx := context.Background()
+	config := &fosite.Config{
+		AccessTokenLifespan:      time.Hour,
+		RefreshTokenLifespan:     time.Hour,
+		ScopeStrategy:            fosite.HierarchicScopeStrategy,
+		AudienceMatchingStrategy: fosite.DefaultAudienceMatchingStrategy,
+	}
+
+	for _, c := range []struct {
+		description string
+		public      bool
+		bound       string
+		jkt         string
+		expectErr   error
+		expectJKT   string
+	}{
+		{description: "should pass for a public client using the bound key", public: true, bound: "key", jkt: "key", expectJKT: "key"},
+		{description: "should fail for a public client using another key", public: true, bound: "key", jkt: "other", expectErr: fosite.ErrInvalidGrant},
+		{description: "should fail for a public client without a proof", public: true, bound: "key", expectErr: fosite.ErrInvalidGrant},
+		{description: "should bind an unbound token of a public client", public: true, jkt: "key", expectJKT: "key"},
+		{description: "should allow a confidential client to use another key", bound: "key", jkt: "other", expectJKT: "other"},
+		{description: "should allow a confidential client to omit the proof", bound: "key"},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			store := storage.NewMemoryStore()
+			handler := &RefreshTokenGrantHandler{
+				TokenRevocationStorage: store,
+				RefreshTokenStrategy:   &hmacshaStrategy,
+				Config:                 config,
+			}
+			client := &fosite.DefaultClient{ID: "foo", Public: c.public, GrantTypes: fosite.Arguments{"refresh_token"}, Scopes: []string{"offline"}}
+
+			token, sig, err := hmacshaStrategy.GenerateRefreshToken(ctx, nil)
+			require.NoError(t, err)
+			require.NoError(t, store.CreateRefreshTokenSession(ctx, sig, &fosite.Request{
+				Client:       client,
+				GrantedScope: fosite.Arguments{"offline"},
+				Session:      &fosite.DefaultSession{DPoPJWKThumbprint: c.bound},
+				RequestedAt:  time.Now().UTC(),
+			}))
+
+			areq := fosite.NewAccessRequest(&fosite.DefaultSession{DPoPJWKThumbprint: c.jkt})
+			areq.GrantTypes = fosite.Arguments{"refresh_token"}
+			areq.Client = client
+			areq.Form = url.Values{"refresh_token": {token}}
+
+			err = handler.HandleTokenEndpointRequest(ctx, areq)
+			if c.expectErr != nil {
+				require.ErrorIs(t, err, c.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expectJKT, fosite.GetDPoPJWKThumbprint(areq.GetSession()))
+		})
+	}
+}
+
 func TestRefreshFlowTransactional_HandleTokenEndpointRequest(t *testing.T) {
 	var mockTransactional *internal.MockTransactional
 	var mockRevocationStore *internal.MockTokenRevocationStorage
@@ -1086,3 +1144,51 @@ func TestRefreshFlowTransactional_PopulateTokenEndpointResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshFlow_NarrowedAuthorizationDetails(t *testing.T) {
+	ctx := context.Background()
+	payment := fosite.AuthorizationDetail{Type: "payment_initiation", Actions: []string{"initiate"}}
+	account := fosite.AuthorizationDetail{Type: "account_information", Actions: []string{"list_accounts"}}
+
+	store := storage.NewMemoryStore()
+	client := &fosite.DefaultClient{ID: "foo", GrantTypes: fosite.Arguments{"refresh_token"}, Scopes: fosite.Arguments{"offline"}}
+	h := RefreshTokenGrantHandler{
+		TokenRevocationStorage: store,
+		RefreshTokenStrategy:   &hmacshaStrategy,
+		AccessTokenStrategy:    &hmacshaStrategy,
+		Config: &fosite.Config{
+			AccessTokenLifespan:      time.Hour,
+			RefreshTokenLifespan:     time.Hour,
+			ScopeStrategy:            fosite.HierarchicScopeStrategy,
+			AudienceMatchingStrategy: fosite.DefaultAudienceMatchingStrategy,
+		},
+	}
+
+	original := fosite.NewAccessRequest(&fosite.DefaultSession{})
+	original.ID = "req-id"
+	original.Client = client
+	original.GrantedScope = fosite.Arguments{"offline"}
+	original.GrantAuthorizationDetails(fosite.AuthorizationDetails{payment, account})
+	token, signature, err := hmacshaStrategy.GenerateRefreshToken(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateRefreshTokenSession(ctx, signature, original))
+
+	refresh := func(token string, details fosite.AuthorizationDetails) (*fosite.AccessRequest, string) {
+		areq := fosite.NewAccessRequest(&fosite.DefaultSession{})
+		areq.GrantTypes = fosite.Arguments{"refresh_token"}
+		areq.Client = client
+		areq.Form = url.Values{"refresh_token": {token}}
+		areq.SetRequestedAuthorizationDetails(details)
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, areq))
+
+		aresp := fosite.NewAccessResponse()
+		require.NoError(t, h.PopulateTokenEndpointResponse(ctx, areq, aresp))
+		return areq, aresp.ToMap()["refresh_token"].(string)
+	}
+
+	areq, token := refresh(token, fosite.AuthorizationDetails{account})
+	assert.Equal(t, fosite.AuthorizationDetails{account}, areq.GetGrantedAuthorizationDetails())
+
+	areq, _ = refresh(token, nil)
+	assert.Equal(t, fosite.AuthorizationDetails{payment, account}, areq.GetGrantedAuthorizationDetails(), "the second refresh must grant all authorization details of the original request")
+}
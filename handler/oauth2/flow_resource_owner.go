@@ -15,6 +15,7 @@ import (
 )
 
 var _ fosite.TokenEndpointHandler = (*ResourceOwnerPasswordCredentialsGrantHandler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*ResourceOwnerPasswordCredentialsGrantHandler)(nil)
 
 // Deprecated: This handler is deprecated as a means to communicate that the ROPC grant type is widely discouraged and
 // is at the time of this writing going to be omitted in the OAuth 2.1 spec. For more information on why this grant type
@@ -116,3 +117,8 @@ func (c *ResourceOwnerPasswordCredentialsGrantHandler) CanHandleTokenEndpointReq
 	// Value MUST be set to "password".
 	return requester.GetGrantTypes().ExactOne("password")
 }
+
+// PopulateAuthorizationServerMetadata adds the resource owner password credentials grant.
+func (c *ResourceOwnerPasswordCredentialsGrantHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddGrantTypes("password")
+}
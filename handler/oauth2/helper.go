@@ -7,6 +7,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/ory/x/errorsx"
+
 	"github.com/ory/fosite"
 )
 
@@ -30,7 +32,7 @@ func (h *HandleHelper) IssueAccessToken(ctx context.Context, defaultLifespan tim
 	}
 
 	responder.SetAccessToken(token)
-	responder.SetTokenType("bearer")
+	responder.SetTokenType(fosite.GetAccessTokenType(requester.GetSession()))
 	responder.SetExpiresIn(getExpiresIn(requester, fosite.AccessToken, defaultLifespan, time.Now().UTC()))
 	responder.SetScopes(requester.GetGrantedScopes())
 	return nil
@@ -42,3 +44,71 @@ func getExpiresIn(r fosite.Requester, key fosite.TokenType, defaultLifespan time
 	}
 	return time.Duration(r.GetSession().GetExpiresAt(key).UnixNano() - now.UnixNano())
 }
+
+// GrantAuthorizationDetails grants the authorization details granted to the original request. If the token request
+// contains authorization details, only those are granted and they must have been granted to the original request, see
+// https://www.rfc-editor.org/rfc/rfc9396#section-6.1
+func GrantAuthorizationDetails(request fosite.Requester, original fosite.Requester) error {
+	requested := request.GetRequestedAuthorizationDetails()
+	if len(requested) == 0 {
+		request.GrantAuthorizationDetails(original.GetGrantedAuthorizationDetails())
+		return nil
+	}
+
+	for _, detail := range requested {
+		if !original.GetGrantedAuthorizationDetails().Has(detail) {
+			return errorsx.WithStack(fosite.ErrInvalidAuthorizationDetails.WithHintf("The authorization detail of type '%s' was not granted to the original request.", detail.Type))
+		}
+	}
+
+	request.GrantAuthorizationDetails(requested)
+	return nil
+}
+
+// GrantAudience grants the audience granted to the original request. If the token request contains resource
+// indicators, the access token is narrowed to those resources, which must have been granted to the original request,
+// see https://www.rfc-editor.org/rfc/rfc8707#section-2.2
+func GrantAudience(request fosite.Requester, original fosite.Requester) error {
+	resources := fosite.GetResources(request.GetRequestForm())
+	if len(resources) == 0 {
+		for _, audience := range original.GetGrantedAudience() {
+			request.GrantAudience(audience)
+		}
+		return nil
+	}
+
+	for _, resource := range resources {
+		if !original.GetGrantedAudience().Has(resource) {
+			return errorsx.WithStack(fosite.ErrInvalidTarget.WithHintf("The resource '%s' was not granted to the original request.", resource))
+		}
+		request.GrantAudience(resource)
+	}
+	return nil
+}
+
+// SanitizeRefreshTokenRequest returns the sanitized request to store with the refresh token. Refresh tokens keep the
+// audience and authorization details granted to the original request even if the access token was narrowed to the
+// requested resources or authorization details, so that they can be used to obtain access tokens for the others.
+func SanitizeRefreshTokenRequest(request fosite.Requester, original fosite.Requester) fosite.Requester {
+	sanitized := request.Sanitize([]string{})
+	r, ok := sanitized.(*fosite.Request)
+
+	if len(fosite.GetResources(request.GetRequestForm())) > 0 {
+		if ok {
+			r.GrantedAudience = fosite.Arguments{}
+		}
+		for _, audience := range original.GetGrantedAudience() {
+			sanitized.GrantAudience(audience)
+		}
+	}
+
+	if len(request.GetRequestedAuthorizationDetails()) > 0 {
+		if ok {
+			r.GrantedAuthorizationDetails = nil
+		}
+		sanitized.SetRequestedAuthorizationDetails(original.GetRequestedAuthorizationDetails())
+		sanitized.GrantAuthorizationDetails(original.GetGrantedAuthorizationDetails())
+	}
+
+	return sanitized
+}
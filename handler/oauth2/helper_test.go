@@ -77,3 +77,53 @@ func TestIssueAccessToken(t *testing.T) {
 		}
 	}
 }
+
+func TestGrantAuthorizationDetails(t *testing.T) {
+	payment := fosite.AuthorizationDetail{Type: "payment_initiation", Actions: []string{"initiate"}}
+	account := fosite.AuthorizationDetail{Type: "account_information", Actions: []string{"list_accounts"}}
+
+	original := fosite.NewRequest()
+	original.GrantAuthorizationDetails(fosite.AuthorizationDetails{payment, account})
+
+	r := fosite.NewAccessRequest(new(fosite.DefaultSession))
+	require.NoError(t, GrantAuthorizationDetails(r, original))
+	assert.Equal(t, fosite.AuthorizationDetails{payment, account}, r.GetGrantedAuthorizationDetails())
+
+	r = fosite.NewAccessRequest(new(fosite.DefaultSession))
+	r.SetRequestedAuthorizationDetails(fosite.AuthorizationDetails{account})
+	require.NoError(t, GrantAuthorizationDetails(r, original))
+	assert.Equal(t, fosite.AuthorizationDetails{account}, r.GetGrantedAuthorizationDetails())
+
+	sanitized := SanitizeRefreshTokenRequest(r, original)
+	assert.Equal(t, fosite.AuthorizationDetails{payment, account}, sanitized.GetGrantedAuthorizationDetails())
+	assert.Equal(t, fosite.AuthorizationDetails{account}, r.GetGrantedAuthorizationDetails())
+
+	r = fosite.NewAccessRequest(new(fosite.DefaultSession))
+	r.SetRequestedAuthorizationDetails(fosite.AuthorizationDetails{{Type: "payment_initiation", Actions: []string{"cancel"}}})
+	assert.ErrorIs(t, GrantAuthorizationDetails(r, original), fosite.ErrInvalidAuthorizationDetails)
+	assert.Empty(t, r.GetGrantedAuthorizationDetails())
+}
+
+func TestGrantAudience(t *testing.T) {
+	original := fosite.NewRequest()
+	original.GrantAudience("https://api.example.com")
+	original.GrantAudience("https://files.example.com")
+
+	r := fosite.NewAccessRequest(new(fosite.DefaultSession))
+	require.NoError(t, GrantAudience(r, original))
+	assert.Equal(t, fosite.Arguments{"https://api.example.com", "https://files.example.com"}, r.GetGrantedAudience())
+
+	r = fosite.NewAccessRequest(new(fosite.DefaultSession))
+	r.Form.Set("resource", "https://files.example.com")
+	require.NoError(t, GrantAudience(r, original))
+	assert.Equal(t, fosite.Arguments{"https://files.example.com"}, r.GetGrantedAudience())
+
+	sanitized := SanitizeRefreshTokenRequest(r, original)
+	assert.Equal(t, fosite.Arguments{"https://api.example.com", "https://files.example.com"}, sanitized.GetGrantedAudience())
+	assert.Equal(t, fosite.Arguments{"https://files.example.com"}, r.GetGrantedAudience())
+	assert.Empty(t, sanitized.GetRequestForm())
+
+	r = fosite.NewAccessRequest(new(fosite.DefaultSession))
+	r.Form.Set("resource", "https://other.example.com")
+	assert.ErrorIs(t, GrantAudience(r, original), fosite.ErrInvalidTarget)
+}
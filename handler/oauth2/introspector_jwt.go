@@ -5,6 +5,7 @@ package oauth2
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/ory/fosite"
@@ -44,6 +45,13 @@ func AccessTokenJWTToRequest(token *jwt.Token) fosite.Requester {
 		}
 	}
 
+	var details fosite.AuthorizationDetails
+	if detailsClaim, ok := mapClaims["authorization_details"]; ok {
+		if raw, err := json.Marshal(detailsClaim); err == nil {
+			_ = json.Unmarshal(raw, &details)
+		}
+	}
+
 	return &fosite.Request{
 		RequestedAt: requestedAt,
 		Client: &fosite.DefaultClient{
@@ -65,6 +73,9 @@ func AccessTokenJWTToRequest(token *jwt.Token) fosite.Requester {
 		// We do not really know which audiences were requested, so we set them to granted.
 		RequestedAudience: claims.Audience,
 		GrantedAudience:   claims.Audience,
+		// The authorization details in the token are the granted ones.
+		RequestedAuthorizationDetails: details,
+		GrantedAuthorizationDetails:   details,
 	}
 }
 
@@ -74,6 +85,12 @@ func (v *StatelessJWTValidator) IntrospectToken(ctx context.Context, token strin
 		return "", err
 	}
 
+	if c, ok := v.Config.(fosite.JWTProfileAccessTokenProvider); ok && c.GetUseJWTProfileAccessTokens(ctx) {
+		if err := validateJWTProfileAccessToken(t); err != nil {
+			return "", err
+		}
+	}
+
 	// TODO: From here we assume it is an access token, but how do we know it is really and that is not an ID token?
 
 	requester := AccessTokenJWTToRequest(t)
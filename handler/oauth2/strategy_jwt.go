@@ -15,6 +15,10 @@ import (
 	"github.com/ory/x/errorsx"
 )
 
+// JWTProfileAccessTokenType is the "typ" header of access tokens following the JWT Profile for OAuth 2.0 Access
+// Tokens, see https://www.rfc-editor.org/rfc/rfc9068#section-2.1
+const JWTProfileAccessTokenType = "at+jwt"
+
 // DefaultJWTStrategy is a JWT RS256 strategy.
 type DefaultJWTStrategy struct {
 	jwt.Signer
@@ -25,6 +29,21 @@ type DefaultJWTStrategy struct {
 	}
 }
 
+// useJWTProfile returns true if the configuration enables RFC 9068 access tokens.
+func (h DefaultJWTStrategy) useJWTProfile(ctx context.Context) bool {
+	c, ok := h.Config.(fosite.JWTProfileAccessTokenProvider)
+	return ok && c.GetUseJWTProfileAccessTokens(ctx)
+}
+
+// jwtProfileDefaultAudience returns the configured audience of RFC 9068 access tokens for which no audience was
+// granted, if any.
+func (h DefaultJWTStrategy) jwtProfileDefaultAudience(ctx context.Context) []string {
+	if c, ok := h.Config.(fosite.JWTProfileAccessTokenProvider); ok {
+		return c.GetJWTProfileAccessTokenDefaultAudience(ctx)
+	}
+	return nil
+}
+
 func (h DefaultJWTStrategy) signature(token string) string {
 	split := strings.Split(token, ".")
 	if len(split) != 3 {
@@ -43,8 +62,15 @@ func (h *DefaultJWTStrategy) GenerateAccessToken(ctx context.Context, requester
 }
 
 func (h *DefaultJWTStrategy) ValidateAccessToken(ctx context.Context, _ fosite.Requester, token string) error {
-	_, err := validate(ctx, h.Signer, token)
-	return err
+	t, err := validate(ctx, h.Signer, token)
+	if err != nil {
+		return err
+	}
+
+	if h.useJWTProfile(ctx) {
+		return validateJWTProfileAccessToken(t)
+	}
+	return nil
 }
 
 func (h DefaultJWTStrategy) RefreshTokenSignature(ctx context.Context, token string) string {
@@ -86,6 +112,23 @@ func validate(ctx context.Context, jwtStrategy jwt.Signer, token string) (t *jwt
 	return
 }
 
+// validateJWTProfileAccessToken validates that the token is an access token following the JWT Profile for OAuth 2.0
+// Access Tokens, see https://www.rfc-editor.org/rfc/rfc9068#section-4
+func validateJWTProfileAccessToken(t *jwt.Token) error {
+	typ, _ := t.Header[string(jwt.JWTHeaderType)].(string)
+	if !strings.EqualFold(typ, JWTProfileAccessTokenType) && !strings.EqualFold(typ, "application/"+JWTProfileAccessTokenType) {
+		return errorsx.WithStack(fosite.ErrInvalidTokenFormat.WithHintf("The access token must be of type '%s' but got '%s'.", JWTProfileAccessTokenType, typ))
+	}
+
+	for _, claim := range []string{"iss", "exp", "aud", "sub", "client_id", "iat", "jti"} {
+		if _, ok := t.Claims[claim]; !ok {
+			return errorsx.WithStack(fosite.ErrTokenClaim.WithHintf("The access token is missing the required claim '%s'.", claim))
+		}
+	}
+
+	return nil
+}
+
 func toRFCErr(v *jwt.ValidationError) *fosite.RFC6749Error {
 	switch {
 	case v == nil:
@@ -114,6 +157,13 @@ func (h *DefaultJWTStrategy) generate(ctx context.Context, tokenType fosite.Toke
 	} else if jwtSession.GetJWTClaims() == nil {
 		return "", "", errors.New("GetTokenClaims() must not be nil")
 	} else {
+		profile := h.useJWTProfile(ctx)
+		scopeField := h.Config.GetJWTScopeField(ctx)
+		if profile && scopeField != jwt.JWTScopeFieldBoth {
+			// https://www.rfc-editor.org/rfc/rfc9068#section-2.2.3
+			scopeField = jwt.JWTScopeFieldString
+		}
+
 		claims := jwtSession.GetJWTClaims().
 			With(
 				jwtSession.GetExpiresAt(tokenType),
@@ -125,9 +175,72 @@ func (h *DefaultJWTStrategy) generate(ctx context.Context, tokenType fosite.Toke
 				h.Config.GetAccessTokenIssuer(ctx),
 			).
 			WithScopeField(
-				h.Config.GetJWTScopeField(ctx),
+				scopeField,
 			)
 
-		return h.Signer.Generate(ctx, claims.ToMapClaims(), jwtSession.GetJWTHeader())
+		mapClaims := claims.ToMapClaims()
+		if sub, _ := mapClaims["sub"].(string); sub != "" {
+			pairwise, err := fosite.PairwiseSubject(ctx, h.Config, requester.GetClient(), sub)
+			if err != nil {
+				return "", "", err
+			}
+			mapClaims["sub"] = pairwise
+		}
+		if details := requester.GetGrantedAuthorizationDetails(); len(details) > 0 {
+			// https://www.rfc-editor.org/rfc/rfc9396#section-9.1
+			mapClaims["authorization_details"] = details
+		}
+		for name, value := range fosite.GetAuthenticationClaims(requester.GetSession()) {
+			// https://www.rfc-editor.org/rfc/rfc9470#section-6.1
+			if _, ok := mapClaims[name]; !ok {
+				mapClaims[name] = value
+			}
+		}
+
+		if !profile {
+			return h.Signer.Generate(ctx, mapClaims, jwtSession.GetJWTHeader())
+		}
+
+		header, err := h.withJWTProfile(ctx, requester, jwtSession, mapClaims)
+		if err != nil {
+			return "", "", err
+		}
+		return h.Signer.Generate(ctx, mapClaims, header)
+	}
+}
+
+// withJWTProfile adds the claims required by the JWT Profile for OAuth 2.0 Access Tokens and returns the header of
+// the token, see https://www.rfc-editor.org/rfc/rfc9068#section-2
+func (h *DefaultJWTStrategy) withJWTProfile(ctx context.Context, requester fosite.Requester, session JWTSessionContainer, claims jwt.MapClaims) (*jwt.Headers, error) {
+	clientID := requester.GetClient().GetID()
+	claims["client_id"] = clientID
+
+	// Tokens issued without a resource owner, such as through the client credentials grant, identify the client.
+	if sub, _ := claims["sub"].(string); sub == "" {
+		var err error
+		if sub = session.GetSubject(); sub == "" {
+			sub = clientID
+		} else if sub, err = fosite.PairwiseSubject(ctx, h.Config, requester.GetClient(), sub); err != nil {
+			return nil, err
+		}
+		claims["sub"] = sub
+	}
+
+	if aud, _ := claims["aud"].([]string); len(aud) == 0 {
+		aud = h.jwtProfileDefaultAudience(ctx)
+		if len(aud) == 0 {
+			return nil, errors.New("the access token has no audience and no default audience is configured")
+		}
+		claims["aud"] = aud
 	}
+
+	if iss, _ := claims["iss"].(string); iss == "" {
+		return nil, errors.New("the access token has no issuer")
+	} else if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("the access token has no expiry")
+	}
+
+	header := &jwt.Headers{Extra: session.GetJWTHeader().ToMap()}
+	header.Add(string(jwt.JWTHeaderType), JWTProfileAccessTokenType)
+	return header, nil
 }
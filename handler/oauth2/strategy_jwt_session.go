@@ -82,6 +82,83 @@ func (j *JWTSession) GetSubject() string {
 	return j.Subject
 }
 
+// SetActor sets the "act" (actor) claim of the token as specified in
+// https://www.rfc-editor.org/rfc/rfc8693#section-4.1. Prior actors are nested in the claim's own "act" member.
+func (j *JWTSession) SetActor(act map[string]interface{}) {
+	claims := j.GetJWTClaims().(*jwt.JWTClaims)
+	if claims.Extra == nil {
+		claims.Extra = map[string]interface{}{}
+	}
+	if act == nil {
+		delete(claims.Extra, "act")
+		return
+	}
+	claims.Extra["act"] = act
+}
+
+// GetActor returns the "act" (actor) claim of the token or nil if the token was not issued through delegation.
+func (j *JWTSession) GetActor() map[string]interface{} {
+	if j == nil || j.JWTClaims == nil {
+		return nil
+	}
+	act, _ := j.JWTClaims.Extra["act"].(map[string]interface{})
+	return act
+}
+
+// SetDPoPJWKThumbprint binds the token to a DPoP key by setting the "jkt" member of the "cnf" (confirmation) claim
+// as specified in https://www.rfc-editor.org/rfc/rfc9449#section-6.1.
+func (j *JWTSession) SetDPoPJWKThumbprint(jkt string) {
+	j.setConfirmation("jkt", jkt)
+}
+
+// GetDPoPJWKThumbprint returns the "jkt" member of the "cnf" (confirmation) claim.
+func (j *JWTSession) GetDPoPJWKThumbprint() string {
+	return j.getConfirmation("jkt")
+}
+
+// SetCertificateThumbprint binds the token to a client certificate by setting the "x5t#S256" member of the "cnf"
+// (confirmation) claim as specified in https://www.rfc-editor.org/rfc/rfc8705#section-3.1.
+func (j *JWTSession) SetCertificateThumbprint(x5t string) {
+	j.setConfirmation("x5t#S256", x5t)
+}
+
+// GetCertificateThumbprint returns the "x5t#S256" member of the "cnf" (confirmation) claim.
+func (j *JWTSession) GetCertificateThumbprint() string {
+	return j.getConfirmation("x5t#S256")
+}
+
+func (j *JWTSession) setConfirmation(member, value string) {
+	claims := j.GetJWTClaims().(*jwt.JWTClaims)
+	if claims.Extra == nil {
+		claims.Extra = map[string]interface{}{}
+	}
+
+	cnf, _ := claims.Extra["cnf"].(map[string]interface{})
+	if cnf == nil {
+		cnf = map[string]interface{}{}
+	}
+	if value == "" {
+		delete(cnf, member)
+	} else {
+		cnf[member] = value
+	}
+
+	if len(cnf) == 0 {
+		delete(claims.Extra, "cnf")
+		return
+	}
+	claims.Extra["cnf"] = cnf
+}
+
+func (j *JWTSession) getConfirmation(member string) string {
+	if j == nil || j.JWTClaims == nil {
+		return ""
+	}
+	cnf, _ := j.JWTClaims.Extra["cnf"].(map[string]interface{})
+	value, _ := cnf[member].(string)
+	return value
+}
+
 func (j *JWTSession) Clone() fosite.Session {
 	if j == nil {
 		return nil
@@ -232,3 +232,155 @@ func TestAccessToken(t *testing.T) {
 		}
 	}
 }
+
+func TestAccessTokenConfirmationClaim(t *testing.T) {
+	r := jwtValidCase(fosite.AccessToken)
+	session := r.GetSession().(*JWTSession)
+	session.SetDPoPJWKThumbprint("jkt-value")
+	session.SetCertificateThumbprint("x5t-value")
+
+	j.Config = &fosite.Config{}
+	token, _, err := j.GenerateAccessToken(context.Background(), r)
+	require.NoError(t, err)
+
+	parsed, err := j.Decode(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"jkt": "jkt-value", "x5t#S256": "x5t-value"}, parsed.Claims["cnf"])
+
+	// The binding survives reconstructing the request from the JWT.
+	requester := AccessTokenJWTToRequest(parsed)
+	assert.Equal(t, "jkt-value", fosite.GetDPoPJWKThumbprint(requester.GetSession()))
+	assert.Equal(t, "x5t-value", fosite.GetCertificateThumbprint(requester.GetSession()))
+
+	session.SetDPoPJWKThumbprint("")
+	assert.Equal(t, map[string]interface{}{"x5t#S256": "x5t-value"}, session.JWTClaims.Extra["cnf"])
+	session.SetCertificateThumbprint("")
+	assert.NotContains(t, session.JWTClaims.Extra, "cnf")
+}
+
+func TestAccessTokenAuthorizationDetailsClaim(t *testing.T) {
+	details := fosite.AuthorizationDetails{{Type: "payment_initiation", Actions: []string{"initiate"}, Extra: map[string]interface{}{"creditorName": "Merchant A"}}}
+	r := jwtValidCase(fosite.AccessToken)
+	r.GrantAuthorizationDetails(details)
+
+	j.Config = &fosite.Config{}
+	token, _, err := j.GenerateAccessToken(context.Background(), r)
+	require.NoError(t, err)
+
+	parsed, err := j.Decode(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "payment_initiation", "actions": []interface{}{"initiate"}, "creditorName": "Merchant A"}}, parsed.Claims["authorization_details"])
+
+	requester := AccessTokenJWTToRequest(parsed)
+	assert.Equal(t, details, requester.GetGrantedAuthorizationDetails())
+}
+
+type authenticatedJWTSession struct {
+	*JWTSession
+	acr      string
+	authTime time.Time
+}
+
+func (s *authenticatedJWTSession) GetAuthenticationContextClassReference() string { return s.acr }
+func (s *authenticatedJWTSession) GetAuthenticationMethodsReferences() []string   { return nil }
+func (s *authenticatedJWTSession) GetAuthTime() time.Time                         { return s.authTime }
+
+func TestAccessTokenAuthenticationClaims(t *testing.T) {
+	r := jwtValidCase(fosite.AccessToken)
+	r.Session = &authenticatedJWTSession{JWTSession: r.GetSession().(*JWTSession), acr: "silver", authTime: time.Unix(1700000000, 0)}
+
+	j.Config = &fosite.Config{}
+	token, _, err := j.GenerateAccessToken(context.Background(), r)
+	require.NoError(t, err)
+
+	parsed, err := j.Decode(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "silver", parsed.Claims["acr"])
+	assert.EqualValues(t, 1700000000, parsed.Claims["auth_time"])
+	assert.NotContains(t, parsed.Claims, "amr")
+}
+
+func TestAccessTokenPairwiseSubject(t *testing.T) {
+	config := &fosite.Config{PairwiseSubjectAlgorithm: &fosite.HMACPairwiseSubjectAlgorithm{Salt: []byte("salt")}}
+	client := &fosite.DefaultOpenIDConnectClient{
+		DefaultClient: &fosite.DefaultClient{ID: "foo", RedirectURIs: []string{"https://client.example.com/cb"}},
+		SubjectType:   fosite.SubjectTypePairwise,
+	}
+	pairwise, err := fosite.PairwiseSubject(context.Background(), config, client, "peter")
+	require.NoError(t, err)
+
+	r := jwtValidCase(fosite.AccessToken)
+	r.Client = client
+
+	j.Config = config
+	token, _, err := j.GenerateAccessToken(context.Background(), r)
+	require.NoError(t, err)
+
+	parsed, err := j.Decode(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, pairwise, parsed.Claims["sub"])
+	assert.Equal(t, "peter", r.GetSession().(*JWTSession).JWTClaims.Subject)
+}
+
+func TestJWTProfileAccessToken(t *testing.T) {
+	ctx := context.Background()
+	config := &fosite.Config{UseJWTProfileAccessTokens: true}
+	strategy := &DefaultJWTStrategy{Signer: j.Signer, Config: config}
+	validator := &StatelessJWTValidator{Signer: j.Signer, Config: config}
+
+	r := jwtValidCase(fosite.AccessToken)
+	r.Client.(*fosite.DefaultClient).ID = "foo"
+	claims := r.Session.(*JWTSession).JWTClaims
+	claims.AuthTime = time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	claims.AuthenticationContextClassReference = "urn:mace:incommon:iap:silver"
+	claims.Groups = []string{"admins"}
+
+	token, _, err := strategy.GenerateAccessToken(ctx, r)
+	require.NoError(t, err)
+	require.NoError(t, strategy.ValidateAccessToken(ctx, r, token))
+
+	parsed, err := j.Decode(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, JWTProfileAccessTokenType, parsed.Header["typ"])
+	assert.Equal(t, "foo", parsed.Claims["client_id"])
+	assert.Equal(t, "peter", parsed.Claims["sub"])
+	assert.Equal(t, "email offline", parsed.Claims["scope"])
+	assert.NotContains(t, parsed.Claims, "scp")
+	assert.Equal(t, "urn:mace:incommon:iap:silver", parsed.Claims["acr"])
+	assert.Equal(t, []interface{}{"admins"}, parsed.Claims["groups"])
+
+	requester := AccessTokenJWTToRequest(parsed)
+	assert.Equal(t, claims.AuthTime, requester.GetSession().(*JWTSession).JWTClaims.AuthTime)
+	assert.Equal(t, []string{"admins"}, requester.GetSession().(*JWTSession).JWTClaims.Groups)
+
+	_, err = validator.IntrospectToken(ctx, token, fosite.AccessToken, fosite.NewAccessRequest(new(JWTSession)), []string{})
+	require.NoError(t, err)
+
+	t.Run("case=should use the client as subject and the default audience", func(t *testing.T) {
+		r := jwtValidCase(fosite.AccessToken)
+		r.Client.(*fosite.DefaultClient).ID = "foo"
+		r.Session.(*JWTSession).JWTClaims.Subject = ""
+		r.GrantedAudience = fosite.Arguments{}
+
+		_, _, err := strategy.GenerateAccessToken(ctx, r)
+		require.Error(t, err)
+
+		strategy := &DefaultJWTStrategy{Signer: j.Signer, Config: &fosite.Config{UseJWTProfileAccessTokens: true, JWTProfileAccessTokenDefaultAudience: []string{"https://api.example.com"}}}
+		token, _, err := strategy.GenerateAccessToken(ctx, r)
+		require.NoError(t, err)
+
+		parsed, err := j.Decode(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", parsed.Claims["sub"])
+		assert.Equal(t, []interface{}{"https://api.example.com"}, parsed.Claims["aud"])
+	})
+
+	t.Run("case=should reject tokens of another type", func(t *testing.T) {
+		token, _, err := j.GenerateAccessToken(ctx, jwtValidCase(fosite.AccessToken))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, strategy.ValidateAccessToken(ctx, r, token), fosite.ErrInvalidTokenFormat)
+		_, err = validator.IntrospectToken(ctx, token, fosite.AccessToken, fosite.NewAccessRequest(new(JWTSession)), []string{})
+		assert.ErrorIs(t, err, fosite.ErrInvalidTokenFormat)
+	})
+}
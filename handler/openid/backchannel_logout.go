@@ -0,0 +1,114 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package openid
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite"
+)
+
+// defaultBackChannelLogoutConcurrency is the default number of clients notified concurrently.
+const defaultBackChannelLogoutConcurrency = 10
+
+// BackChannelLogoutResult is the result of notifying a client about a logout.
+type BackChannelLogoutResult struct {
+	// ClientID is the ID of the notified client.
+	ClientID string
+
+	// Error is nil if the client acknowledged the logout token.
+	Error error
+}
+
+// BackChannelLogoutDispatcher notifies clients about ended sessions by sending logout tokens to their
+// backchannel_logout_uri, see https://openid.net/specs/openid-connect-backchannel-1_0.html#BCRequest
+//
+// Failed requests are retried according to the retry policy of the HTTP client returned by GetHTTPClient. The
+// default policy retries connection errors and server errors with exponential backoff, but not client errors, with
+// which clients reject logout tokens.
+type BackChannelLogoutDispatcher struct {
+	Strategy LogoutTokenStrategy
+
+	// MaxConcurrency is the maximum number of clients notified concurrently. Defaults to 10.
+	MaxConcurrency int
+
+	Config interface {
+		fosite.HTTPClientProvider
+	}
+}
+
+// Dispatch notifies all clients implementing fosite.BackChannelLogoutClient which registered a back-channel logout
+// URI that the session identified by the subject and session ID ended. It returns a result for each notified client
+// in the order of the given clients.
+func (d *BackChannelLogoutDispatcher) Dispatch(ctx context.Context, subject, sessionID string, clients []fosite.Client) []BackChannelLogoutResult {
+	var targets []fosite.BackChannelLogoutClient
+	for _, client := range clients {
+		if c, ok := client.(fosite.BackChannelLogoutClient); ok && c.GetBackChannelLogoutURI() != "" {
+			targets = append(targets, c)
+		}
+	}
+
+	concurrency := d.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBackChannelLogoutConcurrency
+	}
+
+	results := make([]BackChannelLogoutResult, len(targets))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, client := range targets {
+		results[i].ClientID = client.GetID()
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Error = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, client fosite.BackChannelLogoutClient) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i].Error = d.notify(ctx, client, subject, sessionID)
+		}(i, client)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (d *BackChannelLogoutDispatcher) notify(ctx context.Context, client fosite.BackChannelLogoutClient, subject, sessionID string) error {
+	token, err := d.Strategy.GenerateLogoutToken(ctx, client, subject, sessionID)
+	if err != nil {
+		return err
+	}
+
+	body := url.Values{"logout_token": {token}}.Encode()
+	req, err := retryablehttp.NewRequestWithContext(ctx, "POST", client.GetBackChannelLogoutURI(), strings.NewReader(body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := d.Config.GetHTTPClient(ctx).Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("the back-channel logout endpoint of client '%s' responded with status code %d", client.GetID(), resp.StatusCode)
+	}
+	return nil
+}
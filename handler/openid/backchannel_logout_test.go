@@ -0,0 +1,142 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package openid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestBackChannelLogout(t *testing.T) {
+	ctx := context.Background()
+	strategy := &DefaultStrategy{
+		Signer: &jwt.DefaultSigner{
+			GetPrivateKey: func(_ context.Context) (interface{}, error) {
+				return key, nil
+			}},
+		Config: &fosite.Config{IDTokenIssuer: "https://auth.example.com"},
+	}
+
+	newClient := func(id, uri string, sessionRequired bool) *fosite.DefaultOpenIDConnectClient {
+		return &fosite.DefaultOpenIDConnectClient{
+			DefaultClient:                    &fosite.DefaultClient{ID: id},
+			BackChannelLogoutURI:             uri,
+			BackChannelLogoutSessionRequired: sessionRequired,
+		}
+	}
+
+	t.Run("case=should generate logout tokens", func(t *testing.T) {
+		token, err := strategy.GenerateLogoutToken(ctx, newClient("foo", "", true), "peter", "session-id")
+		require.NoError(t, err)
+
+		decoded, err := strategy.Decode(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, LogoutTokenType, decoded.Header["typ"])
+		assert.Equal(t, "peter", decoded.Claims["sub"])
+		assert.Equal(t, "session-id", decoded.Claims["sid"])
+		assert.Equal(t, "https://auth.example.com", decoded.Claims["iss"])
+		assert.Equal(t, []interface{}{"foo"}, decoded.Claims["aud"])
+		assert.Equal(t, map[string]interface{}{jwt.BackChannelLogoutEvent: map[string]interface{}{}}, decoded.Claims["events"])
+		assert.NotEmpty(t, decoded.Claims["jti"])
+		assert.NotContains(t, decoded.Claims, "nonce")
+
+		_, err = strategy.GenerateLogoutToken(ctx, newClient("foo", "", false), "", "")
+		require.Error(t, err)
+		_, err = strategy.GenerateLogoutToken(ctx, newClient("foo", "", true), "peter", "")
+		require.Error(t, err)
+	})
+
+	t.Run("case=should notify the clients", func(t *testing.T) {
+		var mu sync.Mutex
+		received := map[string]string{}
+		var attempts, inFlight, maxInFlight int32
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			switch r.URL.Path {
+			case "/flaky":
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					rw.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+			case "/reject":
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+			mu.Lock()
+			received[r.URL.Path] = r.PostFormValue("logout_token")
+			mu.Unlock()
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		hc := retryablehttp.NewClient()
+		hc.RetryMax = 2
+		hc.RetryWaitMin = time.Millisecond
+		hc.RetryWaitMax = time.Millisecond
+		dispatcher := &BackChannelLogoutDispatcher{
+			Strategy:       strategy,
+			MaxConcurrency: 2,
+			Config:         &fosite.Config{HTTPClient: hc},
+		}
+
+		results := dispatcher.Dispatch(ctx, "peter", "session-id", []fosite.Client{
+			newClient("ok", server.URL+"/ok", false),
+			&fosite.DefaultClient{ID: "no-back-channel"},
+			newClient("no-uri", "", false),
+			newClient("flaky", server.URL+"/flaky", false),
+			newClient("reject", server.URL+"/reject", false),
+			newClient("other", server.URL+"/other", true),
+		})
+
+		require.Len(t, results, 4)
+		for k, tc := range []struct {
+			clientID  string
+			expectErr bool
+		}{
+			{clientID: "ok"},
+			{clientID: "flaky"},
+			{clientID: "reject", expectErr: true},
+			{clientID: "other"},
+		} {
+			assert.Equal(t, tc.clientID, results[k].ClientID)
+			if tc.expectErr {
+				assert.Error(t, results[k].Error, "%d", k)
+			} else {
+				assert.NoError(t, results[k].Error, "%d", k)
+			}
+		}
+
+		assert.Len(t, received, 3)
+		assert.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+
+		decoded, err := strategy.Decode(ctx, received["/other"])
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"other"}, decoded.Claims["aud"])
+		assert.Equal(t, "session-id", decoded.Claims["sid"])
+	})
+}
@@ -25,6 +25,7 @@ type OpenIDConnectExplicitHandler struct {
 
 var _ fosite.AuthorizeEndpointHandler = (*OpenIDConnectExplicitHandler)(nil)
 var _ fosite.TokenEndpointHandler = (*OpenIDConnectExplicitHandler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*OpenIDConnectExplicitHandler)(nil)
 
 var oidcParameters = []string{"grant_type",
 	"max_age",
@@ -59,3 +60,11 @@ func (c *OpenIDConnectExplicitHandler) HandleAuthorizeEndpointRequest(ctx contex
 
 	return nil
 }
+
+// PopulateAuthorizationServerMetadata adds the OpenID Connect authorization code flow.
+func (c *OpenIDConnectExplicitHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddScopes("openid")
+	metadata.AddSubjectTypes("public")
+	metadata.AddResponseTypes("code")
+	metadata.AddGrantTypes("authorization_code")
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/ory/fosite/token/jwt"
 )
 
+var _ fosite.AuthorizationServerMetadataHandler = (*OpenIDConnectHybridHandler)(nil)
+
 type OpenIDConnectHybridHandler struct {
 	AuthorizeImplicitGrantTypeHandler *oauth2.AuthorizeImplicitGrantTypeHandler
 	AuthorizeExplicitGrantHandler     *oauth2.AuthorizeExplicitGrantHandler
@@ -154,3 +156,11 @@ func (c *OpenIDConnectHybridHandler) HandleAuthorizeEndpointRequest(ctx context.
 	// there is no need to check for https, because implicit flow does not require https
 	// https://tools.ietf.org/html/rfc6819#section-4.4.2
 }
+
+// PopulateAuthorizationServerMetadata adds the OpenID Connect hybrid flow.
+func (c *OpenIDConnectHybridHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddScopes("openid")
+	metadata.AddSubjectTypes("public")
+	metadata.AddResponseTypes("code id_token", "code token", "code id_token token")
+	metadata.AddGrantTypes("authorization_code", "implicit")
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/ory/fosite/token/jwt"
 )
 
+var _ fosite.AuthorizationServerMetadataHandler = (*OpenIDConnectImplicitHandler)(nil)
+
 type OpenIDConnectImplicitHandler struct {
 	*IDTokenHandleHelper
 
@@ -98,3 +100,11 @@ func (c *OpenIDConnectImplicitHandler) HandleAuthorizeEndpointRequest(ctx contex
 	ar.SetResponseTypeHandled("id_token")
 	return nil
 }
+
+// PopulateAuthorizationServerMetadata adds the OpenID Connect implicit flow.
+func (c *OpenIDConnectImplicitHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddScopes("openid")
+	metadata.AddSubjectTypes("public")
+	metadata.AddResponseTypes("id_token", "id_token token")
+	metadata.AddGrantTypes("implicit")
+}
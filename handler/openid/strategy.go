@@ -13,3 +13,10 @@ import (
 type OpenIDConnectTokenStrategy interface {
 	GenerateIDToken(ctx context.Context, lifespan time.Duration, requester fosite.Requester) (token string, err error)
 }
+
+// LogoutTokenStrategy generates the logout tokens of OpenID Connect Back-Channel Logout.
+type LogoutTokenStrategy interface {
+	// GenerateLogoutToken returns a logout token which notifies the client that the session identified by the subject
+	// and session ID ended.
+	GenerateLogoutToken(ctx context.Context, client fosite.Client, subject, sessionID string) (token string, err error)
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/go-jose/go-jose/v3"
 	"github.com/ory/x/errorsx"
 
 	"github.com/mohae/deepcopy"
@@ -20,6 +21,13 @@ import (
 
 const defaultExpiryTime = time.Hour
 
+// defaultLogoutTokenLifespan is short, because logout tokens are sent immediately.
+const defaultLogoutTokenLifespan = 2 * time.Minute
+
+// LogoutTokenType is the "typ" header of logout tokens, see
+// https://openid.net/specs/openid-connect-backchannel-1_0.html#LogoutToken
+const LogoutTokenType = "logout+jwt"
+
 type Session interface {
 	// IDTokenClaims returns a pointer to claims which will be modified in-place by handlers.
 	// Session should store this pointer and return always the same pointer.
@@ -38,6 +46,15 @@ type DefaultSession struct {
 	ExpiresAt map[fosite.TokenType]time.Time `json:"expires_at"`
 	Username  string                         `json:"username"`
 	Subject   string                         `json:"subject"`
+
+	// DPoPJWKThumbprint is the JWK SHA-256 thumbprint of the DPoP key the tokens are bound to.
+	DPoPJWKThumbprint string `json:"dpop_jkt,omitempty"`
+
+	// CertificateThumbprint is the SHA-256 thumbprint of the client certificate the tokens are bound to.
+	CertificateThumbprint string `json:"x5t_s256,omitempty"`
+
+	// RequestedClaims are the claims requested through the claims parameter of the authorization request.
+	RequestedClaims *fosite.ClaimsRequest `json:"requested_claims,omitempty"`
 }
 
 func NewDefaultSession() *DefaultSession {
@@ -49,6 +66,60 @@ func NewDefaultSession() *DefaultSession {
 	}
 }
 
+func (s *DefaultSession) SetDPoPJWKThumbprint(jkt string) {
+	s.DPoPJWKThumbprint = jkt
+}
+
+func (s *DefaultSession) GetDPoPJWKThumbprint() string {
+	if s == nil {
+		return ""
+	}
+	return s.DPoPJWKThumbprint
+}
+
+func (s *DefaultSession) SetCertificateThumbprint(x5t string) {
+	s.CertificateThumbprint = x5t
+}
+
+func (s *DefaultSession) GetCertificateThumbprint() string {
+	if s == nil {
+		return ""
+	}
+	return s.CertificateThumbprint
+}
+
+func (s *DefaultSession) SetRequestedClaims(claims *fosite.ClaimsRequest) {
+	s.RequestedClaims = claims
+}
+
+func (s *DefaultSession) GetRequestedClaims() *fosite.ClaimsRequest {
+	if s == nil {
+		return nil
+	}
+	return s.RequestedClaims
+}
+
+func (s *DefaultSession) GetAuthenticationContextClassReference() string {
+	if s == nil || s.Claims == nil {
+		return ""
+	}
+	return s.Claims.AuthenticationContextClassReference
+}
+
+func (s *DefaultSession) GetAuthenticationMethodsReferences() []string {
+	if s == nil || s.Claims == nil {
+		return nil
+	}
+	return s.Claims.AuthenticationMethodsReferences
+}
+
+func (s *DefaultSession) GetAuthTime() time.Time {
+	if s == nil || s.Claims == nil {
+		return time.Time{}
+	}
+	return s.Claims.AuthTime
+}
+
 func (s *DefaultSession) Clone() fosite.Session {
 	if s == nil {
 		return nil
@@ -115,6 +186,7 @@ type DefaultStrategy struct {
 		fosite.IDTokenIssuerProvider
 		fosite.IDTokenLifespanProvider
 		fosite.MinParameterEntropyProvider
+		fosite.JWKSFetcherStrategyProvider
 	}
 }
 
@@ -136,6 +208,17 @@ func (h DefaultStrategy) GenerateIDToken(ctx context.Context, lifespan time.Dura
 		return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to generate id token because subject is an empty string."))
 	}
 
+	// Clients using pairwise subject identifiers receive them in the ID token and in turn use them in id_token_hint.
+	subject, err := fosite.PairwiseSubject(ctx, h.Config, requester.GetClient(), claims.Subject)
+	if err != nil {
+		return "", err
+	}
+
+	// Clients which require the session ID for logout match it against the sid claim of their ID tokens.
+	if claims.SessionID == "" && requiresSessionID(requester.GetClient()) {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to generate id token because the OAuth 2.0 Client requires the session ID but the sid claim is empty."))
+	}
+
 	if requester.GetRequestForm().Get("grant_type") != "refresh_token" {
 		maxAge, err := strconv.ParseInt(requester.GetRequestForm().Get("max_age"), 10, 64)
 		if err != nil {
@@ -194,7 +277,7 @@ func (h DefaultStrategy) GenerateIDToken(ctx context.Context, lifespan time.Dura
 
 			if hintSub, _ := tokenHint.Claims["sub"].(string); hintSub == "" {
 				return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Provided id token from 'id_token_hint' does not have a subject."))
-			} else if hintSub != claims.Subject {
+			} else if hintSub != subject {
 				return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Subject from authorization mismatches id token subject from 'id_token_hint'."))
 			}
 		}
@@ -228,6 +311,112 @@ func (h DefaultStrategy) GenerateIDToken(ctx context.Context, lifespan time.Dura
 	claims.Audience = stringslice.Unique(append(claims.Audience, requester.GetClient().GetID()))
 	claims.IssuedAt = time.Now().UTC()
 
-	token, _, err = h.Signer.Generate(ctx, claims.ToMapClaims(), sess.IDTokenHeaders())
+	idTokenClaims := *claims
+	idTokenClaims.Subject = subject
+	if requested := fosite.GetRequestedClaims(sess); requested != nil {
+		if err := validateRequestedClaims(&idTokenClaims, requested.IDToken); err != nil {
+			return "", err
+		}
+		idTokenClaims.Extra = requested.IDToken.Filter(claims.Extra)
+	}
+
+	signer, err := h.idTokenSigner(ctx, requester.GetClient())
+	if err != nil {
+		return "", err
+	}
+
+	token, _, err = signer.Generate(ctx, idTokenClaims.ToMapClaims(), sess.IDTokenHeaders())
 	return token, err
 }
+
+// validateRequestedClaims enforces the sub claim requested with a value and the essential acr claim requested with
+// values, see https://openid.net/specs/openid-connect-core-1_0.html#IndividualClaimsRequests
+func validateRequestedClaims(claims *jwt.IDTokenClaims, requested fosite.ClaimRequests) error {
+	if !requested["sub"].Matches(claims.Subject) {
+		return errorsx.WithStack(fosite.ErrAccessDenied.WithHint("The subject of the session does not match the subject requested through the 'claims' parameter."))
+	}
+
+	if acr := requested["acr"]; acr.IsEssential() && !acr.Matches(claims.AuthenticationContextClassReference) {
+		return errorsx.WithStack(fosite.ErrUnmetAuthenticationRequirements.WithHintf("The authentication context class reference '%s' does not satisfy the essential 'acr' claim requested through the 'claims' parameter.", claims.AuthenticationContextClassReference))
+	}
+
+	return nil
+}
+
+// idTokenSigner returns the signer of ID tokens sent to the client, which encrypts them to a key of the client if
+// its metadata asks for encrypted ID tokens.
+func (h DefaultStrategy) idTokenSigner(ctx context.Context, client fosite.Client) (jwt.Signer, error) {
+	c, ok := client.(fosite.IDTokenEncryptionClient)
+	if !ok || c.GetIDTokenEncryptedResponseAlgorithm() == "" {
+		return h.Signer, nil
+	}
+
+	alg := jose.KeyAlgorithm(c.GetIDTokenEncryptedResponseAlgorithm())
+	var key *jose.JSONWebKey
+	if set := c.GetJSONWebKeys(); set != nil {
+		key = jwt.FindEncryptionKey(set, alg)
+	} else if location := c.GetJSONWebKeysURI(); location != "" {
+		for _, forceRefresh := range []bool{false, true} {
+			set, err := h.Config.GetJWKSFetcherStrategy(ctx).Resolve(ctx, location, forceRefresh)
+			if err != nil {
+				return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebugf("Unable to fetch the JSON Web Keys of the OAuth 2.0 Client to encrypt the id token: %s", err))
+			} else if key = jwt.FindEncryptionKey(set, alg); key != nil {
+				break
+			}
+		}
+	}
+
+	if key == nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithDebugf("Failed to generate id token because the OAuth 2.0 Client has no JSON Web Key registered which can be used with encryption algorithm '%s'.", alg))
+	}
+
+	return &jwt.EncryptingSigner{
+		Signer:            h.Signer,
+		EncryptionKey:     key,
+		KeyAlgorithm:      alg,
+		ContentEncryption: jose.ContentEncryption(c.GetIDTokenEncryptedResponseEncryption()),
+	}, nil
+}
+
+// GenerateLogoutToken returns a signed logout token. Either the subject or the session ID must be set, and the
+// session ID is required if the client is a fosite.BackChannelLogoutClient requiring it.
+func (h DefaultStrategy) GenerateLogoutToken(ctx context.Context, client fosite.Client, subject, sessionID string) (token string, err error) {
+	if subject == "" && sessionID == "" {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to generate logout token because neither subject nor session ID is set."))
+	}
+
+	if c, ok := client.(fosite.BackChannelLogoutClient); ok && c.GetBackChannelLogoutSessionRequired() && sessionID == "" {
+		return "", errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to generate logout token because the OAuth 2.0 Client requires a session ID."))
+	}
+
+	if subject, err = fosite.PairwiseSubject(ctx, h.Config, client, subject); err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	claims := &jwt.LogoutTokenClaims{
+		Issuer:    h.Config.GetIDTokenIssuer(ctx),
+		Subject:   subject,
+		SessionID: sessionID,
+		Audience:  []string{client.GetID()},
+		IssuedAt:  now,
+		ExpiresAt: now.Add(defaultLogoutTokenLifespan),
+	}
+
+	headers := jwt.NewHeaders()
+	headers.Add(string(jwt.JWTHeaderType), LogoutTokenType)
+
+	token, _, err = h.Signer.Generate(ctx, claims.ToMapClaims(), headers)
+	return token, err
+}
+
+// requiresSessionID returns true if the client requires the sid claim for front-channel or back-channel logout.
+func requiresSessionID(client fosite.Client) bool {
+	if c, ok := client.(fosite.FrontChannelLogoutClient); ok && c.GetFrontChannelLogoutSessionRequired() {
+		return true
+	}
+	if c, ok := client.(fosite.BackChannelLogoutClient); ok && c.GetBackChannelLogoutSessionRequired() {
+		return true
+	}
+	return false
+}
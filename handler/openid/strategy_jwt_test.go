@@ -9,9 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-jose/go-jose/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/internal/gen"
 	"github.com/ory/fosite/token/jwt"
 )
 
@@ -272,6 +275,34 @@ func TestJWTStrategy_GenerateIDToken(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			description: "should fail because the client requires the session ID",
+			setup: func() {
+				req = fosite.NewAccessRequest(&DefaultSession{
+					Claims:  &jwt.IDTokenClaims{Subject: "peter"},
+					Headers: &jwt.Headers{},
+				})
+				req.Client = &fosite.DefaultOpenIDConnectClient{
+					DefaultClient:                     &fosite.DefaultClient{ID: "foo"},
+					FrontChannelLogoutSessionRequired: true,
+				}
+			},
+			expectErr: true,
+		},
+		{
+			description: "should pass because the session ID is set",
+			setup: func() {
+				req = fosite.NewAccessRequest(&DefaultSession{
+					Claims:  &jwt.IDTokenClaims{Subject: "peter", SessionID: "session-id"},
+					Headers: &jwt.Headers{},
+				})
+				req.Client = &fosite.DefaultOpenIDConnectClient{
+					DefaultClient:                     &fosite.DefaultClient{ID: "foo"},
+					FrontChannelLogoutSessionRequired: true,
+				}
+			},
+			expectErr: false,
+		},
 	} {
 		t.Run(fmt.Sprintf("case=%d/description=%s", k, c.description), func(t *testing.T) {
 			c.setup()
@@ -283,3 +314,141 @@ func TestJWTStrategy_GenerateIDToken(t *testing.T) {
 		})
 	}
 }
+
+func TestJWTStrategy_GenerateEncryptedIDToken(t *testing.T) {
+	ctx := context.Background()
+	clientKey := gen.MustRSAKey()
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return key, nil }}
+	j := &DefaultStrategy{Signer: signer, Config: &fosite.Config{MinParameterEntropy: fosite.MinParameterEntropy}}
+
+	newRequest := func(client fosite.Client) *fosite.AccessRequest {
+		req := fosite.NewAccessRequest(&DefaultSession{
+			Claims:  &jwt.IDTokenClaims{Subject: "peter"},
+			Headers: &jwt.Headers{},
+		})
+		req.Client = client
+		return req
+	}
+	newClient := func(alg, enc string, keys ...jose.JSONWebKey) *fosite.DefaultOpenIDConnectClient {
+		return &fosite.DefaultOpenIDConnectClient{
+			DefaultClient:                      &fosite.DefaultClient{ID: "foo"},
+			JSONWebKeys:                        &jose.JSONWebKeySet{Keys: keys},
+			IDTokenEncryptedResponseAlgorithm:  alg,
+			IDTokenEncryptedResponseEncryption: enc,
+		}
+	}
+	encryptionKey := jose.JSONWebKey{Key: &clientKey.PublicKey, KeyID: "enc", Use: "enc"}
+
+	t.Run("case=should sign the id token if the client does not ask for encryption", func(t *testing.T) {
+		token, err := j.GenerateIDToken(ctx, time.Hour, newRequest(newClient("", "", encryptionKey)))
+		require.NoError(t, err)
+		assert.False(t, jwt.IsEncrypted(token))
+	})
+
+	t.Run("case=should encrypt the id token to the key of the client", func(t *testing.T) {
+		token, err := j.GenerateIDToken(ctx, time.Hour, newRequest(newClient("RSA-OAEP-256", "A256GCM", encryptionKey)))
+		require.NoError(t, err)
+		require.True(t, jwt.IsEncrypted(token))
+
+		jwe, err := jose.ParseEncrypted(token)
+		require.NoError(t, err)
+		assert.Equal(t, "RSA-OAEP-256", jwe.Header.Algorithm)
+		assert.Equal(t, "enc", jwe.Header.KeyID)
+		assert.EqualValues(t, "A256GCM", jwe.Header.ExtraHeaders["enc"])
+
+		decoder := &jwt.EncryptingSigner{Signer: signer, Decrypter: &jwt.DefaultDecrypter{GetPrivateKeys: func(context.Context) (*jose.JSONWebKeySet, error) {
+			return &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: clientKey, KeyID: "enc", Use: "enc"}}}, nil
+		}}}
+		decoded, err := decoder.Decode(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, "peter", decoded.Claims["sub"])
+	})
+
+	t.Run("case=should fail because the client has no suitable encryption key", func(t *testing.T) {
+		_, err := j.GenerateIDToken(ctx, time.Hour, newRequest(newClient("ECDH-ES", "", encryptionKey)))
+		assert.ErrorIs(t, err, fosite.ErrServerError)
+	})
+}
+
+func TestJWTStrategy_GenerateIDTokenWithRequestedClaims(t *testing.T) {
+	ctx := context.Background()
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return key, nil }}
+	j := &DefaultStrategy{Signer: signer, Config: &fosite.Config{MinParameterEntropy: fosite.MinParameterEntropy}}
+
+	newRequest := func(raw string) *fosite.AccessRequest {
+		requested, err := fosite.ParseClaimsRequest(raw)
+		require.NoError(t, err)
+
+		session := &DefaultSession{
+			Claims: &jwt.IDTokenClaims{
+				Subject:                             "peter",
+				AuthenticationContextClassReference: "urn:mace:incommon:iap:bronze",
+				Extra:                               map[string]interface{}{"locale": "en", "email": "peter@example.org"},
+			},
+			Headers: &jwt.Headers{},
+		}
+		session.SetRequestedClaims(requested)
+
+		req := fosite.NewAccessRequest(session)
+		req.Client = &fosite.DefaultClient{ID: "foo"}
+		return req
+	}
+
+	t.Run("case=should drop claims which do not match the requested value", func(t *testing.T) {
+		token, err := j.GenerateIDToken(ctx, time.Hour, newRequest(`{"id_token":{"locale":{"value":"de"},"email":null}}`))
+		require.NoError(t, err)
+
+		decoded, err := signer.Decode(ctx, token)
+		require.NoError(t, err)
+		assert.NotContains(t, decoded.Claims, "locale")
+		assert.Equal(t, "peter@example.org", decoded.Claims["email"])
+	})
+
+	t.Run("case=should fail because the subject does not match", func(t *testing.T) {
+		_, err := j.GenerateIDToken(ctx, time.Hour, newRequest(`{"id_token":{"sub":{"value":"alice"}}}`))
+		assert.ErrorIs(t, err, fosite.ErrAccessDenied)
+	})
+
+	t.Run("case=should fail because the essential acr is not satisfied", func(t *testing.T) {
+		_, err := j.GenerateIDToken(ctx, time.Hour, newRequest(`{"id_token":{"acr":{"essential":true,"values":["urn:mace:incommon:iap:silver"]}}}`))
+		assert.ErrorIs(t, err, fosite.ErrUnmetAuthenticationRequirements)
+	})
+
+	t.Run("case=should pass if a voluntary acr is not satisfied", func(t *testing.T) {
+		_, err := j.GenerateIDToken(ctx, time.Hour, newRequest(`{"id_token":{"acr":{"values":["urn:mace:incommon:iap:silver"]}}}`))
+		assert.NoError(t, err)
+	})
+}
+
+func TestJWTStrategy_GenerateIDTokenWithPairwiseSubject(t *testing.T) {
+	ctx := context.Background()
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return key, nil }}
+	config := &fosite.Config{
+		MinParameterEntropy:      fosite.MinParameterEntropy,
+		PairwiseSubjectAlgorithm: &fosite.HMACPairwiseSubjectAlgorithm{Salt: []byte("salt")},
+	}
+	j := &DefaultStrategy{Signer: signer, Config: config}
+
+	client := &fosite.DefaultOpenIDConnectClient{
+		DefaultClient: &fosite.DefaultClient{ID: "foo", RedirectURIs: []string{"https://client.example.com/cb"}},
+		SubjectType:   fosite.SubjectTypePairwise,
+	}
+	pairwise, err := fosite.PairwiseSubject(ctx, config, client, "peter")
+	require.NoError(t, err)
+
+	req := fosite.NewAccessRequest(&DefaultSession{Claims: &jwt.IDTokenClaims{Subject: "peter"}, Headers: &jwt.Headers{}})
+	req.Client = client
+	token, err := j.GenerateIDToken(ctx, time.Hour, req)
+	require.NoError(t, err)
+
+	decoded, err := signer.Decode(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, pairwise, decoded.Claims["sub"])
+	assert.Equal(t, "peter", req.GetSession().(*DefaultSession).Claims.Subject)
+
+	token, err = j.GenerateLogoutToken(ctx, client, "peter", "")
+	require.NoError(t, err)
+	decoded, err = signer.Decode(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, pairwise, decoded.Claims["sub"])
+}
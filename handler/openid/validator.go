@@ -112,6 +112,22 @@ func (v *OpenIDConnectRequestValidator) ValidatePrompt(ctx context.Context, req
 		}
 	}
 
+	// The client knows the end-user by the subject identifier of its ID tokens, which may be pairwise.
+	subject, err := fosite.PairwiseSubject(ctx, v.Config, req.GetClient(), claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	// An essential acr claim must be satisfied by the authentication, whereas the acr_values parameter is only
+	// voluntary, see https://openid.net/specs/openid-connect-core-1_0.html#acrSemantics
+	if requested := req.GetRequestedClaims(); requested != nil {
+		clientClaims := *claims
+		clientClaims.Subject = subject
+		if err := validateRequestedClaims(&clientClaims, requested.IDToken); err != nil {
+			return err
+		}
+	}
+
 	if stringslice.Has(requiredPrompt, "none") {
 		if claims.AuthTime.IsZero() {
 			return errorsx.WithStack(fosite.ErrServerError.WithDebug("Failed to validate OpenID Connect request because because auth_time is missing from session."))
@@ -143,7 +159,7 @@ func (v *OpenIDConnectRequestValidator) ValidatePrompt(ctx context.Context, req
 
 	if hintSub, _ := tokenHint.Claims["sub"].(string); hintSub == "" {
 		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Failed to validate OpenID Connect request because provided id token from id_token_hint does not have a subject."))
-	} else if hintSub != claims.Subject {
+	} else if hintSub != subject {
 		return errorsx.WithStack(fosite.ErrLoginRequired.WithHint("Failed to validate OpenID Connect request because the subject from provided id token from id_token_hint does not match the current session's subject."))
 	}
 
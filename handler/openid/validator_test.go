@@ -46,6 +46,7 @@ func TestValidatePrompt(t *testing.T) {
 		isPublic    bool
 		expectErr   bool
 		idTokenHint string
+		claims      *fosite.ClaimsRequest
 		s           *DefaultSession
 	}{
 		{
@@ -248,6 +249,34 @@ func TestValidatePrompt(t *testing.T) {
 				ExpiresAt:   time.Now().Add(time.Hour),
 			}),
 		},
+		{
+			d:         "should fail because the essential acr is not satisfied",
+			expectErr: true,
+			claims:    &fosite.ClaimsRequest{IDToken: fosite.ClaimRequests{"acr": {Essential: true, Values: []interface{}{"gold"}}}},
+			s: &DefaultSession{
+				Subject: "foo",
+				Claims: &jwt.IDTokenClaims{
+					Subject:                             "foo",
+					RequestedAt:                         time.Now().UTC(),
+					AuthTime:                            time.Now().UTC().Add(-time.Second),
+					AuthenticationContextClassReference: "silver",
+				},
+			},
+		},
+		{
+			d:         "should pass because the essential acr is satisfied",
+			expectErr: false,
+			claims:    &fosite.ClaimsRequest{IDToken: fosite.ClaimRequests{"acr": {Essential: true, Values: []interface{}{"gold", "silver"}}}},
+			s: &DefaultSession{
+				Subject: "foo",
+				Claims: &jwt.IDTokenClaims{
+					Subject:                             "foo",
+					RequestedAt:                         time.Now().UTC(),
+					AuthTime:                            time.Now().UTC().Add(-time.Second),
+					AuthenticationContextClassReference: "silver",
+				},
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
 			t.Logf("%s", tc.idTokenHint)
@@ -257,7 +286,8 @@ func TestValidatePrompt(t *testing.T) {
 					Client:  &fosite.DefaultClient{Public: tc.isPublic},
 					Session: tc.s,
 				},
-				RedirectURI: parse(tc.redirectURL),
+				RedirectURI:     parse(tc.redirectURL),
+				RequestedClaims: tc.claims,
 			})
 			if tc.expectErr {
 				assert.Error(t, err)
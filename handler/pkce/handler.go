@@ -30,6 +30,7 @@ type Handler struct {
 }
 
 var _ fosite.TokenEndpointHandler = (*Handler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*Handler)(nil)
 
 var verifierWrongFormat = regexp.MustCompile("[^\\w\\.\\-~]")
 
@@ -242,3 +243,11 @@ func (c *Handler) CanHandleTokenEndpointRequest(ctx context.Context, requester f
 	// Value MUST be set to "authorization_code"
 	return requester.GetGrantTypes().ExactOne("authorization_code")
 }
+
+// PopulateAuthorizationServerMetadata adds the supported PKCE code challenge methods.
+func (c *Handler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddCodeChallengeMethods("S256")
+	if c.Config.GetEnablePKCEPlainChallengeMethod(ctx) {
+		metadata.AddCodeChallengeMethods("plain")
+	}
+}
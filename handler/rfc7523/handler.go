@@ -38,6 +38,7 @@ type Handler struct {
 }
 
 var _ fosite.TokenEndpointHandler = (*Handler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*Handler)(nil)
 
 // HandleTokenEndpointRequest implements https://tools.ietf.org/html/rfc6749#section-4.1.3 (everything) and
 // https://tools.ietf.org/html/rfc7523#section-2.1 (everything)
@@ -322,3 +323,8 @@ func (c *Handler) getSessionFromRequest(requester fosite.AccessRequester) (exten
 		return jwtSession, nil
 	}
 }
+
+// PopulateAuthorizationServerMetadata adds the JWT bearer grant.
+func (c *Handler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddGrantTypes(grantTypeJWTBearer)
+}
@@ -0,0 +1,100 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite"
+)
+
+// maxUserCodeAttempts is the number of times a user code is regenerated when it collides with an existing one.
+const maxUserCodeAttempts = 3
+
+// DeviceAuthHandler handles the device authorization endpoint as specified in
+// https://www.rfc-editor.org/rfc/rfc8628#section-3.1
+type DeviceAuthHandler struct {
+	Strategy RFC8628CodeStrategy
+	Storage  DeviceAuthStorage
+	Config   interface {
+		fosite.DeviceAndUserCodeLifespanProvider
+		fosite.DeviceProvider
+	}
+}
+
+var _ fosite.DeviceEndpointHandler = (*DeviceAuthHandler)(nil)
+
+// HandleDeviceEndpointRequest issues the device and user codes and stores the device authorization request.
+func (d *DeviceAuthHandler) HandleDeviceEndpointRequest(ctx context.Context, dr fosite.DeviceRequester, resp fosite.DeviceResponder) error {
+	if dr.GetSession() == nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithDebug("The device request session must not be nil."))
+	}
+
+	deviceCode, deviceCodeSignature, err := d.Strategy.GenerateDeviceCode(ctx)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	userCode, userCodeSignature, err := d.generateUserCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	lifespan := d.Config.GetDeviceAndUserCodeLifespan(ctx)
+	expiresAt := time.Now().UTC().Add(lifespan).Round(time.Second)
+	dr.GetSession().SetExpiresAt(fosite.DeviceCode, expiresAt)
+	dr.GetSession().SetExpiresAt(fosite.UserCode, expiresAt)
+	dr.SetDeviceCodeSignature(deviceCodeSignature)
+	dr.SetStatus(fosite.DeviceRequestStatusPending)
+
+	if err := d.Storage.CreateDeviceAuthSession(ctx, deviceCodeSignature, userCodeSignature, sanitize(dr)); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithHint("Unable to store the device authorization session.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	verificationURI := d.Config.GetDeviceVerificationURL(ctx)
+	resp.SetDeviceCode(deviceCode)
+	resp.SetUserCode(userCode)
+	resp.SetVerificationURI(verificationURI)
+	if u, err := url.Parse(verificationURI); err == nil && verificationURI != "" {
+		q := u.Query()
+		q.Set("user_code", userCode)
+		u.RawQuery = q.Encode()
+		resp.SetVerificationURIComplete(u.String())
+	}
+	resp.SetExpiresIn(int64(time.Until(expiresAt).Round(time.Second).Seconds()))
+	resp.SetInterval(int(d.Config.GetDeviceAuthTokenPollingInterval(ctx).Seconds()))
+
+	return nil
+}
+
+// generateUserCode generates a user code which does not collide with a user code that is still stored.
+func (d *DeviceAuthHandler) generateUserCode(ctx context.Context) (code string, signature string, err error) {
+	for i := 0; i < maxUserCodeAttempts; i++ {
+		code, signature, err = d.Strategy.GenerateUserCode(ctx)
+		if err != nil {
+			return "", "", errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+
+		if _, err = d.Storage.GetUserCodeSession(ctx, signature, nil); errors.Is(err, fosite.ErrNotFound) {
+			return code, signature, nil
+		} else if err != nil {
+			return "", "", errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	return "", "", errorsx.WithStack(fosite.ErrServerError.WithHint("Unable to generate a unique user code."))
+}
+
+// sanitize removes all non-default form parameters from the request before it is stored.
+func sanitize(dr fosite.DeviceRequester) fosite.DeviceRequester {
+	if sanitized, ok := dr.Sanitize(nil).(fosite.DeviceRequester); ok {
+		return sanitized
+	}
+	return dr
+}
@@ -0,0 +1,73 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	. "github.com/ory/fosite/handler/rfc8628"
+	"github.com/ory/fosite/storage"
+)
+
+func TestDeviceAuthHandler_HandleDeviceEndpointRequest(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	handler := &DeviceAuthHandler{
+		Strategy: hmacshaStrategy,
+		Storage:  store,
+		Config: &fosite.Config{
+			DeviceAndUserCodeLifespan:      10 * time.Minute,
+			DeviceAuthTokenPollingInterval: 10 * time.Second,
+			DeviceVerificationURL:          "https://www.ory.sh/device?lang=en",
+		},
+	}
+
+	t.Run("case=should fail without a session", func(t *testing.T) {
+		dr := fosite.NewDeviceRequest()
+		err := handler.HandleDeviceEndpointRequest(ctx, dr, fosite.NewDeviceResponse())
+		require.ErrorIs(t, err, fosite.ErrServerError)
+	})
+
+	t.Run("case=should issue and store the codes", func(t *testing.T) {
+		dr := fosite.NewDeviceRequest()
+		dr.Client = &fosite.DefaultClient{ID: "device-client"}
+		dr.SetSession(new(fosite.DefaultSession))
+		dr.SetRequestedScopes(fosite.Arguments{"foo"})
+		dr.Form = url.Values{"scope": {"foo"}, "secret": {"bar"}}
+		resp := fosite.NewDeviceResponse()
+
+		require.NoError(t, handler.HandleDeviceEndpointRequest(ctx, dr, resp))
+
+		assert.NotEmpty(t, resp.GetDeviceCode())
+		assert.NotEmpty(t, resp.GetUserCode())
+		assert.Equal(t, "https://www.ory.sh/device?lang=en", resp.GetVerificationURI())
+		assert.Equal(t, "https://www.ory.sh/device?lang=en&user_code="+url.QueryEscape(resp.GetUserCode()), resp.GetVerificationURIComplete())
+		assert.InDelta(t, 600, resp.GetExpiresIn(), 1)
+		assert.Equal(t, 10, resp.GetInterval())
+
+		deviceCodeSignature, err := hmacshaStrategy.DeviceCodeSignature(ctx, resp.GetDeviceCode())
+		require.NoError(t, err)
+		assert.Equal(t, deviceCodeSignature, dr.GetDeviceCodeSignature())
+
+		stored, err := store.GetDeviceCodeSession(ctx, deviceCodeSignature, nil)
+		require.NoError(t, err)
+		assert.Equal(t, dr.GetID(), stored.GetID())
+		assert.Equal(t, fosite.DeviceRequestStatusPending, stored.GetStatus())
+		assert.Empty(t, stored.GetRequestForm().Get("secret"))
+		assert.False(t, stored.GetSession().GetExpiresAt(fosite.DeviceCode).IsZero())
+
+		userCodeSignature, err := hmacshaStrategy.UserCodeSignature(ctx, resp.GetUserCode())
+		require.NoError(t, err)
+		stored, err = store.GetUserCodeSession(ctx, userCodeSignature, nil)
+		require.NoError(t, err)
+		assert.Equal(t, dr.GetID(), stored.GetID())
+	})
+}
@@ -0,0 +1,251 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/storage"
+)
+
+// DeviceCodeTokenHandler handles the device access token request as specified in
+// https://www.rfc-editor.org/rfc/rfc8628#section-3.4
+type DeviceCodeTokenHandler struct {
+	DeviceCodeStrategy     DeviceCodeStrategy
+	AccessTokenStrategy    oauth2.AccessTokenStrategy
+	RefreshTokenStrategy   oauth2.RefreshTokenStrategy
+	CoreStorage            RFC8628CoreStorage
+	TokenRevocationStorage oauth2.TokenRevocationStorage
+	Config                 interface {
+		fosite.AccessTokenLifespanProvider
+		fosite.RefreshTokenLifespanProvider
+		fosite.RefreshTokenScopesProvider
+		fosite.DeviceProvider
+	}
+}
+
+var _ fosite.TokenEndpointHandler = (*DeviceCodeTokenHandler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*DeviceCodeTokenHandler)(nil)
+
+// HandleTokenEndpointRequest implements
+// * https://www.rfc-editor.org/rfc/rfc8628#section-3.4 (everything)
+// * https://www.rfc-editor.org/rfc/rfc8628#section-3.5 (everything)
+func (c *DeviceCodeTokenHandler) HandleTokenEndpointRequest(ctx context.Context, request fosite.AccessRequester) error {
+	if !c.CanHandleTokenEndpointRequest(ctx, request) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	if !request.GetClient().GetGrantTypes().Has(string(fosite.GrantTypeDeviceCode)) {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant \"%s\".", fosite.GrantTypeDeviceCode))
+	}
+
+	code := request.GetRequestForm().Get("device_code")
+	if code == "" {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The \"device_code\" parameter is missing."))
+	}
+
+	signature, err := c.DeviceCodeStrategy.DeviceCodeSignature(ctx, code)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	deviceRequest, err := c.CoreStorage.GetDeviceCodeSession(ctx, signature, request.GetSession())
+	if errors.Is(err, fosite.ErrInvalidatedDeviceCode) {
+		if deviceRequest == nil {
+			return fosite.ErrServerError.
+				WithHint("Misconfigured code lead to an error that prohibited the OAuth 2.0 Framework from processing this request.").
+				WithDebug("GetDeviceCodeSession must return a value for \"fosite.DeviceRequester\" when returning \"ErrInvalidatedDeviceCode\".")
+		}
+
+		// If a device code is used twice, we revoke all refresh and access tokens associated with this request.
+		reqID := deviceRequest.GetID()
+		hint := "The device code has already been used."
+		debug := ""
+		if revErr := c.TokenRevocationStorage.RevokeAccessToken(ctx, reqID); revErr != nil {
+			hint += " Additionally, an error occurred during processing the access token revocation."
+			debug += "Revocation of access_token lead to error " + revErr.Error() + "."
+		}
+		if revErr := c.TokenRevocationStorage.RevokeRefreshToken(ctx, reqID); revErr != nil {
+			hint += " Additionally, an error occurred during processing the refresh token revocation."
+			debug += "Revocation of refresh_token lead to error " + revErr.Error() + "."
+		}
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint(hint).WithDebug(debug))
+	} else if err != nil && errors.Is(err, fosite.ErrNotFound) {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithWrap(err).WithDebug(err.Error()))
+	} else if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	// The device code must have been issued to the authenticated client.
+	if deviceRequest.GetClient().GetID() != request.GetClient().GetID() {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client ID from this request does not match the one from the device authorization request."))
+	}
+
+	if err := c.DeviceCodeStrategy.ValidateDeviceCode(ctx, deviceRequest, code); errors.Is(err, fosite.ErrDeviceExpiredToken) {
+		return err
+	} else if err != nil {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	switch deviceRequest.GetStatus() {
+	case fosite.DeviceRequestStatusApproved:
+	case fosite.DeviceRequestStatusDenied:
+		return errorsx.WithStack(fosite.ErrAccessDenied.WithHint("The end user denied the device authorization request."))
+	default:
+		return c.handlePendingRequest(ctx, signature, deviceRequest)
+	}
+
+	request.SetRequestedScopes(deviceRequest.GetRequestedScopes())
+	request.SetRequestedAudience(deviceRequest.GetRequestedAudience())
+	jkt := fosite.GetDPoPJWKThumbprint(request.GetSession())
+	request.SetSession(deviceRequest.GetSession())
+	request.SetID(deviceRequest.GetID())
+	if s, ok := request.GetSession().(fosite.DPoPSession); ok {
+		s.SetDPoPJWKThumbprint(jkt)
+	}
+
+	atLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeDeviceCode, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
+	request.GetSession().SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(atLifespan).Round(time.Second))
+
+	rtLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeDeviceCode, fosite.RefreshToken, c.Config.GetRefreshTokenLifespan(ctx))
+	if rtLifespan > -1 {
+		request.GetSession().SetExpiresAt(fosite.RefreshToken, time.Now().UTC().Add(rtLifespan).Round(time.Second))
+	}
+
+	return nil
+}
+
+// handlePendingRequest records the poll and tells the client to either keep polling or to slow down.
+func (c *DeviceCodeTokenHandler) handlePendingRequest(ctx context.Context, signature string, deviceRequest fosite.DeviceRequester) error {
+	pollErr := fosite.CheckPollingInterval(deviceRequest, c.Config.GetDeviceAuthTokenPollingInterval(ctx))
+	if err := c.CoreStorage.UpdateDeviceCodePollingState(ctx, signature, deviceRequest.GetLastPolledAt(), deviceRequest.GetPollingInterval()); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return pollErr
+}
+
+func (c *DeviceCodeTokenHandler) canIssueRefreshToken(ctx context.Context, request fosite.Requester) bool {
+	scope := c.Config.GetRefreshTokenScopes(ctx)
+	// Require one of the refresh token scopes, if set.
+	if len(scope) > 0 && !request.GetGrantedScopes().HasOneOf(scope...) {
+		return false
+	}
+	// Do not issue a refresh token to clients that cannot use the refresh token grant type.
+	if !request.GetClient().GetGrantTypes().Has("refresh_token") {
+		return false
+	}
+	return true
+}
+
+func (c *DeviceCodeTokenHandler) PopulateTokenEndpointResponse(ctx context.Context, requester fosite.AccessRequester, responder fosite.AccessResponder) (err error) {
+	if !c.CanHandleTokenEndpointRequest(ctx, requester) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	code := requester.GetRequestForm().Get("device_code")
+	signature, err := c.DeviceCodeStrategy.DeviceCodeSignature(ctx, code)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	deviceRequest, err := c.CoreStorage.GetDeviceCodeSession(ctx, signature, requester.GetSession())
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if err := c.DeviceCodeStrategy.ValidateDeviceCode(ctx, deviceRequest, code); err != nil {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithWrap(err).WithDebug(err.Error()))
+	} else if deviceRequest.GetStatus() != fosite.DeviceRequestStatusApproved {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The device authorization request has not been approved."))
+	}
+
+	for _, scope := range deviceRequest.GetGrantedScopes() {
+		requester.GrantScope(scope)
+	}
+
+	if err := oauth2.GrantAudience(requester, deviceRequest); err != nil {
+		return err
+	}
+
+	if err := oauth2.GrantAuthorizationDetails(requester, deviceRequest); err != nil {
+		return err
+	}
+
+	access, accessSignature, err := c.AccessTokenStrategy.GenerateAccessToken(ctx, requester)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	var refresh, refreshSignature string
+	if c.canIssueRefreshToken(ctx, deviceRequest) {
+		refresh, refreshSignature, err = c.RefreshTokenStrategy.GenerateRefreshToken(ctx, requester)
+		if err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	ctx, err = storage.MaybeBeginTx(ctx, c.CoreStorage)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	defer func() {
+		if err != nil {
+			if rollBackTxnErr := storage.MaybeRollbackTx(ctx, c.CoreStorage); rollBackTxnErr != nil {
+				err = errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebugf("error: %s; rollback error: %s", err, rollBackTxnErr))
+			}
+		}
+	}()
+
+	if err = c.CoreStorage.InvalidateDeviceCodeSession(ctx, signature); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if err = c.CoreStorage.CreateAccessTokenSession(ctx, accessSignature, requester.Sanitize([]string{})); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if refreshSignature != "" {
+		if err = c.CoreStorage.CreateRefreshTokenSession(ctx, refreshSignature, oauth2.SanitizeRefreshTokenRequest(requester, deviceRequest)); err != nil {
+			return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+	}
+
+	responder.SetAccessToken(access)
+	responder.SetTokenType(fosite.GetAccessTokenType(requester.GetSession()))
+	atLifespan := fosite.GetEffectiveLifespan(requester.GetClient(), fosite.GrantTypeDeviceCode, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
+	responder.SetExpiresIn(getExpiresIn(requester, fosite.AccessToken, atLifespan, time.Now().UTC()))
+	responder.SetScopes(requester.GetGrantedScopes())
+	if refresh != "" {
+		responder.SetExtra("refresh_token", refresh)
+	}
+
+	if err = storage.MaybeCommitTx(ctx, c.CoreStorage); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return nil
+}
+
+func (c *DeviceCodeTokenHandler) CanSkipClientAuth(ctx context.Context, requester fosite.AccessRequester) bool {
+	return false
+}
+
+func (c *DeviceCodeTokenHandler) CanHandleTokenEndpointRequest(ctx context.Context, requester fosite.AccessRequester) bool {
+	// grant_type REQUIRED.
+	// Value MUST be set to "urn:ietf:params:oauth:grant-type:device_code"
+	return requester.GetGrantTypes().ExactOne(string(fosite.GrantTypeDeviceCode))
+}
+
+func getExpiresIn(r fosite.Requester, key fosite.TokenType, defaultLifespan time.Duration, now time.Time) time.Duration {
+	if r.GetSession().GetExpiresAt(key).IsZero() {
+		return defaultLifespan
+	}
+	return time.Duration(r.GetSession().GetExpiresAt(key).UnixNano() - now.UnixNano())
+}
+
+// PopulateAuthorizationServerMetadata adds the device authorization grant.
+func (c *DeviceCodeTokenHandler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddGrantTypes("urn:ietf:params:oauth:grant-type:device_code")
+}
@@ -0,0 +1,230 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	. "github.com/ory/fosite/handler/rfc8628"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/hmac"
+)
+
+func TestDeviceCodeTokenHandler(t *testing.T) {
+	ctx := context.Background()
+	config := &fosite.Config{
+		GlobalSecret:                   []byte("foobarfoobarfoobarfoobarfoobarfoobarfoobarfoobar"),
+		AccessTokenLifespan:            time.Hour,
+		RefreshTokenLifespan:           24 * time.Hour,
+		DeviceAndUserCodeLifespan:      10 * time.Minute,
+		DeviceAuthTokenPollingInterval: time.Hour,
+		DeviceVerificationURL:          "https://www.ory.sh/device",
+	}
+	coreStrategy := &oauth2.HMACSHAStrategy{
+		Enigma: &hmac.HMACStrategy{Config: config},
+		Config: config,
+	}
+	client := &fosite.DefaultClient{
+		ID:         "device-client",
+		Public:     true,
+		GrantTypes: []string{string(fosite.GrantTypeDeviceCode), "refresh_token"},
+		Scopes:     []string{"foo", "offline"},
+	}
+
+	setup := func(t *testing.T) (*storage.MemoryStore, *DeviceCodeTokenHandler, *DeviceUserHandler, fosite.DeviceResponder) {
+		store := storage.NewMemoryStore()
+		authHandler := &DeviceAuthHandler{Strategy: hmacshaStrategy, Storage: store, Config: config}
+		tokenHandler := &DeviceCodeTokenHandler{
+			DeviceCodeStrategy:     hmacshaStrategy,
+			AccessTokenStrategy:    coreStrategy,
+			RefreshTokenStrategy:   coreStrategy,
+			CoreStorage:            store,
+			TokenRevocationStorage: store,
+			Config:                 config,
+		}
+		userHandler := &DeviceUserHandler{Strategy: hmacshaStrategy, Storage: store}
+
+		dr := fosite.NewDeviceRequest()
+		dr.Client = client
+		dr.SetSession(new(fosite.DefaultSession))
+		dr.SetRequestedScopes(fosite.Arguments{"foo", "offline"})
+		resp := fosite.NewDeviceResponse()
+		require.NoError(t, authHandler.HandleDeviceEndpointRequest(ctx, dr, resp))
+
+		return store, tokenHandler, userHandler, resp
+	}
+
+	newAccessRequest := func(deviceCode string, c fosite.Client) *fosite.AccessRequest {
+		ar := fosite.NewAccessRequest(new(fosite.DefaultSession))
+		ar.GrantTypes = fosite.Arguments{string(fosite.GrantTypeDeviceCode)}
+		ar.Client = c
+		ar.Form = url.Values{"device_code": {deviceCode}}
+		return ar
+	}
+
+	t.Run("case=should not handle other grant types", func(t *testing.T) {
+		_, h, _, _ := setup(t)
+		ar := fosite.NewAccessRequest(new(fosite.DefaultSession))
+		ar.GrantTypes = fosite.Arguments{"authorization_code"}
+		assert.False(t, h.CanHandleTokenEndpointRequest(ctx, ar))
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnknownRequest)
+	})
+
+	t.Run("case=should fail because the client may not use the grant", func(t *testing.T) {
+		_, h, _, resp := setup(t)
+		err := h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), &fosite.DefaultClient{ID: "device-client"}))
+		assert.ErrorIs(t, err, fosite.ErrUnauthorizedClient)
+	})
+
+	t.Run("case=should fail because the device code is missing or unknown", func(t *testing.T) {
+		_, h, _, _ := setup(t)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest("", client)), fosite.ErrInvalidRequest)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest("ory_dc_foo.bar", client)), fosite.ErrInvalidGrant)
+	})
+
+	t.Run("case=should fail because the device code belongs to another client", func(t *testing.T) {
+		_, h, _, resp := setup(t)
+		other := &fosite.DefaultClient{ID: "other-client", GrantTypes: client.GrantTypes}
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), other)), fosite.ErrInvalidGrant)
+	})
+
+	t.Run("case=should ask to keep polling and then to slow down", func(t *testing.T) {
+		store, h, _, resp := setup(t)
+		signature, err := hmacshaStrategy.DeviceCodeSignature(ctx, resp.GetDeviceCode())
+		require.NoError(t, err)
+
+		getDeviceCodeSession := func(t *testing.T) fosite.DeviceRequester {
+			stored, err := store.GetDeviceCodeSession(ctx, signature, nil)
+			require.NoError(t, err)
+			return stored
+		}
+
+		// pollAfter stores a last poll which happened the given duration ago.
+		pollAfter := func(t *testing.T, d time.Duration) {
+			stored := getDeviceCodeSession(t)
+			stored.SetLastPolledAt(time.Now().UTC().Add(-d))
+			require.NoError(t, store.UpdateDeviceCodeSession(ctx, signature, stored))
+		}
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), client)), fosite.ErrAuthorizationPending)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), client)), fosite.ErrSlowDown)
+		assert.Equal(t, time.Hour+fosite.SlowDownIntervalIncrease, getDeviceCodeSession(t).GetPollingInterval())
+
+		// Polling at the original interval is too fast once the interval was increased.
+		pollAfter(t, time.Hour+time.Second)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), client)), fosite.ErrSlowDown)
+		assert.Equal(t, time.Hour+2*fosite.SlowDownIntervalIncrease, getDeviceCodeSession(t).GetPollingInterval())
+
+		pollAfter(t, 2*time.Hour)
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), client)), fosite.ErrAuthorizationPending)
+	})
+
+	t.Run("case=should not overwrite a concurrent approval when recording a poll", func(t *testing.T) {
+		store, _, u, resp := setup(t)
+		h := &DeviceCodeTokenHandler{
+			DeviceCodeStrategy:   hmacshaStrategy,
+			AccessTokenStrategy:  coreStrategy,
+			RefreshTokenStrategy: coreStrategy,
+			CoreStorage: &staleDeviceCodeStorage{MemoryStore: store, afterGet: func() {
+				dr, err := u.GetDeviceUserRequest(ctx, resp.GetUserCode(), nil)
+				require.NoError(t, err)
+				require.NoError(t, u.ApproveDeviceUserRequest(ctx, resp.GetUserCode(), dr))
+			}},
+			TokenRevocationStorage: store,
+			Config:                 config,
+		}
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), client)), fosite.ErrAuthorizationPending)
+
+		signature, err := hmacshaStrategy.DeviceCodeSignature(ctx, resp.GetDeviceCode())
+		require.NoError(t, err)
+		stored, err := store.GetDeviceCodeSession(ctx, signature, nil)
+		require.NoError(t, err)
+		assert.Equal(t, fosite.DeviceRequestStatusApproved, stored.GetStatus())
+		assert.False(t, stored.GetLastPolledAt().IsZero())
+	})
+
+	t.Run("case=should fail because the device code expired", func(t *testing.T) {
+		store, h, _, resp := setup(t)
+		signature, err := hmacshaStrategy.DeviceCodeSignature(ctx, resp.GetDeviceCode())
+		require.NoError(t, err)
+		stored, err := store.GetDeviceCodeSession(ctx, signature, nil)
+		require.NoError(t, err)
+		stored.GetSession().SetExpiresAt(fosite.DeviceCode, time.Now().UTC().Add(-time.Minute))
+		require.NoError(t, store.UpdateDeviceCodeSession(ctx, signature, stored))
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), client)), fosite.ErrDeviceExpiredToken)
+	})
+
+	t.Run("case=should fail because the end user denied the request", func(t *testing.T) {
+		_, h, u, resp := setup(t)
+		dr, err := u.GetDeviceUserRequest(ctx, resp.GetUserCode(), nil)
+		require.NoError(t, err)
+		require.NoError(t, u.DenyDeviceUserRequest(ctx, resp.GetUserCode(), dr))
+
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), client)), fosite.ErrAccessDenied)
+
+		_, err = u.GetDeviceUserRequest(ctx, resp.GetUserCode(), nil)
+		assert.ErrorIs(t, err, fosite.ErrInvalidGrant)
+	})
+
+	t.Run("case=should issue tokens once the end user approved the request", func(t *testing.T) {
+		store, h, u, resp := setup(t)
+		_, err := u.GetDeviceUserRequest(ctx, "BCDF-GHJK", nil)
+		assert.ErrorIs(t, err, fosite.ErrInvalidGrant)
+
+		dr, err := u.GetDeviceUserRequest(ctx, resp.GetUserCode(), nil)
+		require.NoError(t, err)
+		dr.GrantScope("foo")
+		dr.GrantScope("offline")
+		dr.SetSession(&fosite.DefaultSession{Subject: "peter", ExpiresAt: dr.GetSession().(*fosite.DefaultSession).ExpiresAt})
+		require.NoError(t, u.ApproveDeviceUserRequest(ctx, resp.GetUserCode(), dr))
+
+		ar := newAccessRequest(resp.GetDeviceCode(), client)
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		assert.Equal(t, dr.GetID(), ar.GetID())
+		assert.Equal(t, "peter", ar.GetSession().GetSubject())
+
+		aresp := fosite.NewAccessResponse()
+		require.NoError(t, h.PopulateTokenEndpointResponse(ctx, ar, aresp))
+		assert.NotEmpty(t, aresp.GetAccessToken())
+		assert.Equal(t, "bearer", aresp.GetTokenType())
+		assert.NotEmpty(t, aresp.GetExtra("refresh_token"))
+		assert.Equal(t, "foo offline", aresp.GetExtra("scope"))
+
+		_, err = store.GetAccessTokenSession(ctx, coreStrategy.AccessTokenSignature(ctx, aresp.GetAccessToken()), nil)
+		require.NoError(t, err)
+
+		// Using the device code a second time must fail and revoke the issued tokens.
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newAccessRequest(resp.GetDeviceCode(), client)), fosite.ErrInvalidGrant)
+		_, err = store.GetAccessTokenSession(ctx, coreStrategy.AccessTokenSignature(ctx, aresp.GetAccessToken()), nil)
+		assert.ErrorIs(t, err, fosite.ErrNotFound)
+	})
+}
+
+// staleDeviceCodeStorage returns copies of the stored device requests, as persistent storages do, and calls afterGet
+// once a request was read to simulate a concurrent update.
+type staleDeviceCodeStorage struct {
+	*storage.MemoryStore
+	afterGet func()
+}
+
+func (s *staleDeviceCodeStorage) GetDeviceCodeSession(ctx context.Context, signature string, session fosite.Session) (fosite.DeviceRequester, error) {
+	dr, err := s.MemoryStore.GetDeviceCodeSession(ctx, signature, session)
+	if err != nil {
+		return dr, err
+	}
+
+	stale := *dr.(*fosite.DeviceRequest)
+	s.afterGet()
+	return &stale, nil
+}
@@ -0,0 +1,76 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+
+	"github.com/ory/fosite"
+)
+
+// DeviceUserHandler is used by the verification page to look up a device authorization request by the user
+// code the end user entered, and to record the end user's decision. See
+// https://www.rfc-editor.org/rfc/rfc8628#section-3.3
+type DeviceUserHandler struct {
+	Strategy UserCodeStrategy
+	Storage  DeviceAuthStorage
+}
+
+// GetDeviceUserRequest returns the pending device authorization request which belongs to the user code.
+func (d *DeviceUserHandler) GetDeviceUserRequest(ctx context.Context, userCode string, session fosite.Session) (fosite.DeviceRequester, error) {
+	signature, err := d.Strategy.UserCodeSignature(ctx, userCode)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	dr, err := d.Storage.GetUserCodeSession(ctx, signature, session)
+	if errors.Is(err, fosite.ErrNotFound) {
+		return nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The user code is unknown.").WithWrap(err).WithDebug(err.Error()))
+	} else if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if err := d.Strategy.ValidateUserCode(ctx, dr, userCode); err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if dr.GetStatus() != fosite.DeviceRequestStatusPending {
+		return nil, errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The user code has already been used."))
+	}
+
+	return dr, nil
+}
+
+// ApproveDeviceUserRequest marks the device authorization request as approved. The granted scopes, granted
+// audience and the session of the given request are stored and used when the client redeems the device code.
+func (d *DeviceUserHandler) ApproveDeviceUserRequest(ctx context.Context, userCode string, dr fosite.DeviceRequester) error {
+	return d.finish(ctx, userCode, dr, fosite.DeviceRequestStatusApproved)
+}
+
+// DenyDeviceUserRequest marks the device authorization request as denied. The client receives the
+// "access_denied" error on its next poll.
+func (d *DeviceUserHandler) DenyDeviceUserRequest(ctx context.Context, userCode string, dr fosite.DeviceRequester) error {
+	return d.finish(ctx, userCode, dr, fosite.DeviceRequestStatusDenied)
+}
+
+func (d *DeviceUserHandler) finish(ctx context.Context, userCode string, dr fosite.DeviceRequester, status fosite.DeviceRequestStatus) error {
+	stored, err := d.GetDeviceUserRequest(ctx, userCode, dr.GetSession())
+	if err != nil {
+		return err
+	}
+
+	if stored.GetID() != dr.GetID() {
+		return errorsx.WithStack(fosite.ErrInvalidGrant.WithHint("The user code does not belong to this device authorization request."))
+	}
+
+	dr.SetStatus(status)
+	if err := d.Storage.UpdateDeviceCodeSession(ctx, stored.GetDeviceCodeSignature(), dr); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+)
+
+// RFC8628CoreStorage is the storage needed by the device authorization grant handlers.
+type RFC8628CoreStorage interface {
+	DeviceAuthStorage
+	oauth2.AccessTokenStorage
+	oauth2.RefreshTokenStorage
+}
+
+// DeviceAuthStorage stores the device authorization requests by their device and user code signatures.
+type DeviceAuthStorage interface {
+	// CreateDeviceAuthSession stores the device request. It must be retrievable by both the device code
+	// signature and the user code signature.
+	CreateDeviceAuthSession(ctx context.Context, deviceCodeSignature, userCodeSignature string, request fosite.DeviceRequester) (err error)
+
+	// GetDeviceCodeSession hydrates the session based on the given device code signature and returns the device request.
+	// If the device code has been invalidated with `InvalidateDeviceCodeSession`, this method should return the
+	// ErrInvalidatedDeviceCode error.
+	//
+	// Make sure to also return the fosite.Requester value when returning the fosite.ErrInvalidatedDeviceCode error!
+	GetDeviceCodeSession(ctx context.Context, signature string, session fosite.Session) (request fosite.DeviceRequester, err error)
+
+	// GetUserCodeSession hydrates the session based on the given user code signature and returns the device request.
+	GetUserCodeSession(ctx context.Context, signature string, session fosite.Session) (request fosite.DeviceRequester, err error)
+
+	// UpdateDeviceCodeSession updates the device request stored for the given device code signature, for example
+	// once the end user approved or denied the request or when the client polled the token endpoint.
+	UpdateDeviceCodeSession(ctx context.Context, signature string, request fosite.DeviceRequester) (err error)
+
+	// UpdateDeviceCodePollingState only updates the last poll time and the polling interval of the device request
+	// stored for the given device code signature when the client polled the token endpoint. It must not overwrite
+	// the rest of the request, as the end user may approve or deny the request concurrently.
+	UpdateDeviceCodePollingState(ctx context.Context, signature string, lastPolledAt time.Time, interval time.Duration) (err error)
+
+	// InvalidateDeviceCodeSession is called when a device code is being used. The state of the device
+	// code should be set to invalid and consecutive requests to GetDeviceCodeSession should return the
+	// ErrInvalidatedDeviceCode error.
+	InvalidateDeviceCodeSession(ctx context.Context, signature string) (err error)
+}
@@ -0,0 +1,40 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+
+	"github.com/ory/fosite"
+)
+
+// RFC8628CodeStrategy generates and validates the device and user codes of the device authorization grant.
+type RFC8628CodeStrategy interface {
+	DeviceCodeStrategy
+	UserCodeStrategy
+}
+
+// DeviceCodeStrategy handles the device code which the client uses to poll the token endpoint.
+type DeviceCodeStrategy interface {
+	// DeviceCodeSignature returns the signature of the device code which is used as the storage key.
+	DeviceCodeSignature(ctx context.Context, code string) (signature string, err error)
+
+	// GenerateDeviceCode generates a new device code and its signature.
+	GenerateDeviceCode(ctx context.Context) (code string, signature string, err error)
+
+	// ValidateDeviceCode validates the device code and checks that it has not expired.
+	ValidateDeviceCode(ctx context.Context, r fosite.Requester, code string) (err error)
+}
+
+// UserCodeStrategy handles the user code which the end user enters on the verification page.
+type UserCodeStrategy interface {
+	// UserCodeSignature returns the signature of the user code which is used as the storage key.
+	UserCodeSignature(ctx context.Context, code string) (signature string, err error)
+
+	// GenerateUserCode generates a new user code and its signature.
+	GenerateUserCode(ctx context.Context) (code string, signature string, err error)
+
+	// ValidateUserCode checks that the user code has not expired.
+	ValidateUserCode(ctx context.Context, r fosite.Requester, code string) (err error)
+}
@@ -0,0 +1,115 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	enigma "github.com/ory/fosite/token/hmac"
+)
+
+const (
+	deviceCodePrefix = "ory_dc_"
+
+	// userCodeAlphabet contains only base-20 consonants to reduce the chance of ambiguous characters and of
+	// forming words, see https://www.rfc-editor.org/rfc/rfc8628#section-6.1
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+)
+
+// DefaultDeviceStrategy generates device codes with the HMAC strategy and user codes from a restricted alphabet.
+// User codes are stored by their HMAC so that they can not be recovered from the storage.
+type DefaultDeviceStrategy struct {
+	Enigma *enigma.HMACStrategy
+	Config interface {
+		fosite.DeviceAndUserCodeLifespanProvider
+	}
+}
+
+var _ RFC8628CodeStrategy = (*DefaultDeviceStrategy)(nil)
+
+func (h *DefaultDeviceStrategy) DeviceCodeSignature(ctx context.Context, code string) (string, error) {
+	return h.Enigma.Signature(code), nil
+}
+
+func (h *DefaultDeviceStrategy) GenerateDeviceCode(ctx context.Context) (code string, signature string, err error) {
+	token, sig, err := h.Enigma.Generate(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	return deviceCodePrefix + token, sig, nil
+}
+
+func (h *DefaultDeviceStrategy) ValidateDeviceCode(ctx context.Context, r fosite.Requester, code string) (err error) {
+	var exp = r.GetSession().GetExpiresAt(fosite.DeviceCode)
+	if exp.IsZero() {
+		exp = r.GetRequestedAt().Add(h.Config.GetDeviceAndUserCodeLifespan(ctx))
+	}
+
+	if exp.Before(time.Now().UTC()) {
+		return errorsx.WithStack(fosite.ErrDeviceExpiredToken.WithHintf("Device code expired at '%s'.", exp))
+	}
+
+	return h.Enigma.Validate(ctx, strings.TrimPrefix(code, deviceCodePrefix))
+}
+
+func (h *DefaultDeviceStrategy) UserCodeSignature(ctx context.Context, code string) (string, error) {
+	return h.Enigma.GenerateHMACForString(ctx, normalizeUserCode(code))
+}
+
+func (h *DefaultDeviceStrategy) GenerateUserCode(ctx context.Context) (code string, signature string, err error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(userCodeAlphabet)))
+	for i := 0; i < userCodeLength; i++ {
+		if i == userCodeLength/2 {
+			b.WriteByte('-')
+		}
+
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", "", errorsx.WithStack(err)
+		}
+		b.WriteByte(userCodeAlphabet[n.Int64()])
+	}
+
+	code = b.String()
+	signature, err = h.UserCodeSignature(ctx, code)
+	if err != nil {
+		return "", "", err
+	}
+
+	return code, signature, nil
+}
+
+func (h *DefaultDeviceStrategy) ValidateUserCode(ctx context.Context, r fosite.Requester, code string) (err error) {
+	var exp = r.GetSession().GetExpiresAt(fosite.UserCode)
+	if exp.IsZero() {
+		exp = r.GetRequestedAt().Add(h.Config.GetDeviceAndUserCodeLifespan(ctx))
+	}
+
+	if exp.Before(time.Now().UTC()) {
+		return errorsx.WithStack(fosite.ErrTokenExpired.WithHintf("User code expired at '%s'.", exp))
+	}
+
+	return nil
+}
+
+// normalizeUserCode removes the characters end users commonly add or change when typing a user code, as
+// recommended by https://www.rfc-editor.org/rfc/rfc8628#section-6.1
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
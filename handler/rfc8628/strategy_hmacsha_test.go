@@ -0,0 +1,107 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8628_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	. "github.com/ory/fosite/handler/rfc8628"
+	"github.com/ory/fosite/token/hmac"
+)
+
+var hmacConfig = &fosite.Config{
+	GlobalSecret:              []byte("foobarfoobarfoobarfoobarfoobarfoobarfoobarfoobar"),
+	DeviceAndUserCodeLifespan: time.Minute,
+}
+
+var hmacshaStrategy = &DefaultDeviceStrategy{
+	Enigma: &hmac.HMACStrategy{Config: hmacConfig},
+	Config: hmacConfig,
+}
+
+func TestDefaultDeviceStrategy_DeviceCode(t *testing.T) {
+	ctx := context.Background()
+	code, signature, err := hmacshaStrategy.GenerateDeviceCode(ctx)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(code, "ory_dc_"))
+
+	actual, err := hmacshaStrategy.DeviceCodeSignature(ctx, code)
+	require.NoError(t, err)
+	assert.Equal(t, signature, actual)
+
+	for k, c := range []struct {
+		r    fosite.Requester
+		code string
+		pass bool
+	}{
+		{
+			r: &fosite.Request{
+				Session: &fosite.DefaultSession{ExpiresAt: map[fosite.TokenType]time.Time{fosite.DeviceCode: time.Now().UTC().Add(time.Hour)}},
+			},
+			code: code,
+			pass: true,
+		},
+		{
+			r: &fosite.Request{
+				Session: &fosite.DefaultSession{ExpiresAt: map[fosite.TokenType]time.Time{fosite.DeviceCode: time.Now().UTC().Add(time.Hour)}},
+			},
+			code: code + "a",
+		},
+		{
+			r: &fosite.Request{
+				Session: &fosite.DefaultSession{ExpiresAt: map[fosite.TokenType]time.Time{fosite.DeviceCode: time.Now().UTC().Add(-time.Hour)}},
+			},
+			code: code,
+		},
+		{
+			r: &fosite.Request{
+				RequestedAt: time.Now().UTC().Add(-time.Hour),
+				Session:     &fosite.DefaultSession{},
+			},
+			code: code,
+		},
+	} {
+		err := hmacshaStrategy.ValidateDeviceCode(ctx, c.r, c.code)
+		if c.pass {
+			assert.NoError(t, err, "%d", k)
+		} else {
+			assert.Error(t, err, "%d", k)
+		}
+	}
+}
+
+func TestDefaultDeviceStrategy_UserCode(t *testing.T) {
+	ctx := context.Background()
+	code, signature, err := hmacshaStrategy.GenerateUserCode(ctx)
+	require.NoError(t, err)
+	assert.Regexp(t, "^[BCDFGHJKLMNPQRSTVWXZ]{4}-[BCDFGHJKLMNPQRSTVWXZ]{4}$", code)
+
+	for _, variant := range []string{code, strings.ToLower(code), strings.ReplaceAll(code, "-", ""), strings.ReplaceAll(code, "-", " ")} {
+		actual, err := hmacshaStrategy.UserCodeSignature(ctx, variant)
+		require.NoError(t, err)
+		assert.Equal(t, signature, actual, variant)
+	}
+
+	other, _, err := hmacshaStrategy.GenerateUserCode(ctx)
+	require.NoError(t, err)
+	if other != code {
+		actual, err := hmacshaStrategy.UserCodeSignature(ctx, other)
+		require.NoError(t, err)
+		assert.NotEqual(t, signature, actual)
+	}
+
+	assert.NoError(t, hmacshaStrategy.ValidateUserCode(ctx, &fosite.Request{
+		Session: &fosite.DefaultSession{ExpiresAt: map[fosite.TokenType]time.Time{fosite.UserCode: time.Now().UTC().Add(time.Hour)}},
+	}, code))
+	assert.ErrorIs(t, hmacshaStrategy.ValidateUserCode(ctx, &fosite.Request{
+		Session: &fosite.DefaultSession{ExpiresAt: map[fosite.TokenType]time.Time{fosite.UserCode: time.Now().UTC().Add(-time.Hour)}},
+	}, code), fosite.ErrTokenExpired)
+}
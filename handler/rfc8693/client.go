@@ -0,0 +1,87 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8693
+
+import "github.com/ory/fosite"
+
+// Policy restricts which tokens a client may exchange and on whose behalf. A client without a policy may only
+// exchange tokens which were issued to itself.
+type Policy struct {
+	// AllowedSubjectTokenTypes lists the "subject_token_type" values the client may present. An empty list allows
+	// all token types supported by the handler.
+	AllowedSubjectTokenTypes []string `json:"allowed_subject_token_types"`
+
+	// AllowedSubjectClients lists the IDs of the clients whose tokens the client may exchange. The client may always
+	// exchange tokens which were issued to itself. "*" allows tokens of any client.
+	AllowedSubjectClients []string `json:"allowed_subject_clients"`
+
+	// AllowedIssuers lists the issuers of JWTs of type "urn:ietf:params:oauth:token-type:jwt" the client may
+	// present as subject or actor token. "*" allows JWTs of any issuer with a registered public key. An empty list
+	// denies all of them.
+	AllowedIssuers []string `json:"allowed_issuers"`
+
+	// AllowedSubjects lists the subjects the client may obtain tokens for. An empty list allows any subject.
+	AllowedSubjects []string `json:"allowed_subjects"`
+
+	// AllowedActors lists the subjects which may act on behalf of the subject when an "actor_token" is
+	// presented. An empty list allows any actor.
+	AllowedActors []string `json:"allowed_actors"`
+
+	// AllowImpersonation allows the client to exchange tokens of other clients without presenting an "actor_token".
+	AllowImpersonation bool `json:"allow_impersonation"`
+}
+
+// Client is a client which has a token exchange policy.
+type Client interface {
+	// GetTokenExchangePolicy returns the token exchange policy of the client or nil if there is none.
+	GetTokenExchangePolicy() *Policy
+
+	fosite.Client
+}
+
+// DefaultClient is a client with a token exchange policy.
+type DefaultClient struct {
+	*fosite.DefaultClient
+	TokenExchangePolicy *Policy `json:"token_exchange_policy"`
+}
+
+func (c *DefaultClient) GetTokenExchangePolicy() *Policy {
+	return c.TokenExchangePolicy
+}
+
+func (p *Policy) allowsSubjectTokenType(tokenType string) bool {
+	return len(p.AllowedSubjectTokenTypes) == 0 || contains(p.AllowedSubjectTokenTypes, tokenType)
+}
+
+func (p *Policy) allowsSubjectClient(clientID string) bool {
+	return contains(p.AllowedSubjectClients, "*") || contains(p.AllowedSubjectClients, clientID)
+}
+
+func (p *Policy) allowsIssuer(issuer string) bool {
+	return contains(p.AllowedIssuers, "*") || contains(p.AllowedIssuers, issuer)
+}
+
+func (p *Policy) allowsSubject(subject string) bool {
+	return len(p.AllowedSubjects) == 0 || contains(p.AllowedSubjects, subject)
+}
+
+func (p *Policy) allowsActor(subject string) bool {
+	return len(p.AllowedActors) == 0 || contains(p.AllowedActors, subject)
+}
+
+func getPolicy(client fosite.Client) *Policy {
+	if c, ok := client.(Client); ok && c.GetTokenExchangePolicy() != nil {
+		return c.GetTokenExchangePolicy()
+	}
+	return &Policy{}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
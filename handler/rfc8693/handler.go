@@ -0,0 +1,257 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8693
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/rfc7523"
+	"github.com/ory/fosite/token/jwt"
+)
+
+type Handler struct {
+	// TokenIntrospector validates subject and actor tokens of type access_token and refresh_token. If nil, these
+	// token types are not supported.
+	TokenIntrospector fosite.TokenIntrospector
+
+	// IDTokenSigner validates subject and actor tokens of type id_token. If nil, this token type is not supported.
+	IDTokenSigner jwt.Signer
+
+	// JWTKeyStorage provides the keys of trusted issuers used to validate subject and actor tokens of type jwt.
+	// If nil, this token type is not supported.
+	JWTKeyStorage rfc7523.RFC7523KeyStorage
+
+	Config interface {
+		fosite.AccessTokenLifespanProvider
+		fosite.IDTokenIssuerProvider
+		fosite.TokenURLProvider
+		fosite.GetJWTMaxDurationProvider
+		fosite.AudienceStrategyProvider
+		fosite.ScopeStrategyProvider
+	}
+
+	*oauth2.HandleHelper
+}
+
+var _ fosite.TokenEndpointHandler = (*Handler)(nil)
+var _ fosite.AuthorizationServerMetadataHandler = (*Handler)(nil)
+
+// HandleTokenEndpointRequest implements https://www.rfc-editor.org/rfc/rfc8693#section-2.1
+func (c *Handler) HandleTokenEndpointRequest(ctx context.Context, request fosite.AccessRequester) error {
+	if err := c.CheckRequest(ctx, request); err != nil {
+		return err
+	}
+
+	form := request.GetRequestForm()
+	client := request.GetClient()
+	policy := getPolicy(client)
+
+	subjectToken, subjectTokenType := form.Get("subject_token"), form.Get("subject_token_type")
+	if subjectToken == "" || subjectTokenType == "" {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"subject_token\" and \"subject_token_type\" request parameters must be set when using grant_type of '%s'.", fosite.GrantTypeTokenExchange))
+	}
+
+	actorToken, actorTokenType := form.Get("actor_token"), form.Get("actor_token_type")
+	if actorToken != "" && actorTokenType == "" {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The \"actor_token_type\" request parameter must be set when the \"actor_token\" request parameter is set."))
+	} else if actorToken == "" && actorTokenType != "" {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The \"actor_token_type\" request parameter must not be set when the \"actor_token\" request parameter is not set."))
+	}
+
+	if requested := form.Get("requested_token_type"); requested != "" && requested != AccessTokenType {
+		return errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The requested token type '%s' is not supported, only '%s' may be requested.", requested, AccessTokenType))
+	}
+
+	if !policy.allowsSubjectTokenType(subjectTokenType) {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to exchange tokens of type '%s'.", subjectTokenType))
+	}
+
+	subject, err := c.validateToken(ctx, request, subjectToken, subjectTokenType, "subject_token")
+	if err != nil {
+		return err
+	}
+
+	if subject.issuer != "" && !policy.allowsIssuer(subject.issuer) {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to exchange tokens issued by '%s'.", subject.issuer))
+	}
+
+	ownToken := subject.issuedTo(client.GetID())
+	if !ownToken {
+		for _, id := range subject.clientIDs {
+			if !policy.allowsSubjectClient(id) {
+				return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to exchange tokens issued to OAuth 2.0 Client '%s'.", id))
+			}
+		}
+	}
+
+	if !policy.allowsSubject(subject.subject) {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to exchange tokens of subject '%s'.", subject.subject))
+	}
+
+	act, _ := subject.claims["act"].(map[string]interface{})
+	if actorToken != "" {
+		actor, err := c.validateToken(ctx, request, actorToken, actorTokenType, "actor_token")
+		if err != nil {
+			return err
+		}
+
+		if actor.issuer != "" && !policy.allowsIssuer(actor.issuer) {
+			return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to exchange tokens with actors issued by '%s'.", actor.issuer))
+		}
+
+		if !policy.allowsActor(actor.subject) {
+			return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to exchange tokens with actor '%s'.", actor.subject))
+		}
+
+		if mayAct, ok := subject.claims["may_act"].(map[string]interface{}); ok {
+			if sub, _ := mayAct["sub"].(string); sub != actor.subject {
+				return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The \"may_act\" claim of the \"subject_token\" does not allow actor '%s' to act on behalf of the subject.", actor.subject))
+			}
+		}
+
+		if err := c.markUsed(ctx, actor); err != nil {
+			return err
+		}
+
+		act = newActClaim(actor, act)
+	} else if !ownToken && !policy.AllowImpersonation {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHint("The OAuth 2.0 Client is not allowed to impersonate the subject of tokens which were not issued to it, an \"actor_token\" is required."))
+	}
+
+	if err := c.grantScopes(ctx, request, subject); err != nil {
+		return err
+	}
+
+	if err := c.Config.GetAudienceStrategy(ctx)(client.GetAudience(), request.GetRequestedAudience()); err != nil {
+		return err
+	}
+
+	for _, audience := range request.GetRequestedAudience() {
+		request.GrantAudience(audience)
+	}
+
+	session, err := c.getSessionFromRequest(request)
+	if err != nil {
+		return err
+	}
+
+	session.SetSubject(subject.subject)
+	session.SetActor(act)
+	if container, ok := session.(oauth2.JWTSessionContainer); ok {
+		if claims := container.GetJWTClaims(); claims != nil {
+			if jwtClaims, ok := claims.(*jwt.JWTClaims); ok {
+				jwtClaims.Subject = subject.subject
+			}
+		}
+	}
+
+	atLifespan := fosite.GetEffectiveLifespan(client, fosite.GrantTypeTokenExchange, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
+	session.SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(atLifespan).Round(time.Second))
+
+	return nil
+}
+
+func (c *Handler) PopulateTokenEndpointResponse(ctx context.Context, request fosite.AccessRequester, response fosite.AccessResponder) error {
+	if err := c.CheckRequest(ctx, request); err != nil {
+		return err
+	}
+
+	atLifespan := fosite.GetEffectiveLifespan(request.GetClient(), fosite.GrantTypeTokenExchange, fosite.AccessToken, c.Config.GetAccessTokenLifespan(ctx))
+	if err := c.IssueAccessToken(ctx, atLifespan, request, response); err != nil {
+		return err
+	}
+
+	response.SetExtra("issued_token_type", AccessTokenType)
+	return nil
+}
+
+func (c *Handler) CanSkipClientAuth(ctx context.Context, requester fosite.AccessRequester) bool {
+	return false
+}
+
+func (c *Handler) CanHandleTokenEndpointRequest(ctx context.Context, requester fosite.AccessRequester) bool {
+	// grant_type REQUIRED.
+	// Value MUST be set to "urn:ietf:params:oauth:grant-type:token-exchange"
+	return requester.GetGrantTypes().ExactOne(string(fosite.GrantTypeTokenExchange))
+}
+
+func (c *Handler) CheckRequest(ctx context.Context, request fosite.AccessRequester) error {
+	if !c.CanHandleTokenEndpointRequest(ctx, request) {
+		return errorsx.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	if !request.GetClient().GetGrantTypes().Has(string(fosite.GrantTypeTokenExchange)) {
+		return errorsx.WithStack(fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant \"%s\".", fosite.GrantTypeTokenExchange))
+	}
+
+	return nil
+}
+
+// grantScopes grants the requested scopes if they are held by both the subject token and the client. If no scopes
+// are requested, the scopes of the subject token which the client is allowed to request are granted.
+func (c *Handler) grantScopes(ctx context.Context, request fosite.AccessRequester, subject *validatedToken) error {
+	strategy := c.Config.GetScopeStrategy(ctx)
+	clientScopes := request.GetClient().GetScopes()
+
+	requested := request.GetRequestedScopes()
+	if len(requested) == 0 {
+		for _, scope := range subject.scopes {
+			if strategy(clientScopes, scope) {
+				request.GrantScope(scope)
+			}
+		}
+		return nil
+	}
+
+	for _, scope := range requested {
+		if !strategy(clientScopes, scope) {
+			return errorsx.WithStack(fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope))
+		}
+		if subject.scopes != nil && !strategy(subject.scopes, scope) {
+			return errorsx.WithStack(fosite.ErrInvalidScope.WithHintf("The scope '%s' exceeds the scopes of the \"subject_token\".", scope))
+		}
+		request.GrantScope(scope)
+	}
+
+	return nil
+}
+
+// newActClaim builds the "act" claim identifying the actor, nesting the prior actor chain of the subject token as
+// described in https://www.rfc-editor.org/rfc/rfc8693#section-4.1
+func newActClaim(actor *validatedToken, prior map[string]interface{}) map[string]interface{} {
+	act := map[string]interface{}{"sub": actor.subject}
+	if actor.issuer != "" {
+		act["iss"] = actor.issuer
+	}
+	if len(prior) > 0 {
+		act["act"] = prior
+	}
+	return act
+}
+
+type extendedSession interface {
+	Session
+	fosite.Session
+}
+
+func (c *Handler) getSessionFromRequest(requester fosite.AccessRequester) (extendedSession, error) {
+	session := requester.GetSession()
+	if s, ok := session.(extendedSession); !ok {
+		return nil, errorsx.WithStack(
+			fosite.ErrServerError.WithHintf("Session must implement rfc8693.Session but got type: %T", session),
+		)
+	} else {
+		return s, nil
+	}
+}
+
+// PopulateAuthorizationServerMetadata adds the token exchange grant.
+func (c *Handler) PopulateAuthorizationServerMetadata(ctx context.Context, metadata *fosite.AuthorizationServerMetadata) {
+	metadata.AddGrantTypes("urn:ietf:params:oauth:grant-type:token-exchange")
+}
@@ -0,0 +1,471 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8693_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	josejwt "github.com/go-jose/go-jose/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
+	. "github.com/ory/fosite/handler/rfc8693"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/hmac"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestTokenExchangeHandler(t *testing.T) {
+	ctx := context.Background()
+	config := &fosite.Config{
+		GlobalSecret:                  []byte("foobarfoobarfoobarfoobarfoobarfoobarfoobarfoobar"),
+		AccessTokenLifespan:           time.Hour,
+		IDTokenIssuer:                 "https://auth.example.com",
+		TokenURL:                      "https://auth.example.com/oauth2/token",
+		GrantTypeJWTBearerMaxDuration: time.Hour,
+	}
+	coreStrategy := &oauth2.HMACSHAStrategy{
+		Enigma: &hmac.HMACStrategy{Config: config},
+		Config: config,
+	}
+	idTokenKey := gen.MustRSAKey()
+	idTokenSigner := &jwt.DefaultSigner{GetPrivateKey: func(context.Context) (interface{}, error) {
+		return idTokenKey, nil
+	}}
+	externalKey := gen.MustRSAKey()
+
+	newClient := func(id string, policy *Policy) *DefaultClient {
+		return &DefaultClient{
+			DefaultClient: &fosite.DefaultClient{
+				ID:         id,
+				GrantTypes: []string{string(fosite.GrantTypeTokenExchange)},
+				Scopes:     []string{"foo", "bar"},
+				Audience:   []string{"https://api.example.com"},
+			},
+			TokenExchangePolicy: policy,
+		}
+	}
+
+	setup := func(t *testing.T) (*storage.MemoryStore, *Handler) {
+		store := storage.NewMemoryStore()
+		store.IssuerPublicKeys["https://issuer.example.com"] = storage.IssuerPublicKeys{
+			Issuer: "https://issuer.example.com",
+			KeysBySub: map[string]storage.SubjectPublicKeys{
+				"external-user": {
+					Subject: "external-user",
+					Keys: map[string]storage.PublicKeyScopes{
+						"key-1": {
+							Key:    &jose.JSONWebKey{Key: &externalKey.PublicKey, KeyID: "key-1", Algorithm: string(jose.RS256)},
+							Scopes: []string{"foo"},
+						},
+					},
+				},
+			},
+		}
+		return store, &Handler{
+			TokenIntrospector: &oauth2.CoreValidator{CoreStrategy: coreStrategy, CoreStorage: store, Config: config},
+			IDTokenSigner:     idTokenSigner,
+			JWTKeyStorage:     store,
+			Config:            config,
+			HandleHelper: &oauth2.HandleHelper{
+				AccessTokenStrategy: coreStrategy,
+				AccessTokenStorage:  store,
+				Config:              config,
+			},
+		}
+	}
+
+	issueAccessToken := func(t *testing.T, store *storage.MemoryStore, client fosite.Client, subject string, scopes ...string) string {
+		ar := fosite.NewAccessRequest(&oauth2.JWTSession{JWTClaims: &jwt.JWTClaims{Subject: subject}, Subject: subject})
+		ar.Client = client
+		ar.GrantedScope = scopes
+		ar.GetSession().SetExpiresAt(fosite.AccessToken, time.Now().UTC().Add(time.Hour))
+		token, signature, err := coreStrategy.GenerateAccessToken(ctx, ar)
+		require.NoError(t, err)
+		require.NoError(t, store.CreateAccessTokenSession(ctx, signature, ar))
+		return token
+	}
+
+	issueJWT := func(t *testing.T, claims josejwt.Claims) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: externalKey}, (&jose.SignerOptions{}).WithHeader("kid", "key-1"))
+		require.NoError(t, err)
+		token, err := josejwt.Signed(signer).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	newRequest := func(client fosite.Client, form url.Values) *fosite.AccessRequest {
+		ar := fosite.NewAccessRequest(&oauth2.JWTSession{})
+		ar.GrantTypes = fosite.Arguments{string(fosite.GrantTypeTokenExchange)}
+		ar.Client = client
+		ar.Form = form
+		return ar
+	}
+
+	t.Run("case=should not handle other grant types", func(t *testing.T) {
+		_, h := setup(t)
+		ar := newRequest(newClient("client", nil), url.Values{})
+		ar.GrantTypes = fosite.Arguments{"client_credentials"}
+		assert.False(t, h.CanHandleTokenEndpointRequest(ctx, ar))
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnknownRequest)
+	})
+
+	t.Run("case=should fail if the client may not use the grant type", func(t *testing.T) {
+		_, h := setup(t)
+		client := newClient("client", nil)
+		client.GrantTypes = []string{"client_credentials"}
+		ar := newRequest(client, url.Values{})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnauthorizedClient)
+	})
+
+	t.Run("case=should fail without subject token", func(t *testing.T) {
+		_, h := setup(t)
+		ar := newRequest(newClient("client", nil), url.Values{"subject_token_type": {AccessTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should fail with an invalid subject token", func(t *testing.T) {
+		_, h := setup(t)
+		ar := newRequest(newClient("client", nil), url.Values{"subject_token": {"foo.bar"}, "subject_token_type": {AccessTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should fail with an unsupported subject token type", func(t *testing.T) {
+		_, h := setup(t)
+		ar := newRequest(newClient("client", nil), url.Values{"subject_token": {"foo"}, "subject_token_type": {"urn:example:unknown"}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should fail with an unsupported requested token type", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", nil)
+		token := issueAccessToken(t, store, client, "alice", "foo")
+		ar := newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}, "requested_token_type": {RefreshTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should fail if actor token type is missing", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", nil)
+		token := issueAccessToken(t, store, client, "alice", "foo")
+		ar := newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}, "actor_token": {token}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should exchange own access token and downscope", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", nil)
+		token := issueAccessToken(t, store, client, "alice", "foo", "bar")
+		ar := newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}})
+		ar.RequestedScope = fosite.Arguments{"foo"}
+		ar.RequestedAudience = fosite.Arguments{"https://api.example.com"}
+
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		assert.Equal(t, fosite.Arguments{"foo"}, ar.GetGrantedScopes())
+		assert.Equal(t, fosite.Arguments{"https://api.example.com"}, ar.GetGrantedAudience())
+		assert.Equal(t, "alice", ar.GetSession().GetSubject())
+		assert.Nil(t, ar.GetSession().(*oauth2.JWTSession).GetActor())
+
+		resp := fosite.NewAccessResponse()
+		require.NoError(t, h.PopulateTokenEndpointResponse(ctx, ar, resp))
+		assert.NotEmpty(t, resp.GetAccessToken())
+		assert.Equal(t, AccessTokenType, resp.GetExtra("issued_token_type"))
+	})
+
+	t.Run("case=should grant subject token scopes if none are requested", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", nil)
+		client.Scopes = []string{"foo"}
+		token := issueAccessToken(t, store, client, "alice", "foo", "bar")
+		ar := newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}})
+
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		assert.Equal(t, fosite.Arguments{"foo"}, ar.GetGrantedScopes())
+	})
+
+	t.Run("case=should fail if requested scope exceeds the subject token", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", nil)
+		token := issueAccessToken(t, store, client, "alice", "foo")
+		ar := newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}})
+		ar.RequestedScope = fosite.Arguments{"bar"}
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidScope)
+	})
+
+	t.Run("case=should fail to impersonate with a token of another client", func(t *testing.T) {
+		store, h := setup(t)
+		token := issueAccessToken(t, store, newClient("other", nil), "alice", "foo")
+		ar := newRequest(newClient("client", &Policy{AllowedSubjectClients: []string{"other"}}), url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnauthorizedClient)
+	})
+
+	t.Run("case=should fail to exchange tokens of clients not allowed by the policy", func(t *testing.T) {
+		store, h := setup(t)
+		token := issueAccessToken(t, store, newClient("other", nil), "alice", "foo")
+		ar := newRequest(newClient("client", &Policy{AllowImpersonation: true}), url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnauthorizedClient)
+	})
+
+	t.Run("case=should impersonate if allowed by the policy", func(t *testing.T) {
+		store, h := setup(t)
+		token := issueAccessToken(t, store, newClient("other", nil), "alice", "foo")
+		ar := newRequest(newClient("client", &Policy{AllowedSubjectClients: []string{"*"}, AllowImpersonation: true}), url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}})
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		assert.Equal(t, "alice", ar.GetSession().GetSubject())
+	})
+
+	t.Run("case=should enforce allowed subjects and token types", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", &Policy{AllowedSubjects: []string{"bob"}})
+		token := issueAccessToken(t, store, client, "alice", "foo")
+		ar := newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnauthorizedClient)
+
+		client = newClient("client", &Policy{AllowedSubjectTokenTypes: []string{IDTokenType}})
+		ar = newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnauthorizedClient)
+	})
+
+	t.Run("case=should delegate and build the act claim chain", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", &Policy{AllowedSubjectClients: []string{"other"}, AllowedActors: []string{"service"}})
+		subjectToken := issueAccessToken(t, store, newClient("other", nil), "alice", "foo")
+		actorToken := issueAccessToken(t, store, client, "service", "foo")
+
+		ar := newRequest(client, url.Values{
+			"subject_token": {subjectToken}, "subject_token_type": {AccessTokenType},
+			"actor_token": {actorToken}, "actor_token_type": {AccessTokenType},
+		})
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+
+		session := ar.GetSession().(*oauth2.JWTSession)
+		assert.Equal(t, "alice", session.GetSubject())
+		assert.Equal(t, "alice", session.JWTClaims.Subject)
+		assert.Equal(t, map[string]interface{}{"sub": "service"}, session.GetActor())
+
+		resp := fosite.NewAccessResponse()
+		require.NoError(t, h.PopulateTokenEndpointResponse(ctx, ar, resp))
+
+		// Exchanging the delegated token again nests the prior actor.
+		secondActor := issueAccessToken(t, store, client, "service", "foo")
+		ar = newRequest(client, url.Values{
+			"subject_token": {resp.GetAccessToken()}, "subject_token_type": {AccessTokenType},
+			"actor_token": {secondActor}, "actor_token_type": {AccessTokenType},
+		})
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		assert.Equal(t, map[string]interface{}{"sub": "service", "act": map[string]interface{}{"sub": "service"}}, ar.GetSession().(*oauth2.JWTSession).GetActor())
+	})
+
+	t.Run("case=should fail if the actor is not allowed", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", &Policy{AllowedActors: []string{"service"}})
+		subjectToken := issueAccessToken(t, store, client, "alice", "foo")
+		actorToken := issueAccessToken(t, store, client, "mallory", "foo")
+
+		ar := newRequest(client, url.Values{
+			"subject_token": {subjectToken}, "subject_token_type": {AccessTokenType},
+			"actor_token": {actorToken}, "actor_token_type": {AccessTokenType},
+		})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnauthorizedClient)
+	})
+
+	t.Run("case=should exchange an ID token", func(t *testing.T) {
+		_, h := setup(t)
+		client := newClient("client", nil)
+		token, _, err := idTokenSigner.Generate(ctx, jwt.MapClaims{
+			"iss": config.IDTokenIssuer,
+			"sub": "alice",
+			"aud": []string{"client"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"iat": time.Now().Unix(),
+		}, jwt.NewHeaders())
+		require.NoError(t, err)
+
+		ar := newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {IDTokenType}})
+		ar.RequestedScope = fosite.Arguments{"foo"}
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		assert.Equal(t, "alice", ar.GetSession().GetSubject())
+		assert.Equal(t, fosite.Arguments{"foo"}, ar.GetGrantedScopes())
+	})
+
+	t.Run("case=should fail with an ID token of another issuer", func(t *testing.T) {
+		_, h := setup(t)
+		token, _, err := idTokenSigner.Generate(ctx, jwt.MapClaims{
+			"iss": "https://evil.example.com",
+			"sub": "alice",
+			"aud": []string{"client"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"iat": time.Now().Unix(),
+		}, jwt.NewHeaders())
+		require.NoError(t, err)
+
+		ar := newRequest(newClient("client", nil), url.Values{"subject_token": {token}, "subject_token_type": {IDTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should fail with a logout token", func(t *testing.T) {
+		_, h := setup(t)
+		headers := jwt.NewHeaders()
+		headers.Add(string(jwt.JWTHeaderType), "logout+jwt")
+		token, _, err := idTokenSigner.Generate(ctx, jwt.MapClaims{
+			"iss":    config.IDTokenIssuer,
+			"sub":    "alice",
+			"aud":    []string{"client"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"iat":    time.Now().Unix(),
+			"jti":    "logout-1",
+			"events": map[string]interface{}{jwt.BackChannelLogoutEvent: map[string]interface{}{}},
+		}, headers)
+		require.NoError(t, err)
+
+		ar := newRequest(newClient("client", nil), url.Values{"subject_token": {token}, "subject_token_type": {IDTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+
+		// Logout tokens without the "typ" header are recognized by their "events" claim.
+		token, _, err = idTokenSigner.Generate(ctx, jwt.MapClaims{
+			"iss":    config.IDTokenIssuer,
+			"sub":    "alice",
+			"aud":    []string{"client"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"iat":    time.Now().Unix(),
+			"events": map[string]interface{}{jwt.BackChannelLogoutEvent: map[string]interface{}{}},
+		}, jwt.NewHeaders())
+		require.NoError(t, err)
+
+		ar = newRequest(newClient("client", nil), url.Values{"subject_token": {token}, "subject_token_type": {IDTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should fail with a JWT secured authorization response", func(t *testing.T) {
+		_, h := setup(t)
+		token, _, err := idTokenSigner.Generate(ctx, jwt.MapClaims{
+			"iss":   config.IDTokenIssuer,
+			"aud":   "client",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"code":  "some-code",
+			"state": "some-state",
+			"sub":   "alice",
+		}, jwt.NewHeaders())
+		require.NoError(t, err)
+
+		ar := newRequest(newClient("client", nil), url.Values{"subject_token": {token}, "subject_token_type": {IDTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should exchange a JWT of a trusted issuer", func(t *testing.T) {
+		_, h := setup(t)
+		token := issueJWT(t, josejwt.Claims{
+			Issuer:   "https://issuer.example.com",
+			Subject:  "external-user",
+			Audience: josejwt.Audience{config.TokenURL},
+			Expiry:   josejwt.NewNumericDate(time.Now().Add(time.Minute)),
+			IssuedAt: josejwt.NewNumericDate(time.Now()),
+			ID:       "jti-1",
+		})
+
+		client := newClient("client", &Policy{AllowedIssuers: []string{"https://issuer.example.com"}, AllowImpersonation: true})
+		ar := newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {JWTTokenType}})
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+		assert.Equal(t, "external-user", ar.GetSession().GetSubject())
+		assert.Equal(t, fosite.Arguments{"foo"}, ar.GetGrantedScopes())
+
+		ar = newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {JWTTokenType}})
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, ar))
+	})
+
+	t.Run("case=should fail with a JWT of an issuer not allowed by the policy", func(t *testing.T) {
+		_, h := setup(t)
+		token := issueJWT(t, josejwt.Claims{
+			Issuer:   "https://issuer.example.com",
+			Subject:  "external-user",
+			Expiry:   josejwt.NewNumericDate(time.Now().Add(time.Minute)),
+			IssuedAt: josejwt.NewNumericDate(time.Now()),
+		})
+
+		for _, policy := range []*Policy{
+			nil,
+			{AllowedSubjectClients: []string{"*"}, AllowImpersonation: true},
+			{AllowedIssuers: []string{"https://other.example.com"}, AllowImpersonation: true},
+		} {
+			ar := newRequest(newClient("client", policy), url.Values{"subject_token": {token}, "subject_token_type": {JWTTokenType}})
+			assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnauthorizedClient)
+		}
+	})
+
+	t.Run("case=should reject replayed JWT actor tokens", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", &Policy{AllowedIssuers: []string{"*"}})
+		subjectToken := issueAccessToken(t, store, client, "alice", "foo")
+		actorToken := issueJWT(t, josejwt.Claims{
+			Issuer:   "https://issuer.example.com",
+			Subject:  "external-user",
+			Expiry:   josejwt.NewNumericDate(time.Now().Add(time.Minute)),
+			IssuedAt: josejwt.NewNumericDate(time.Now()),
+			ID:       "jti-1",
+		})
+
+		form := url.Values{
+			"subject_token": {subjectToken}, "subject_token_type": {AccessTokenType},
+			"actor_token": {actorToken}, "actor_token_type": {JWTTokenType},
+		}
+		require.NoError(t, h.HandleTokenEndpointRequest(ctx, newRequest(client, form)))
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, newRequest(client, form)), fosite.ErrJTIKnown)
+	})
+
+	t.Run("case=should fail with a JWT of an unknown issuer", func(t *testing.T) {
+		_, h := setup(t)
+		token := issueJWT(t, josejwt.Claims{
+			Issuer:  "https://unknown.example.com",
+			Subject: "external-user",
+			Expiry:  josejwt.NewNumericDate(time.Now().Add(time.Minute)),
+		})
+
+		ar := newRequest(newClient("client", &Policy{AllowImpersonation: true}), url.Values{"subject_token": {token}, "subject_token_type": {JWTTokenType}})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrInvalidRequest)
+	})
+
+	t.Run("case=should honor the may_act claim", func(t *testing.T) {
+		_, h := setup(t)
+		client := newClient("client", &Policy{AllowImpersonation: true})
+		subjectToken, _, err := idTokenSigner.Generate(ctx, jwt.MapClaims{
+			"iss":     config.IDTokenIssuer,
+			"sub":     "alice",
+			"aud":     []string{"client"},
+			"exp":     time.Now().Add(time.Hour).Unix(),
+			"iat":     time.Now().Unix(),
+			"may_act": map[string]interface{}{"sub": "service"},
+		}, jwt.NewHeaders())
+		require.NoError(t, err)
+		actorToken, _, err := idTokenSigner.Generate(ctx, jwt.MapClaims{
+			"iss": config.IDTokenIssuer,
+			"sub": "mallory",
+			"aud": []string{"client"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"iat": time.Now().Unix(),
+		}, jwt.NewHeaders())
+		require.NoError(t, err)
+
+		ar := newRequest(client, url.Values{
+			"subject_token": {subjectToken}, "subject_token_type": {IDTokenType},
+			"actor_token": {actorToken}, "actor_token_type": {IDTokenType},
+		})
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrUnauthorizedClient)
+	})
+
+	t.Run("case=should fail if the session does not support token exchange", func(t *testing.T) {
+		store, h := setup(t)
+		client := newClient("client", nil)
+		token := issueAccessToken(t, store, client, "alice", "foo")
+		ar := newRequest(client, url.Values{"subject_token": {token}, "subject_token_type": {AccessTokenType}})
+		ar.SetSession(new(fosite.DefaultSession))
+		assert.ErrorIs(t, h.HandleTokenEndpointRequest(ctx, ar), fosite.ErrServerError)
+	})
+}
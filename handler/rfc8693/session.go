@@ -0,0 +1,13 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8693
+
+// Session must be implemented by the session if RFC8693 is to be supported. oauth2.JWTSession implements it.
+type Session interface {
+	// SetSubject sets the session's subject.
+	SetSubject(subject string)
+
+	// SetActor sets the "act" claim of the issued token.
+	SetActor(act map[string]interface{})
+}
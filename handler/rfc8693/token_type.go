@@ -0,0 +1,12 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8693
+
+// Token type identifiers as specified in https://www.rfc-editor.org/rfc/rfc8693#section-3
+const (
+	AccessTokenType  = "urn:ietf:params:oauth:token-type:access_token"  //nolint:gosec // this is not a hardcoded credential
+	RefreshTokenType = "urn:ietf:params:oauth:token-type:refresh_token" //nolint:gosec // this is not a hardcoded credential
+	IDTokenType      = "urn:ietf:params:oauth:token-type:id_token"      //nolint:gosec // this is not a hardcoded credential
+	JWTTokenType     = "urn:ietf:params:oauth:token-type:jwt"           //nolint:gosec // this is not a hardcoded credential
+)
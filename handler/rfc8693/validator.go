@@ -0,0 +1,253 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package rfc8693
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	josejwt "github.com/go-jose/go-jose/v3/jwt"
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/token/jwt"
+)
+
+// validatedToken is a subject or actor token which passed validation.
+type validatedToken struct {
+	// subject is the subject the token was issued for.
+	subject string
+	// issuer is the issuer of JWTs which were not issued by this authorization server.
+	issuer string
+	// clientIDs are the clients the token was issued to. It is empty for JWTs of other issuers.
+	clientIDs []string
+	// scopes are the scopes of the token, or nil if they are unknown.
+	scopes fosite.Arguments
+	// claims are the claims of the token, used to read "act" and "may_act".
+	claims map[string]interface{}
+	// jti is the "jti" claim of JWTs which were not issued by this authorization server.
+	jti string
+	// expiresAt is the expiry of JWTs which were not issued by this authorization server.
+	expiresAt time.Time
+}
+
+func (t *validatedToken) issuedTo(clientID string) bool {
+	return contains(t.clientIDs, clientID)
+}
+
+func (c *Handler) validateToken(ctx context.Context, request fosite.AccessRequester, token, tokenType, param string) (*validatedToken, error) {
+	switch tokenType {
+	case AccessTokenType:
+		return c.validateOAuth2Token(ctx, request, token, fosite.AccessToken, param)
+	case RefreshTokenType:
+		return c.validateOAuth2Token(ctx, request, token, fosite.RefreshToken, param)
+	case IDTokenType:
+		if c.IDTokenSigner != nil {
+			return c.validateIDToken(ctx, token, param)
+		}
+	case JWTTokenType:
+		if c.JWTKeyStorage != nil {
+			return c.validateJWT(ctx, token, param)
+		}
+	}
+
+	return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The token type '%s' of the \"%s\" parameter is not supported.", tokenType, param))
+}
+
+func (c *Handler) validateOAuth2Token(ctx context.Context, request fosite.AccessRequester, token string, tokenUse fosite.TokenUse, param string) (*validatedToken, error) {
+	if c.TokenIntrospector == nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The token type of the \"%s\" parameter is not supported.", param))
+	}
+
+	or := fosite.NewAccessRequest(request.GetSession().Clone())
+	tu, err := c.TokenIntrospector.IntrospectToken(ctx, token, tokenUse, or, []string{})
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"%s\" is invalid or has expired.", param).WithWrap(err).WithDebug(err.Error()))
+	} else if tu != tokenUse {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"%s\" is not of type '%s'.", param, tokenUse))
+	}
+
+	claims := map[string]interface{}{}
+	if session, ok := or.GetSession().(fosite.ExtraClaimsSession); ok {
+		for k, v := range session.GetExtraClaims() {
+			claims[k] = v
+		}
+	}
+
+	return &validatedToken{
+		subject:   or.GetSession().GetSubject(),
+		clientIDs: []string{or.GetClient().GetID()},
+		scopes:    or.GetGrantedScopes(),
+		claims:    claims,
+	}, nil
+}
+
+func (c *Handler) validateIDToken(ctx context.Context, token string, param string) (*validatedToken, error) {
+	t, err := c.IDTokenSigner.Decode(ctx, token)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"%s\" is not a valid ID Token.", param).WithWrap(err).WithDebug(err.Error()))
+	}
+
+	// Other JWTs signed by the same signer, such as logout tokens, signed UserInfo, introspection and authorization
+	// responses, must not be accepted as ID tokens. They either use a dedicated "typ" header or lack ID token claims.
+	if typ, ok := t.Header[string(jwt.JWTHeaderType)]; ok {
+		if typ, _ := typ.(string); !strings.EqualFold(typ, jwt.JWTHeaderTypeValue) {
+			return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"%s\" is not an ID Token because its \"typ\" header is '%s'.", param, typ))
+		}
+	}
+
+	claims := t.Claims
+	if issuer := c.Config.GetIDTokenIssuer(ctx); issuer != "" && !claims.VerifyIssuer(issuer, true) {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"%s\" was not issued by this authorization server.", param))
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"%s\" does not contain a \"sub\" claim.", param))
+	}
+
+	for _, claim := range []string{"exp", "iat"} {
+		if _, ok := claims[claim]; !ok {
+			return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"%s\" does not contain a \"%s\" claim.", param, claim))
+		}
+	}
+
+	if _, ok := claims["events"]; ok {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"%s\" is a logout token, not an ID Token.", param))
+	}
+
+	var audience []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		if aud != "" {
+			audience = []string{aud}
+		}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok && s != "" {
+				audience = append(audience, s)
+			}
+		}
+	}
+	if len(audience) == 0 {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The \"%s\" does not contain an \"aud\" claim.", param))
+	}
+
+	return &validatedToken{
+		subject:   subject,
+		clientIDs: audience,
+		claims:    claims,
+	}, nil
+}
+
+func (c *Handler) validateJWT(ctx context.Context, token string, param string) (*validatedToken, error) {
+	t, err := josejwt.ParseSigned(token)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Unable to parse the JSON Web Token passed in the \"%s\" parameter.", param).WithWrap(err).WithDebug(err.Error()))
+	}
+
+	unverified := josejwt.Claims{}
+	if err := t.UnsafeClaimsWithoutVerification(&unverified); err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Unable to parse the claims of the JSON Web Token passed in the \"%s\" parameter.", param).WithWrap(err).WithDebug(err.Error()))
+	} else if unverified.Issuer == "" || unverified.Subject == "" {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The JSON Web Token passed in the \"%s\" parameter MUST contain an \"iss\" (issuer) and a \"sub\" (subject) claim.", param))
+	}
+
+	key, err := c.findPublicKey(ctx, t, unverified)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("No public JWK was registered for issuer \"%s\" and subject \"%s\" which verifies the JSON Web Token passed in the \"%s\" parameter.", unverified.Issuer, unverified.Subject, param).WithWrap(err).WithDebug(err.Error()))
+	}
+
+	verified := josejwt.Claims{}
+	claims := map[string]interface{}{}
+	if err := t.Claims(key, &verified, &claims); err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Unable to verify the integrity of the JSON Web Token passed in the \"%s\" parameter.", param).WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if verified.Expiry == nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The JSON Web Token passed in the \"%s\" parameter MUST contain an \"exp\" (expiration time) claim.", param))
+	}
+
+	if err := verified.ValidateWithLeeway(josejwt.Expected{Time: time.Now()}, 0); err != nil {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The JSON Web Token passed in the \"%s\" parameter is expired or not yet valid.", param).WithWrap(err).WithDebug(err.Error()))
+	}
+
+	issuedAt := time.Now()
+	if verified.IssuedAt != nil {
+		issuedAt = verified.IssuedAt.Time()
+	}
+	if verified.Expiry.Time().Sub(issuedAt) > c.Config.GetJWTMaxDuration(ctx) {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("The JSON Web Token passed in the \"%s\" parameter contains an \"exp\" (expiration time) claim that is unreasonably far in the future.", param))
+	}
+
+	if len(verified.Audience) > 0 && !audienceMatchesTokenURLs(verified.Audience, c.Config.GetTokenURLs(ctx)) {
+		return nil, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf(`The JSON Web Token passed in the "%s" parameter contains an "aud" (audience) claim which does not identify this authorization server as "%s".`, param, strings.Join(c.Config.GetTokenURLs(ctx), `" or "`)))
+	}
+
+	scopes, err := c.JWTKeyStorage.GetPublicKeyScopes(ctx, verified.Issuer, verified.Subject, key.KeyID)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return &validatedToken{
+		subject:   verified.Subject,
+		issuer:    verified.Issuer,
+		scopes:    scopes,
+		claims:    claims,
+		jti:       verified.ID,
+		expiresAt: verified.Expiry.Time(),
+	}, nil
+}
+
+// markUsed rejects JWTs of other issuers whose "jti" was seen before and marks it as used. Subject tokens may be
+// exchanged several times, for example for different audiences, so only actor tokens are checked for replay.
+func (c *Handler) markUsed(ctx context.Context, t *validatedToken) error {
+	if t.jti == "" {
+		return nil
+	}
+
+	if used, err := c.JWTKeyStorage.IsJWTUsed(ctx, t.jti); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if used {
+		return errorsx.WithStack(fosite.ErrJTIKnown)
+	}
+
+	if err := c.JWTKeyStorage.MarkJWTUsedForTime(ctx, t.jti, t.expiresAt); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return nil
+}
+
+func (c *Handler) findPublicKey(ctx context.Context, t *josejwt.JSONWebToken, unverified josejwt.Claims) (*jose.JSONWebKey, error) {
+	for _, header := range t.Headers {
+		if header.KeyID != "" {
+			return c.JWTKeyStorage.GetPublicKey(ctx, unverified.Issuer, unverified.Subject, header.KeyID)
+		}
+	}
+
+	keys, err := c.JWTKeyStorage.GetPublicKeys(ctx, unverified.Issuer, unverified.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys.Keys {
+		key := key
+		if err := t.Claims(&key, &josejwt.Claims{}); err == nil {
+			return &key, nil
+		}
+	}
+
+	return nil, errorsx.WithStack(fosite.ErrNotFound)
+}
+
+func audienceMatchesTokenURLs(audience josejwt.Audience, tokenURLs []string) bool {
+	for _, tokenURL := range tokenURLs {
+		if audience.Contains(tokenURL) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package integration_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/integration/clients"
+)
+
+func TestAuthorizeResponseIssuerIdentification(t *testing.T) {
+	config := &fosite.Config{AccessTokenIssuer: "https://auth.example.com"}
+	f := compose.Compose(config, fositeStore, hmacStrategy, compose.OAuth2AuthorizeExplicitFactory)
+	ts := mockServer(t, f, &openid.DefaultSession{Subject: "foo-sub"})
+	defer ts.Close()
+
+	fositeStore.Clients["my-client"].(*fosite.DefaultClient).RedirectURIs[0] = ts.URL + "/callback"
+
+	httpClient := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	for _, c := range []struct {
+		description string
+		scopes      []string
+		expectError bool
+	}{
+		{description: "should add the issuer to the authorization response", scopes: []string{"fosite"}},
+		{description: "should add the issuer to the authorization error response", scopes: []string{"not-exist"}, expectError: true},
+	} {
+		t.Run("case="+c.description, func(t *testing.T) {
+			oauthClient := newOAuth2Client(ts)
+			oauthClient.Scopes = c.scopes
+
+			resp, err := httpClient.Get(oauthClient.AuthCodeURL("12345678901234567890"))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			location, err := resp.Location()
+			require.NoError(t, err)
+
+			response := location.Query()
+			assert.Equal(t, c.expectError, response.Get("error") != "")
+			assert.NoError(t, clients.VerifyAuthorizationResponseIssuer(response, "https://auth.example.com", true))
+			assert.Error(t, clients.VerifyAuthorizationResponseIssuer(response, "https://attacker.example.com", true))
+		})
+	}
+}
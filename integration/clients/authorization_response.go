@@ -0,0 +1,32 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// VerifyAuthorizationResponseIssuer protects clients against mix-up attacks by checking that the authorization
+// response was sent by the authorization server the authorization request was sent to, see
+// https://www.rfc-editor.org/rfc/rfc9207#section-2.4
+//
+// The "iss" parameter of the response must equal the issuer the client expects. If the response has no "iss"
+// parameter, it is only accepted if the authorization server does not advertise
+// "authorization_response_iss_parameter_supported" in its metadata.
+func VerifyAuthorizationResponseIssuer(response url.Values, issuer string, issParameterSupported bool) error {
+	iss, ok := response["iss"]
+	if !ok {
+		if issParameterSupported {
+			return errors.New("the authorization response has no 'iss' parameter although the authorization server supports it")
+		}
+		return nil
+	}
+
+	if len(iss) != 1 || iss[0] != issuer {
+		return fmt.Errorf("the 'iss' parameter %q of the authorization response does not match the expected issuer %q", iss, issuer)
+	}
+	return nil
+}
@@ -34,6 +34,7 @@ type introspectionResponse struct {
 	IssuedAt  int64    `json:"iat,omitempty"`
 	Subject   string   `json:"sub,omitempty"`
 	Username  string   `json:"username,omitempty"`
+	AuthTime  int64    `json:"auth_time,omitempty"`
 }
 
 func TestRefreshTokenFlow(t *testing.T) {
@@ -94,6 +94,20 @@ func (mr *MockAccessRequesterMockRecorder) GetGrantedAudience() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGrantedAudience", reflect.TypeOf((*MockAccessRequester)(nil).GetGrantedAudience))
 }
 
+// GetGrantedAuthorizationDetails mocks base method.
+func (m *MockAccessRequester) GetGrantedAuthorizationDetails() fosite.AuthorizationDetails {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGrantedAuthorizationDetails")
+	ret0, _ := ret[0].(fosite.AuthorizationDetails)
+	return ret0
+}
+
+// GetGrantedAuthorizationDetails indicates an expected call of GetGrantedAuthorizationDetails.
+func (mr *MockAccessRequesterMockRecorder) GetGrantedAuthorizationDetails() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGrantedAuthorizationDetails", reflect.TypeOf((*MockAccessRequester)(nil).GetGrantedAuthorizationDetails))
+}
+
 // GetGrantedScopes mocks base method.
 func (m *MockAccessRequester) GetGrantedScopes() fosite.Arguments {
 	m.ctrl.T.Helper()
@@ -164,6 +178,20 @@ func (mr *MockAccessRequesterMockRecorder) GetRequestedAudience() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestedAudience", reflect.TypeOf((*MockAccessRequester)(nil).GetRequestedAudience))
 }
 
+// GetRequestedAuthorizationDetails mocks base method.
+func (m *MockAccessRequester) GetRequestedAuthorizationDetails() fosite.AuthorizationDetails {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRequestedAuthorizationDetails")
+	ret0, _ := ret[0].(fosite.AuthorizationDetails)
+	return ret0
+}
+
+// GetRequestedAuthorizationDetails indicates an expected call of GetRequestedAuthorizationDetails.
+func (mr *MockAccessRequesterMockRecorder) GetRequestedAuthorizationDetails() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestedAuthorizationDetails", reflect.TypeOf((*MockAccessRequester)(nil).GetRequestedAuthorizationDetails))
+}
+
 // GetRequestedScopes mocks base method.
 func (m *MockAccessRequester) GetRequestedScopes() fosite.Arguments {
 	m.ctrl.T.Helper()
@@ -204,6 +232,18 @@ func (mr *MockAccessRequesterMockRecorder) GrantAudience(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantAudience", reflect.TypeOf((*MockAccessRequester)(nil).GrantAudience), arg0)
 }
 
+// GrantAuthorizationDetails mocks base method.
+func (m *MockAccessRequester) GrantAuthorizationDetails(arg0 fosite.AuthorizationDetails) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GrantAuthorizationDetails", arg0)
+}
+
+// GrantAuthorizationDetails indicates an expected call of GrantAuthorizationDetails.
+func (mr *MockAccessRequesterMockRecorder) GrantAuthorizationDetails(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantAuthorizationDetails", reflect.TypeOf((*MockAccessRequester)(nil).GrantAuthorizationDetails), arg0)
+}
+
 // GrantScope mocks base method.
 func (m *MockAccessRequester) GrantScope(arg0 string) {
 	m.ctrl.T.Helper()
@@ -266,6 +306,18 @@ func (mr *MockAccessRequesterMockRecorder) SetRequestedAudience(arg0 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRequestedAudience", reflect.TypeOf((*MockAccessRequester)(nil).SetRequestedAudience), arg0)
 }
 
+// SetRequestedAuthorizationDetails mocks base method.
+func (m *MockAccessRequester) SetRequestedAuthorizationDetails(arg0 fosite.AuthorizationDetails) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRequestedAuthorizationDetails", arg0)
+}
+
+// SetRequestedAuthorizationDetails indicates an expected call of SetRequestedAuthorizationDetails.
+func (mr *MockAccessRequesterMockRecorder) SetRequestedAuthorizationDetails(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRequestedAuthorizationDetails", reflect.TypeOf((*MockAccessRequester)(nil).SetRequestedAuthorizationDetails), arg0)
+}
+
 // SetRequestedScopes mocks base method.
 func (m *MockAccessRequester) SetRequestedScopes(arg0 fosite.Arguments) {
 	m.ctrl.T.Helper()
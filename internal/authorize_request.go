@@ -108,6 +108,20 @@ func (mr *MockAuthorizeRequesterMockRecorder) GetGrantedAudience() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGrantedAudience", reflect.TypeOf((*MockAuthorizeRequester)(nil).GetGrantedAudience))
 }
 
+// GetGrantedAuthorizationDetails mocks base method.
+func (m *MockAuthorizeRequester) GetGrantedAuthorizationDetails() fosite.AuthorizationDetails {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGrantedAuthorizationDetails")
+	ret0, _ := ret[0].(fosite.AuthorizationDetails)
+	return ret0
+}
+
+// GetGrantedAuthorizationDetails indicates an expected call of GetGrantedAuthorizationDetails.
+func (mr *MockAuthorizeRequesterMockRecorder) GetGrantedAuthorizationDetails() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGrantedAuthorizationDetails", reflect.TypeOf((*MockAuthorizeRequester)(nil).GetGrantedAuthorizationDetails))
+}
+
 // GetGrantedScopes mocks base method.
 func (m *MockAuthorizeRequester) GetGrantedScopes() fosite.Arguments {
 	m.ctrl.T.Helper()
@@ -178,6 +192,20 @@ func (mr *MockAuthorizeRequesterMockRecorder) GetRequestedAt() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestedAt", reflect.TypeOf((*MockAuthorizeRequester)(nil).GetRequestedAt))
 }
 
+// GetRequestedACRValues mocks base method.
+func (m *MockAuthorizeRequester) GetRequestedACRValues() fosite.Arguments {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRequestedACRValues")
+	ret0, _ := ret[0].(fosite.Arguments)
+	return ret0
+}
+
+// GetRequestedACRValues indicates an expected call of GetRequestedACRValues.
+func (mr *MockAuthorizeRequesterMockRecorder) GetRequestedACRValues() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestedACRValues", reflect.TypeOf((*MockAuthorizeRequester)(nil).GetRequestedACRValues))
+}
+
 // GetRequestedAudience mocks base method.
 func (m *MockAuthorizeRequester) GetRequestedAudience() fosite.Arguments {
 	m.ctrl.T.Helper()
@@ -192,6 +220,34 @@ func (mr *MockAuthorizeRequesterMockRecorder) GetRequestedAudience() *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestedAudience", reflect.TypeOf((*MockAuthorizeRequester)(nil).GetRequestedAudience))
 }
 
+// GetRequestedAuthorizationDetails mocks base method.
+func (m *MockAuthorizeRequester) GetRequestedAuthorizationDetails() fosite.AuthorizationDetails {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRequestedAuthorizationDetails")
+	ret0, _ := ret[0].(fosite.AuthorizationDetails)
+	return ret0
+}
+
+// GetRequestedAuthorizationDetails indicates an expected call of GetRequestedAuthorizationDetails.
+func (mr *MockAuthorizeRequesterMockRecorder) GetRequestedAuthorizationDetails() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestedAuthorizationDetails", reflect.TypeOf((*MockAuthorizeRequester)(nil).GetRequestedAuthorizationDetails))
+}
+
+// GetRequestedClaims mocks base method.
+func (m *MockAuthorizeRequester) GetRequestedClaims() *fosite.ClaimsRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRequestedClaims")
+	ret0, _ := ret[0].(*fosite.ClaimsRequest)
+	return ret0
+}
+
+// GetRequestedClaims indicates an expected call of GetRequestedClaims.
+func (mr *MockAuthorizeRequesterMockRecorder) GetRequestedClaims() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestedClaims", reflect.TypeOf((*MockAuthorizeRequester)(nil).GetRequestedClaims))
+}
+
 // GetRequestedScopes mocks base method.
 func (m *MockAuthorizeRequester) GetRequestedScopes() fosite.Arguments {
 	m.ctrl.T.Helper()
@@ -274,6 +330,18 @@ func (mr *MockAuthorizeRequesterMockRecorder) GrantAudience(arg0 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantAudience", reflect.TypeOf((*MockAuthorizeRequester)(nil).GrantAudience), arg0)
 }
 
+// GrantAuthorizationDetails mocks base method.
+func (m *MockAuthorizeRequester) GrantAuthorizationDetails(arg0 fosite.AuthorizationDetails) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GrantAuthorizationDetails", arg0)
+}
+
+// GrantAuthorizationDetails indicates an expected call of GrantAuthorizationDetails.
+func (mr *MockAuthorizeRequesterMockRecorder) GrantAuthorizationDetails(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantAuthorizationDetails", reflect.TypeOf((*MockAuthorizeRequester)(nil).GrantAuthorizationDetails), arg0)
+}
+
 // GrantScope mocks base method.
 func (m *MockAuthorizeRequester) GrantScope(arg0 string) {
 	m.ctrl.T.Helper()
@@ -362,6 +430,18 @@ func (mr *MockAuthorizeRequesterMockRecorder) SetRequestedAudience(arg0 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRequestedAudience", reflect.TypeOf((*MockAuthorizeRequester)(nil).SetRequestedAudience), arg0)
 }
 
+// SetRequestedAuthorizationDetails mocks base method.
+func (m *MockAuthorizeRequester) SetRequestedAuthorizationDetails(arg0 fosite.AuthorizationDetails) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRequestedAuthorizationDetails", arg0)
+}
+
+// SetRequestedAuthorizationDetails indicates an expected call of SetRequestedAuthorizationDetails.
+func (mr *MockAuthorizeRequesterMockRecorder) SetRequestedAuthorizationDetails(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRequestedAuthorizationDetails", reflect.TypeOf((*MockAuthorizeRequester)(nil).SetRequestedAuthorizationDetails), arg0)
+}
+
 // SetRequestedScopes mocks base method.
 func (m *MockAuthorizeRequester) SetRequestedScopes(arg0 fosite.Arguments) {
 	m.ctrl.T.Helper()
@@ -80,6 +80,20 @@ func (mr *MockRequesterMockRecorder) GetGrantedAudience() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGrantedAudience", reflect.TypeOf((*MockRequester)(nil).GetGrantedAudience))
 }
 
+// GetGrantedAuthorizationDetails mocks base method.
+func (m *MockRequester) GetGrantedAuthorizationDetails() fosite.AuthorizationDetails {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGrantedAuthorizationDetails")
+	ret0, _ := ret[0].(fosite.AuthorizationDetails)
+	return ret0
+}
+
+// GetGrantedAuthorizationDetails indicates an expected call of GetGrantedAuthorizationDetails.
+func (mr *MockRequesterMockRecorder) GetGrantedAuthorizationDetails() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGrantedAuthorizationDetails", reflect.TypeOf((*MockRequester)(nil).GetGrantedAuthorizationDetails))
+}
+
 // GetGrantedScopes mocks base method.
 func (m *MockRequester) GetGrantedScopes() fosite.Arguments {
 	m.ctrl.T.Helper()
@@ -150,6 +164,20 @@ func (mr *MockRequesterMockRecorder) GetRequestedAudience() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestedAudience", reflect.TypeOf((*MockRequester)(nil).GetRequestedAudience))
 }
 
+// GetRequestedAuthorizationDetails mocks base method.
+func (m *MockRequester) GetRequestedAuthorizationDetails() fosite.AuthorizationDetails {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRequestedAuthorizationDetails")
+	ret0, _ := ret[0].(fosite.AuthorizationDetails)
+	return ret0
+}
+
+// GetRequestedAuthorizationDetails indicates an expected call of GetRequestedAuthorizationDetails.
+func (mr *MockRequesterMockRecorder) GetRequestedAuthorizationDetails() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestedAuthorizationDetails", reflect.TypeOf((*MockRequester)(nil).GetRequestedAuthorizationDetails))
+}
+
 // GetRequestedScopes mocks base method.
 func (m *MockRequester) GetRequestedScopes() fosite.Arguments {
 	m.ctrl.T.Helper()
@@ -190,6 +218,18 @@ func (mr *MockRequesterMockRecorder) GrantAudience(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantAudience", reflect.TypeOf((*MockRequester)(nil).GrantAudience), arg0)
 }
 
+// GrantAuthorizationDetails mocks base method.
+func (m *MockRequester) GrantAuthorizationDetails(arg0 fosite.AuthorizationDetails) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GrantAuthorizationDetails", arg0)
+}
+
+// GrantAuthorizationDetails indicates an expected call of GrantAuthorizationDetails.
+func (mr *MockRequesterMockRecorder) GrantAuthorizationDetails(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantAuthorizationDetails", reflect.TypeOf((*MockRequester)(nil).GrantAuthorizationDetails), arg0)
+}
+
 // GrantScope mocks base method.
 func (m *MockRequester) GrantScope(arg0 string) {
 	m.ctrl.T.Helper()
@@ -252,6 +292,18 @@ func (mr *MockRequesterMockRecorder) SetRequestedAudience(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRequestedAudience", reflect.TypeOf((*MockRequester)(nil).SetRequestedAudience), arg0)
 }
 
+// SetRequestedAuthorizationDetails mocks base method.
+func (m *MockRequester) SetRequestedAuthorizationDetails(arg0 fosite.AuthorizationDetails) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRequestedAuthorizationDetails", arg0)
+}
+
+// SetRequestedAuthorizationDetails indicates an expected call of SetRequestedAuthorizationDetails.
+func (mr *MockRequesterMockRecorder) SetRequestedAuthorizationDetails(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRequestedAuthorizationDetails", reflect.TypeOf((*MockRequester)(nil).SetRequestedAuthorizationDetails), arg0)
+}
+
 // SetRequestedScopes mocks base method.
 func (m *MockRequester) SetRequestedScopes(arg0 fosite.Arguments) {
 	m.ctrl.T.Helper()
@@ -23,10 +23,11 @@ func AccessTokenFromRequest(req *http.Request) string {
 	// According to https://tools.ietf.org/html/rfc6750 you can pass tokens through:
 	// - Form-Encoded Body Parameter. Recommended, more likely to appear. e.g.: Authorization: Bearer mytoken123
 	// - URI Query Parameter e.g. access_token=mytoken123
+	// DPoP-bound access tokens use the DPoP authorization scheme instead, see https://www.rfc-editor.org/rfc/rfc9449#section-7.1
 
 	auth := req.Header.Get("Authorization")
 	split := strings.SplitN(auth, " ", 2)
-	if len(split) != 2 || !strings.EqualFold(split[0], "bearer") {
+	if len(split) != 2 || !(strings.EqualFold(split[0], "bearer") || strings.EqualFold(split[0], DPoPAccessToken)) {
 		// Nothing in Authorization header, try access_token
 		// Empty string returned if there's no such parameter
 		if err := req.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
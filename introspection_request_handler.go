@@ -112,16 +112,19 @@ func (f *Fosite) NewIntrospectionRequest(ctx context.Context, r *http.Request, s
 	token := r.PostForm.Get("token")
 	tokenTypeHint := r.PostForm.Get("token_type_hint")
 	scope := r.PostForm.Get("scope")
+	var client Client
 	if clientToken := AccessTokenFromRequest(r); clientToken != "" {
 		if token == clientToken {
 			return &IntrospectionResponse{Active: false}, errorsx.WithStack(ErrRequestUnauthorized.WithHint("Bearer and introspection token are identical."))
 		}
 
-		if tu, _, err := f.IntrospectToken(ctx, clientToken, AccessToken, session.Clone()); err != nil {
+		tu, car, err := f.IntrospectToken(ctx, clientToken, AccessToken, session.Clone())
+		if err != nil {
 			return &IntrospectionResponse{Active: false}, errorsx.WithStack(ErrRequestUnauthorized.WithHint("HTTP Authorization header missing, malformed, or credentials used are invalid."))
 		} else if tu != "" && tu != AccessToken {
 			return &IntrospectionResponse{Active: false}, errorsx.WithStack(ErrRequestUnauthorized.WithHintf("HTTP Authorization header did not provide a token of type 'access_token', got type '%s'.", tu))
 		}
+		client = car.GetClient()
 	} else {
 		id, secret, ok := r.BasicAuth()
 		if !ok {
@@ -138,7 +141,7 @@ func (f *Fosite) NewIntrospectionRequest(ctx context.Context, r *http.Request, s
 			return &IntrospectionResponse{Active: false}, errorsx.WithStack(ErrRequestUnauthorized.WithHint("Unable to decode OAuth 2.0 Client Secret from HTTP basic authorization header, make sure it is properly encoded.").WithWrap(err).WithDebug(err.Error()))
 		}
 
-		client, err := f.Store.GetClient(ctx, clientID)
+		client, err = f.Store.GetClient(ctx, clientID)
 		if err != nil {
 			return &IntrospectionResponse{Active: false}, errorsx.WithStack(ErrRequestUnauthorized.WithHint("Unable to find OAuth 2.0 Client from HTTP basic authorization header.").WithWrap(err).WithDebug(err.Error()))
 		}
@@ -149,9 +152,10 @@ func (f *Fosite) NewIntrospectionRequest(ctx context.Context, r *http.Request, s
 		}
 	}
 
+	jwtResponseRequested := f.isJWTIntrospectionResponseRequested(ctx, r)
 	tu, ar, err := f.IntrospectToken(ctx, token, TokenUse(tokenTypeHint), session, RemoveEmpty(strings.Split(scope, " "))...)
 	if err != nil {
-		return &IntrospectionResponse{Active: false}, errorsx.WithStack(ErrInactiveToken.WithHint("An introspection strategy indicated that the token is inactive.").WithWrap(err).WithDebug(err.Error()))
+		return &IntrospectionResponse{Active: false, Client: client, JWTResponseRequested: jwtResponseRequested}, errorsx.WithStack(ErrInactiveToken.WithHint("An introspection strategy indicated that the token is inactive.").WithWrap(err).WithDebug(err.Error()))
 	}
 	accessTokenType := ""
 
@@ -160,10 +164,12 @@ func (f *Fosite) NewIntrospectionRequest(ctx context.Context, r *http.Request, s
 	}
 
 	return &IntrospectionResponse{
-		Active:          true,
-		AccessRequester: ar,
-		TokenUse:        tu,
-		AccessTokenType: accessTokenType,
+		Active:               true,
+		AccessRequester:      ar,
+		TokenUse:             tu,
+		AccessTokenType:      accessTokenType,
+		Client:               client,
+		JWTResponseRequested: jwtResponseRequested,
 	}, nil
 }
 
@@ -173,6 +179,12 @@ type IntrospectionResponse struct {
 	TokenUse        TokenUse        `json:"token_use,omitempty"`
 	AccessTokenType string          `json:"token_type,omitempty"`
 	Lang            language.Tag    `json:"-"`
+
+	// Client is the authenticated client, usually a resource server, which requested the introspection.
+	Client Client `json:"-"`
+
+	// JWTResponseRequested is true if the client requested a JWT-formatted introspection response.
+	JWTResponseRequested bool `json:"-"`
 }
 
 func (r *IntrospectionResponse) IsActive() bool {
@@ -190,3 +202,11 @@ func (r *IntrospectionResponse) GetTokenUse() TokenUse {
 func (r *IntrospectionResponse) GetAccessTokenType() string {
 	return r.AccessTokenType
 }
+
+func (r *IntrospectionResponse) GetClient() Client {
+	return r.Client
+}
+
+func (r *IntrospectionResponse) IsJWTResponseRequested() bool {
+	return r.JWTResponseRequested
+}
@@ -179,6 +179,17 @@ func (f *Fosite) WriteIntrospectionError(ctx context.Context, rw http.ResponseWr
 //	  "active": false
 //	}
 func (f *Fosite) WriteIntrospectionResponse(ctx context.Context, rw http.ResponseWriter, r IntrospectionResponder) {
+	response, err := f.introspectionResponse(ctx, r)
+	if err != nil {
+		f.WriteIntrospectionError(ctx, rw, err)
+		return
+	}
+
+	if jr, ok := r.(JWTIntrospectionResponder); ok && jr.IsJWTResponseRequested() {
+		f.writeJWTIntrospectionResponse(ctx, rw, jr.GetClient(), response)
+		return
+	}
+
 	if !r.IsActive() {
 		_ = json.NewEncoder(rw).Encode(&struct {
 			Active bool `json:"active"`
@@ -186,6 +197,19 @@ func (f *Fosite) WriteIntrospectionResponse(ctx context.Context, rw http.Respons
 		return
 	}
 
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+	_ = json.NewEncoder(rw).Encode(response)
+}
+
+// introspectionResponse returns the members of the introspection response, see
+// https://tools.ietf.org/html/rfc7662#section-2.2
+func (f *Fosite) introspectionResponse(ctx context.Context, r IntrospectionResponder) (map[string]interface{}, error) {
+	if !r.IsActive() {
+		return map[string]interface{}{"active": false}, nil
+	}
+
 	response := map[string]interface{}{
 		"active": true,
 	}
@@ -217,7 +241,12 @@ func (f *Fosite) WriteIntrospectionResponse(ctx context.Context, rw http.Respons
 		response["iat"] = r.GetAccessRequester().GetRequestedAt().Unix()
 	}
 	if r.GetAccessRequester().GetSession().GetSubject() != "" {
-		response["sub"] = r.GetAccessRequester().GetSession().GetSubject()
+		// The subject is the one the client of the token knows the resource owner by.
+		sub, err := PairwiseSubject(ctx, f.Config, r.GetAccessRequester().GetClient(), r.GetAccessRequester().GetSession().GetSubject())
+		if err != nil {
+			return nil, err
+		}
+		response["sub"] = sub
 	}
 	if len(r.GetAccessRequester().GetGrantedAudience()) > 0 {
 		response["aud"] = r.GetAccessRequester().GetGrantedAudience()
@@ -225,9 +254,41 @@ func (f *Fosite) WriteIntrospectionResponse(ctx context.Context, rw http.Respons
 	if r.GetAccessRequester().GetSession().GetUsername() != "" {
 		response["username"] = r.GetAccessRequester().GetSession().GetUsername()
 	}
+	if len(r.GetAccessRequester().GetGrantedAuthorizationDetails()) > 0 {
+		// https://www.rfc-editor.org/rfc/rfc9396#section-9.2
+		response["authorization_details"] = r.GetAccessRequester().GetGrantedAuthorizationDetails()
+	}
+	for name, value := range GetAuthenticationClaims(r.GetAccessRequester().GetSession()) {
+		// https://www.rfc-editor.org/rfc/rfc9470#section-6.2
+		if _, ok := response[name]; !ok {
+			response[name] = value
+		}
+	}
+	if cnf := confirmationClaim(r.GetAccessRequester().GetSession(), response["cnf"]); len(cnf) > 0 {
+		response["cnf"] = cnf
+	}
+	if GetDPoPJWKThumbprint(r.GetAccessRequester().GetSession()) != "" {
+		// https://www.rfc-editor.org/rfc/rfc9449#section-6.2
+		response["token_type"] = DPoPAccessToken
+	}
 
-	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
-	rw.Header().Set("Cache-Control", "no-store")
-	rw.Header().Set("Pragma", "no-cache")
-	_ = json.NewEncoder(rw).Encode(response)
+	return response, nil
+}
+
+// confirmationClaim returns the "cnf" (confirmation) claim for the key or certificate the token is bound to, as
+// specified in https://www.rfc-editor.org/rfc/rfc9449#section-6.2 and https://www.rfc-editor.org/rfc/rfc8705#section-3.2
+func confirmationClaim(session Session, existing interface{}) map[string]interface{} {
+	cnf := map[string]interface{}{}
+	if e, ok := existing.(map[string]interface{}); ok {
+		for k, v := range e {
+			cnf[k] = v
+		}
+	}
+	if jkt := GetDPoPJWKThumbprint(session); jkt != "" {
+		cnf["jkt"] = jkt
+	}
+	if x5t := GetCertificateThumbprint(session); x5t != "" {
+		cnf["x5t#S256"] = x5t
+	}
+	return cnf
 }
@@ -0,0 +1,98 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite/token/jwt"
+)
+
+const (
+	// JWTIntrospectionResponseContentType is the media type of JWT-formatted token introspection responses, see
+	// https://www.rfc-editor.org/rfc/rfc9701#section-4
+	JWTIntrospectionResponseContentType = "application/token-introspection+jwt"
+
+	jwtIntrospectionResponseType = "token-introspection+jwt"
+)
+
+// jwtIntrospectionResponseSigner returns the signer of JWT-formatted introspection responses, or nil if the
+// configuration does not implement JWTIntrospectionResponseProvider.
+func (f *Fosite) jwtIntrospectionResponseSigner(ctx context.Context) jwt.Signer {
+	if c, ok := f.Config.(JWTIntrospectionResponseProvider); ok {
+		return c.GetJWTIntrospectionResponseSigner(ctx)
+	}
+	return nil
+}
+
+// isJWTIntrospectionResponseRequested returns true if the client accepts JWT-formatted introspection responses and
+// a signer for them is configured.
+func (f *Fosite) isJWTIntrospectionResponseRequested(ctx context.Context, r *http.Request) bool {
+	if f.jwtIntrospectionResponseSigner(ctx) == nil {
+		return false
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && mediaType == JWTIntrospectionResponseContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJWTIntrospectionResponse writes the introspection response as a signed, and optionally encrypted, JWT as
+// specified in https://www.rfc-editor.org/rfc/rfc9701#section-5
+func (f *Fosite) writeJWTIntrospectionResponse(ctx context.Context, rw http.ResponseWriter, client Client, response map[string]interface{}) {
+	token, err := f.generateJWTIntrospectionResponse(ctx, client, response)
+	if err != nil {
+		f.writeJsonError(ctx, rw, nil, err)
+		return
+	}
+
+	rw.Header().Set("Content-Type", JWTIntrospectionResponseContentType)
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+	_, _ = rw.Write([]byte(token))
+}
+
+func (f *Fosite) generateJWTIntrospectionResponse(ctx context.Context, client Client, response map[string]interface{}) (string, error) {
+	config, ok := f.Config.(JWTIntrospectionResponseProvider)
+	if !ok || config.GetJWTIntrospectionResponseSigner(ctx) == nil {
+		return "", errorsx.WithStack(ErrServerError.WithDebug("A JWT-formatted introspection response was requested but no signer is configured."))
+	} else if client == nil {
+		return "", errorsx.WithStack(ErrServerError.WithDebug("A JWT-formatted introspection response was requested but the client is unknown."))
+	}
+
+	signer := config.GetJWTIntrospectionResponseSigner(ctx)
+
+	claims := jwt.MapClaims{
+		"iss":                 config.GetJWTIntrospectionResponseIssuer(ctx),
+		"aud":                 client.GetID(),
+		"iat":                 time.Now().UTC().Unix(),
+		"token_introspection": response,
+	}
+
+	headers := jwt.NewHeaders()
+	headers.Add(string(jwt.JWTHeaderType), jwtIntrospectionResponseType)
+
+	introspectionClient, ok := client.(JWTIntrospectionClient)
+	if !ok {
+		return generateSignedResponse(ctx, signer, claims, headers, "")
+	}
+
+	token, err := generateSignedResponse(ctx, signer, claims, headers, introspectionClient.GetIntrospectionSignedResponseAlgorithm())
+	if err != nil {
+		return "", err
+	} else if introspectionClient.GetIntrospectionEncryptedResponseAlgorithm() == "" {
+		return token, nil
+	}
+
+	return f.encryptResponse(ctx, introspectionClient, token, introspectionClient.GetIntrospectionEncryptedResponseAlgorithm(), introspectionClient.GetIntrospectionEncryptedResponseEncryption())
+}
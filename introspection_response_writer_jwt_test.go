@@ -0,0 +1,167 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/internal"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestWriteJWTIntrospectionResponse(t *testing.T) {
+	ctx := context.Background()
+	key := gen.MustRSAKey()
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return key, nil }}
+	f := &Fosite{Config: &Config{JWTIntrospectionResponseSigner: signer, AccessTokenIssuer: "https://auth.example.com"}}
+
+	newClient := func() *DefaultOpenIDConnectClient {
+		return &DefaultOpenIDConnectClient{
+			DefaultClient: &DefaultClient{ID: "resource-server"},
+		}
+	}
+
+	newResponse := func(client Client) *IntrospectionResponse {
+		ar := NewAccessRequest(&DefaultSession{Subject: "peter"})
+		ar.Client = &DefaultClient{ID: "foo"}
+		ar.GrantScope("photos")
+		return &IntrospectionResponse{Active: true, AccessRequester: ar, TokenUse: AccessToken, Client: client, JWTResponseRequested: true}
+	}
+
+	decode := func(t *testing.T, token string) jwt.MapClaims {
+		parsed, err := signer.Decode(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, "token-introspection+jwt", parsed.Header["typ"])
+		return parsed.Claims
+	}
+
+	t.Run("case=should write a signed response", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(ctx, rw, newResponse(newClient()))
+
+		require.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, JWTIntrospectionResponseContentType, rw.Header().Get("Content-Type"))
+
+		claims := decode(t, rw.Body.String())
+		assert.Equal(t, "https://auth.example.com", claims["iss"])
+		assert.Equal(t, "resource-server", claims["aud"])
+		assert.NotEmpty(t, claims["iat"])
+
+		introspection := claims["token_introspection"].(map[string]interface{})
+		assert.Equal(t, true, introspection["active"])
+		assert.Equal(t, "foo", introspection["client_id"])
+		assert.Equal(t, "peter", introspection["sub"])
+		assert.Equal(t, "photos", introspection["scope"])
+	})
+
+	t.Run("case=should write inactive tokens", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(ctx, rw, &IntrospectionResponse{Client: newClient(), JWTResponseRequested: true})
+
+		claims := decode(t, rw.Body.String())
+		assert.Equal(t, map[string]interface{}{"active": false}, claims["token_introspection"])
+	})
+
+	t.Run("case=should encrypt the response", func(t *testing.T) {
+		encryptionKey := gen.MustRSAKey()
+		client := newClient()
+		client.IntrospectionEncryptedResponseAlgorithm = string(jose.RSA_OAEP_256)
+		client.IntrospectionEncryptedResponseEncryption = string(jose.A256GCM)
+		client.JSONWebKeys = &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "enc", Use: "enc", Key: &encryptionKey.PublicKey}}}
+
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(ctx, rw, newResponse(client))
+
+		jwe, err := jose.ParseEncrypted(rw.Body.String())
+		require.NoError(t, err)
+		assert.Equal(t, "enc", jwe.Header.KeyID)
+
+		nested, err := jwe.Decrypt(encryptionKey)
+		require.NoError(t, err)
+		assert.Equal(t, "resource-server", decode(t, string(nested))["aud"])
+	})
+
+	t.Run("case=should fail if the client requires another signing algorithm", func(t *testing.T) {
+		client := newClient()
+		client.IntrospectionSignedResponseAlgorithm = "ES256"
+
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(ctx, rw, newResponse(client))
+
+		assert.Equal(t, http.StatusInternalServerError, rw.Code)
+		assert.Contains(t, rw.Body.String(), "server_error")
+	})
+
+	t.Run("case=should sign with the algorithm the client requires", func(t *testing.T) {
+		ecKey := gen.MustES256Key()
+		signers := &jwt.AlgorithmSigners{DefaultAlgorithm: "RS256", Signers: map[string]jwt.Signer{
+			"RS256": signer,
+			"ES256": &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return ecKey, nil }},
+		}}
+		f := &Fosite{Config: &Config{JWTIntrospectionResponseSigner: signers, AccessTokenIssuer: "https://auth.example.com"}}
+
+		client := newClient()
+		client.IntrospectionSignedResponseAlgorithm = "ES256"
+
+		rw := httptest.NewRecorder()
+		f.WriteIntrospectionResponse(ctx, rw, newResponse(client))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		parsed, err := jose.ParseSigned(rw.Body.String())
+		require.NoError(t, err)
+		assert.Equal(t, "ES256", parsed.Signatures[0].Header.Algorithm)
+
+		client.IntrospectionSignedResponseAlgorithm = "PS256"
+
+		rw = httptest.NewRecorder()
+		f.WriteIntrospectionResponse(ctx, rw, newResponse(client))
+		assert.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
+
+func TestNewIntrospectionRequestWithJWTResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return gen.MustRSAKey(), nil }}
+	validator := internal.NewMockTokenIntrospector(ctrl)
+	f := &Fosite{Store: storage.NewExampleStore(), Config: &Config{
+		JWTIntrospectionResponseSigner: signer,
+		TokenIntrospectionHandlers:     TokenIntrospectionHandlers{validator},
+	}}
+
+	newRequest := func(accept string) *http.Request {
+		r, err := http.NewRequest("POST", "https://auth.example.com/oauth2/introspect", strings.NewReader(url.Values{"token": {"some-token"}}.Encode()))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("Accept", accept)
+		r.SetBasicAuth("my-client", "foobar")
+		return r
+	}
+
+	validator.EXPECT().IntrospectToken(gomock.Any(), "some-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(AccessToken, nil)
+	res, err := f.NewIntrospectionRequest(context.Background(), newRequest("application/json"), &DefaultSession{})
+	require.NoError(t, err)
+	assert.False(t, res.(JWTIntrospectionResponder).IsJWTResponseRequested())
+
+	validator.EXPECT().IntrospectToken(gomock.Any(), "some-token", gomock.Any(), gomock.Any(), gomock.Any()).Return(TokenUse(""), errors.New("inactive"))
+	res, err = f.NewIntrospectionRequest(context.Background(), newRequest("application/json, application/token-introspection+jwt"), &DefaultSession{})
+	require.ErrorIs(t, err, ErrInactiveToken)
+	assert.True(t, res.(JWTIntrospectionResponder).IsJWTResponseRequested())
+	assert.Equal(t, "my-client", res.(JWTIntrospectionResponder).GetClient().GetID())
+}
@@ -0,0 +1,93 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite/token/jwt"
+)
+
+// generateSignedResponse signs a response sent to the client with the JWS algorithm the client registered, or with
+// the default algorithm of the signer if the client registered none. Signing with a registered algorithm other than
+// the default one requires a jwt.AlgorithmSigner.
+func generateSignedResponse(ctx context.Context, signer jwt.Signer, claims jwt.MapClaims, headers jwt.Mapper, alg string) (string, error) {
+	if s, ok := signer.(jwt.AlgorithmSigner); ok && alg != "" {
+		if !StringInSlice(alg, s.GetSigningAlgorithms(ctx)) {
+			return "", errorsx.WithStack(ErrServerError.WithDebugf("The OAuth 2.0 Client requires responses signed with algorithm '%s' but the configured signer does not support it.", alg))
+		}
+
+		token, _, err := s.GenerateWithAlgorithm(ctx, alg, claims, headers)
+		if err != nil {
+			return "", errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+		}
+		return token, nil
+	}
+
+	token, _, err := signer.Generate(ctx, claims, headers)
+	if err != nil {
+		return "", errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if alg == "" {
+		return token, nil
+	}
+
+	jws, err := jose.ParseSigned(token)
+	if err != nil {
+		return "", errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	} else if actual := jws.Signatures[0].Header.Algorithm; actual != alg {
+		return "", errorsx.WithStack(ErrServerError.WithDebugf("The OAuth 2.0 Client requires responses signed with algorithm '%s' but the configured signer uses '%s'.", alg, actual))
+	}
+	return token, nil
+}
+
+// encryptResponse encrypts the signed response to a key of the client, yielding a nested JWT. The content encryption
+// algorithm defaults to A128CBC-HS256.
+func (f *Fosite) encryptResponse(ctx context.Context, client OpenIDConnectClient, token string, keyAlgorithm string, contentEncryption string) (string, error) {
+	alg := jose.KeyAlgorithm(keyAlgorithm)
+	enc := jose.ContentEncryption(contentEncryption)
+	if enc == "" {
+		enc = jose.A128CBC_HS256
+	}
+
+	key, err := f.findClientEncryptionJWK(ctx, client, alg)
+	if err != nil {
+		return "", err
+	}
+
+	encrypter, err := jose.NewEncrypter(enc, jose.Recipient{Algorithm: alg, Key: key.Key, KeyID: key.KeyID}, (&jose.EncrypterOptions{}).WithContentType("JWT"))
+	if err != nil {
+		return "", errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	jwe, err := encrypter.Encrypt([]byte(token))
+	if err != nil {
+		return "", errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+
+	return jwe.CompactSerialize()
+}
+
+// findClientEncryptionJWK returns the public key of the client which can be used with the given key management
+// algorithm.
+func (f *Fosite) findClientEncryptionJWK(ctx context.Context, client OpenIDConnectClient, alg jose.KeyAlgorithm) (*jose.JSONWebKey, error) {
+	if set := client.GetJSONWebKeys(); set != nil {
+		if key := jwt.FindEncryptionKey(set, alg); key != nil {
+			return key, nil
+		}
+	} else if location := client.GetJSONWebKeysURI(); location != "" {
+		for _, forceRefresh := range []bool{false, true} {
+			set, err := f.Config.GetJWKSFetcherStrategy(ctx).Resolve(ctx, location, forceRefresh)
+			if err != nil {
+				return nil, err
+			} else if key := jwt.FindEncryptionKey(set, alg); key != nil {
+				return key, nil
+			}
+		}
+	}
+
+	return nil, errorsx.WithStack(ErrServerError.WithDebugf("The OAuth 2.0 Client has no JSON Web Key registered which can be used with encryption algorithm '%s'.", alg))
+}
@@ -0,0 +1,189 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/otelx"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/text/language"
+
+	"github.com/ory/fosite/i18n"
+	"github.com/ory/fosite/token/jwt"
+)
+
+// LogoutRequest is a validated request to the end session endpoint, see
+// https://openid.net/specs/openid-connect-rpinitiated-1_0.html#RPLogout
+type LogoutRequest struct {
+	// IDTokenHintClaims are the claims of the id_token_hint, or nil if the request did not contain one. The hint may
+	// be expired.
+	IDTokenHintClaims jwt.MapClaims
+
+	// Client is the client which requested the logout, or nil if it could neither be identified by the client_id
+	// nor by the id_token_hint.
+	Client Client
+
+	// PostLogoutRedirectURI is the registered URL the End-User is redirected to after the logout, or nil if the
+	// request did not contain one.
+	PostLogoutRedirectURI *url.URL
+
+	// State is passed back to the client when redirecting to the PostLogoutRedirectURI.
+	State string
+
+	// UILocales are the End-User's preferred languages for the logout user interface.
+	UILocales []string
+
+	// Lang is the language used to localize errors.
+	Lang language.Tag
+
+	Form url.Values
+}
+
+// GetSubject returns the subject of the id_token_hint, or an empty string if the request did not contain one.
+func (r *LogoutRequest) GetSubject() string {
+	sub, _ := r.IDTokenHintClaims["sub"].(string)
+	return sub
+}
+
+// NewLogoutRequest validates a request to the end session endpoint. The id_token_hint must be an ID token issued
+// by us, although it may be expired. The post_logout_redirect_uri is only accepted if the client is identified by
+// the client_id or the id_token_hint and exactly matches one of its registered post logout redirect URIs.
+//
+// The application is responsible for asking the End-User whether to log out and for ending the session before
+// writing the response using WriteLogoutResponse.
+func (f *Fosite) NewLogoutRequest(ctx context.Context, r *http.Request) (_ *LogoutRequest, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewLogoutRequest")
+	defer otelx.End(span, &err)
+
+	request := &LogoutRequest{Lang: i18n.GetLangFromRequest(f.Config.GetMessageCatalog(ctx), r)}
+
+	if r.Method != "GET" && r.Method != "POST" {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'GET' or 'POST'.", r.Method))
+	} else if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		return request, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to parse HTTP body, make sure to send a properly formatted form request body.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	request.Form = r.Form
+	request.State = request.Form.Get("state")
+	request.UILocales = RemoveEmpty(strings.Split(request.Form.Get("ui_locales"), " "))
+
+	clientID := request.Form.Get("client_id")
+	if idTokenHint := request.Form.Get("id_token_hint"); idTokenHint != "" {
+		claims, err := f.decodeIDTokenHint(ctx, idTokenHint)
+		if err != nil {
+			return request, err
+		}
+		request.IDTokenHintClaims = claims
+
+		if clientID == "" {
+			clientID = idTokenHintClientID(claims)
+		} else if !claims.VerifyAudience(clientID, true) {
+			return request, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'client_id' does not match the audience of the 'id_token_hint'."))
+		}
+	}
+
+	if clientID != "" {
+		client, err := f.Store.GetClient(ctx, clientID)
+		if err != nil {
+			return request, errorsx.WithStack(ErrInvalidClient.WithHint("The requested OAuth 2.0 Client does not exist.").WithWrap(err).WithDebug(err.Error()))
+		}
+		request.Client = client
+	}
+
+	if rawRedirectURI := request.Form.Get("post_logout_redirect_uri"); rawRedirectURI != "" {
+		redirectURI, err := f.validatePostLogoutRedirectURI(ctx, request.Client, rawRedirectURI)
+		if err != nil {
+			return request, err
+		}
+		request.PostLogoutRedirectURI = redirectURI
+	}
+
+	return request, nil
+}
+
+// decodeIDTokenHint verifies the signature and issuer of an id_token_hint. Expired ID tokens are accepted, because
+// logout and backchannel authentication requests are commonly sent after the ID token expired.
+func (f *Fosite) decodeIDTokenHint(ctx context.Context, idTokenHint string) (jwt.MapClaims, error) {
+	config, ok := f.Config.(EndSessionProvider)
+	if !ok || config.GetIDTokenSigner(ctx) == nil {
+		return nil, errorsx.WithStack(ErrServerError.WithDebug("The request contains an 'id_token_hint' but no ID token signer is configured."))
+	}
+
+	token, err := config.GetIDTokenSigner(ctx).Decode(ctx, idTokenHint)
+	var ve *jwt.ValidationError
+	if errors.As(err, &ve) && ve.Has(jwt.ValidationErrorExpired) {
+		// Expired tokens are ok
+	} else if err != nil {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("Unable to decode the 'id_token_hint' parameter.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	if sub, _ := token.Claims["sub"].(string); sub == "" {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'id_token_hint' does not have a subject."))
+	} else if issuer := f.Config.GetIDTokenIssuer(ctx); issuer != "" && !token.Claims.VerifyIssuer(issuer, true) {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'id_token_hint' was not issued by this authorization server."))
+	}
+
+	return token.Claims, nil
+}
+
+// validatePostLogoutRedirectURI checks the post_logout_redirect_uri against the URIs registered by the client.
+// Unlike redirect URIs of authorization requests, post logout redirect URIs must match exactly.
+func (f *Fosite) validatePostLogoutRedirectURI(ctx context.Context, client Client, rawRedirectURI string) (*url.URL, error) {
+	if client == nil {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'post_logout_redirect_uri' parameter requires the 'client_id' or 'id_token_hint' parameter."))
+	}
+
+	oidcClient, ok := client.(OpenIDConnectClient)
+	if !ok {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The OAuth 2.0 Client has not registered any post logout redirect URIs."))
+	}
+
+	var registered bool
+	for _, uri := range oidcClient.GetPostLogoutRedirectURIs() {
+		registered = registered || uri == rawRedirectURI
+	}
+	if !registered {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'post_logout_redirect_uri' parameter does not match any of the OAuth 2.0 Client's pre-registered post logout redirect URIs."))
+	}
+
+	redirectURI, err := url.Parse(rawRedirectURI)
+	if err != nil {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHint("The 'post_logout_redirect_uri' parameter is malformed.").WithWrap(err).WithDebug(err.Error()))
+	} else if !IsValidRedirectURI(redirectURI) {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHintf("The post logout redirect URI '%s' contains an illegal character (for example #) or is otherwise invalid.", redirectURI))
+	} else if !f.Config.GetRedirectSecureChecker(ctx)(ctx, redirectURI) {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHintf("The post logout redirect URI '%s' is not secure.", redirectURI))
+	}
+
+	return redirectURI, nil
+}
+
+// idTokenHintClientID returns the client the ID token was issued to, which is the authorized party or the only
+// audience.
+func idTokenHintClientID(claims jwt.MapClaims) string {
+	if azp, _ := claims["azp"].(string); azp != "" {
+		return azp
+	}
+
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud
+	case []string:
+		if len(aud) == 1 {
+			return aud[0]
+		}
+	case []interface{}:
+		if len(aud) == 1 {
+			id, _ := aud[0].(string)
+			return id
+		}
+	}
+	return ""
+}
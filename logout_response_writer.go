@@ -0,0 +1,137 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/url"
+)
+
+var DefaultLogoutConfirmationTemplate = template.Must(template.New("logout_confirmation").Parse(`<html>
+   <head>
+      <title>Logged Out</title>
+   </head>
+   <body>
+      <p>You have been logged out.</p>
+   </body>
+</html>`))
+
+var DefaultFrontChannelLogoutTemplate = template.Must(template.New("frontchannel_logout").Parse(`<html>
+   <head>
+      <title>Logging Out</title>
+   </head>
+   <body{{ if .RedirURL }} onload="javascript:window.location.replace({{ .RedirURL }})"{{ end }}>
+      {{ range .LogoutURIs }}
+         <iframe src="{{ . }}" style="display:none"></iframe>
+      {{ end }}
+      {{ if not .RedirURL }}
+         <p>You have been logged out.</p>
+      {{ end }}
+   </body>
+</html>`))
+
+// WriteLogoutResponse completes a logout request. If the request contains a valid post logout redirect URI, the
+// End-User is redirected to it, passing back the state. Otherwise, the logout confirmation page is rendered.
+func (f *Fosite) WriteLogoutResponse(ctx context.Context, rw http.ResponseWriter, r *http.Request, lr *LogoutRequest) {
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+
+	if lr.PostLogoutRedirectURI == nil {
+		rw.Header().Set("Content-Type", "text/html;charset=UTF-8")
+		rw.WriteHeader(http.StatusOK)
+		_ = GetLogoutConfirmationHTMLTemplate(ctx, f).Execute(rw, lr)
+		return
+	}
+
+	http.Redirect(rw, r, postLogoutRedirectURL(lr), http.StatusSeeOther)
+}
+
+// WriteFrontChannelLogoutResponse completes a logout request by rendering a page which loads the front-channel logout
+// URIs of the clients the End-User was logged into in iframes, see
+// https://openid.net/specs/openid-connect-frontchannel-1_0.html#RPLogout
+//
+// Clients which require it receive the issuer and the session ID as query parameters. Once all iframes are loaded,
+// the End-User is redirected to the post logout redirect URI of the request, if any.
+func (f *Fosite) WriteFrontChannelLogoutResponse(ctx context.Context, rw http.ResponseWriter, lr *LogoutRequest, sessionID string, clients []Client) {
+	var logoutURIs []string
+	for _, client := range clients {
+		c, ok := client.(FrontChannelLogoutClient)
+		if !ok || c.GetFrontChannelLogoutURI() == "" {
+			continue
+		}
+
+		logoutURI, err := url.Parse(c.GetFrontChannelLogoutURI())
+		if err != nil {
+			continue
+		}
+
+		if c.GetFrontChannelLogoutSessionRequired() && sessionID != "" {
+			query := logoutURI.Query()
+			query.Set("iss", f.Config.GetIDTokenIssuer(ctx))
+			query.Set("sid", sessionID)
+			logoutURI.RawQuery = query.Encode()
+		}
+		logoutURIs = append(logoutURIs, logoutURI.String())
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+	rw.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	rw.WriteHeader(http.StatusOK)
+
+	_ = GetFrontChannelLogoutHTMLTemplate(ctx, f).Execute(rw, struct {
+		RedirURL   string
+		LogoutURIs []string
+	}{
+		RedirURL:   postLogoutRedirectURL(lr),
+		LogoutURIs: logoutURIs,
+	})
+}
+
+// WriteLogoutError writes the error of the end session endpoint. The End-User is never redirected, because the post
+// logout redirect URI of a failed request can not be trusted.
+func (f *Fosite) WriteLogoutError(ctx context.Context, rw http.ResponseWriter, lr *LogoutRequest, err error) {
+	rfcerr := ErrorToRFC6749Error(err)
+	if lr != nil {
+		rfcerr = rfcerr.WithLocalizer(f.Config.GetMessageCatalog(ctx), lr.Lang)
+	}
+
+	f.writeJsonError(ctx, rw, nil, rfcerr)
+}
+
+func GetFrontChannelLogoutHTMLTemplate(ctx context.Context, f *Fosite) *template.Template {
+	if c, ok := f.Config.(FrontChannelLogoutHTMLTemplateProvider); ok {
+		if t := c.GetFrontChannelLogoutHTMLTemplate(ctx); t != nil {
+			return t
+		}
+	}
+	return DefaultFrontChannelLogoutTemplate
+}
+
+func GetLogoutConfirmationHTMLTemplate(ctx context.Context, f *Fosite) *template.Template {
+	if c, ok := f.Config.(EndSessionProvider); ok {
+		if t := c.GetLogoutConfirmationHTMLTemplate(ctx); t != nil {
+			return t
+		}
+	}
+	return DefaultLogoutConfirmationTemplate
+}
+
+// postLogoutRedirectURL returns the post logout redirect URI including the state, or an empty string if the request
+// does not contain a post logout redirect URI.
+func postLogoutRedirectURL(lr *LogoutRequest) string {
+	if lr == nil || lr.PostLogoutRedirectURI == nil {
+		return ""
+	}
+
+	redirectURI := *lr.PostLogoutRedirectURI
+	if lr.State != "" {
+		query := redirectURI.Query()
+		query.Set("state", lr.State)
+		redirectURI.RawQuery = query.Encode()
+	}
+	return redirectURI.String()
+}
@@ -0,0 +1,141 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	key := gen.MustRSAKey()
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return key, nil }}
+	otherKey := gen.MustRSAKey()
+	otherSigner := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return otherKey, nil }}
+
+	store := storage.NewMemoryStore()
+	store.Clients["foo"] = &DefaultOpenIDConnectClient{
+		DefaultClient:          &DefaultClient{ID: "foo"},
+		PostLogoutRedirectURIs: []string{"https://foo.example.com/logged-out", "http://foo.example.com/insecure"},
+	}
+	store.Clients["bar"] = &DefaultClient{ID: "bar"}
+
+	f := &Fosite{Store: store, Config: &Config{
+		IDTokenIssuer: "https://auth.example.com",
+		IDTokenSigner: signer,
+	}}
+
+	idToken := func(t *testing.T, s jwt.Signer, claims jwt.MapClaims) string {
+		claims["iat"] = time.Now().Unix()
+		if _, ok := claims["exp"]; !ok {
+			claims["exp"] = time.Now().Add(time.Hour).Unix()
+		}
+		token, _, err := s.Generate(ctx, claims, jwt.NewHeaders())
+		require.NoError(t, err)
+		return token
+	}
+
+	newRequest := func(query url.Values) *http.Request {
+		return httptest.NewRequest("GET", "https://auth.example.com/oauth2/sessions/logout?"+query.Encode(), nil)
+	}
+
+	t.Run("case=should reject invalid requests", func(t *testing.T) {
+		for k, tc := range []struct {
+			d     string
+			query url.Values
+		}{
+			{d: "foreign id token", query: url.Values{"id_token_hint": {idToken(t, otherSigner, jwt.MapClaims{"sub": "peter", "aud": "foo", "iss": "https://auth.example.com"})}}},
+			{d: "id token of another issuer", query: url.Values{"id_token_hint": {idToken(t, signer, jwt.MapClaims{"sub": "peter", "aud": "foo", "iss": "https://other.example.com"})}}},
+			{d: "id token without subject", query: url.Values{"id_token_hint": {idToken(t, signer, jwt.MapClaims{"aud": "foo", "iss": "https://auth.example.com"})}}},
+			{d: "mismatching client", query: url.Values{"client_id": {"bar"}, "id_token_hint": {idToken(t, signer, jwt.MapClaims{"sub": "peter", "aud": "foo", "iss": "https://auth.example.com"})}}},
+			{d: "unknown client", query: url.Values{"client_id": {"unknown"}}},
+			{d: "redirect without client", query: url.Values{"post_logout_redirect_uri": {"https://foo.example.com/logged-out"}}},
+			{d: "unregistered redirect", query: url.Values{"client_id": {"foo"}, "post_logout_redirect_uri": {"https://foo.example.com/logged-out/other"}}},
+			{d: "insecure redirect", query: url.Values{"client_id": {"foo"}, "post_logout_redirect_uri": {"http://foo.example.com/insecure"}}},
+			{d: "client without redirects", query: url.Values{"client_id": {"bar"}, "post_logout_redirect_uri": {"https://foo.example.com/logged-out"}}},
+		} {
+			lr, err := f.NewLogoutRequest(ctx, newRequest(tc.query))
+			require.Error(t, err, "%d: %s", k, tc.d)
+
+			rw := httptest.NewRecorder()
+			f.WriteLogoutError(ctx, rw, lr, err)
+			assert.Empty(t, rw.Header().Get("Location"), "%d: %s", k, tc.d)
+		}
+	})
+
+	t.Run("case=should accept an expired id_token_hint and redirect", func(t *testing.T) {
+		hint := idToken(t, signer, jwt.MapClaims{"sub": "peter", "aud": []string{"foo"}, "iss": "https://auth.example.com", "exp": time.Now().Add(-time.Hour).Unix()})
+		r := newRequest(url.Values{
+			"id_token_hint":            {hint},
+			"post_logout_redirect_uri": {"https://foo.example.com/logged-out"},
+			"state":                    {"some-state"},
+			"ui_locales":               {"de-CH en"},
+		})
+
+		lr, err := f.NewLogoutRequest(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, "peter", lr.GetSubject())
+		assert.Equal(t, "foo", lr.Client.GetID())
+		assert.Equal(t, []string{"de-CH", "en"}, lr.UILocales)
+
+		rw := httptest.NewRecorder()
+		f.WriteLogoutResponse(ctx, rw, r, lr)
+		assert.Equal(t, http.StatusSeeOther, rw.Code)
+		assert.Equal(t, "https://foo.example.com/logged-out?state=some-state", rw.Header().Get("Location"))
+	})
+
+	t.Run("case=should render the confirmation without redirect", func(t *testing.T) {
+		r := newRequest(url.Values{"client_id": {"foo"}})
+
+		lr, err := f.NewLogoutRequest(ctx, r)
+		require.NoError(t, err)
+		assert.Nil(t, lr.PostLogoutRedirectURI)
+
+		rw := httptest.NewRecorder()
+		f.WriteLogoutResponse(ctx, rw, r, lr)
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.Empty(t, rw.Header().Get("Location"))
+		assert.Contains(t, rw.Body.String(), "You have been logged out.")
+	})
+
+	t.Run("case=should render the front-channel logout iframes", func(t *testing.T) {
+		r := newRequest(url.Values{"client_id": {"foo"}, "post_logout_redirect_uri": {"https://foo.example.com/logged-out"}, "state": {"some-state"}})
+		lr, err := f.NewLogoutRequest(ctx, r)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		f.WriteFrontChannelLogoutResponse(ctx, rw, lr, "session-id", []Client{
+			&DefaultOpenIDConnectClient{
+				DefaultClient:         &DefaultClient{ID: "foo"},
+				FrontChannelLogoutURI: "https://foo.example.com/frontchannel",
+			},
+			&DefaultOpenIDConnectClient{
+				DefaultClient:                     &DefaultClient{ID: "bar"},
+				FrontChannelLogoutURI:             "https://bar.example.com/frontchannel?foo=bar",
+				FrontChannelLogoutSessionRequired: true,
+			},
+			&DefaultClient{ID: "baz"},
+		})
+		assert.Equal(t, http.StatusOK, rw.Code)
+
+		body := rw.Body.String()
+		assert.Contains(t, body, `<iframe src="https://foo.example.com/frontchannel"`)
+		assert.Contains(t, body, `<iframe src="https://bar.example.com/frontchannel?foo=bar&amp;iss=https%3A%2F%2Fauth.example.com&amp;sid=session-id"`)
+		assert.Contains(t, body, `window.location.replace(&#34;https://foo.example.com/logged-out?state=some-state&#34;)`)
+		assert.NotContains(t, body, "You have been logged out.")
+	})
+}
@@ -25,15 +25,25 @@ const (
 	IDToken       TokenType = "id_token"
 	// PushedAuthorizeRequestContext represents the PAR context object
 	PushedAuthorizeRequestContext TokenType = "par_context"
+	// DeviceCode represents the device code of the device authorization grant
+	DeviceCode TokenType = "device_code"
+	// UserCode represents the user code of the device authorization grant
+	UserCode TokenType = "user_code"
+	// AuthReqID represents the auth_req_id of the OpenID Connect CIBA grant
+	AuthReqID TokenType = "auth_req_id"
 
 	GrantTypeImplicit          GrantType = "implicit"
 	GrantTypeRefreshToken      GrantType = "refresh_token"
 	GrantTypeAuthorizationCode GrantType = "authorization_code"
 	GrantTypePassword          GrantType = "password"
 	GrantTypeClientCredentials GrantType = "client_credentials"
-	GrantTypeJWTBearer         GrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer" //nolint:gosec // this is not a hardcoded credential
+	GrantTypeJWTBearer         GrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"     //nolint:gosec // this is not a hardcoded credential
+	GrantTypeDeviceCode        GrantType = "urn:ietf:params:oauth:grant-type:device_code"    //nolint:gosec // this is not a hardcoded credential
+	GrantTypeTokenExchange     GrantType = "urn:ietf:params:oauth:grant-type:token-exchange" //nolint:gosec // this is not a hardcoded credential
+	GrantTypeCIBA              GrantType = "urn:openid:params:grant-type:ciba"
 
 	BearerAccessToken string = "bearer"
+	DPoPAccessToken   string = "DPoP"
 )
 
 // OAuth2Provider is an interface that enables you to write OAuth2 handlers with only a few lines of code.
@@ -167,6 +177,99 @@ type OAuth2Provider interface {
 
 	// WritePushedAuthorizeError writes the PAR error
 	WritePushedAuthorizeError(ctx context.Context, rw http.ResponseWriter, ar AuthorizeRequester, err error)
+
+	// NewDeviceRequest validates the request at the device authorization endpoint and returns a DeviceRequester.
+	// See https://www.rfc-editor.org/rfc/rfc8628#section-3.1
+	NewDeviceRequest(ctx context.Context, r *http.Request) (DeviceRequester, error)
+
+	// NewDeviceResponse executes the device endpoint handlers and builds the response.
+	// See https://www.rfc-editor.org/rfc/rfc8628#section-3.2
+	NewDeviceResponse(ctx context.Context, requester DeviceRequester, session Session) (DeviceResponder, error)
+
+	// WriteDeviceResponse writes the device authorization response.
+	WriteDeviceResponse(ctx context.Context, rw http.ResponseWriter, requester DeviceRequester, responder DeviceResponder)
+
+	// WriteDeviceError writes the device authorization error.
+	WriteDeviceError(ctx context.Context, rw http.ResponseWriter, requester DeviceRequester, err error)
+
+	// NewClientRegistrationRequest validates a request at the client registration endpoint.
+	// See https://www.rfc-editor.org/rfc/rfc7591#section-3.1
+	NewClientRegistrationRequest(ctx context.Context, r *http.Request) (*ClientRegistrationRequest, error)
+
+	// NewClientRegistrationResponse registers the client and builds the client information response.
+	// See https://www.rfc-editor.org/rfc/rfc7591#section-3.2.1
+	NewClientRegistrationResponse(ctx context.Context, request *ClientRegistrationRequest) (*ClientRegistrationResponse, error)
+
+	// NewClientConfigurationRequest authenticates and validates a request at the client configuration endpoint.
+	// See https://www.rfc-editor.org/rfc/rfc7592#section-2
+	NewClientConfigurationRequest(ctx context.Context, r *http.Request, clientID string) (*ClientRegistrationRequest, error)
+
+	// NewClientConfigurationResponse reads, updates or deletes the client and builds the response.
+	// See https://www.rfc-editor.org/rfc/rfc7592#section-3
+	NewClientConfigurationResponse(ctx context.Context, request *ClientRegistrationRequest) (*ClientRegistrationResponse, error)
+
+	// WriteClientRegistrationResponse writes the response of the client registration and configuration endpoints.
+	WriteClientRegistrationResponse(ctx context.Context, rw http.ResponseWriter, resp *ClientRegistrationResponse)
+
+	// WriteClientRegistrationError writes the error of the client registration and configuration endpoints.
+	WriteClientRegistrationError(ctx context.Context, rw http.ResponseWriter, req *ClientRegistrationRequest, err error)
+
+	// NewAuthorizationServerMetadata generates the authorization server metadata from the configuration.
+	// See https://www.rfc-editor.org/rfc/rfc8414#section-2
+	NewAuthorizationServerMetadata(ctx context.Context) (*AuthorizationServerMetadata, error)
+
+	// WriteAuthorizationServerMetadata writes the authorization server metadata.
+	// See https://www.rfc-editor.org/rfc/rfc8414#section-3.2
+	WriteAuthorizationServerMetadata(ctx context.Context, rw http.ResponseWriter, metadata *AuthorizationServerMetadata)
+
+	// AuthorizationServerMetadataEndpoint returns a http.Handler which serves the authorization server metadata.
+	AuthorizationServerMetadataEndpoint() http.Handler
+
+	// NewUserinfoRequest validates the access token of a UserInfo request and returns the claims about the end-user.
+	// See https://openid.net/specs/openid-connect-core-1_0.html#UserInfoRequest
+	NewUserinfoRequest(ctx context.Context, r *http.Request, session Session) (*UserinfoResponse, error)
+
+	// WriteUserinfoResponse writes the claims about the end-user as JSON or as signed, and optionally encrypted, JWT.
+	// See https://openid.net/specs/openid-connect-core-1_0.html#UserInfoResponse
+	WriteUserinfoResponse(ctx context.Context, rw http.ResponseWriter, resp *UserinfoResponse)
+
+	// WriteUserinfoError writes the error of the UserInfo endpoint.
+	// See https://openid.net/specs/openid-connect-core-1_0.html#UserInfoError
+	WriteUserinfoError(ctx context.Context, rw http.ResponseWriter, err error)
+
+	// NewLogoutRequest validates a request to the end session endpoint, including the id_token_hint, client_id and
+	// post_logout_redirect_uri parameters.
+	// See https://openid.net/specs/openid-connect-rpinitiated-1_0.html#RPLogout
+	NewLogoutRequest(ctx context.Context, r *http.Request) (*LogoutRequest, error)
+
+	// WriteLogoutResponse redirects the End-User to the post logout redirect URI or renders the logout confirmation.
+	// See https://openid.net/specs/openid-connect-rpinitiated-1_0.html#RedirectionAfterLogout
+	WriteLogoutResponse(ctx context.Context, rw http.ResponseWriter, r *http.Request, lr *LogoutRequest)
+
+	// WriteLogoutError writes the error of the end session endpoint.
+	WriteLogoutError(ctx context.Context, rw http.ResponseWriter, lr *LogoutRequest, err error)
+
+	// WriteFrontChannelLogoutResponse renders the front-channel logout URIs of the clients in iframes and redirects
+	// the End-User to the post logout redirect URI afterwards.
+	// See https://openid.net/specs/openid-connect-frontchannel-1_0.html#RPLogout
+	WriteFrontChannelLogoutResponse(ctx context.Context, rw http.ResponseWriter, lr *LogoutRequest, sessionID string, clients []Client)
+
+	// NewBackchannelAuthenticationRequest validates the request at the backchannel authentication endpoint and
+	// returns a BackchannelAuthenticationRequester.
+	// See https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#auth_request
+	NewBackchannelAuthenticationRequest(ctx context.Context, r *http.Request) (BackchannelAuthenticationRequester, error)
+
+	// NewBackchannelAuthenticationResponse executes the backchannel authentication endpoint handlers and builds the
+	// response.
+	// See https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#successful_authentication_request_acknowdlegment
+	NewBackchannelAuthenticationResponse(ctx context.Context, requester BackchannelAuthenticationRequester, session Session) (BackchannelAuthenticationResponder, error)
+
+	// WriteBackchannelAuthenticationResponse writes the backchannel authentication response.
+	WriteBackchannelAuthenticationResponse(ctx context.Context, rw http.ResponseWriter, requester BackchannelAuthenticationRequester, responder BackchannelAuthenticationResponder)
+
+	// WriteBackchannelAuthenticationError writes the backchannel authentication error.
+	// See https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#auth_error_response
+	WriteBackchannelAuthenticationError(ctx context.Context, rw http.ResponseWriter, requester BackchannelAuthenticationRequester, err error)
 }
 
 // IntrospectionResponder is the response object that will be returned when token introspection was successful,
@@ -188,6 +291,20 @@ type IntrospectionResponder interface {
 	GetAccessTokenType() string
 }
 
+// JWTIntrospectionResponder is implemented by introspection responses which can be written as JWT-formatted token
+// introspection responses as specified in https://www.rfc-editor.org/rfc/rfc9701. For inactive tokens,
+// NewIntrospectionRequest returns such a responder along with the error, so that it can be written using
+// WriteIntrospectionResponse.
+type JWTIntrospectionResponder interface {
+	// GetClient returns the authenticated client which requested the introspection.
+	GetClient() Client
+
+	// IsJWTResponseRequested returns true if the client requested a JWT-formatted introspection response.
+	IsJWTResponseRequested() bool
+
+	IntrospectionResponder
+}
+
 // Requester is an abstract interface for handling requests in Fosite.
 type Requester interface {
 	// SetID sets the unique identifier.
@@ -229,6 +346,18 @@ type Requester interface {
 	// GrantAudience marks a request's audience as granted.
 	GrantAudience(audience string)
 
+	// GetRequestedAuthorizationDetails returns the authorization details (RFC 9396) requested by the client.
+	GetRequestedAuthorizationDetails() (details AuthorizationDetails)
+
+	// SetRequestedAuthorizationDetails sets the requested authorization details.
+	SetRequestedAuthorizationDetails(details AuthorizationDetails)
+
+	// GetGrantedAuthorizationDetails returns all granted authorization details.
+	GetGrantedAuthorizationDetails() (details AuthorizationDetails)
+
+	// GrantAuthorizationDetails marks the authorization details as granted.
+	GrantAuthorizationDetails(details AuthorizationDetails)
+
 	// GetSession returns a pointer to the request's session or nil if none is set.
 	GetSession() (session Session)
 
@@ -284,6 +413,14 @@ type AuthorizeRequester interface {
 	// GetDefaultResponseMode gets default response mode for a response type in a flow
 	GetDefaultResponseMode() ResponseModeType
 
+	// GetRequestedClaims returns the claims requested through the claims parameter, or nil. The consent layer uses them
+	// to decide which claims are added to the session, see https://openid.net/specs/openid-connect-core-1_0.html#ClaimsParameter
+	GetRequestedClaims() *ClaimsRequest
+
+	// GetRequestedACRValues returns the authentication context class references requested through the acr_values
+	// parameter in order of preference, see https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest
+	GetRequestedACRValues() Arguments
+
 	Requester
 }
 
@@ -360,6 +497,168 @@ type PushedAuthorizeResponder interface {
 	ToMap() map[string]interface{}
 }
 
+// DeviceRequestStatus is the state of a device authorization request.
+type DeviceRequestStatus string
+
+const (
+	// DeviceRequestStatusPending indicates that the end user has not yet completed the user interaction.
+	DeviceRequestStatusPending DeviceRequestStatus = "pending"
+	// DeviceRequestStatusApproved indicates that the end user has approved the request.
+	DeviceRequestStatusApproved DeviceRequestStatus = "approved"
+	// DeviceRequestStatusDenied indicates that the end user has denied the request.
+	DeviceRequestStatusDenied DeviceRequestStatus = "denied"
+)
+
+// DeviceRequester is a device authorization endpoint's request context.
+type DeviceRequester interface {
+	// GetStatus returns the state of the device authorization request.
+	GetStatus() DeviceRequestStatus
+
+	// SetStatus sets the state of the device authorization request.
+	SetStatus(status DeviceRequestStatus)
+
+	// GetDeviceCodeSignature returns the signature of the device code that belongs to this request.
+	GetDeviceCodeSignature() string
+
+	// SetDeviceCodeSignature sets the signature of the device code that belongs to this request.
+	SetDeviceCodeSignature(signature string)
+
+	PollingRequester
+	Requester
+}
+
+// DeviceResponder is the device authorization endpoint's response.
+type DeviceResponder interface {
+	// GetDeviceCode returns the device_code
+	GetDeviceCode() string
+	// SetDeviceCode sets the device_code
+	SetDeviceCode(code string)
+
+	// GetUserCode returns the user_code
+	GetUserCode() string
+	// SetUserCode sets the user_code
+	SetUserCode(code string)
+
+	// GetVerificationURI returns the verification_uri
+	GetVerificationURI() string
+	// SetVerificationURI sets the verification_uri
+	SetVerificationURI(uri string)
+
+	// GetVerificationURIComplete returns the verification_uri_complete
+	GetVerificationURIComplete() string
+	// SetVerificationURIComplete sets the verification_uri_complete
+	SetVerificationURIComplete(uri string)
+
+	// GetExpiresIn returns the expires_in
+	GetExpiresIn() int64
+	// SetExpiresIn sets the expires_in
+	SetExpiresIn(seconds int64)
+
+	// GetInterval returns the interval
+	GetInterval() int
+	// SetInterval sets the interval
+	SetInterval(seconds int)
+
+	// GetHeader returns the response's header
+	GetHeader() (header http.Header)
+
+	// AddHeader adds an header key value pair to the response
+	AddHeader(key, value string)
+
+	// SetExtra sets a key value pair for the response.
+	SetExtra(key string, value interface{})
+
+	// GetExtra returns a key's value.
+	GetExtra(key string) interface{}
+
+	// ToMap converts the response to a map.
+	ToMap() map[string]interface{}
+}
+
+// BackchannelAuthenticationRequestStatus is the state of a backchannel authentication request.
+type BackchannelAuthenticationRequestStatus string
+
+const (
+	// BackchannelAuthenticationRequestStatusPending indicates that the end user has not yet authenticated.
+	BackchannelAuthenticationRequestStatusPending BackchannelAuthenticationRequestStatus = "pending"
+	// BackchannelAuthenticationRequestStatusApproved indicates that the end user has authenticated and consented.
+	BackchannelAuthenticationRequestStatusApproved BackchannelAuthenticationRequestStatus = "approved"
+	// BackchannelAuthenticationRequestStatusDenied indicates that the end user has denied the request.
+	BackchannelAuthenticationRequestStatusDenied BackchannelAuthenticationRequestStatus = "denied"
+)
+
+// BackchannelAuthenticationRequester is a backchannel authentication endpoint's request context.
+type BackchannelAuthenticationRequester interface {
+	// GetStatus returns the state of the backchannel authentication request.
+	GetStatus() BackchannelAuthenticationRequestStatus
+
+	// SetStatus sets the state of the backchannel authentication request.
+	SetStatus(status BackchannelAuthenticationRequestStatus)
+
+	// GetAuthReqIDSignature returns the signature of the auth_req_id that belongs to this request.
+	GetAuthReqIDSignature() string
+
+	// SetAuthReqIDSignature sets the signature of the auth_req_id that belongs to this request.
+	SetAuthReqIDSignature(signature string)
+
+	// GetLoginHint returns the login_hint, or an empty string if another hint was used.
+	GetLoginHint() string
+
+	// GetLoginHintToken returns the login_hint_token, or an empty string if another hint was used.
+	GetLoginHintToken() string
+
+	// GetIDTokenHintClaims returns the verified claims of the id_token_hint, or nil if another hint was used.
+	GetIDTokenHintClaims() map[string]interface{}
+
+	// GetBindingMessage returns the binding_message which is displayed on the consumption and authentication device.
+	GetBindingMessage() string
+
+	// GetUserCode returns the user_code which the end user has to confirm on the authentication device.
+	GetUserCode() string
+
+	// GetRequestedExpiry returns the lifetime of the auth_req_id requested by the client, or zero.
+	GetRequestedExpiry() time.Duration
+
+	// GetClientNotificationToken returns the bearer token the client expects when it is pinged.
+	GetClientNotificationToken() string
+
+	PollingRequester
+	Requester
+}
+
+// BackchannelAuthenticationResponder is the backchannel authentication endpoint's response.
+type BackchannelAuthenticationResponder interface {
+	// GetAuthReqID returns the auth_req_id
+	GetAuthReqID() string
+	// SetAuthReqID sets the auth_req_id
+	SetAuthReqID(id string)
+
+	// GetExpiresIn returns the expires_in
+	GetExpiresIn() int64
+	// SetExpiresIn sets the expires_in
+	SetExpiresIn(seconds int64)
+
+	// GetInterval returns the interval
+	GetInterval() int
+	// SetInterval sets the interval
+	SetInterval(seconds int)
+
+	// GetHeader returns the response's header
+	GetHeader() (header http.Header)
+
+	// AddHeader adds an header key value pair to the response
+	AddHeader(key, value string)
+
+	// SetExtra sets a key value pair for the response.
+	SetExtra(key string, value interface{})
+
+	// GetExtra returns a key's value.
+	GetExtra(key string) interface{}
+
+	// ToMap converts the response to a map.
+	ToMap() map[string]interface{}
+}
+
 // G11NContext is the globalization context
 type G11NContext interface {
 	// GetLang returns the current language in the context
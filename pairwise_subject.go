@@ -0,0 +1,147 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+)
+
+// The subject identifier types of https://openid.net/specs/openid-connect-core-1_0.html#SubjectIDTypes
+const (
+	SubjectTypePublic   = "public"
+	SubjectTypePairwise = "pairwise"
+)
+
+// PairwiseSubjectAlgorithm calculates pairwise subject identifiers, which differ for every sector identifier so that
+// clients of different sectors can not correlate the end-user's activities.
+type PairwiseSubjectAlgorithm interface {
+	// GetPairwiseSubject returns the pairwise subject identifier of the local subject for the sector identifier. It
+	// must always return the same identifier for the same sector identifier and subject.
+	GetPairwiseSubject(ctx context.Context, sectorIdentifier, subject string) (string, error)
+}
+
+// HMACPairwiseSubjectAlgorithm calculates pairwise subject identifiers as HMAC-SHA256 of the sector identifier and
+// local subject, keyed with a salt, see https://openid.net/specs/openid-connect-core-1_0.html#PairwiseAlg
+type HMACPairwiseSubjectAlgorithm struct {
+	// Salt is the secret key of the HMAC. It must not change, as this changes all pairwise subject identifiers.
+	Salt []byte
+}
+
+// GetPairwiseSubject returns the base64url encoded HMAC of the sector identifier and subject.
+func (a *HMACPairwiseSubjectAlgorithm) GetPairwiseSubject(_ context.Context, sectorIdentifier, subject string) (string, error) {
+	if len(a.Salt) == 0 {
+		return "", errors.New("the salt of pairwise subject identifiers is not set")
+	}
+
+	mac := hmac.New(sha256.New, a.Salt)
+	// The separator prevents different pairs of sector identifier and subject from having the same input.
+	_, _ = mac.Write([]byte(sectorIdentifier))
+	_, _ = mac.Write([]byte{0})
+	_, _ = mac.Write([]byte(subject))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// pairwiseSubjectAlgorithm returns the configured pairwise subject algorithm, or nil if the configuration does not
+// implement PairwiseSubjectProvider.
+func pairwiseSubjectAlgorithm(ctx context.Context, config interface{}) PairwiseSubjectAlgorithm {
+	if c, ok := config.(PairwiseSubjectProvider); ok {
+		return c.GetPairwiseSubjectAlgorithm(ctx)
+	}
+	return nil
+}
+
+// PairwiseSubject returns the subject identifier the client receives for the local subject. This is the pairwise
+// subject identifier if the client registered the pairwise subject type and the subject otherwise. The pairwise
+// subject algorithm is taken from config if it implements PairwiseSubjectProvider.
+func PairwiseSubject(ctx context.Context, config interface{}, client Client, subject string) (string, error) {
+	c, ok := client.(SubjectTypeClient)
+	if !ok || c.GetSubjectType() != SubjectTypePairwise || subject == "" {
+		return subject, nil
+	}
+
+	algorithm := pairwiseSubjectAlgorithm(ctx, config)
+	if algorithm == nil {
+		return "", errorsx.WithStack(ErrServerError.WithDebug("The OAuth 2.0 Client uses pairwise subject identifiers, but no pairwise subject algorithm is configured."))
+	}
+
+	sectorIdentifier, err := SectorIdentifier(c)
+	if err != nil {
+		return "", errorsx.WithStack(ErrServerError.WithWrap(err).WithDebugf("Unable to determine the sector identifier of the OAuth 2.0 Client: %s", ErrorToRFC6749Error(err).GetDescription()))
+	}
+
+	pairwise, err := algorithm.GetPairwiseSubject(ctx, sectorIdentifier, subject)
+	if err != nil {
+		return "", errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error()))
+	}
+	return pairwise, nil
+}
+
+// SectorIdentifier returns the host of the sector identifier URI of the client or, if the client has none, the host
+// of its redirect URIs, which must all have the same host, see
+// https://openid.net/specs/openid-connect-core-1_0.html#PairwiseAlg
+func SectorIdentifier(client SubjectTypeClient) (string, error) {
+	if raw := client.GetSectorIdentifierURI(); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return "", errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("Unable to parse the sector identifier URI '%s'.", raw))
+		}
+		return u.Host, nil
+	}
+
+	var host string
+	for _, raw := range client.GetRedirectURIs() {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return "", errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("Unable to parse redirect URI '%s'.", raw).WithWrap(err).WithDebug(err.Error()))
+		} else if host != "" && host != u.Host {
+			return "", errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The 'sector_identifier_uri' field is required for pairwise subject identifiers if the redirect URIs have different hosts."))
+		}
+		host = u.Host
+	}
+
+	if host == "" {
+		return "", errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The 'sector_identifier_uri' field is required for pairwise subject identifiers if there are no redirect URIs."))
+	}
+	return host, nil
+}
+
+// ValidateSectorIdentifierURI fetches the JSON array of redirect URIs from the sector identifier URI and checks that
+// it contains all redirect URIs of the client, see
+// https://openid.net/specs/openid-connect-registration-1_0.html#SectorIdentifierValidation
+func ValidateSectorIdentifierURI(ctx context.Context, config HTTPClientProvider, sectorIdentifierURI string, redirectURIs []string) error {
+	if u, err := url.Parse(sectorIdentifierURI); err != nil || u.Scheme != "https" || u.Host == "" {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The 'sector_identifier_uri' field must be an HTTPS URL."))
+	}
+
+	response, err := config.GetHTTPClient(ctx).Get(sectorIdentifierURI)
+	if err != nil {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("Unable to fetch the redirect URIs from 'sector_identifier_uri' because: %s.", err.Error()).WithWrap(err).WithDebug(err.Error()))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("Unable to fetch the redirect URIs from 'sector_identifier_uri' because status code '%d' was expected, but got '%d'.", http.StatusOK, response.StatusCode))
+	}
+
+	var registered []string
+	if err := json.NewDecoder(response.Body).Decode(&registered); err != nil {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("The document at 'sector_identifier_uri' must be a JSON array of redirect URIs.").WithWrap(err).WithDebug(err.Error()))
+	}
+
+	for _, redirectURI := range redirectURIs {
+		if !Arguments(registered).Has(redirectURI) {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("The redirect URI '%s' is not included in the document at 'sector_identifier_uri'.", redirectURI))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+)
+
+func TestPairwiseSubject(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{PairwiseSubjectAlgorithm: &HMACPairwiseSubjectAlgorithm{Salt: []byte("salt")}}
+
+	newClient := func(subjectType, sectorIdentifierURI string, redirectURIs ...string) *DefaultOpenIDConnectClient {
+		return &DefaultOpenIDConnectClient{
+			DefaultClient:       &DefaultClient{ID: "foo", RedirectURIs: redirectURIs},
+			SubjectType:         subjectType,
+			SectorIdentifierURI: sectorIdentifierURI,
+		}
+	}
+
+	t.Run("case=should not change the subject of public clients", func(t *testing.T) {
+		for _, client := range []Client{
+			&DefaultClient{ID: "foo"},
+			newClient("", "", "https://a.example.com/cb"),
+			newClient(SubjectTypePublic, "", "https://a.example.com/cb"),
+		} {
+			sub, err := PairwiseSubject(ctx, config, client, "peter")
+			require.NoError(t, err)
+			assert.Equal(t, "peter", sub)
+		}
+	})
+
+	t.Run("case=should calculate the subject per sector", func(t *testing.T) {
+		a, err := PairwiseSubject(ctx, config, newClient(SubjectTypePairwise, "", "https://a.example.com/cb", "https://a.example.com/other"), "peter")
+		require.NoError(t, err)
+		assert.NotEqual(t, "peter", a)
+
+		sameSector, err := PairwiseSubject(ctx, config, newClient(SubjectTypePairwise, "https://a.example.com/sector.json", "https://b.example.com/cb"), "peter")
+		require.NoError(t, err)
+		assert.Equal(t, a, sameSector)
+
+		b, err := PairwiseSubject(ctx, config, newClient(SubjectTypePairwise, "", "https://b.example.com/cb"), "peter")
+		require.NoError(t, err)
+		assert.NotEqual(t, a, b)
+
+		otherSubject, err := PairwiseSubject(ctx, config, newClient(SubjectTypePairwise, "", "https://a.example.com/cb"), "alice")
+		require.NoError(t, err)
+		assert.NotEqual(t, a, otherSubject)
+
+		otherSalt, err := PairwiseSubject(ctx, &Config{PairwiseSubjectAlgorithm: &HMACPairwiseSubjectAlgorithm{Salt: []byte("pepper")}}, newClient(SubjectTypePairwise, "", "https://a.example.com/cb"), "peter")
+		require.NoError(t, err)
+		assert.NotEqual(t, a, otherSalt)
+	})
+
+	t.Run("case=should fail without a sector identifier", func(t *testing.T) {
+		_, err := PairwiseSubject(ctx, config, newClient(SubjectTypePairwise, "", "https://a.example.com/cb", "https://b.example.com/cb"), "peter")
+		assert.ErrorIs(t, err, ErrServerError)
+
+		_, err = PairwiseSubject(ctx, config, newClient(SubjectTypePairwise, ""), "peter")
+		assert.ErrorIs(t, err, ErrServerError)
+	})
+
+	t.Run("case=should fail without a pairwise algorithm", func(t *testing.T) {
+		_, err := PairwiseSubject(ctx, &Config{}, newClient(SubjectTypePairwise, "", "https://a.example.com/cb"), "peter")
+		assert.ErrorIs(t, err, ErrServerError)
+
+		_, err = PairwiseSubject(ctx, &Config{PairwiseSubjectAlgorithm: &HMACPairwiseSubjectAlgorithm{}}, newClient(SubjectTypePairwise, "", "https://a.example.com/cb"), "peter")
+		assert.ErrorIs(t, err, ErrServerError)
+	})
+}
+
+func TestValidateSectorIdentifierURI(t *testing.T) {
+	ctx := context.Background()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sector.json":
+			_, _ = w.Write([]byte(`["https://a.example.com/cb","https://b.example.com/cb"]`))
+		case "/malformed.json":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.HTTPClient = ts.Client()
+	hc.RetryMax = 0
+	config := &Config{HTTPClient: hc}
+
+	assert.NoError(t, ValidateSectorIdentifierURI(ctx, config, ts.URL+"/sector.json", []string{"https://a.example.com/cb", "https://b.example.com/cb"}))
+	assert.ErrorIs(t, ValidateSectorIdentifierURI(ctx, config, ts.URL+"/sector.json", []string{"https://c.example.com/cb"}), ErrInvalidClientMetadata)
+	assert.ErrorIs(t, ValidateSectorIdentifierURI(ctx, config, ts.URL+"/malformed.json", nil), ErrInvalidClientMetadata)
+	assert.ErrorIs(t, ValidateSectorIdentifierURI(ctx, config, ts.URL+"/unknown.json", nil), ErrInvalidClientMetadata)
+	assert.ErrorIs(t, ValidateSectorIdentifierURI(ctx, config, "http://a.example.com/sector.json", nil), ErrInvalidClientMetadata)
+}
+
+func TestIntrospectionResponsePairwiseSubject(t *testing.T) {
+	ctx := context.Background()
+	f := &Fosite{Config: &Config{PairwiseSubjectAlgorithm: &HMACPairwiseSubjectAlgorithm{Salt: []byte("salt")}}}
+	client := &DefaultOpenIDConnectClient{
+		DefaultClient: &DefaultClient{ID: "foo", RedirectURIs: []string{"https://client.example.com/cb"}},
+		SubjectType:   SubjectTypePairwise,
+	}
+	pairwise, err := PairwiseSubject(ctx, f.Config, client, "peter")
+	require.NoError(t, err)
+
+	ar := NewAccessRequest(&DefaultSession{Subject: "peter"})
+	ar.Client = client
+
+	rw := httptest.NewRecorder()
+	f.WriteIntrospectionResponse(ctx, rw, &IntrospectionResponse{Active: true, TokenUse: AccessToken, AccessRequester: ar})
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+	assert.Equal(t, pairwise, body["sub"])
+}
@@ -0,0 +1,51 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"time"
+
+	"github.com/ory/x/errorsx"
+)
+
+// SlowDownIntervalIncrease is the amount the polling interval of a request is increased by with every slow_down
+// error, see https://www.rfc-editor.org/rfc/rfc8628#section-3.5
+const SlowDownIntervalIncrease = 5 * time.Second
+
+// PollingRequester is implemented by requests whose result the client polls the token endpoint for, such as device
+// authorization and backchannel authentication requests.
+type PollingRequester interface {
+	// GetLastPolledAt returns the last time the client polled the token endpoint for this request.
+	GetLastPolledAt() time.Time
+
+	// SetLastPolledAt sets the last time the client polled the token endpoint for this request.
+	SetLastPolledAt(t time.Time)
+
+	// GetPollingInterval returns the polling interval the client was asked to use by a slow_down error, or zero.
+	GetPollingInterval() time.Duration
+
+	// SetPollingInterval sets the polling interval the client must use from now on.
+	SetPollingInterval(interval time.Duration)
+}
+
+// CheckPollingInterval records a poll of the token endpoint for a pending request. If the client polled faster than
+// the polling interval of the request, which defaults to interval, the interval of the request is increased by
+// SlowDownIntervalIncrease and ErrSlowDown is returned. Otherwise ErrAuthorizationPending is returned. A zero interval
+// disables the check. The caller must store the updated poll state of the request.
+func CheckPollingInterval(r PollingRequester, interval time.Duration) error {
+	now := time.Now().UTC()
+	lastPolledAt := r.GetLastPolledAt()
+	r.SetLastPolledAt(now)
+
+	if current := r.GetPollingInterval(); current > interval {
+		interval = current
+	}
+
+	if interval > 0 && !lastPolledAt.IsZero() && now.Sub(lastPolledAt) < interval {
+		r.SetPollingInterval(interval + SlowDownIntervalIncrease)
+		return errorsx.WithStack(ErrSlowDown)
+	}
+
+	return errorsx.WithStack(ErrAuthorizationPending)
+}
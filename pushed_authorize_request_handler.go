@@ -58,6 +58,18 @@ func (f *Fosite) NewPushedAuthorizeRequest(ctx context.Context, r *http.Request)
 		return request, errorsx.WithStack(ErrInvalidRequest.WithHint("The request must not contain 'request_uri'."))
 	}
 
+	// A DPoP proof sent to the pushed authorization endpoint binds the authorization code to its key, in the
+	// same way as the "dpop_jkt" parameter: https://www.rfc-editor.org/rfc/rfc9449#section-10.1
+	if jkt, err := f.validateDPoPProof(ctx, r, []string{dpopRequestURL(r)}, ""); err != nil {
+		return request, err
+	} else if jkt != "" {
+		if bound := r.Form.Get("dpop_jkt"); bound == "" {
+			r.Form.Set("dpop_jkt", jkt)
+		} else if bound != jkt {
+			return request, errorsx.WithStack(ErrInvalidDPoPProof.WithHint("The 'dpop_jkt' parameter does not match the key of the DPoP proof."))
+		}
+	}
+
 	// For private_key_jwt or basic auth client authentication, "client_id" may not inside the form
 	// However this is required by NewAuthorizeRequest implementation
 	if len(r.Form.Get("client_id")) == 0 {
@@ -23,6 +23,9 @@ type Request struct {
 	RequestedAudience Arguments    `json:"requestedAudience"`
 	GrantedAudience   Arguments    `json:"grantedAudience"`
 	Lang              language.Tag `json:"-"`
+
+	RequestedAuthorizationDetails AuthorizationDetails `json:"requestedAuthorizationDetails,omitempty"`
+	GrantedAuthorizationDetails   AuthorizationDetails `json:"grantedAuthorizationDetails,omitempty"`
 }
 
 func NewRequest() *Request {
@@ -126,6 +129,26 @@ func (a *Request) GrantScope(scope string) {
 	a.GrantedScope = append(a.GrantedScope, scope)
 }
 
+func (a *Request) GetRequestedAuthorizationDetails() AuthorizationDetails {
+	return a.RequestedAuthorizationDetails
+}
+
+func (a *Request) SetRequestedAuthorizationDetails(details AuthorizationDetails) {
+	a.RequestedAuthorizationDetails = details
+}
+
+func (a *Request) GetGrantedAuthorizationDetails() AuthorizationDetails {
+	return a.GrantedAuthorizationDetails
+}
+
+func (a *Request) GrantAuthorizationDetails(details AuthorizationDetails) {
+	for _, detail := range details {
+		if !a.GrantedAuthorizationDetails.Has(detail) {
+			a.GrantedAuthorizationDetails = append(a.GrantedAuthorizationDetails, detail)
+		}
+	}
+}
+
 func (a *Request) SetSession(session Session) {
 	a.Session = session
 }
@@ -149,6 +172,13 @@ func (a *Request) Merge(request Requester) {
 		a.GrantAudience(aud)
 	}
 
+	for _, detail := range request.GetRequestedAuthorizationDetails() {
+		if !a.RequestedAuthorizationDetails.Has(detail) {
+			a.RequestedAuthorizationDetails = append(a.RequestedAuthorizationDetails, detail)
+		}
+	}
+	a.GrantAuthorizationDetails(request.GetGrantedAuthorizationDetails())
+
 	a.ID = request.GetID()
 	a.RequestedAt = request.GetRequestedAt()
 	a.Client = request.GetClient()
@@ -25,7 +25,9 @@ type ResponseModeHandler interface {
 	// header.Set("Pragma", "no-cache")
 	WriteAuthorizeResponse(ctx context.Context, rw http.ResponseWriter, ar AuthorizeRequester, resp AuthorizeResponder)
 
-	// WriteAuthorizeError writes error responses
+	// WriteAuthorizeError writes error responses. The "iss" parameter of https://www.rfc-editor.org/rfc/rfc9207
+	// should be added to them with the value of AuthorizeResponseIssuerContextKey. Successful responses already
+	// contain it in their parameters.
 	//
 	// Following headers are expected to be set by default:
 	// header.Set("Cache-Control", "no-store")
@@ -38,6 +38,12 @@ type DefaultSession struct {
 	Username  string                  `json:"username"`
 	Subject   string                  `json:"subject"`
 	Extra     map[string]interface{}  `json:"extra"`
+
+	// DPoPJWKThumbprint is the JWK SHA-256 thumbprint of the DPoP key the tokens are bound to.
+	DPoPJWKThumbprint string `json:"dpop_jkt,omitempty"`
+
+	// CertificateThumbprint is the SHA-256 thumbprint of the client certificate the tokens are bound to.
+	CertificateThumbprint string `json:"x5t_s256,omitempty"`
 }
 
 func (s *DefaultSession) SetExpiresAt(key TokenType, exp time.Time) {
@@ -74,6 +80,28 @@ func (s *DefaultSession) GetSubject() string {
 	return s.Subject
 }
 
+func (s *DefaultSession) SetDPoPJWKThumbprint(jkt string) {
+	s.DPoPJWKThumbprint = jkt
+}
+
+func (s *DefaultSession) GetDPoPJWKThumbprint() string {
+	if s == nil {
+		return ""
+	}
+	return s.DPoPJWKThumbprint
+}
+
+func (s *DefaultSession) SetCertificateThumbprint(x5t string) {
+	s.CertificateThumbprint = x5t
+}
+
+func (s *DefaultSession) GetCertificateThumbprint() string {
+	if s == nil {
+		return ""
+	}
+	return s.CertificateThumbprint
+}
+
 func (s *DefaultSession) Clone() Session {
 	if s == nil {
 		return nil
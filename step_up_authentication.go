@@ -0,0 +1,99 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ory/x/errorsx"
+)
+
+// AuthenticationSession is implemented by sessions which know how the resource owner authenticated, such as
+// openid.DefaultSession. The authentication information is added to JWT access tokens and introspection responses,
+// see https://www.rfc-editor.org/rfc/rfc9470#section-6
+type AuthenticationSession interface {
+	// GetAuthenticationContextClassReference returns the authentication context class reference (acr) the
+	// authentication satisfied, or an empty string.
+	GetAuthenticationContextClassReference() string
+
+	// GetAuthenticationMethodsReferences returns the authentication methods references (amr) used in the
+	// authentication.
+	GetAuthenticationMethodsReferences() []string
+
+	// GetAuthTime returns the time the resource owner authenticated, or the zero time.
+	GetAuthTime() time.Time
+}
+
+// GetAuthenticationClaims returns the "acr", "amr" and "auth_time" claims of the session. It returns an empty map if
+// the session does not implement AuthenticationSession.
+func GetAuthenticationClaims(session Session) map[string]interface{} {
+	claims := map[string]interface{}{}
+	s, ok := session.(AuthenticationSession)
+	if !ok {
+		return claims
+	}
+
+	if acr := s.GetAuthenticationContextClassReference(); acr != "" {
+		claims["acr"] = acr
+	}
+	if amr := s.GetAuthenticationMethodsReferences(); len(amr) > 0 {
+		claims["amr"] = amr
+	}
+	if authTime := s.GetAuthTime(); !authTime.IsZero() {
+		claims["auth_time"] = authTime.Unix()
+	}
+	return claims
+}
+
+// CheckUserAuthentication is used by resource servers to check if the authentication of the resource owner satisfies
+// one of the acrValues and happened no longer than maxAge ago. Empty acrValues and a zero maxAge are not checked.
+// If the requirements are not met, ErrInsufficientUserAuthentication is returned and the client should be challenged
+// with WriteInsufficientUserAuthenticationError, see https://www.rfc-editor.org/rfc/rfc9470#section-3
+func CheckUserAuthentication(session Session, acrValues []string, maxAge time.Duration) error {
+	s, ok := session.(AuthenticationSession)
+	if !ok {
+		return errorsx.WithStack(ErrInsufficientUserAuthentication.WithHint("The access token carries no information about the authentication of the resource owner."))
+	}
+
+	if len(acrValues) > 0 && !Arguments(acrValues).Has(s.GetAuthenticationContextClassReference()) {
+		return errorsx.WithStack(ErrInsufficientUserAuthentication.WithHintf("The authentication context class reference '%s' is not one of the required values.", s.GetAuthenticationContextClassReference()))
+	}
+
+	if maxAge > 0 {
+		if authTime := s.GetAuthTime(); authTime.IsZero() || authTime.Add(maxAge).Before(time.Now().UTC()) {
+			return errorsx.WithStack(ErrInsufficientUserAuthentication.WithHint("The authentication of the resource owner is too old."))
+		}
+	}
+
+	return nil
+}
+
+// InsufficientUserAuthenticationChallenge returns the WWW-Authenticate challenge which asks the client to obtain an
+// access token satisfying one of the acrValues and with an authentication no older than maxAge, see
+// https://www.rfc-editor.org/rfc/rfc9470#section-3
+func InsufficientUserAuthenticationChallenge(acrValues []string, maxAge time.Duration) string {
+	params := []string{
+		fmt.Sprintf(`error="%s"`, errInsufficientUserAuthenticationName),
+		fmt.Sprintf(`error_description="%s"`, ErrInsufficientUserAuthentication.DescriptionField),
+	}
+	if len(acrValues) > 0 {
+		params = append(params, fmt.Sprintf(`acr_values="%s"`, strings.Join(acrValues, " ")))
+	}
+	if maxAge > 0 {
+		params = append(params, fmt.Sprintf(`max_age="%s"`, strconv.FormatInt(int64(maxAge/time.Second), 10)))
+	}
+	return "Bearer " + strings.Join(params, ", ")
+}
+
+// WriteInsufficientUserAuthenticationError responds with the insufficient_user_authentication challenge of
+// InsufficientUserAuthenticationChallenge.
+func WriteInsufficientUserAuthenticationError(rw http.ResponseWriter, acrValues []string, maxAge time.Duration) {
+	rw.Header().Set("WWW-Authenticate", InsufficientUserAuthenticationChallenge(acrValues, maxAge))
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(http.StatusUnauthorized)
+}
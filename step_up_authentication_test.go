@@ -0,0 +1,68 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/handler/openid"
+)
+
+func TestCheckUserAuthentication(t *testing.T) {
+	session := openid.NewDefaultSession()
+	session.Claims.AuthenticationContextClassReference = "silver"
+	session.Claims.AuthenticationMethodsReferences = []string{"pwd", "otp"}
+	session.Claims.AuthTime = time.Now().UTC().Add(-time.Hour)
+
+	assert.Equal(t, map[string]interface{}{
+		"acr":       "silver",
+		"amr":       []string{"pwd", "otp"},
+		"auth_time": session.Claims.AuthTime.Unix(),
+	}, GetAuthenticationClaims(session))
+	assert.Empty(t, GetAuthenticationClaims(&DefaultSession{}))
+
+	assert.NoError(t, CheckUserAuthentication(session, nil, 0))
+	assert.NoError(t, CheckUserAuthentication(session, []string{"gold", "silver"}, 2*time.Hour))
+	assert.ErrorIs(t, CheckUserAuthentication(session, []string{"gold"}, 0), ErrInsufficientUserAuthentication)
+	assert.ErrorIs(t, CheckUserAuthentication(session, nil, time.Minute), ErrInsufficientUserAuthentication)
+	assert.ErrorIs(t, CheckUserAuthentication(&DefaultSession{}, nil, 0), ErrInsufficientUserAuthentication)
+}
+
+func TestWriteInsufficientUserAuthenticationError(t *testing.T) {
+	rw := httptest.NewRecorder()
+	WriteInsufficientUserAuthenticationError(rw, []string{"gold", "silver"}, 5*time.Minute)
+
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+	assert.Equal(t, `Bearer error="insufficient_user_authentication", error_description="The authentication event associated with the access token presented with the request does not meet the authentication requirements of the protected resource.", acr_values="gold silver", max_age="300"`, rw.Header().Get("WWW-Authenticate"))
+
+	assert.Equal(t, `Bearer error="insufficient_user_authentication", error_description="The authentication event associated with the access token presented with the request does not meet the authentication requirements of the protected resource."`, InsufficientUserAuthenticationChallenge(nil, 0))
+}
+
+func TestIntrospectionResponseAuthenticationClaims(t *testing.T) {
+	session := openid.NewDefaultSession()
+	session.Claims.AuthenticationContextClassReference = "silver"
+	session.Claims.AuthTime = time.Unix(1700000000, 0).UTC()
+
+	rw := httptest.NewRecorder()
+	new(Fosite).WriteIntrospectionResponse(context.Background(), rw, &IntrospectionResponse{
+		Active:          true,
+		TokenUse:        AccessToken,
+		AccessRequester: NewAccessRequest(session),
+	})
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+	assert.Equal(t, "silver", body["acr"])
+	assert.EqualValues(t, 1700000000, body["auth_time"])
+	assert.NotContains(t, body, "amr")
+}
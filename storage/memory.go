@@ -6,6 +6,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -50,35 +51,50 @@ type MemoryStore struct {
 	// Public keys to check signature in auth grant jwt assertion.
 	IssuerPublicKeys map[string]IssuerPublicKeys
 	PARSessions      map[string]fosite.AuthorizeRequester
-
-	clientsMutex                sync.RWMutex
-	authorizeCodesMutex         sync.RWMutex
-	idSessionsMutex             sync.RWMutex
-	accessTokensMutex           sync.RWMutex
-	refreshTokensMutex          sync.RWMutex
-	pkcesMutex                  sync.RWMutex
-	usersMutex                  sync.RWMutex
-	blacklistedJTIsMutex        sync.RWMutex
-	accessTokenRequestIDsMutex  sync.RWMutex
-	refreshTokenRequestIDsMutex sync.RWMutex
-	issuerPublicKeysMutex       sync.RWMutex
-	parSessionsMutex            sync.RWMutex
+	// Device authorization requests by device code signature, and user code signatures to device code signatures.
+	DeviceCodes map[string]StoreDeviceCode
+	UserCodes   map[string]string
+	// Backchannel authentication requests by auth_req_id signature.
+	BackchannelAuthenticationRequests map[string]StoreBackchannelAuthenticationRequest
+	// JTIs of used DPoP proofs.
+	DPoPProofJTIs map[string]time.Time
+
+	clientsMutex                 sync.RWMutex
+	authorizeCodesMutex          sync.RWMutex
+	idSessionsMutex              sync.RWMutex
+	accessTokensMutex            sync.RWMutex
+	refreshTokensMutex           sync.RWMutex
+	pkcesMutex                   sync.RWMutex
+	usersMutex                   sync.RWMutex
+	blacklistedJTIsMutex         sync.RWMutex
+	accessTokenRequestIDsMutex   sync.RWMutex
+	refreshTokenRequestIDsMutex  sync.RWMutex
+	issuerPublicKeysMutex        sync.RWMutex
+	parSessionsMutex             sync.RWMutex
+	deviceCodesMutex             sync.RWMutex
+	userCodesMutex               sync.RWMutex
+	backchannelAuthRequestsMutex sync.RWMutex
+	dpopProofJTIsMutex           sync.RWMutex
 }
 
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		Clients:                make(map[string]fosite.Client),
-		AuthorizeCodes:         make(map[string]StoreAuthorizeCode),
-		IDSessions:             make(map[string]fosite.Requester),
-		AccessTokens:           make(map[string]fosite.Requester),
-		RefreshTokens:          make(map[string]StoreRefreshToken),
-		PKCES:                  make(map[string]fosite.Requester),
-		Users:                  make(map[string]MemoryUserRelation),
-		AccessTokenRequestIDs:  make(map[string]string),
-		RefreshTokenRequestIDs: make(map[string]string),
-		BlacklistedJTIs:        make(map[string]time.Time),
-		IssuerPublicKeys:       make(map[string]IssuerPublicKeys),
-		PARSessions:            make(map[string]fosite.AuthorizeRequester),
+		Clients:                           make(map[string]fosite.Client),
+		AuthorizeCodes:                    make(map[string]StoreAuthorizeCode),
+		IDSessions:                        make(map[string]fosite.Requester),
+		AccessTokens:                      make(map[string]fosite.Requester),
+		RefreshTokens:                     make(map[string]StoreRefreshToken),
+		PKCES:                             make(map[string]fosite.Requester),
+		Users:                             make(map[string]MemoryUserRelation),
+		AccessTokenRequestIDs:             make(map[string]string),
+		RefreshTokenRequestIDs:            make(map[string]string),
+		BlacklistedJTIs:                   make(map[string]time.Time),
+		IssuerPublicKeys:                  make(map[string]IssuerPublicKeys),
+		PARSessions:                       make(map[string]fosite.AuthorizeRequester),
+		DeviceCodes:                       make(map[string]StoreDeviceCode),
+		UserCodes:                         make(map[string]string),
+		BackchannelAuthenticationRequests: make(map[string]StoreBackchannelAuthenticationRequest),
+		DPoPProofJTIs:                     make(map[string]time.Time),
 	}
 }
 
@@ -92,6 +108,16 @@ type StoreRefreshToken struct {
 	fosite.Requester
 }
 
+type StoreDeviceCode struct {
+	active bool
+	fosite.DeviceRequester
+}
+
+type StoreBackchannelAuthenticationRequest struct {
+	active bool
+	fosite.BackchannelAuthenticationRequester
+}
+
 func NewExampleStore() *MemoryStore {
 	return &MemoryStore{
 		IDSessions: make(map[string]fosite.Requester),
@@ -135,14 +161,18 @@ func NewExampleStore() *MemoryStore {
 				Password: "secret",
 			},
 		},
-		AuthorizeCodes:         map[string]StoreAuthorizeCode{},
-		AccessTokens:           map[string]fosite.Requester{},
-		RefreshTokens:          map[string]StoreRefreshToken{},
-		PKCES:                  map[string]fosite.Requester{},
-		AccessTokenRequestIDs:  map[string]string{},
-		RefreshTokenRequestIDs: map[string]string{},
-		IssuerPublicKeys:       map[string]IssuerPublicKeys{},
-		PARSessions:            map[string]fosite.AuthorizeRequester{},
+		AuthorizeCodes:                    map[string]StoreAuthorizeCode{},
+		AccessTokens:                      map[string]fosite.Requester{},
+		RefreshTokens:                     map[string]StoreRefreshToken{},
+		PKCES:                             map[string]fosite.Requester{},
+		AccessTokenRequestIDs:             map[string]string{},
+		RefreshTokenRequestIDs:            map[string]string{},
+		IssuerPublicKeys:                  map[string]IssuerPublicKeys{},
+		PARSessions:                       map[string]fosite.AuthorizeRequester{},
+		DeviceCodes:                       map[string]StoreDeviceCode{},
+		UserCodes:                         map[string]string{},
+		BackchannelAuthenticationRequests: map[string]StoreBackchannelAuthenticationRequest{},
+		DPoPProofJTIs:                     map[string]time.Time{},
 	}
 }
 
@@ -184,6 +214,39 @@ func (s *MemoryStore) GetClient(_ context.Context, id string) (fosite.Client, er
 	return cl, nil
 }
 
+func (s *MemoryStore) CreateClient(_ context.Context, client fosite.Client) error {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	if _, ok := s.Clients[client.GetID()]; ok {
+		return fmt.Errorf("client with id '%s' already exists", client.GetID())
+	}
+	s.Clients[client.GetID()] = client
+	return nil
+}
+
+func (s *MemoryStore) UpdateClient(_ context.Context, client fosite.Client) error {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	if _, ok := s.Clients[client.GetID()]; !ok {
+		return fosite.ErrNotFound
+	}
+	s.Clients[client.GetID()] = client
+	return nil
+}
+
+func (s *MemoryStore) DeleteClient(_ context.Context, id string) error {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	if _, ok := s.Clients[id]; !ok {
+		return fosite.ErrNotFound
+	}
+	delete(s.Clients, id)
+	return nil
+}
+
 func (s *MemoryStore) SetTokenLifespans(clientID string, lifespans *fosite.ClientLifespanConfig) error {
 	if client, ok := s.Clients[clientID]; ok {
 		if clc, ok := client.(*fosite.DefaultClientWithCustomTokenLifespans); ok {
@@ -225,6 +288,36 @@ func (s *MemoryStore) SetClientAssertionJWT(_ context.Context, jti string, exp t
 	return nil
 }
 
+func (s *MemoryStore) DPoPProofJTIValid(_ context.Context, jti string) error {
+	s.dpopProofJTIsMutex.RLock()
+	defer s.dpopProofJTIsMutex.RUnlock()
+
+	if exp, exists := s.DPoPProofJTIs[jti]; exists && exp.After(time.Now()) {
+		return fosite.ErrJTIKnown
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) SetDPoPProofJTI(_ context.Context, jti string, exp time.Time) error {
+	s.dpopProofJTIsMutex.Lock()
+	defer s.dpopProofJTIsMutex.Unlock()
+
+	// delete expired jtis
+	for j, e := range s.DPoPProofJTIs {
+		if e.Before(time.Now()) {
+			delete(s.DPoPProofJTIs, j)
+		}
+	}
+
+	if _, exists := s.DPoPProofJTIs[jti]; exists {
+		return fosite.ErrJTIKnown
+	}
+
+	s.DPoPProofJTIs[jti] = exp
+	return nil
+}
+
 func (s *MemoryStore) CreateAuthorizeCodeSession(_ context.Context, code string, req fosite.Requester) error {
 	s.authorizeCodesMutex.Lock()
 	defer s.authorizeCodesMutex.Unlock()
@@ -496,3 +589,158 @@ func (s *MemoryStore) DeletePARSession(ctx context.Context, requestURI string) (
 	delete(s.PARSessions, requestURI)
 	return nil
 }
+
+// CreateDeviceAuthSession stores the device authorization request by its device code signature and
+// its user code signature.
+func (s *MemoryStore) CreateDeviceAuthSession(_ context.Context, deviceCodeSignature, userCodeSignature string, req fosite.DeviceRequester) error {
+	s.deviceCodesMutex.Lock()
+	defer s.deviceCodesMutex.Unlock()
+	s.userCodesMutex.Lock()
+	defer s.userCodesMutex.Unlock()
+
+	s.DeviceCodes[deviceCodeSignature] = StoreDeviceCode{active: true, DeviceRequester: req}
+	s.UserCodes[userCodeSignature] = deviceCodeSignature
+	return nil
+}
+
+func (s *MemoryStore) GetDeviceCodeSession(_ context.Context, signature string, _ fosite.Session) (fosite.DeviceRequester, error) {
+	s.deviceCodesMutex.RLock()
+	defer s.deviceCodesMutex.RUnlock()
+
+	rel, ok := s.DeviceCodes[signature]
+	if !ok {
+		return nil, fosite.ErrNotFound
+	}
+	if !rel.active {
+		return rel.DeviceRequester, fosite.ErrInvalidatedDeviceCode
+	}
+
+	return rel.DeviceRequester, nil
+}
+
+func (s *MemoryStore) GetUserCodeSession(ctx context.Context, signature string, session fosite.Session) (fosite.DeviceRequester, error) {
+	s.userCodesMutex.RLock()
+	deviceCodeSignature, ok := s.UserCodes[signature]
+	s.userCodesMutex.RUnlock()
+	if !ok {
+		return nil, fosite.ErrNotFound
+	}
+
+	s.deviceCodesMutex.RLock()
+	defer s.deviceCodesMutex.RUnlock()
+
+	rel, ok := s.DeviceCodes[deviceCodeSignature]
+	if !ok || !rel.active {
+		return nil, fosite.ErrNotFound
+	}
+
+	return rel.DeviceRequester, nil
+}
+
+func (s *MemoryStore) UpdateDeviceCodeSession(_ context.Context, signature string, req fosite.DeviceRequester) error {
+	s.deviceCodesMutex.Lock()
+	defer s.deviceCodesMutex.Unlock()
+
+	rel, ok := s.DeviceCodes[signature]
+	if !ok {
+		return fosite.ErrNotFound
+	}
+
+	rel.DeviceRequester = req
+	s.DeviceCodes[signature] = rel
+	return nil
+}
+
+func (s *MemoryStore) UpdateDeviceCodePollingState(_ context.Context, signature string, lastPolledAt time.Time, interval time.Duration) error {
+	s.deviceCodesMutex.Lock()
+	defer s.deviceCodesMutex.Unlock()
+
+	rel, ok := s.DeviceCodes[signature]
+	if !ok {
+		return fosite.ErrNotFound
+	}
+
+	rel.DeviceRequester.SetLastPolledAt(lastPolledAt)
+	rel.DeviceRequester.SetPollingInterval(interval)
+	return nil
+}
+
+func (s *MemoryStore) InvalidateDeviceCodeSession(_ context.Context, signature string) error {
+	s.deviceCodesMutex.Lock()
+	defer s.deviceCodesMutex.Unlock()
+
+	rel, ok := s.DeviceCodes[signature]
+	if !ok {
+		return fosite.ErrNotFound
+	}
+
+	rel.active = false
+	s.DeviceCodes[signature] = rel
+	return nil
+}
+
+// CreateBackchannelAuthenticationSession stores the backchannel authentication request by its auth_req_id signature.
+func (s *MemoryStore) CreateBackchannelAuthenticationSession(_ context.Context, signature string, req fosite.BackchannelAuthenticationRequester) error {
+	s.backchannelAuthRequestsMutex.Lock()
+	defer s.backchannelAuthRequestsMutex.Unlock()
+
+	s.BackchannelAuthenticationRequests[signature] = StoreBackchannelAuthenticationRequest{active: true, BackchannelAuthenticationRequester: req}
+	return nil
+}
+
+func (s *MemoryStore) GetBackchannelAuthenticationSession(_ context.Context, signature string, _ fosite.Session) (fosite.BackchannelAuthenticationRequester, error) {
+	s.backchannelAuthRequestsMutex.RLock()
+	defer s.backchannelAuthRequestsMutex.RUnlock()
+
+	rel, ok := s.BackchannelAuthenticationRequests[signature]
+	if !ok {
+		return nil, fosite.ErrNotFound
+	}
+	if !rel.active {
+		return rel.BackchannelAuthenticationRequester, fosite.ErrInvalidatedAuthReqID
+	}
+
+	return rel.BackchannelAuthenticationRequester, nil
+}
+
+func (s *MemoryStore) UpdateBackchannelAuthenticationSession(_ context.Context, signature string, req fosite.BackchannelAuthenticationRequester) error {
+	s.backchannelAuthRequestsMutex.Lock()
+	defer s.backchannelAuthRequestsMutex.Unlock()
+
+	rel, ok := s.BackchannelAuthenticationRequests[signature]
+	if !ok {
+		return fosite.ErrNotFound
+	}
+
+	rel.BackchannelAuthenticationRequester = req
+	s.BackchannelAuthenticationRequests[signature] = rel
+	return nil
+}
+
+func (s *MemoryStore) UpdateBackchannelAuthenticationPollingState(_ context.Context, signature string, lastPolledAt time.Time, interval time.Duration) error {
+	s.backchannelAuthRequestsMutex.Lock()
+	defer s.backchannelAuthRequestsMutex.Unlock()
+
+	rel, ok := s.BackchannelAuthenticationRequests[signature]
+	if !ok {
+		return fosite.ErrNotFound
+	}
+
+	rel.BackchannelAuthenticationRequester.SetLastPolledAt(lastPolledAt)
+	rel.BackchannelAuthenticationRequester.SetPollingInterval(interval)
+	return nil
+}
+
+func (s *MemoryStore) InvalidateBackchannelAuthenticationSession(_ context.Context, signature string) error {
+	s.backchannelAuthRequestsMutex.Lock()
+	defer s.backchannelAuthRequestsMutex.Unlock()
+
+	rel, ok := s.BackchannelAuthenticationRequests[signature]
+	if !ok {
+		return fosite.ErrNotFound
+	}
+
+	rel.active = false
+	s.BackchannelAuthenticationRequests[signature] = rel
+	return nil
+}
@@ -170,6 +170,25 @@ func (c *HMACStrategy) Signature(token string) string {
 	return split[1]
 }
 
+// GenerateHMACForString returns the base64 encoded HMAC of the given text, signed with the global secret. It is
+// useful for deriving lookup signatures from low-entropy values such as user codes.
+func (c *HMACStrategy) GenerateHMACForString(ctx context.Context, text string) (string, error) {
+	secrets, err := c.Config.GetGlobalSecret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(secrets) < minimumSecretLength {
+		return "", errors.Errorf("secret for signing HMAC-SHA512/256 is expected to be 32 byte long, got %d byte", len(secrets))
+	}
+
+	var signingKey [32]byte
+	copy(signingKey[:], secrets)
+
+	signature := c.generateHMAC(ctx, []byte(text), &signingKey)
+	return b64.EncodeToString(signature), nil
+}
+
 func (c *HMACStrategy) generateHMAC(ctx context.Context, data []byte, key *[32]byte) []byte {
 	hasher := c.Config.GetHMACHasher(ctx)
 	if hasher == nil {
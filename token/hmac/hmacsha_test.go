@@ -133,3 +133,22 @@ func TestCustomHMAC(t *testing.T) {
 	require.NoError(t, sha512.Validate(context.Background(), token512))
 	require.EqualError(t, def.Validate(context.Background(), token512), fosite.ErrTokenSignatureMismatch.Error())
 }
+
+func TestGenerateHMACForString(t *testing.T) {
+	cg := HMACStrategy{Config: &fosite.Config{GlobalSecret: []byte("1234567890123456789012345678901234567890")}}
+
+	a, err := cg.GenerateHMACForString(context.Background(), "ABCDEFGH")
+	require.NoError(t, err)
+	b, err := cg.GenerateHMACForString(context.Background(), "ABCDEFGH")
+	require.NoError(t, err)
+	c, err := cg.GenerateHMACForString(context.Background(), "ABCDEFGI")
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, a)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+
+	cg.Config = &fosite.Config{GlobalSecret: []byte("foo")}
+	_, err = cg.GenerateHMACForString(context.Background(), "ABCDEFGH")
+	require.Error(t, err)
+}
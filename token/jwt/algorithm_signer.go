@@ -0,0 +1,121 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/pkg/errors"
+)
+
+// AlgorithmSigner is implemented by signers which can sign tokens with more than one JWS algorithm, for example
+// responses which must be signed with the algorithm a client registered.
+type AlgorithmSigner interface {
+	Signer
+
+	// GetSigningAlgorithms returns the JWS algorithms tokens can be signed with. The first one is used by Generate.
+	GetSigningAlgorithms(ctx context.Context) []string
+
+	// GenerateWithAlgorithm generates a token signed with the JWS algorithm alg.
+	GenerateWithAlgorithm(ctx context.Context, alg string, claims MapClaims, header Mapper) (string, string, error)
+}
+
+// AlgorithmSigners is an AlgorithmSigner which delegates to a signer per JWS algorithm.
+type AlgorithmSigners struct {
+	// DefaultAlgorithm is the JWS algorithm Generate signs tokens with.
+	DefaultAlgorithm string
+
+	// Signers are the signers of the supported JWS algorithms, keyed by algorithm.
+	Signers map[string]Signer
+}
+
+var _ AlgorithmSigner = (*AlgorithmSigners)(nil)
+
+// GetSigningAlgorithms returns the default algorithm followed by the other supported algorithms.
+func (s *AlgorithmSigners) GetSigningAlgorithms(_ context.Context) []string {
+	algs := make([]string, 0, len(s.Signers))
+	for alg := range s.Signers {
+		if alg != s.DefaultAlgorithm {
+			algs = append(algs, alg)
+		}
+	}
+	sort.Strings(algs)
+
+	if _, ok := s.Signers[s.DefaultAlgorithm]; ok {
+		algs = append([]string{s.DefaultAlgorithm}, algs...)
+	}
+	return algs
+}
+
+// Generate generates a token signed with the default algorithm.
+func (s *AlgorithmSigners) Generate(ctx context.Context, claims MapClaims, header Mapper) (string, string, error) {
+	return s.GenerateWithAlgorithm(ctx, s.DefaultAlgorithm, claims, header)
+}
+
+// GenerateWithAlgorithm generates a token with the signer of the algorithm.
+func (s *AlgorithmSigners) GenerateWithAlgorithm(ctx context.Context, alg string, claims MapClaims, header Mapper) (string, string, error) {
+	signer, err := s.signer(alg)
+	if err != nil {
+		return "", "", err
+	}
+	return signer.Generate(ctx, claims, header)
+}
+
+// Validate validates the token with the signer of the algorithm in its header.
+func (s *AlgorithmSigners) Validate(ctx context.Context, token string) (string, error) {
+	signer, err := s.tokenSigner(token)
+	if err != nil {
+		return "", err
+	}
+	return signer.Validate(ctx, token)
+}
+
+// Decode decodes the token with the signer of the algorithm in its header.
+func (s *AlgorithmSigners) Decode(ctx context.Context, token string) (*Token, error) {
+	signer, err := s.tokenSigner(token)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Decode(ctx, token)
+}
+
+// GetSignature will return the signature of a token
+func (s *AlgorithmSigners) GetSignature(_ context.Context, token string) (string, error) {
+	return getTokenSignature(token)
+}
+
+// Hash will return a given hash based on the byte input or an error upon fail
+func (s *AlgorithmSigners) Hash(_ context.Context, in []byte) ([]byte, error) {
+	return hashSHA256(in)
+}
+
+// GetSigningMethodLength will return the length of the signing method
+func (s *AlgorithmSigners) GetSigningMethodLength(_ context.Context) int {
+	return SHA256HashSize
+}
+
+func (s *AlgorithmSigners) signer(alg string) (Signer, error) {
+	signer, ok := s.Signers[alg]
+	if !ok || signer == nil {
+		return nil, errors.Errorf("no signer is configured for algorithm '%s'", alg)
+	}
+	return signer, nil
+}
+
+func (s *AlgorithmSigners) tokenSigner(token string) (Signer, error) {
+	parsed, err := jose.ParseSigned(token)
+	if err != nil {
+		return nil, &ValidationError{Errors: ValidationErrorMalformed, Inner: err}
+	} else if len(parsed.Signatures) != 1 {
+		return nil, &ValidationError{Errors: ValidationErrorMalformed, Inner: errors.New("the token must have exactly one signature")}
+	}
+
+	signer, err := s.signer(parsed.Signatures[0].Header.Algorithm)
+	if err != nil {
+		return nil, &ValidationError{Errors: ValidationErrorUnverifiable, Inner: err}
+	}
+	return signer, nil
+}
@@ -0,0 +1,51 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite/internal/gen"
+)
+
+func TestAlgorithmSigners(t *testing.T) {
+	ctx := context.Background()
+	rsaKey, ecKey := gen.MustRSAKey(), gen.MustES256Key()
+	signers := &AlgorithmSigners{
+		DefaultAlgorithm: "RS256",
+		Signers: map[string]Signer{
+			"RS256": &DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return rsaKey, nil }},
+			"ES256": &DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return ecKey, nil }},
+		},
+	}
+
+	algorithm := func(token string) string {
+		parsed, err := jose.ParseSigned(token)
+		require.NoError(t, err)
+		return parsed.Signatures[0].Header.Algorithm
+	}
+
+	assert.Equal(t, []string{"RS256", "ES256"}, signers.GetSigningAlgorithms(ctx))
+
+	token, _, err := signers.Generate(ctx, MapClaims{"foo": "bar"}, NewHeaders())
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", algorithm(token))
+	_, err = signers.Validate(ctx, token)
+	assert.NoError(t, err)
+
+	token, _, err = signers.GenerateWithAlgorithm(ctx, "ES256", MapClaims{"foo": "bar"}, NewHeaders())
+	require.NoError(t, err)
+	assert.Equal(t, "ES256", algorithm(token))
+	decoded, err := signers.Decode(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", decoded.Claims["foo"])
+
+	_, _, err = signers.GenerateWithAlgorithm(ctx, "PS256", MapClaims{"foo": "bar"}, NewHeaders())
+	assert.Error(t, err)
+}
@@ -24,6 +24,7 @@ type IDTokenClaims struct {
 	AuthenticationContextClassReference string                 `json:"acr"`
 	AuthenticationMethodsReferences     []string               `json:"amr"`
 	CodeHash                            string                 `json:"c_hash"`
+	SessionID                           string                 `json:"sid"`
 	Extra                               map[string]interface{} `json:"ext"`
 }
 
@@ -109,6 +110,12 @@ func (c *IDTokenClaims) ToMap() map[string]interface{} {
 		delete(ret, "amr")
 	}
 
+	if len(c.SessionID) > 0 {
+		ret["sid"] = c.SessionID
+	} else {
+		delete(ret, "sid")
+	}
+
 	return ret
 
 }
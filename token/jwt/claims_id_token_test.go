@@ -75,4 +75,7 @@ func TestIDTokenClaimsToMap(t *testing.T) {
 		"amr":       idTokenClaims.AuthenticationMethodsReferences,
 		"nonce":     idTokenClaims.Nonce,
 	}, idTokenClaims.ToMap())
+
+	idTokenClaims.SessionID = "session-id"
+	assert.Equal(t, "session-id", idTokenClaims.ToMap()["sid"])
 }
@@ -54,6 +54,18 @@ type JWTClaims struct {
 	Scope      []string
 	Extra      map[string]interface{}
 	ScopeField JWTScopeFieldEnum
+
+	// AuthTime, AuthenticationContextClassReference and AuthenticationMethodsReferences describe the authentication
+	// of the resource owner, see https://www.rfc-editor.org/rfc/rfc9068#section-2.2.1
+	AuthTime                            time.Time
+	AuthenticationContextClassReference string
+	AuthenticationMethodsReferences     []string
+
+	// Groups, Roles and Entitlements are the authorization attributes of the resource owner, see
+	// https://www.rfc-editor.org/rfc/rfc9068#section-2.2.3.1
+	Groups       []string
+	Roles        []string
+	Entitlements []string
 }
 
 func (c *JWTClaims) With(expiry time.Time, scope, audience []string) JWTClaimsContainer {
@@ -138,6 +150,30 @@ func (c *JWTClaims) ToMap() map[string]interface{} {
 		delete(ret, "scope")
 	}
 
+	if !c.AuthTime.IsZero() {
+		ret["auth_time"] = c.AuthTime.Unix()
+	}
+
+	if c.AuthenticationContextClassReference != "" {
+		ret["acr"] = c.AuthenticationContextClassReference
+	}
+
+	if len(c.AuthenticationMethodsReferences) > 0 {
+		ret["amr"] = c.AuthenticationMethodsReferences
+	}
+
+	if len(c.Groups) > 0 {
+		ret["groups"] = c.Groups
+	}
+
+	if len(c.Roles) > 0 {
+		ret["roles"] = c.Roles
+	}
+
+	if len(c.Entitlements) > 0 {
+		ret["entitlements"] = c.Entitlements
+	}
+
 	return ret
 }
 
@@ -201,12 +237,42 @@ func (c *JWTClaims) FromMap(m map[string]interface{}) {
 					c.ScopeField = JWTScopeFieldString
 				}
 			}
+		case "auth_time":
+			c.AuthTime = toTime(v, c.AuthTime)
+		case "acr":
+			if s, ok := v.(string); ok {
+				c.AuthenticationContextClassReference = s
+			}
+		case "amr":
+			c.AuthenticationMethodsReferences = toStrings(v)
+		case "groups":
+			c.Groups = toStrings(v)
+		case "roles":
+			c.Roles = toStrings(v)
+		case "entitlements":
+			c.Entitlements = toStrings(v)
 		default:
 			c.Extra[k] = v
 		}
 	}
 }
 
+func toStrings(v interface{}) []string {
+	switch s := v.(type) {
+	case []string:
+		return s
+	case []interface{}:
+		ret := make([]string, 0, len(s))
+		for _, vi := range s {
+			if s, ok := vi.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+	return nil
+}
+
 func toTime(v interface{}, def time.Time) (t time.Time) {
 	t = def
 	switch a := v.(type) {
@@ -0,0 +1,86 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackChannelLogoutEvent is the member of the events claim which identifies a logout token, see
+// https://openid.net/specs/openid-connect-backchannel-1_0.html#LogoutToken
+const BackChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// LogoutTokenClaims represent the claims used in back-channel logout tokens. Logout tokens must contain a subject,
+// a session ID or both, and must never contain a nonce.
+type LogoutTokenClaims struct {
+	JTI       string                 `json:"jti"`
+	Issuer    string                 `json:"iss"`
+	Subject   string                 `json:"sub"`
+	Audience  []string               `json:"aud"`
+	SessionID string                 `json:"sid"`
+	IssuedAt  time.Time              `json:"iat"`
+	ExpiresAt time.Time              `json:"exp"`
+	Extra     map[string]interface{} `json:"ext"`
+}
+
+// ToMap will transform the headers to a map structure
+func (c *LogoutTokenClaims) ToMap() map[string]interface{} {
+	var ret = Copy(c.Extra)
+
+	// The nonce claim is prohibited, so that logout tokens can not be used as ID tokens.
+	delete(ret, "nonce")
+
+	if c.Subject != "" {
+		ret["sub"] = c.Subject
+	} else {
+		delete(ret, "sub")
+	}
+
+	if c.SessionID != "" {
+		ret["sid"] = c.SessionID
+	} else {
+		delete(ret, "sid")
+	}
+
+	if c.Issuer != "" {
+		ret["iss"] = c.Issuer
+	} else {
+		delete(ret, "iss")
+	}
+
+	if c.JTI != "" {
+		ret["jti"] = c.JTI
+	} else {
+		ret["jti"] = uuid.New().String()
+	}
+
+	if len(c.Audience) > 0 {
+		ret["aud"] = c.Audience
+	} else {
+		ret["aud"] = []string{}
+	}
+
+	if !c.IssuedAt.IsZero() {
+		ret["iat"] = c.IssuedAt.Unix()
+	} else {
+		delete(ret, "iat")
+	}
+
+	if !c.ExpiresAt.IsZero() {
+		ret["exp"] = c.ExpiresAt.Unix()
+	} else {
+		delete(ret, "exp")
+	}
+
+	ret["events"] = map[string]interface{}{BackChannelLogoutEvent: map[string]interface{}{}}
+
+	return ret
+}
+
+// ToMapClaims will return a jwt-go MapClaims representation
+func (c LogoutTokenClaims) ToMapClaims() MapClaims {
+	return c.ToMap()
+}
@@ -0,0 +1,73 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/pkg/errors"
+)
+
+// Decrypter decrypts JSON Web Encryption (JWE) tokens, for example nested JWTs, which were encrypted to keys of the
+// authorization server.
+type Decrypter interface {
+	// Decrypt decrypts the compact serialized JWE and returns its plaintext and header.
+	Decrypt(ctx context.Context, token string) ([]byte, jose.Header, error)
+}
+
+// GetPrivateKeysFunc returns the private keys tokens are decrypted with.
+type GetPrivateKeysFunc func(ctx context.Context) (*jose.JSONWebKeySet, error)
+
+// DefaultDecrypter decrypts tokens with the private key identified by their "kid" header, or tries all private
+// encryption keys if the token has none.
+type DefaultDecrypter struct {
+	GetPrivateKeys GetPrivateKeysFunc
+}
+
+// IsEncrypted returns true if the token is a compact serialized JWE, which consists of five parts instead of the
+// three parts of a JWS.
+func IsEncrypted(token string) bool {
+	return strings.Count(token, ".") == 4
+}
+
+// Decrypt decrypts the compact serialized JWE and returns its plaintext and header.
+func (d *DefaultDecrypter) Decrypt(ctx context.Context, token string) ([]byte, jose.Header, error) {
+	jwe, err := jose.ParseEncrypted(token)
+	if err != nil {
+		return nil, jose.Header{}, errors.WithStack(err)
+	}
+
+	set, err := d.GetPrivateKeys(ctx)
+	if err != nil {
+		return nil, jose.Header{}, err
+	}
+
+	var candidates []jose.JSONWebKey
+	if kid := jwe.Header.KeyID; kid != "" {
+		candidates = set.Key(kid)
+	} else {
+		for _, key := range set.Keys {
+			if key.Use != "" && key.Use != "enc" {
+				continue
+			} else if key.Algorithm != "" && key.Algorithm != jwe.Header.Algorithm {
+				continue
+			}
+			candidates = append(candidates, key)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, jose.Header{}, errors.New("no private key found to decrypt the token")
+	}
+
+	var plaintext []byte
+	for _, key := range candidates {
+		if plaintext, err = jwe.Decrypt(key.Key); err == nil {
+			return plaintext, jwe.Header, nil
+		}
+	}
+	return nil, jose.Header{}, errors.WithStack(err)
+}
@@ -0,0 +1,57 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite/internal/gen"
+)
+
+func TestDefaultDecrypter(t *testing.T) {
+	ctx := context.Background()
+	signingKey := gen.MustRSAKey()
+	encryptionKey := gen.MustRSAKey()
+	otherKey := gen.MustRSAKey()
+	decrypter := &DefaultDecrypter{GetPrivateKeys: func(context.Context) (*jose.JSONWebKeySet, error) {
+		return &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: signingKey, KeyID: "sig", Use: "sig", Algorithm: "RS256"},
+			{Key: encryptionKey, KeyID: "enc", Use: "enc", Algorithm: string(jose.RSA_OAEP)},
+		}}, nil
+	}}
+
+	encrypt := func(t *testing.T, key interface{}, kid string) string {
+		encrypter, err := jose.NewEncrypter(jose.A128GCM, jose.Recipient{Algorithm: jose.RSA_OAEP, Key: key, KeyID: kid}, nil)
+		require.NoError(t, err)
+		jwe, err := encrypter.Encrypt([]byte("secret"))
+		require.NoError(t, err)
+		token, err := jwe.CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	for _, kid := range []string{"enc", ""} {
+		token := encrypt(t, &encryptionKey.PublicKey, kid)
+		assert.True(t, IsEncrypted(token))
+
+		plaintext, header, err := decrypter.Decrypt(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(plaintext))
+		assert.Equal(t, string(jose.RSA_OAEP), header.Algorithm)
+		assert.Equal(t, "A128GCM", header.ExtraHeaders["enc"])
+	}
+
+	_, _, err := decrypter.Decrypt(ctx, encrypt(t, &otherKey.PublicKey, ""))
+	assert.Error(t, err)
+
+	_, _, err = decrypter.Decrypt(ctx, encrypt(t, &encryptionKey.PublicKey, "unknown"))
+	assert.Error(t, err)
+
+	assert.False(t, IsEncrypted("eyJhbGciOiJub25lIn0.e30."))
+}
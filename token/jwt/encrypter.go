@@ -0,0 +1,136 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"strings"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/pkg/errors"
+)
+
+// EncryptingSigner wraps a Signer and encrypts the tokens it generates, yielding nested JWTs as specified in
+// https://www.rfc-editor.org/rfc/rfc7519#section-5.2. Nested JWTs passed to Decode, Validate and GetSignature are
+// decrypted before they are verified by the wrapped Signer.
+type EncryptingSigner struct {
+	Signer
+
+	// EncryptionKey is the public key of the recipient tokens are encrypted to. If nil, generated tokens are only
+	// signed.
+	EncryptionKey *jose.JSONWebKey
+
+	// KeyAlgorithm is the JWE alg used to encrypt the content encryption key, for example RSA-OAEP-256 or
+	// ECDH-ES+A128KW. Defaults to the algorithm of the EncryptionKey.
+	KeyAlgorithm jose.KeyAlgorithm
+
+	// ContentEncryption is the JWE enc used to encrypt the token. Defaults to A128CBC-HS256.
+	ContentEncryption jose.ContentEncryption
+
+	// Decrypter decrypts nested JWTs which were encrypted to the authorization server. If nil, only signed tokens
+	// can be decoded.
+	Decrypter Decrypter
+}
+
+// Generate signs the claims with the wrapped Signer and encrypts the signed token to the EncryptionKey. The returned
+// signature is the one of the signed token.
+func (j *EncryptingSigner) Generate(ctx context.Context, claims MapClaims, header Mapper) (string, string, error) {
+	token, sig, err := j.Signer.Generate(ctx, claims, header)
+	if err != nil || j.EncryptionKey == nil {
+		return token, sig, err
+	}
+
+	alg := j.KeyAlgorithm
+	if alg == "" {
+		alg = jose.KeyAlgorithm(j.EncryptionKey.Algorithm)
+	}
+	enc := j.ContentEncryption
+	if enc == "" {
+		enc = jose.A128CBC_HS256
+	}
+
+	encrypter, err := jose.NewEncrypter(enc, jose.Recipient{Algorithm: alg, Key: j.EncryptionKey.Key, KeyID: j.EncryptionKey.KeyID}, (&jose.EncrypterOptions{}).WithContentType("JWT"))
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+
+	jwe, err := encrypter.Encrypt([]byte(token))
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+
+	token, err = jwe.CompactSerialize()
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+	return token, sig, nil
+}
+
+// Validate decrypts nested JWTs and validates the signed token with the wrapped Signer.
+func (j *EncryptingSigner) Validate(ctx context.Context, token string) (string, error) {
+	token, err := j.decrypt(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	return j.Signer.Validate(ctx, token)
+}
+
+// Decode decrypts nested JWTs and decodes the signed token with the wrapped Signer.
+func (j *EncryptingSigner) Decode(ctx context.Context, token string) (*Token, error) {
+	token, err := j.decrypt(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return j.Signer.Decode(ctx, token)
+}
+
+// GetSignature decrypts nested JWTs and returns the signature of the signed token.
+func (j *EncryptingSigner) GetSignature(ctx context.Context, token string) (string, error) {
+	token, err := j.decrypt(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	return j.Signer.GetSignature(ctx, token)
+}
+
+func (j *EncryptingSigner) decrypt(ctx context.Context, token string) (string, error) {
+	if !IsEncrypted(token) {
+		return token, nil
+	} else if j.Decrypter == nil {
+		return "", &ValidationError{Errors: ValidationErrorMalformed, Inner: errors.New("the token is encrypted, but no decrypter is configured")}
+	}
+
+	plaintext, _, err := j.Decrypter.Decrypt(ctx, token)
+	if err != nil {
+		return "", &ValidationError{Errors: ValidationErrorMalformed, Inner: err}
+	}
+	return string(plaintext), nil
+}
+
+// FindEncryptionKey returns the first public key of the set which can be used with the given key management
+// algorithm, or nil if there is none.
+func FindEncryptionKey(set *jose.JSONWebKeySet, alg jose.KeyAlgorithm) *jose.JSONWebKey {
+	for i := range set.Keys {
+		key := &set.Keys[i]
+		if key.Use != "" && key.Use != "enc" {
+			continue
+		} else if key.Algorithm != "" && key.Algorithm != string(alg) {
+			continue
+		}
+
+		switch key.Key.(type) {
+		case *rsa.PublicKey:
+			if strings.HasPrefix(string(alg), "RSA") {
+				return key
+			}
+		case *ecdsa.PublicKey:
+			if strings.HasPrefix(string(alg), "ECDH-ES") {
+				return key
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite/internal/gen"
+)
+
+func TestEncryptingSigner(t *testing.T) {
+	ctx := context.Background()
+	signingKey := gen.MustRSAKey()
+	recipientKey := gen.MustES256Key()
+	signer := &DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return signingKey, nil }}
+	decrypter := &DefaultDecrypter{GetPrivateKeys: func(context.Context) (*jose.JSONWebKeySet, error) {
+		return &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: recipientKey, KeyID: "recipient", Use: "enc"}}}, nil
+	}}
+
+	encrypting := &EncryptingSigner{
+		Signer:        signer,
+		EncryptionKey: &jose.JSONWebKey{Key: &recipientKey.PublicKey, KeyID: "recipient", Algorithm: string(jose.ECDH_ES_A128KW)},
+		Decrypter:     decrypter,
+	}
+
+	token, sig, err := encrypting.Generate(ctx, MapClaims{"sub": "peter"}, NewHeaders())
+	require.NoError(t, err)
+	require.True(t, IsEncrypted(token))
+
+	jwe, err := jose.ParseEncrypted(token)
+	require.NoError(t, err)
+	assert.Equal(t, string(jose.ECDH_ES_A128KW), jwe.Header.Algorithm)
+	assert.EqualValues(t, "A128CBC-HS256", jwe.Header.ExtraHeaders["enc"])
+	assert.EqualValues(t, "JWT", jwe.Header.ExtraHeaders["cty"])
+
+	decoded, err := encrypting.Decode(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "peter", decoded.Claims["sub"])
+
+	validated, err := encrypting.Validate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, sig, validated)
+
+	actual, err := encrypting.GetSignature(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, sig, actual)
+
+	// Without an encryption key, tokens are only signed.
+	signed, _, err := (&EncryptingSigner{Signer: signer, Decrypter: decrypter}).Generate(ctx, MapClaims{"sub": "peter"}, NewHeaders())
+	require.NoError(t, err)
+	assert.False(t, IsEncrypted(signed))
+
+	// Without a decrypter, nested tokens can not be decoded.
+	_, err = (&EncryptingSigner{Signer: signer}).Decode(ctx, token)
+	var ve *ValidationError
+	require.ErrorAs(t, err, &ve)
+	assert.True(t, ve.Has(ValidationErrorMalformed))
+}
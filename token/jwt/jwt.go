@@ -34,9 +34,16 @@ var SHA256HashSize = crypto.SHA256.Size()
 
 type GetPrivateKeyFunc func(ctx context.Context) (interface{}, error)
 
+// GetPublicKeysFunc returns the public keys tokens are verified with.
+type GetPublicKeysFunc func(ctx context.Context) (*jose.JSONWebKeySet, error)
+
 // DefaultSigner is responsible for generating and validating JWT challenges
 type DefaultSigner struct {
 	GetPrivateKey GetPrivateKeyFunc
+
+	// GetPublicKeys returns the public keys tokens are verified with, for example the keys of a KeyManager. If nil,
+	// tokens are verified with the public key of the private key.
+	GetPublicKeys GetPublicKeysFunc
 }
 
 // Generate generates a new authorize code or returns an error. set secret
@@ -48,13 +55,13 @@ func (j *DefaultSigner) Generate(ctx context.Context, claims MapClaims, header M
 
 	switch t := key.(type) {
 	case *jose.JSONWebKey:
-		return generateToken(claims, header, jose.SignatureAlgorithm(t.Algorithm), t.Key)
+		return generateToken(claims, header, jose.SignatureAlgorithm(t.Algorithm), t.Key, t.KeyID)
 	case jose.JSONWebKey:
-		return generateToken(claims, header, jose.SignatureAlgorithm(t.Algorithm), t.Key)
+		return generateToken(claims, header, jose.SignatureAlgorithm(t.Algorithm), t.Key, t.KeyID)
 	case *rsa.PrivateKey:
-		return generateToken(claims, header, jose.RS256, t)
+		return generateToken(claims, header, jose.RS256, t, "")
 	case *ecdsa.PrivateKey:
-		return generateToken(claims, header, jose.ES256, t)
+		return generateToken(claims, header, jose.ES256, t, "")
 	case jose.OpaqueSigner:
 		switch tt := t.Public().Key.(type) {
 		case *rsa.PrivateKey:
@@ -63,14 +70,14 @@ func (j *DefaultSigner) Generate(ctx context.Context, claims MapClaims, header M
 				alg = t.Algs()[0]
 			}
 
-			return generateToken(claims, header, alg, t)
+			return generateToken(claims, header, alg, t, t.Public().KeyID)
 		case *ecdsa.PrivateKey:
 			alg := jose.ES256
 			if len(t.Algs()) > 0 {
 				alg = t.Algs()[0]
 			}
 
-			return generateToken(claims, header, alg, t)
+			return generateToken(claims, header, alg, t, t.Public().KeyID)
 		default:
 			return "", "", errors.Errorf("unsupported private / public key pairs: %T, %T", t, tt)
 		}
@@ -81,6 +88,13 @@ func (j *DefaultSigner) Generate(ctx context.Context, claims MapClaims, header M
 
 // Validate validates a token and returns its signature or an error if the token is not valid.
 func (j *DefaultSigner) Validate(ctx context.Context, token string) (string, error) {
+	if j.GetPublicKeys != nil {
+		if _, err := j.decodeWithPublicKeys(ctx, token); err != nil {
+			return "", err
+		}
+		return getTokenSignature(token)
+	}
+
 	key, err := j.GetPrivateKey(ctx)
 	if err != nil {
 		return "", err
@@ -104,6 +118,10 @@ func (j *DefaultSigner) Validate(ctx context.Context, token string) (string, err
 
 // Decode will decode a JWT token
 func (j *DefaultSigner) Decode(ctx context.Context, token string) (*Token, error) {
+	if j.GetPublicKeys != nil {
+		return j.decodeWithPublicKeys(ctx, token)
+	}
+
 	key, err := j.GetPrivateKey(ctx)
 	if err != nil {
 		return nil, err
@@ -125,6 +143,33 @@ func (j *DefaultSigner) Decode(ctx context.Context, token string) (*Token, error
 	}
 }
 
+// decodeWithPublicKeys decodes the token using the public key identified by its "kid" header, or tries all public
+// keys if the token has none.
+func (j *DefaultSigner) decodeWithPublicKeys(ctx context.Context, token string) (*Token, error) {
+	set, err := j.GetPublicKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []jose.JSONWebKey
+	if parsed, err := jose.ParseSigned(token); err == nil && len(parsed.Signatures) == 1 && parsed.Signatures[0].Header.KeyID != "" {
+		candidates = set.Key(parsed.Signatures[0].Header.KeyID)
+	} else {
+		candidates = set.Keys
+	}
+
+	if len(candidates) == 0 {
+		return nil, &ValidationError{Errors: ValidationErrorUnverifiable, Inner: errors.New("no public key found to verify the token")}
+	}
+
+	for _, key := range candidates[:len(candidates)-1] {
+		if decoded, err := decodeToken(token, key.Key); err == nil {
+			return decoded, nil
+		}
+	}
+	return decodeToken(token, candidates[len(candidates)-1].Key)
+}
+
 // GetSignature will return the signature of a token
 func (j *DefaultSigner) GetSignature(ctx context.Context, token string) (string, error) {
 	return getTokenSignature(token)
@@ -140,7 +185,7 @@ func (j *DefaultSigner) GetSigningMethodLength(ctx context.Context) int {
 	return SHA256HashSize
 }
 
-func generateToken(claims MapClaims, header Mapper, signingMethod jose.SignatureAlgorithm, privateKey interface{}) (rawToken string, sig string, err error) {
+func generateToken(claims MapClaims, header Mapper, signingMethod jose.SignatureAlgorithm, privateKey interface{}, keyID string) (rawToken string, sig string, err error) {
 	if header == nil || claims == nil {
 		err = errors.New("either claims or header is nil")
 		return
@@ -148,6 +193,9 @@ func generateToken(claims MapClaims, header Mapper, signingMethod jose.Signature
 
 	token := NewWithClaims(signingMethod, claims)
 	token.Header = assign(token.Header, header.ToMap())
+	if _, ok := token.Header["kid"]; !ok && keyID != "" {
+		token.Header["kid"] = keyID
+	}
 
 	rawToken, err = token.SignedString(privateKey)
 	if err != nil {
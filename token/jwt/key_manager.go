@@ -0,0 +1,441 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/google/uuid"
+	"github.com/ory/x/errorsx"
+	"github.com/pkg/errors"
+)
+
+// KeyState is the state of a signing key managed by the KeyManager.
+type KeyState string
+
+const (
+	// KeyStateNext keys are published, so that relying parties know them before they are used, but do not sign
+	// tokens yet.
+	KeyStateNext KeyState = "next"
+
+	// KeyStateActive keys are published and verify tokens. The most recently activated key signs tokens.
+	KeyStateActive KeyState = "active"
+
+	// KeyStateRetired keys are neither published nor used to verify tokens.
+	KeyStateRetired KeyState = "retired"
+)
+
+// ManagedKey is a signing key managed by the KeyManager.
+type ManagedKey struct {
+	// Key is the private JSON Web Key. Its KeyID and Algorithm must be set.
+	Key *jose.JSONWebKey `json:"key"`
+
+	State       KeyState  `json:"state"`
+	CreatedAt   time.Time `json:"created_at"`
+	ActivatedAt time.Time `json:"activated_at,omitempty"`
+	RetiredAt   time.Time `json:"retired_at,omitempty"`
+}
+
+// ErrKeySetModified is returned by KeyStorage.UpdateSigningKeys if the signing keys were modified since they were
+// read.
+var ErrKeySetModified = errors.New("the signing keys were modified concurrently")
+
+// KeyStorage stores the signing keys of the KeyManager. Several KeyManagers, for example of different instances of
+// the authorization server, may share the storage.
+type KeyStorage interface {
+	// GetSigningKeys returns all signing keys, including the retired ones, and the version of the key set.
+	GetSigningKeys(ctx context.Context) (keys []ManagedKey, version int64, err error)
+
+	// UpdateSigningKeys replaces all signing keys if the key set still has the given version, and returns
+	// ErrKeySetModified otherwise.
+	UpdateSigningKeys(ctx context.Context, keys []ManagedKey, version int64) error
+}
+
+// KeyGenerator generates a new private signing key. The KeyID and Algorithm of the key must be set.
+type KeyGenerator func(ctx context.Context) (*jose.JSONWebKey, error)
+
+// KeyManager manages the signing keys of a DefaultSigner and rotates them. It keeps an active key which signs tokens
+// and a next key which is published ahead of time, so that relying parties caching the public JSON Web Key Set
+// already know it when it becomes active. Keys superseded by a rotation keep verifying tokens for the
+// RetirementDelay before they are retired.
+//
+// Signing and verifying tokens only reads the keys, which are cached for the CacheTTL. The keys are rotated by
+// calling Rotate periodically, for example every minute. Updates of the keys are compare-and-swap operations on the
+// KeyStorage, so several instances may share the storage and rotate the keys concurrently.
+type KeyManager struct {
+	Storage KeyStorage
+
+	// GenerateKey generates new signing keys. Defaults to GenerateRSAKey.
+	GenerateKey KeyGenerator
+
+	// RotationInterval is the time a key signs tokens before Rotate supersedes it by the next key. If zero, keys are
+	// only rotated by calling RotateNow.
+	RotationInterval time.Duration
+
+	// RetirementDelay is the time a superseded key keeps verifying tokens. It should exceed the lifespan of the
+	// tokens signed with the key.
+	RetirementDelay time.Duration
+
+	// JWKSCacheMaxAge is the time relying parties may cache the public JSON Web Key Set. Defaults to one hour and
+	// never exceeds the RotationInterval.
+	JWKSCacheMaxAge time.Duration
+
+	// CacheTTL is the time the keys are cached before they are read from the Storage again. Changes of other
+	// instances, including retired keys, take effect after at most this time. Defaults to one minute.
+	CacheTTL time.Duration
+
+	cached   []ManagedKey
+	cachedAt time.Time
+	mu       sync.RWMutex
+}
+
+// maxKeyUpdateAttempts is the number of times an update of the keys is retried after a concurrent modification.
+const maxKeyUpdateAttempts = 5
+
+// GenerateRSAKey generates a 2048 bit RSA key for the RS256 algorithm.
+func GenerateRSAKey(_ context.Context) (*jose.JSONWebKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, errorsx.WithStack(err)
+	}
+	return &jose.JSONWebKey{Key: key, KeyID: uuid.New().String(), Algorithm: string(jose.RS256), Use: "sig"}, nil
+}
+
+// NewSigner returns a DefaultSigner which signs tokens with the active key and verifies them with all keys which
+// are not retired.
+func (m *KeyManager) NewSigner() *DefaultSigner {
+	return &DefaultSigner{GetPrivateKey: m.GetPrivateKey, GetPublicKeys: m.GetPublicKeys}
+}
+
+// GetPrivateKey returns the active key as *jose.JSONWebKey. It can be used as GetPrivateKeyFunc.
+func (m *KeyManager) GetPrivateKey(ctx context.Context) (interface{}, error) {
+	keys, err := m.getKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active := signingKey(keys)
+	if active == nil {
+		return nil, errors.New("no active signing key available")
+	}
+	return active.Key, nil
+}
+
+// GetPublicKeys returns the public keys of the active and next keys, starting with the key which signs tokens.
+func (m *KeyManager) GetPublicKeys(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	keys, err := m.getKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &jose.JSONWebKeySet{}
+	for _, k := range keys {
+		if k.State != KeyStateRetired {
+			set.Keys = append(set.Keys, k.Key.Public())
+		}
+	}
+	return set, nil
+}
+
+// Rotate rotates the keys on schedule: it activates the next key if the signing key is older than the
+// RotationInterval, retires keys which were superseded longer than the RetirementDelay ago and generates missing
+// keys. It must be called periodically.
+func (m *KeyManager) Rotate(ctx context.Context) error {
+	_, err := m.update(ctx, func(keys []ManagedKey) ([]ManagedKey, error) {
+		active := signingKey(keys)
+		return m.rotate(ctx, keys, active != nil && m.RotationInterval > 0 && !active.ActivatedAt.Add(m.RotationInterval).After(time.Now().UTC()))
+	})
+	return err
+}
+
+// RotateNow activates the next key immediately and generates a new next key.
+func (m *KeyManager) RotateNow(ctx context.Context) error {
+	_, err := m.update(ctx, func(keys []ManagedKey) ([]ManagedKey, error) {
+		return m.rotate(ctx, keys, true)
+	})
+	return err
+}
+
+// RetireKey retires the key immediately, for example because it was compromised. If it was the key which signs
+// tokens, the next key is activated, superseding all other active keys. Other instances stop using the key after at
+// most the CacheTTL.
+func (m *KeyManager) RetireKey(ctx context.Context, kid string) error {
+	_, err := m.update(ctx, func(keys []ManagedKey) ([]ManagedKey, error) {
+		keys = append([]ManagedKey{}, keys...)
+		signing := signingKey(keys)
+		isSigningKey := signing != nil && signing.Key.KeyID == kid
+
+		var found bool
+		for i := range keys {
+			if keys[i].Key.KeyID == kid && keys[i].State != KeyStateRetired {
+				keys[i].State = KeyStateRetired
+				keys[i].RetiredAt = time.Now().UTC()
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("signing key with kid '%s' not found", kid)
+		}
+
+		return m.rotate(ctx, keys, isSigningKey)
+	})
+	return err
+}
+
+// JWKSHandler returns a http.Handler which serves the public JSON Web Key Set, for example at
+// "/.well-known/jwks.json".
+func (m *KeyManager) JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "HEAD" {
+			rw.Header().Set("Allow", "GET, HEAD")
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		set, err := m.GetPublicKeys(r.Context())
+		if err != nil {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		js, err := json.Marshal(set)
+		if err != nil {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		maxAge := m.JWKSCacheMaxAge
+		if maxAge == 0 {
+			maxAge = time.Hour
+		}
+		if m.RotationInterval > 0 && maxAge > m.RotationInterval {
+			maxAge = m.RotationInterval
+		}
+
+		rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+		rw.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write(js)
+	})
+}
+
+// getKeys returns the cached keys, or loads them if the cache expired. If there is no signing key yet, the keys are
+// created.
+func (m *KeyManager) getKeys(ctx context.Context) ([]ManagedKey, error) {
+	m.mu.RLock()
+	keys, cachedAt := m.cached, m.cachedAt
+	m.mu.RUnlock()
+
+	ttl := m.CacheTTL
+	if ttl == 0 {
+		ttl = time.Minute
+	}
+	if keys != nil && time.Since(cachedAt) < ttl {
+		return keys, nil
+	}
+
+	keys, _, err := m.Storage.GetSigningKeys(ctx)
+	if err != nil {
+		return nil, err
+	} else if signingKey(keys) == nil {
+		return m.update(ctx, func(keys []ManagedKey) ([]ManagedKey, error) {
+			return m.rotate(ctx, keys, false)
+		})
+	}
+
+	m.setCache(keys)
+	return keys, nil
+}
+
+// update applies the change to the stored keys and caches the result. If the keys were modified concurrently, they
+// are read again and the change is applied to them.
+func (m *KeyManager) update(ctx context.Context, change func(keys []ManagedKey) ([]ManagedKey, error)) ([]ManagedKey, error) {
+	for attempt := 1; ; attempt++ {
+		keys, version, err := m.Storage.GetSigningKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		changed, err := change(keys)
+		if err != nil {
+			return nil, err
+		}
+
+		if !keysEqual(keys, changed) {
+			if err := m.Storage.UpdateSigningKeys(ctx, changed, version); errors.Is(err, ErrKeySetModified) && attempt < maxKeyUpdateAttempts {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+		}
+
+		m.setCache(changed)
+		return changed, nil
+	}
+}
+
+func (m *KeyManager) setCache(keys []ManagedKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cached, m.cachedAt = keys, time.Now()
+}
+
+// rotate ensures that there is an active and a next key, activates the next key if there is no active key or
+// activate is set, and retires superseded keys after the RetirementDelay. It returns the keys sorted by state and
+// activation time.
+func (m *KeyManager) rotate(ctx context.Context, keys []ManagedKey, activate bool) ([]ManagedKey, error) {
+	now := time.Now().UTC()
+	keys = append([]ManagedKey{}, keys...)
+
+	if signingKey(keys) == nil || activate {
+		next := -1
+		for i := range keys {
+			if keys[i].State == KeyStateNext {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			key, err := m.generateKey(ctx, now)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+			next = len(keys) - 1
+		}
+
+		keys[next].State = KeyStateActive
+		keys[next].ActivatedAt = now
+	}
+
+	var hasNext bool
+	for _, k := range keys {
+		hasNext = hasNext || k.State == KeyStateNext
+	}
+	if !hasNext {
+		key, err := m.generateKey(ctx, now)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	// A key is superseded when the next key is activated after it. That key may have been retired since, so retired
+	// keys are considered as well.
+	signing := signingKey(keys)
+	for i := range keys {
+		if keys[i].State != KeyStateActive || keys[i].Key.KeyID == signing.Key.KeyID {
+			continue
+		}
+		if at := supersededAt(keys, keys[i]); !at.IsZero() && !at.Add(m.RetirementDelay).After(now) {
+			keys[i].State = KeyStateRetired
+			keys[i].RetiredAt = now
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].State != keys[j].State {
+			return keyStateOrder[keys[i].State] < keyStateOrder[keys[j].State]
+		}
+		return keys[i].ActivatedAt.After(keys[j].ActivatedAt)
+	})
+
+	return keys, nil
+}
+
+func (m *KeyManager) generateKey(ctx context.Context, now time.Time) (ManagedKey, error) {
+	generate := m.GenerateKey
+	if generate == nil {
+		generate = GenerateRSAKey
+	}
+
+	key, err := generate(ctx)
+	if err != nil {
+		return ManagedKey{}, err
+	} else if key.KeyID == "" || key.Algorithm == "" {
+		return ManagedKey{}, errors.New("generated signing keys must have a key ID and an algorithm")
+	}
+
+	return ManagedKey{Key: key, State: KeyStateNext, CreatedAt: now}, nil
+}
+
+var keyStateOrder = map[KeyState]int{KeyStateActive: 0, KeyStateNext: 1, KeyStateRetired: 2}
+
+// signingKey returns the most recently activated key.
+func signingKey(keys []ManagedKey) *ManagedKey {
+	var active *ManagedKey
+	for i := range keys {
+		if keys[i].State == KeyStateActive && (active == nil || keys[i].ActivatedAt.After(active.ActivatedAt)) {
+			active = &keys[i]
+		}
+	}
+	return active
+}
+
+// supersededAt returns the time the key was superseded, which is when the next key was activated after it, or the
+// zero time if it was not superseded.
+func supersededAt(keys []ManagedKey, key ManagedKey) time.Time {
+	var at time.Time
+	for _, k := range keys {
+		if !k.ActivatedAt.IsZero() && k.ActivatedAt.After(key.ActivatedAt) && (at.IsZero() || k.ActivatedAt.Before(at)) {
+			at = k.ActivatedAt
+		}
+	}
+	return at
+}
+
+func keysEqual(a, b []ManagedKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key.KeyID != b[i].Key.KeyID || a[i].State != b[i].State || !a[i].ActivatedAt.Equal(b[i].ActivatedAt) {
+			return false
+		}
+	}
+	return true
+}
+
+// MemoryKeyStorage is an in-memory KeyStorage.
+type MemoryKeyStorage struct {
+	keys    []ManagedKey
+	version int64
+	mu      sync.RWMutex
+}
+
+// NewMemoryKeyStorage returns an empty in-memory KeyStorage.
+func NewMemoryKeyStorage() *MemoryKeyStorage {
+	return &MemoryKeyStorage{}
+}
+
+func (s *MemoryKeyStorage) GetSigningKeys(_ context.Context) ([]ManagedKey, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]ManagedKey{}, s.keys...), s.version, nil
+}
+
+func (s *MemoryKeyStorage) UpdateSigningKeys(_ context.Context, keys []ManagedKey, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.version != version {
+		return errorsx.WithStack(ErrKeySetModified)
+	}
+
+	s.keys = append([]ManagedKey{}, keys...)
+	s.version++
+	return nil
+}
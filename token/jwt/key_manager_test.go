@@ -0,0 +1,272 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/fosite/internal/gen"
+)
+
+func TestKeyManager(t *testing.T) {
+	ctx := context.Background()
+
+	var generated int
+	newKeyManager := func() *KeyManager {
+		return &KeyManager{
+			Storage:         NewMemoryKeyStorage(),
+			RetirementDelay: time.Hour,
+			GenerateKey: func(_ context.Context) (*jose.JSONWebKey, error) {
+				generated++
+				return &jose.JSONWebKey{Key: gen.MustES256Key(), KeyID: fmt.Sprintf("key-%d", generated), Algorithm: string(jose.ES256), Use: "sig"}, nil
+			},
+		}
+	}
+
+	sign := func(t *testing.T, signer Signer) (string, string) {
+		token, _, err := signer.Generate(ctx, MapClaims{"sub": "foo"}, &Headers{})
+		require.NoError(t, err)
+		parsed, err := jose.ParseSigned(token)
+		require.NoError(t, err)
+		return token, parsed.Signatures[0].Header.KeyID
+	}
+
+	kids := func(t *testing.T, m *KeyManager) []string {
+		set, err := m.GetPublicKeys(ctx)
+		require.NoError(t, err)
+		var kids []string
+		for _, k := range set.Keys {
+			assert.True(t, k.IsPublic())
+			kids = append(kids, k.KeyID)
+		}
+		return kids
+	}
+
+	t.Run("case=should create the active and next key and sign with the active key", func(t *testing.T) {
+		m := newKeyManager()
+		signer := m.NewSigner()
+
+		published := kids(t, m)
+		require.Len(t, published, 2)
+
+		token, kid := sign(t, signer)
+		assert.Equal(t, published[0], kid)
+
+		_, err := signer.Validate(ctx, token)
+		require.NoError(t, err)
+		decoded, err := signer.Decode(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", decoded.Claims["sub"])
+	})
+
+	t.Run("case=should keep verifying with superseded keys until they are retired", func(t *testing.T) {
+		m := newKeyManager()
+		signer := m.NewSigner()
+		published := kids(t, m)
+
+		token, _ := sign(t, signer)
+		require.NoError(t, m.RotateNow(ctx))
+
+		_, kid := sign(t, signer)
+		assert.Equal(t, published[1], kid)
+		assert.Len(t, kids(t, m), 3)
+
+		_, err := signer.Validate(ctx, token)
+		require.NoError(t, err)
+
+		m.RetirementDelay = 0
+		require.NoError(t, m.RotateNow(ctx))
+		assert.NotContains(t, kids(t, m), published[0])
+
+		_, err = signer.Validate(ctx, token)
+		require.Error(t, err)
+	})
+
+	t.Run("case=should rotate the keys on schedule", func(t *testing.T) {
+		m := newKeyManager()
+		m.RotationInterval = time.Hour
+		published := kids(t, m)
+
+		require.NoError(t, m.Rotate(ctx))
+		_, kid := sign(t, m.NewSigner())
+		assert.Equal(t, published[0], kid, "the signing key must not be rotated before the rotation interval")
+
+		keys, version, err := m.Storage.GetSigningKeys(ctx)
+		require.NoError(t, err)
+		for i := range keys {
+			keys[i].ActivatedAt = keys[i].ActivatedAt.Add(-2 * time.Hour)
+		}
+		require.NoError(t, m.Storage.UpdateSigningKeys(ctx, keys, version))
+
+		require.NoError(t, m.Rotate(ctx))
+		_, kid = sign(t, m.NewSigner())
+		assert.Equal(t, published[1], kid)
+	})
+
+	t.Run("case=should retire a compromised key", func(t *testing.T) {
+		m := newKeyManager()
+		signer := m.NewSigner()
+		published := kids(t, m)
+
+		token, _ := sign(t, signer)
+		require.NoError(t, m.RetireKey(ctx, published[0]))
+		require.Error(t, m.RetireKey(ctx, "unknown"))
+
+		_, kid := sign(t, signer)
+		assert.Equal(t, published[1], kid)
+		assert.NotContains(t, kids(t, m), published[0])
+
+		_, err := signer.Validate(ctx, token)
+		require.Error(t, err)
+	})
+
+	t.Run("case=should activate the next key when the signing key is retired", func(t *testing.T) {
+		m := newKeyManager()
+		signer := m.NewSigner()
+		first := kids(t, m)
+
+		firstToken, _ := sign(t, signer)
+		require.NoError(t, m.RotateNow(ctx))
+		second := kids(t, m)
+		require.Len(t, second, 3)
+		assert.Equal(t, first[1], second[0])
+		next := second[2]
+
+		require.NoError(t, m.RetireKey(ctx, second[0]))
+
+		_, kid := sign(t, signer)
+		assert.Equal(t, next, kid, "the next key must sign tokens, not the superseded key")
+		assert.NotContains(t, kids(t, m), second[0])
+		assert.Contains(t, kids(t, m), first[0], "the superseded key must keep verifying tokens until it is retired")
+
+		_, err := signer.Validate(ctx, firstToken)
+		require.NoError(t, err)
+
+		// The superseded key was superseded when the retired key was activated, not when the next key was.
+		keys, version, err := m.Storage.GetSigningKeys(ctx)
+		require.NoError(t, err)
+		for i := range keys {
+			if keys[i].Key.KeyID != next {
+				keys[i].ActivatedAt = keys[i].ActivatedAt.Add(-2 * time.Hour)
+			}
+		}
+		require.NoError(t, m.Storage.UpdateSigningKeys(ctx, keys, version))
+		require.NoError(t, m.Rotate(ctx))
+
+		published := kids(t, m)
+		assert.NotContains(t, published, first[0])
+		assert.Equal(t, next, published[0])
+	})
+
+	t.Run("case=should cache the keys", func(t *testing.T) {
+		m := newKeyManager()
+		storage := &countingKeyStorage{KeyStorage: m.Storage}
+		m.Storage = storage
+		published := kids(t, m)
+
+		reads := storage.reads
+		_, kid := sign(t, m.NewSigner())
+		assert.Equal(t, published[0], kid)
+		assert.Equal(t, reads, storage.reads)
+
+		other := newKeyManager()
+		other.Storage = m.Storage
+		require.NoError(t, other.RotateNow(ctx))
+		_, kid = sign(t, m.NewSigner())
+		assert.Equal(t, published[0], kid, "the cached keys must be used until the cache expires")
+
+		m.CacheTTL = time.Nanosecond
+		_, kid = sign(t, m.NewSigner())
+		assert.Equal(t, published[1], kid)
+	})
+
+	t.Run("case=should apply the rotation to the keys of a concurrent update", func(t *testing.T) {
+		m := newKeyManager()
+		published := kids(t, m)
+
+		other := newKeyManager()
+		other.Storage = m.Storage
+		m.Storage = &conflictingKeyStorage{KeyStorage: m.Storage, modify: func() {
+			require.NoError(t, other.RotateNow(ctx))
+		}}
+		require.NoError(t, m.RotateNow(ctx))
+
+		keys, _, err := m.Storage.GetSigningKeys(ctx)
+		require.NoError(t, err)
+		var states []KeyState
+		for _, k := range keys {
+			states = append(states, k.State)
+		}
+		assert.Equal(t, []KeyState{KeyStateActive, KeyStateActive, KeyStateActive, KeyStateNext}, states, "both rotations must be stored")
+		assert.Equal(t, published[0], keys[2].Key.KeyID)
+	})
+
+	t.Run("case=should fail if the keys keep being modified concurrently", func(t *testing.T) {
+		m := newKeyManager()
+		kids(t, m)
+
+		other := newKeyManager()
+		other.Storage = m.Storage
+		storage := &conflictingKeyStorage{KeyStorage: m.Storage, always: true, modify: func() {
+			require.NoError(t, other.RotateNow(ctx))
+		}}
+		m.Storage = storage
+		assert.ErrorIs(t, m.RotateNow(ctx), ErrKeySetModified)
+		assert.Equal(t, maxKeyUpdateAttempts, storage.updates)
+	})
+
+	t.Run("case=should serve the public keys", func(t *testing.T) {
+		m := newKeyManager()
+		m.RotationInterval = 30 * time.Minute
+
+		rw := httptest.NewRecorder()
+		m.JWKSHandler().ServeHTTP(rw, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, "public, max-age=1800", rw.Header().Get("Cache-Control"))
+
+		var set jose.JSONWebKeySet
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &set))
+		require.Len(t, set.Keys, 2)
+		for _, k := range set.Keys {
+			assert.True(t, k.IsPublic())
+		}
+		assert.NotContains(t, rw.Body.String(), `"d":`)
+	})
+}
+
+type countingKeyStorage struct {
+	KeyStorage
+	reads int
+}
+
+func (s *countingKeyStorage) GetSigningKeys(ctx context.Context) ([]ManagedKey, int64, error) {
+	s.reads++
+	return s.KeyStorage.GetSigningKeys(ctx)
+}
+
+// conflictingKeyStorage modifies the keys before the first update, or before every update if always is set.
+type conflictingKeyStorage struct {
+	KeyStorage
+	modify  func()
+	always  bool
+	updates int
+}
+
+func (s *conflictingKeyStorage) UpdateSigningKeys(ctx context.Context, keys []ManagedKey, version int64) error {
+	s.updates++
+	if s.always || s.updates == 1 {
+		s.modify()
+	}
+	return s.KeyStorage.UpdateSigningKeys(ctx, keys, version)
+}
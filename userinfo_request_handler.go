@@ -0,0 +1,136 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/otelx"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/fosite/token/jwt"
+)
+
+// UserinfoSession is implemented by sessions which contain the claims about the end-user, such as openid.Session.
+type UserinfoSession interface {
+	// IDTokenClaims returns the claims of the ID token. The claims about the end-user are taken from its Subject and
+	// Extra fields.
+	IDTokenClaims() *jwt.IDTokenClaims
+
+	Session
+}
+
+// UserinfoResponse contains the claims about the end-user returned by the UserInfo endpoint.
+type UserinfoResponse struct {
+	// Claims are the claims about the end-user the access token grants access to.
+	Claims map[string]interface{}
+
+	// AccessRequester is the request the access token was issued for.
+	AccessRequester AccessRequester
+}
+
+// GetClient returns the client the access token was issued to.
+func (r *UserinfoResponse) GetClient() Client {
+	if r.AccessRequester == nil {
+		return nil
+	}
+	return r.AccessRequester.GetClient()
+}
+
+// userinfoScopeClaims maps the scopes to the claims they grant access to, see
+// https://openid.net/specs/openid-connect-core-1_0.html#ScopeClaims
+var userinfoScopeClaims = map[string][]string{
+	"profile": {
+		"name", "family_name", "given_name", "middle_name", "nickname", "preferred_username", "profile", "picture",
+		"website", "gender", "birthdate", "zoneinfo", "locale", "updated_at",
+	},
+	"email":   {"email", "email_verified"},
+	"address": {"address"},
+	"phone":   {"phone_number", "phone_number_verified"},
+}
+
+// NewUserinfoRequest validates the access token of a request to the UserInfo endpoint and returns the claims about
+// the end-user the granted scopes give access to, see https://openid.net/specs/openid-connect-core-1_0.html#UserInfo
+//
+// The session is used to decode the access token and must be an UserinfoSession.
+func (f *Fosite) NewUserinfoRequest(ctx context.Context, r *http.Request, session Session) (_ *UserinfoResponse, err error) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ory/fosite").Start(ctx, "Fosite.NewUserinfoRequest")
+	defer otelx.End(span, &err)
+
+	if r.Method != "GET" && r.Method != "POST" {
+		return nil, errorsx.WithStack(ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'GET' or 'POST'.", r.Method))
+	}
+
+	token := AccessTokenFromRequest(r)
+	if token == "" {
+		return nil, errorsx.WithStack(ErrRequestUnauthorized.WithHint("The request must contain an access token."))
+	}
+
+	tokenUse, ar, err := f.IntrospectToken(ctx, token, AccessToken, session)
+	if err != nil {
+		return nil, err
+	} else if tokenUse != AccessToken {
+		return nil, errorsx.WithStack(ErrRequestUnauthorized.WithHint("The token is not an access token."))
+	}
+
+	// Sender-constrained access tokens must be presented together with the proof of possession of the DPoP key or
+	// client certificate they are bound to, see https://www.rfc-editor.org/rfc/rfc9449#section-7.1 and
+	// https://www.rfc-editor.org/rfc/rfc8705#section-3
+	if jkt := GetDPoPJWKThumbprint(ar.GetSession()); jkt != "" {
+		if scheme := strings.SplitN(r.Header.Get("Authorization"), " ", 2)[0]; !strings.EqualFold(scheme, DPoPAccessToken) {
+			return nil, errorsx.WithStack(ErrRequestUnauthorized.WithHintf("The access token is bound to a DPoP key and must be presented using the '%s' authorization scheme.", DPoPAccessToken))
+		} else if err := f.ValidateDPoPProof(ctx, r, "", token, jkt); err != nil {
+			return nil, err
+		}
+	}
+	if err := f.ValidateCertificateBinding(ctx, r, ar.GetSession()); err != nil {
+		return nil, err
+	}
+
+	if !ar.GetGrantedScopes().Has("openid") {
+		return nil, errorsx.WithStack(ErrInsufficientScope.WithHint("The access token was not granted the 'openid' scope."))
+	}
+
+	userinfoSession, ok := ar.GetSession().(UserinfoSession)
+	if !ok {
+		return nil, errorsx.WithStack(ErrServerError.WithDebug("The session must implement UserinfoSession."))
+	}
+
+	idTokenClaims := userinfoSession.IDTokenClaims()
+	if idTokenClaims == nil || idTokenClaims.Subject == "" {
+		return nil, errorsx.WithStack(ErrServerError.WithDebug("The session does not contain the subject of the end-user."))
+	}
+
+	// The sub claim must match the one of the ID tokens, which may be pairwise.
+	subject, err := PairwiseSubject(ctx, f.Config, ar.GetClient(), idTokenClaims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{"sub": subject}
+	for _, scope := range ar.GetGrantedScopes() {
+		for _, claim := range userinfoScopeClaims[scope] {
+			if value, ok := idTokenClaims.Extra[claim]; ok {
+				claims[claim] = value
+			}
+		}
+	}
+
+	// Claims requested through the claims parameter are returned in addition to those of the granted scopes, unless
+	// they do not match the requested value, see https://openid.net/specs/openid-connect-core-1_0.html#ClaimsParameter
+	if requested := GetRequestedClaims(ar.GetSession()); requested != nil {
+		for claim := range requested.UserInfo {
+			if value, ok := idTokenClaims.Extra[claim]; ok {
+				claims[claim] = value
+			}
+		}
+		claims = requested.UserInfo.Filter(claims)
+		claims["sub"] = subject
+	}
+
+	return &UserinfoResponse{Claims: claims, AccessRequester: ar}, nil
+}
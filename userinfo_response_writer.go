@@ -0,0 +1,105 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ory/x/errorsx"
+
+	"github.com/ory/fosite/token/jwt"
+)
+
+// UserinfoJWTContentType is the media type of signed or encrypted UserInfo responses, see
+// https://openid.net/specs/openid-connect-core-1_0.html#UserInfoResponse
+const UserinfoJWTContentType = "application/jwt"
+
+// WriteUserinfoResponse writes the claims about the end-user. The response is plain JSON, unless the client
+// registered a signing or encryption algorithm for UserInfo responses, in which case it is a signed, and optionally
+// encrypted, JWT.
+func (f *Fosite) WriteUserinfoResponse(ctx context.Context, rw http.ResponseWriter, resp *UserinfoResponse) {
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+
+	if client, ok := resp.GetClient().(UserinfoClient); ok && (client.GetUserinfoSignedResponseAlgorithm() != "" || client.GetUserinfoEncryptedResponseAlgorithm() != "") {
+		token, err := f.generateUserinfoJWT(ctx, resp.GetClient(), client, resp.Claims)
+		if err != nil {
+			f.WriteUserinfoError(ctx, rw, err)
+			return
+		}
+
+		rw.Header().Set("Content-Type", UserinfoJWTContentType)
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(token))
+		return
+	}
+
+	js, err := json.Marshal(resp.Claims)
+	if err != nil {
+		f.WriteUserinfoError(ctx, rw, errorsx.WithStack(ErrServerError.WithWrap(err).WithDebug(err.Error())))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(js)
+}
+
+// WriteUserinfoError writes the error of the UserInfo endpoint. Errors caused by the access token are additionally
+// described in the WWW-Authenticate header, see https://openid.net/specs/openid-connect-core-1_0.html#UserInfoError
+func (f *Fosite) WriteUserinfoError(ctx context.Context, rw http.ResponseWriter, err error) {
+	rfcerr := ErrorToRFC6749Error(err)
+
+	switch {
+	case rfcerr.ErrorField == errInvalidDPoPProofName:
+		// https://www.rfc-editor.org/rfc/rfc9449#section-7.1
+		rw.Header().Set("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+	case rfcerr.CodeField == http.StatusUnauthorized:
+		rw.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	case rfcerr.CodeField == http.StatusForbidden:
+		rw.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+	case rfcerr.CodeField == http.StatusBadRequest:
+		rw.Header().Set("WWW-Authenticate", `Bearer error="invalid_request"`)
+	}
+
+	f.writeJsonError(ctx, rw, nil, err)
+}
+
+// userinfoSigner returns the signer of signed UserInfo responses, or nil if the configuration does not implement
+// UserinfoProvider.
+func (f *Fosite) userinfoSigner(ctx context.Context) jwt.Signer {
+	if c, ok := f.Config.(UserinfoProvider); ok {
+		return c.GetUserinfoSigner(ctx)
+	}
+	return nil
+}
+
+// generateUserinfoJWT signs the claims, and encrypts them if the client registered an encryption algorithm. Encrypted
+// responses are always signed, yielding a nested JWT.
+func (f *Fosite) generateUserinfoJWT(ctx context.Context, client Client, userinfoClient UserinfoClient, claims map[string]interface{}) (string, error) {
+	signer := f.userinfoSigner(ctx)
+	if signer == nil {
+		return "", errorsx.WithStack(ErrServerError.WithDebug("The OAuth 2.0 Client requires signed UserInfo responses but no signer is configured."))
+	}
+
+	mapClaims := jwt.MapClaims{}
+	for k, v := range claims {
+		mapClaims[k] = v
+	}
+	mapClaims["aud"] = client.GetID()
+	if issuer := f.Config.GetIDTokenIssuer(ctx); issuer != "" {
+		mapClaims["iss"] = issuer
+	}
+
+	token, err := generateSignedResponse(ctx, signer, mapClaims, jwt.NewHeaders(), userinfoClient.GetUserinfoSignedResponseAlgorithm())
+	if err != nil {
+		return "", err
+	} else if userinfoClient.GetUserinfoEncryptedResponseAlgorithm() == "" {
+		return token, nil
+	}
+
+	return f.encryptResponse(ctx, userinfoClient, token, userinfoClient.GetUserinfoEncryptedResponseAlgorithm(), userinfoClient.GetUserinfoEncryptedResponseEncryption())
+}
@@ -0,0 +1,302 @@
+// Copyright © 2024 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package fosite_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ory/fosite"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/internal"
+	"github.com/ory/fosite/internal/gen"
+	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/token/jwt"
+)
+
+func TestUserinfo(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := gen.MustRSAKey()
+	signer := &jwt.DefaultSigner{GetPrivateKey: func(_ context.Context) (interface{}, error) { return key, nil }}
+
+	var scopes Arguments
+	var client Client
+	introspector := internal.NewMockTokenIntrospector(ctrl)
+	introspector.EXPECT().IntrospectToken(gomock.Any(), "valid-token", AccessToken, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ TokenUse, ar AccessRequester, _ []string) (TokenUse, error) {
+			ar.(*AccessRequest).GrantedScope = scopes
+			ar.(*AccessRequest).Client = client
+			return AccessToken, nil
+		}).AnyTimes()
+	introspector.EXPECT().IntrospectToken(gomock.Any(), "invalid-token", AccessToken, gomock.Any(), gomock.Any()).Return(TokenUse(""), ErrInactiveToken).AnyTimes()
+
+	f := &Fosite{Store: storage.NewMemoryStore(), Config: &Config{
+		IDTokenIssuer:              "https://auth.example.com",
+		UserinfoSigner:             signer,
+		TokenIntrospectionHandlers: TokenIntrospectionHandlers{introspector},
+	}}
+
+	newSession := func() *openid.DefaultSession {
+		session := openid.NewDefaultSession()
+		session.Claims.Subject = "peter"
+		session.Claims.Extra = map[string]interface{}{
+			"name":           "Peter",
+			"email":          "peter@example.com",
+			"email_verified": true,
+			"phone_number":   "+1 555 0100",
+			"internal":       "secret",
+		}
+		return session
+	}
+
+	newRequest := func(token string) *http.Request {
+		r := httptest.NewRequest("GET", "https://auth.example.com/userinfo", nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		return r
+	}
+
+	newClient := func() *DefaultOpenIDConnectClient {
+		return &DefaultOpenIDConnectClient{DefaultClient: &DefaultClient{ID: "foo"}}
+	}
+
+	t.Run("case=should reject invalid requests", func(t *testing.T) {
+		scopes = Arguments{"profile"}
+		client = newClient()
+
+		for k, tc := range []struct {
+			token     string
+			expectErr error
+			header    string
+		}{
+			{token: "", expectErr: ErrRequestUnauthorized, header: `Bearer error="invalid_token"`},
+			{token: "invalid-token", expectErr: ErrInactiveToken, header: `Bearer error="invalid_token"`},
+			{token: "valid-token", expectErr: ErrInsufficientScope, header: `Bearer error="insufficient_scope"`},
+		} {
+			_, err := f.NewUserinfoRequest(ctx, newRequest(tc.token), newSession())
+			require.ErrorIs(t, err, tc.expectErr, "%d", k)
+
+			rw := httptest.NewRecorder()
+			f.WriteUserinfoError(ctx, rw, err)
+			assert.Equal(t, tc.header, rw.Header().Get("WWW-Authenticate"), "%d", k)
+		}
+	})
+
+	t.Run("case=should filter the claims by the granted scopes", func(t *testing.T) {
+		scopes = Arguments{"openid", "email"}
+		client = newClient()
+
+		resp, err := f.NewUserinfoRequest(ctx, newRequest("valid-token"), newSession())
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		f.WriteUserinfoResponse(ctx, rw, resp)
+		require.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, "application/json;charset=UTF-8", rw.Header().Get("Content-Type"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+		assert.Equal(t, map[string]interface{}{"sub": "peter", "email": "peter@example.com", "email_verified": true}, body)
+	})
+
+	t.Run("case=should add the claims requested through the claims parameter", func(t *testing.T) {
+		scopes = Arguments{"openid", "email"}
+		client = newClient()
+
+		session := newSession()
+		session.SetRequestedClaims(&ClaimsRequest{UserInfo: ClaimRequests{
+			"name":           nil,
+			"email_verified": {Value: false},
+		}})
+
+		resp, err := f.NewUserinfoRequest(ctx, newRequest("valid-token"), session)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"sub": "peter", "email": "peter@example.com", "name": "Peter"}, resp.Claims)
+	})
+
+	t.Run("case=should return the pairwise subject", func(t *testing.T) {
+		scopes = Arguments{"openid"}
+		c := newClient()
+		c.RedirectURIs = []string{"https://client.example.com/cb"}
+		c.SubjectType = SubjectTypePairwise
+		client = c
+
+		pf := &Fosite{Config: &Config{
+			PairwiseSubjectAlgorithm:   &HMACPairwiseSubjectAlgorithm{Salt: []byte("salt")},
+			TokenIntrospectionHandlers: TokenIntrospectionHandlers{introspector},
+		}}
+		pairwise, err := PairwiseSubject(ctx, pf.Config, c, "peter")
+		require.NoError(t, err)
+
+		resp, err := pf.NewUserinfoRequest(ctx, newRequest("valid-token"), newSession())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"sub": pairwise}, resp.Claims)
+	})
+
+	t.Run("case=should write a signed response", func(t *testing.T) {
+		scopes = Arguments{"openid", "profile", "phone"}
+		c := newClient()
+		c.UserinfoSignedResponseAlgorithm = "RS256"
+		client = c
+
+		resp, err := f.NewUserinfoRequest(ctx, newRequest("valid-token"), newSession())
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		f.WriteUserinfoResponse(ctx, rw, resp)
+		require.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, UserinfoJWTContentType, rw.Header().Get("Content-Type"))
+
+		token, err := signer.Decode(ctx, rw.Body.String())
+		require.NoError(t, err)
+		assert.Equal(t, "peter", token.Claims["sub"])
+		assert.Equal(t, "Peter", token.Claims["name"])
+		assert.Equal(t, "+1 555 0100", token.Claims["phone_number"])
+		assert.Equal(t, "foo", token.Claims["aud"])
+		assert.Equal(t, "https://auth.example.com", token.Claims["iss"])
+		assert.NotContains(t, token.Claims, "internal")
+		assert.NotContains(t, token.Claims, "email")
+	})
+
+	t.Run("case=should write an encrypted response", func(t *testing.T) {
+		encryptionKey := gen.MustRSAKey()
+		scopes = Arguments{"openid"}
+		c := newClient()
+		c.UserinfoEncryptedResponseAlgorithm = string(jose.RSA_OAEP)
+		c.JSONWebKeys = &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "enc", Use: "enc", Key: &encryptionKey.PublicKey}}}
+		client = c
+
+		resp, err := f.NewUserinfoRequest(ctx, newRequest("valid-token"), newSession())
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		f.WriteUserinfoResponse(ctx, rw, resp)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		jwe, err := jose.ParseEncrypted(rw.Body.String())
+		require.NoError(t, err)
+		nested, err := jwe.Decrypt(encryptionKey)
+		require.NoError(t, err)
+
+		token, err := signer.Decode(ctx, string(nested))
+		require.NoError(t, err)
+		assert.Equal(t, "peter", token.Claims["sub"])
+	})
+
+	t.Run("case=should fail if the client requires another signing algorithm", func(t *testing.T) {
+		scopes = Arguments{"openid"}
+		c := newClient()
+		c.UserinfoSignedResponseAlgorithm = "ES256"
+		client = c
+
+		resp, err := f.NewUserinfoRequest(ctx, newRequest("valid-token"), newSession())
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		f.WriteUserinfoResponse(ctx, rw, resp)
+		assert.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+
+	t.Run("case=should require the proof of possession of DPoP-bound access tokens", func(t *testing.T) {
+		scopes = Arguments{"openid"}
+		client = newClient()
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		hash := sha256.Sum256([]byte("valid-token"))
+		newDPoPRequest := func(scheme string, key *ecdsa.PrivateKey) *http.Request {
+			r := httptest.NewRequest("GET", "https://auth.example.com/userinfo", nil)
+			r.Header.Set("Authorization", scheme+" valid-token")
+			if key != nil {
+				r.Header.Set(DPoPHeader, newDPoPProof(t, key, "dpop+jwt", dpopProof{
+					JTI:             randomJTI(t),
+					HTTPMethod:      "GET",
+					HTTPURI:         "https://auth.example.com/userinfo",
+					IssuedAt:        time.Now().Unix(),
+					AccessTokenHash: base64.RawURLEncoding.EncodeToString(hash[:]),
+				}))
+			}
+			return r
+		}
+		newBoundSession := func() *openid.DefaultSession {
+			session := newSession()
+			session.DPoPJWKThumbprint = dpopThumbprint(t, key)
+			return session
+		}
+
+		for k, tc := range []struct {
+			d         string
+			request   *http.Request
+			expectErr error
+			header    string
+		}{
+			{d: "bearer scheme", request: newDPoPRequest("Bearer", key), expectErr: ErrRequestUnauthorized, header: `Bearer error="invalid_token"`},
+			{d: "missing proof", request: newDPoPRequest("DPoP", nil), expectErr: ErrInvalidDPoPProof, header: `DPoP error="invalid_dpop_proof"`},
+			{d: "proof of another key", request: newDPoPRequest("DPoP", otherKey), expectErr: ErrInvalidDPoPProof, header: `DPoP error="invalid_dpop_proof"`},
+		} {
+			t.Run("case="+tc.d, func(t *testing.T) {
+				_, err := f.NewUserinfoRequest(ctx, tc.request, newBoundSession())
+				require.ErrorIs(t, err, tc.expectErr, "%d", k)
+
+				rw := httptest.NewRecorder()
+				f.WriteUserinfoError(ctx, rw, err)
+				assert.Equal(t, tc.header, rw.Header().Get("WWW-Authenticate"), "%d", k)
+			})
+		}
+
+		resp, err := f.NewUserinfoRequest(ctx, newDPoPRequest("DPoP", key), newBoundSession())
+		require.NoError(t, err)
+		assert.Equal(t, "peter", resp.Claims["sub"])
+	})
+
+	t.Run("case=should require the certificate of certificate-bound access tokens", func(t *testing.T) {
+		scopes = Arguments{"openid"}
+		client = newClient()
+
+		cert := newTestCertificate(t, "client")
+		newTLSRequest := func(certs ...*x509.Certificate) *http.Request {
+			r := newRequest("valid-token")
+			r.TLS = &tls.ConnectionState{PeerCertificates: certs}
+			return r
+		}
+		newBoundSession := func() *openid.DefaultSession {
+			session := newSession()
+			session.CertificateThumbprint = CertificateThumbprint(cert)
+			return session
+		}
+
+		_, err := f.NewUserinfoRequest(ctx, newTLSRequest(), newBoundSession())
+		require.ErrorIs(t, err, ErrRequestUnauthorized)
+
+		_, err = f.NewUserinfoRequest(ctx, newTLSRequest(newTestCertificate(t, "other")), newBoundSession())
+		require.ErrorIs(t, err, ErrRequestUnauthorized)
+
+		resp, err := f.NewUserinfoRequest(ctx, newTLSRequest(cert), newBoundSession())
+		require.NoError(t, err)
+		assert.Equal(t, "peter", resp.Claims["sub"])
+	})
+}